@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sakura/duofm/internal/ui"
+)
+
+// lastDirEnv names the variable that gives the last directory file when
+// --last-dir-file is not used
+const lastDirEnv = "DUOFM_LAST_DIR_FILE"
+
+// options are the parsed command-line arguments
+type options struct {
+	version    bool
+	configPath string
+	noConfig   bool
+	start      ui.StartOptions
+	chooseMode ui.ChooseMode
+	chooseFile string // File to write the chosen paths to ("-" = stdout)
+	lastDir    string // File to write the final directory to ("" = none)
+}
+
+const usageText = `Usage: duofm [options] [LEFT_DIR [RIGHT_DIR]]
+
+Start duofm with LEFT_DIR and RIGHT_DIR in the left and right panes.
+Without directories the panes of the previous session are restored.
+
+Options:
+  --config PATH   read the configuration from PATH
+  --no-config     ignore the configuration file and use the defaults
+  --select FILE   open the directory of FILE in the left pane with the cursor on it
+  --hidden        show hidden files
+  --sort FIELD[:ORDER]
+                  sort by name, size or date, in asc or desc order (e.g. size:desc)
+  --choose-files FILE
+                  pick files: Enter on a file or Ctrl+O writes the marked files
+                  (or the file under the cursor) to FILE and exits ("-" = stdout)
+  --choose-dir FILE
+                  pick a directory: Enter on a file or Ctrl+O writes the current
+                  directory to FILE and exits ("-" = stdout)
+  --last-dir-file FILE
+                  on exit write the directory of the active pane to FILE
+                  (default: $DUOFM_LAST_DIR_FILE), for cd-on-exit shell wrappers
+  -v, --version   print the version and exit
+  -h, --help      print this help and exit
+`
+
+// parseArgs parses the command-line arguments (without the program name).
+// Options and directories may be given in any order. flag.ErrHelp is
+// returned when the usage was requested.
+func parseArgs(args []string) (*options, error) {
+	opts := &options{}
+	var selectFile, sortSpec, chooseFiles, chooseDir string
+
+	flags := flag.NewFlagSet("duofm", flag.ContinueOnError)
+	// Errors are reported by the caller
+	flags.SetOutput(io.Discard)
+	flags.Usage = func() {}
+	flags.BoolVar(&opts.version, "v", false, "")
+	flags.BoolVar(&opts.version, "version", false, "")
+	flags.StringVar(&opts.configPath, "config", "", "")
+	flags.BoolVar(&opts.noConfig, "no-config", false, "")
+	flags.StringVar(&selectFile, "select", "", "")
+	flags.BoolVar(&opts.start.ShowHidden, "hidden", false, "")
+	flags.StringVar(&sortSpec, "sort", "", "")
+	flags.StringVar(&chooseFiles, "choose-files", "", "")
+	flags.StringVar(&chooseDir, "choose-dir", "", "")
+	flags.StringVar(&opts.lastDir, "last-dir-file", os.Getenv(lastDirEnv), "")
+
+	// Everything after "--" is a directory
+	var dirs []string
+	for i, arg := range args {
+		if arg == "--" {
+			dirs = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+
+	// flag stops at the first directory, so parse the rest again after it
+	var leading []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		leading = append(leading, args[0])
+		args = args[1:]
+	}
+	dirs = append(leading, dirs...)
+
+	if opts.version {
+		return opts, nil
+	}
+	if opts.configPath != "" && opts.noConfig {
+		return nil, errors.New("--config and --no-config cannot be used together")
+	}
+	switch {
+	case chooseFiles != "" && chooseDir != "":
+		return nil, errors.New("--choose-files and --choose-dir cannot be used together")
+	case chooseFiles != "":
+		opts.chooseMode, opts.chooseFile = ui.ChooseFiles, chooseFiles
+	case chooseDir != "":
+		opts.chooseMode, opts.chooseFile = ui.ChooseDir, chooseDir
+	}
+	if len(dirs) > 2 {
+		return nil, fmt.Errorf("too many directories: %d (at most LEFT_DIR and RIGHT_DIR)", len(dirs))
+	}
+
+	for i, dir := range dirs {
+		path, err := resolveDirectory(dir)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			opts.start.LeftPath = path
+		} else {
+			opts.start.RightPath = path
+		}
+	}
+
+	if selectFile != "" {
+		// A relative path is relative to LEFT_DIR when one is given
+		if !filepath.IsAbs(selectFile) && opts.start.LeftPath != "" {
+			selectFile = filepath.Join(opts.start.LeftPath, selectFile)
+		}
+		path, err := filepath.Abs(selectFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Lstat(path); err != nil {
+			return nil, fmt.Errorf("--select: %w", err)
+		}
+		opts.start.SelectFile = path
+	}
+
+	if sortSpec != "" {
+		sortConfig, err := ui.ParseSortConfig(sortSpec)
+		if err != nil {
+			return nil, fmt.Errorf("--sort: %w", err)
+		}
+		opts.start.Sort = &sortConfig
+	}
+
+	return opts, nil
+}
+
+// resolveDirectory returns the absolute path of dir, which must be a directory
+func resolveDirectory(dir string) (string, error) {
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", dir)
+	}
+	return path, nil
+}
+
+// writeChosen writes the chosen paths, one per line, to path ("-" = stdout)
+func writeChosen(path string, chosen []string, stdout io.Writer) error {
+	data := strings.Join(chosen, "\n") + "\n"
+	if path == "-" {
+		_, err := io.WriteString(stdout, data)
+		return err
+	}
+	return os.WriteFile(path, []byte(data), 0644)
+}
+
+// writeLastDir writes the final directory to path for a cd-on-exit wrapper
+func writeLastDir(path, dir string) error {
+	return os.WriteFile(path, []byte(dir), 0600)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sakura/duofm/internal/ui"
+)
+
+func TestParseArgs(t *testing.T) {
+	left, right := t.TempDir(), t.TempDir()
+	file := filepath.Join(left, "notes.txt")
+	os.WriteFile(file, []byte("notes"), 0644)
+
+	t.Run("directories and options in any order", func(t *testing.T) {
+		opts, err := parseArgs([]string{left, "--hidden", right, "--sort", "date:desc", "--config", "/tmp/duofm.toml"})
+		if err != nil {
+			t.Fatalf("parseArgs() error = %v", err)
+		}
+		want := ui.SortConfig{Field: ui.SortByDate, Order: ui.SortDesc}
+		if opts.start.LeftPath != left || opts.start.RightPath != right || !opts.start.ShowHidden ||
+			opts.start.Sort == nil || *opts.start.Sort != want || opts.configPath != "/tmp/duofm.toml" {
+			t.Errorf("parseArgs() = %+v", opts)
+		}
+	})
+
+	t.Run("select is relative to the left directory", func(t *testing.T) {
+		opts, err := parseArgs([]string{"--select", "notes.txt", left})
+		if err != nil || opts.start.SelectFile != file {
+			t.Errorf("parseArgs() = %+v, %v", opts, err)
+		}
+	})
+
+	t.Run("everything after -- is a directory", func(t *testing.T) {
+		dashDir := filepath.Join(left, "-hidden")
+		os.Mkdir(dashDir, 0755)
+		opts, err := parseArgs([]string{"--no-config", "--", dashDir})
+		if err != nil || opts.start.LeftPath != dashDir || !opts.noConfig || opts.start.ShowHidden {
+			t.Errorf("parseArgs() = %+v, %v", opts, err)
+		}
+	})
+
+	t.Run("version and help", func(t *testing.T) {
+		if opts, err := parseArgs([]string{"--version"}); err != nil || !opts.version {
+			t.Errorf("parseArgs(--version) = %+v, %v", opts, err)
+		}
+		if _, err := parseArgs([]string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+			t.Errorf("parseArgs(-h) error = %v, want flag.ErrHelp", err)
+		}
+	})
+
+	errorCases := map[string][]string{
+		"missing directory":  {filepath.Join(left, "missing")},
+		"file as directory":  {file},
+		"too many":           {left, right, left},
+		"missing select":     {"--select", filepath.Join(left, "missing")},
+		"bad sort":           {"--sort", "color"},
+		"conflicting config": {"--config", "x.toml", "--no-config"},
+		"unknown option":     {"--bogus"},
+	}
+	for name, args := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseArgs(args); err == nil {
+				t.Errorf("parseArgs(%q) should fail", args)
+			}
+		})
+	}
+}
+
+func TestParseArgsChooser(t *testing.T) {
+	opts, err := parseArgs([]string{"--choose-files", "-"})
+	if err != nil || opts.chooseMode != ui.ChooseFiles || opts.chooseFile != "-" {
+		t.Errorf("parseArgs(--choose-files -) = %+v, %v", opts, err)
+	}
+	opts, err = parseArgs([]string{"--choose-dir", "/tmp/dir"})
+	if err != nil || opts.chooseMode != ui.ChooseDir || opts.chooseFile != "/tmp/dir" {
+		t.Errorf("parseArgs(--choose-dir) = %+v, %v", opts, err)
+	}
+	if _, err := parseArgs([]string{"--choose-files", "a", "--choose-dir", "b"}); err == nil {
+		t.Error("--choose-files and --choose-dir together should fail")
+	}
+}
+
+func TestWriteChosen(t *testing.T) {
+	chosen := []string{"/tmp/a b.txt", "/tmp/c.txt"}
+
+	var stdout strings.Builder
+	if err := writeChosen("-", chosen, &stdout); err != nil || stdout.String() != "/tmp/a b.txt\n/tmp/c.txt\n" {
+		t.Errorf("writeChosen(-) wrote %q, %v", stdout.String(), err)
+	}
+
+	path := filepath.Join(t.TempDir(), "chosen")
+	if err := writeChosen(path, chosen, &stdout); err != nil {
+		t.Fatalf("writeChosen() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "/tmp/a b.txt\n/tmp/c.txt\n" {
+		t.Errorf("file contents = %q", data)
+	}
+}
+
+func TestParseArgsLastDir(t *testing.T) {
+	t.Setenv(lastDirEnv, "")
+	if opts, err := parseArgs(nil); err != nil || opts.lastDir != "" {
+		t.Errorf("parseArgs() = %+v, %v", opts, err)
+	}
+
+	t.Setenv(lastDirEnv, "/tmp/from-env")
+	if opts, err := parseArgs(nil); err != nil || opts.lastDir != "/tmp/from-env" {
+		t.Errorf("parseArgs() lastDir = %q, %v, want the environment", opts.lastDir, err)
+	}
+	if opts, err := parseArgs([]string{"--last-dir-file", "/tmp/from-flag"}); err != nil || opts.lastDir != "/tmp/from-flag" {
+		t.Errorf("parseArgs() lastDir = %q, %v, want the flag", opts.lastDir, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "lastdir")
+	if err := writeLastDir(path, "/home/user/projects"); err != nil {
+		t.Fatalf("writeLastDir() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "/home/user/projects" {
+		t.Errorf("file contents = %q", data)
+	}
+}
@@ -1,33 +1,56 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mattn/go-runewidth"
 	"github.com/sakura/duofm/internal/config"
+	"github.com/sakura/duofm/internal/frecency"
+	"github.com/sakura/duofm/internal/session"
 	"github.com/sakura/duofm/internal/ui"
+	"github.com/sakura/duofm/internal/undo"
 	"github.com/sakura/duofm/internal/version"
 )
 
 func main() {
-	// Handle version flag
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
-		case "-v", "-version", "--version":
-			fmt.Printf("duofm %s\n", version.Version)
-			return
-		}
+	opts, err := parseArgs(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Print(usageText)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "duofm: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Try 'duofm --help' for more information.")
+		os.Exit(2)
 	}
+	if opts.version {
+		fmt.Printf("duofm %s\n", version.Version)
+		return
+	}
+
 	// Ambiguous幅文字（☆、ü、①など）を幅1として扱う
 	// 多くのモダンターミナルの実際の表示に合わせる設定
 	// TODO: 将来的には設定ファイルで変更可能にする
 	runewidth.DefaultCondition.EastAsianWidth = false
 
 	// 設定ファイルの読み込み
+	switch {
+	case opts.noConfig:
+		config.DisableConfig()
+	case opts.configPath != "":
+		// 明示的に指定された設定ファイルは自動生成しない
+		if _, err := os.Stat(opts.configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "duofm: --config: %v\n", err)
+			os.Exit(2)
+		}
+		config.SetConfigPath(opts.configPath)
+	}
 	configPath, err := config.GetConfigPath()
-	if err != nil {
+	if err != nil && !errors.Is(err, config.ErrConfigDisabled) {
 		fmt.Fprintf(os.Stderr, "Warning: could not determine config path: %v\n", err)
 	}
 
@@ -47,6 +70,7 @@ func main() {
 		cfg = &config.Config{
 			Keybindings: config.DefaultKeybindings(),
 			Colors:      config.DefaultColors(),
+			Copy:        config.DefaultCopyConfig(),
 		}
 	}
 
@@ -60,14 +84,97 @@ func main() {
 	// Themeを生成
 	theme := ui.NewTheme(cfg.Colors)
 
-	p := tea.NewProgram(
-		ui.NewModelWithConfig(keybindingMap, theme, warnings),
+	// 取り消し履歴の読み込み（読み込めなくても空の履歴で続行）
+	var journal *undo.Journal
+	if journalPath, err := undo.DefaultPath(); err == nil {
+		journal, err = undo.Open(journalPath)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Warning: %v", err))
+		}
+	}
+
+	// ディレクトリの訪問記録の読み込み（読み込めなくても空の記録で続行）
+	var visits *frecency.DB
+	if visitsPath, err := frecency.DefaultPath(); err == nil {
+		visits, err = frecency.Open(visitsPath)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Warning: %v", err))
+		}
+	}
+
+	// 前回終了時のセッションの読み込み（読み込めなくても新しいタブで起動）
+	var sessionPath string
+	var savedSession *session.State
+	if path, err := session.DefaultPath(); err == nil {
+		sessionPath = path
+		savedSession, err = session.Load(path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Warning: %v", err))
+		}
+	}
+
+	model := ui.NewModelWithConfig(keybindingMap, theme, warnings)
+	model.SetCopyConfig(cfg.Copy)
+	if journal != nil {
+		model.SetUndoJournal(journal)
+	}
+	if visits != nil {
+		model.SetDirectoryVisits(visits)
+	}
+	model.SetSession(sessionPath, savedSession)
+	model.SetStartOptions(opts.start)
+	model.SetChooseMode(opts.chooseMode)
+
+	programOptions := []tea.ProgramOption{
 		tea.WithAltScreen(),       // 代替画面バッファを使用
 		tea.WithMouseCellMotion(), // マウスサポート（将来用）
-	)
+	}
+	if opts.chooseFile == "-" {
+		// 標準出力は選択結果に使うので画面は標準エラー出力に描画する
+		programOptions = append(programOptions, tea.WithOutput(os.Stderr))
+	}
+	p := tea.NewProgram(model, programOptions...)
+
+	finalModel, err := p.Run()
+
+	// まだ書き出していないディレクトリの訪問記録を保存
+	if visits != nil {
+		if err := visits.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+
+	m, ok := finalModel.(ui.Model)
+	if !ok {
+		return
+	}
+
+	// 次回の起動のためにセッションを保存
+	if err := m.SaveSession(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// シェルのラッパー関数が cd できるように最後のディレクトリを書き出す
+	if opts.lastDir != "" {
+		if err := writeLastDir(opts.lastDir, m.LastDirectory()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not write the last directory: %v\n", err)
+		}
+	}
+
+	// 選択モードの結果を書き出す（選択せずに終了した場合は終了コード1）
+	if opts.chooseMode != ui.ChooseNone {
+		chosen := m.Chosen()
+		if chosen == nil {
+			os.Exit(1)
+		}
+		if err := writeChosen(opts.chooseFile, chosen, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "duofm: %v\n", err)
+			os.Exit(1)
+		}
+	}
 }
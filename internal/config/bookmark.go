@@ -107,26 +107,32 @@ func SaveBookmarks(path string, bookmarks []Bookmark) error {
 
 // removeBookmarksSection removes all [[bookmarks]] sections from TOML content.
 func removeBookmarksSection(content string) string {
+	return removeArraySection(content, "bookmarks")
+}
+
+// removeArraySection removes all [[name]] sections from TOML content.
+func removeArraySection(content, name string) string {
 	lines := strings.Split(content, "\n")
 	var result []string
-	inBookmarksSection := false
+	inSection := false
+	header := "[[" + name + "]]"
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
-		// Check if we're entering a bookmarks section
-		if trimmed == "[[bookmarks]]" {
-			inBookmarksSection = true
+		// Check if we're entering a section to remove
+		if trimmed == header {
+			inSection = true
 			continue
 		}
 
 		// Check if we're entering a different section
 		if strings.HasPrefix(trimmed, "[") {
-			inBookmarksSection = false
+			inSection = false
 		}
 
-		// Skip lines that are part of bookmarks section
-		if inBookmarksSection {
+		// Skip lines that are part of the removed section
+		if inSection {
 			continue
 		}
 
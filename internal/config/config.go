@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/BurntSushi/toml"
 )
@@ -11,12 +12,40 @@ import (
 type Config struct {
 	Keybindings map[string][]string `toml:"keybindings"`
 	Colors      *ColorConfig
+	Copy        CopyConfig
 }
 
-// rawConfig is used for TOML parsing to handle the [keybindings] and [colors] sections.
+// CopyConfig represents the [copy] section that controls copy and move operations.
+type CopyConfig struct {
+	// PreserveMetadata keeps permissions, timestamps, ownership (when running
+	// as root) and user.* extended attributes of copied files.
+	PreserveMetadata bool `toml:"preserve_metadata"`
+	// DereferenceSymlinks copies the targets of symlinks instead of
+	// recreating the links.
+	DereferenceSymlinks bool `toml:"dereference_symlinks"`
+	// Verify re-reads every copied file and compares checksums with the
+	// source: "sha256", "xxhash" or "" (off). A move keeps its source
+	// unless verification passed.
+	Verify string `toml:"verify"`
+}
+
+// VerifyAlgorithms lists the accepted values of CopyConfig.Verify in toggle order.
+var VerifyAlgorithms = []string{"", "sha256", "xxhash"}
+
+// DefaultCopyConfig returns the default copy settings.
+func DefaultCopyConfig() CopyConfig {
+	return CopyConfig{
+		PreserveMetadata:    false,
+		DereferenceSymlinks: false,
+		Verify:              "",
+	}
+}
+
+// rawConfig is used for TOML parsing to handle the [keybindings], [colors] and [copy] sections.
 type rawConfig struct {
 	Keybindings map[string][]string    `toml:"keybindings"`
 	Colors      map[string]interface{} `toml:"colors"`
+	Copy        CopyConfig             `toml:"copy"`
 }
 
 // LoadConfig loads the configuration from the specified path.
@@ -30,8 +59,8 @@ func LoadConfig(path string) (*Config, []string) {
 		return defaultConfig(), warnings
 	}
 
-	// Parse TOML file
-	var raw rawConfig
+	// Parse TOML file (keys missing from [copy] keep their defaults)
+	raw := rawConfig{Copy: DefaultCopyConfig()}
 	if _, err := toml.DecodeFile(path, &raw); err != nil {
 		warnings = append(warnings, fmt.Sprintf("Warning: config parse error, using defaults: %v", err))
 		return defaultConfig(), warnings
@@ -50,6 +79,12 @@ func LoadConfig(path string) (*Config, []string) {
 	cfg.Colors = colors
 	warnings = append(warnings, colorWarnings...)
 
+	cfg.Copy = raw.Copy
+	if !slices.Contains(VerifyAlgorithms, cfg.Copy.Verify) {
+		warnings = append(warnings, fmt.Sprintf("Warning: invalid copy.verify %q, verification disabled", cfg.Copy.Verify))
+		cfg.Copy.Verify = ""
+	}
+
 	return cfg, warnings
 }
 
@@ -58,5 +93,6 @@ func defaultConfig() *Config {
 	return &Config{
 		Keybindings: DefaultKeybindings(),
 		Colors:      DefaultColors(),
+		Copy:        DefaultCopyConfig(),
 	}
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -51,6 +52,37 @@ func TestGetConfigPath_WithXDG(t *testing.T) {
 	}
 }
 
+func TestStatePath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/custom/state")
+	if path, err := StatePath("undo.json"); err != nil || path != "/custom/state/duofm/undo.json" {
+		t.Errorf("StatePath() = %q, %v", path, err)
+	}
+
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/test")
+	if path, err := StatePath("session.json"); err != nil || path != "/home/test/.local/state/duofm/session.json" {
+		t.Errorf("StatePath() without XDG_STATE_HOME = %q, %v", path, err)
+	}
+}
+
+func TestGetConfigPath_Override(t *testing.T) {
+	defer SetConfigPath("")
+
+	override := filepath.Join(t.TempDir(), "custom.toml")
+	SetConfigPath(override)
+	if path, err := GetConfigPath(); err != nil || path != override {
+		t.Errorf("GetConfigPath() = %q, %v, want %q", path, err, override)
+	}
+
+	DisableConfig()
+	if _, err := GetConfigPath(); !errors.Is(err, ErrConfigDisabled) {
+		t.Errorf("GetConfigPath() error = %v, want ErrConfigDisabled", err)
+	}
+	if _, err := GetConfigDir(); !errors.Is(err, ErrConfigDisabled) {
+		t.Errorf("GetConfigDir() error = %v, want ErrConfigDisabled", err)
+	}
+}
+
 func TestLoadConfig_FileNotExists(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "nonexistent", "config.toml")
@@ -188,6 +220,44 @@ quit = ["Q"]
 	}
 }
 
+func TestLoadConfig_CopySection(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	content := `[copy]
+preserve_metadata = true
+dereference_symlinks = true
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, warnings := LoadConfig(configPath)
+	if len(warnings) != 0 {
+		t.Errorf("LoadConfig() returned warnings: %v", warnings)
+	}
+	if !cfg.Copy.PreserveMetadata {
+		t.Error("Copy.PreserveMetadata = false, want true")
+	}
+	if !cfg.Copy.DereferenceSymlinks {
+		t.Error("Copy.DereferenceSymlinks = false, want true")
+	}
+}
+
+func TestLoadConfig_CopySectionDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	if err := os.WriteFile(configPath, []byte("[keybindings]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, _ := LoadConfig(configPath)
+	if cfg.Copy != DefaultCopyConfig() {
+		t.Errorf("Copy = %+v, want defaults %+v", cfg.Copy, DefaultCopyConfig())
+	}
+}
+
 func TestGenerateDefaultConfig_CreatesDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "nested", "dir", "config.toml")
@@ -318,3 +388,33 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoadConfig_CopyVerify(t *testing.T) {
+	tests := []struct {
+		value        string
+		want         string
+		wantWarnings int
+	}{
+		{"sha256", "sha256", 0},
+		{"xxhash", "xxhash", 0},
+		{"md5", "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.toml")
+			content := "[copy]\nverify = \"" + tt.value + "\"\n"
+			if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test config: %v", err)
+			}
+
+			cfg, warnings := LoadConfig(configPath)
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+			if cfg.Copy.Verify != tt.want {
+				t.Errorf("Copy.Verify = %q, want %q", cfg.Copy.Verify, tt.want)
+			}
+		})
+	}
+}
@@ -31,12 +31,17 @@ func DefaultKeybindings() map[string][]string {
 		"prev_dir":        {"-"},
 		"history_back":    {"Alt+Left", "["},
 		"history_forward": {"Alt+Right", "]"},
+		"history_list":    {"Shift+H"},
 		"refresh":         {"F5", "Ctrl+R"},
 		"sync_pane":       {"="},
+		"jump":            {"Z"},
 
 		// Search
 		"search":       {"/"},
 		"regex_search": {"Ctrl+F"},
+		"find_files":   {"F"},
+		"grep":         {"G"},
+		"fuzzy_find":   {"Ctrl+P"},
 
 		// External applications
 		"view":          {"V"},
@@ -51,6 +56,40 @@ func DefaultKeybindings() map[string][]string {
 		// Bookmarks
 		"bookmark":     {"B"},
 		"add_bookmark": {"Shift+B"},
+
+		// Jobs
+		"jobs": {"Shift+J"},
+
+		// Trash
+		"delete_permanently": {"Shift+D"},
+		"trash":              {"T"},
+
+		// Undo
+		"undo": {"U"},
+		"redo": {"Shift+U"},
+
+		// Copy options
+		"toggle_preserve":  {"Shift+P"},
+		"toggle_verify":    {"Shift+V"},
+		"operation_filter": {"Shift+F"},
+
+		// Directory sync
+		"sync_directories":    {"Shift+S"},
+		"compare_directories": {"Shift+C"},
+		"diff_files":          {"Shift+X"},
+
+		// Tabs
+		"new_tab":       {"Ctrl+T"},
+		"duplicate_tab": {"Alt+T"},
+		"close_tab":     {"Ctrl+W"},
+		"next_tab":      {"Tab", "Alt+L"},
+		"prev_tab":      {"Alt+H"},
+
+		// Sessions
+		"sessions": {"Shift+W"},
+
+		// Chooser mode
+		"choose": {"Ctrl+O"},
 	}
 }
 
@@ -77,10 +116,15 @@ func AllActions() []string {
 		"prev_dir",
 		"history_back",
 		"history_forward",
+		"history_list",
 		"refresh",
 		"sync_pane",
+		"jump",
 		"search",
 		"regex_search",
+		"find_files",
+		"grep",
+		"fuzzy_find",
 		"view",
 		"edit",
 		"shell_command",
@@ -89,5 +133,23 @@ func AllActions() []string {
 		"escape",
 		"bookmark",
 		"add_bookmark",
+		"jobs",
+		"delete_permanently",
+		"trash",
+		"undo",
+		"redo",
+		"toggle_preserve",
+		"toggle_verify",
+		"operation_filter",
+		"sync_directories",
+		"compare_directories",
+		"diff_files",
+		"new_tab",
+		"duplicate_tab",
+		"close_tab",
+		"next_tab",
+		"prev_tab",
+		"sessions",
+		"choose",
 	}
 }
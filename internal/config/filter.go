@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FilterSet is a named set of include/exclude glob patterns for copy, move
+// and delete operations.
+type FilterSet struct {
+	Name    string   `toml:"name"`
+	Include []string `toml:"include"`
+	Exclude []string `toml:"exclude"`
+}
+
+// filtersConfig is used for TOML parsing of filters section.
+type filtersConfig struct {
+	Filters []FilterSet `toml:"filters"`
+}
+
+// LoadFilterSets loads filter sets from a TOML configuration file.
+// Returns empty slice if file doesn't exist or has no filters section.
+func LoadFilterSets(path string) ([]FilterSet, []string) {
+	var warnings []string
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []FilterSet{}, warnings
+	}
+
+	var cfg filtersConfig
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		warnings = append(warnings, fmt.Sprintf("Warning: failed to parse filters: %v", err))
+		return []FilterSet{}, warnings
+	}
+
+	var valid []FilterSet
+	for _, f := range cfg.Filters {
+		if f.Name == "" {
+			warnings = append(warnings, "Warning: skipping filter with empty name")
+			continue
+		}
+		if len(f.Include) == 0 && len(f.Exclude) == 0 {
+			warnings = append(warnings, fmt.Sprintf("Warning: skipping filter '%s' without patterns", f.Name))
+			continue
+		}
+		if pattern, ok := invalidPattern(f); ok {
+			warnings = append(warnings, fmt.Sprintf("Warning: skipping filter '%s' with invalid pattern %q", f.Name, pattern))
+			continue
+		}
+		valid = append(valid, f)
+	}
+
+	return valid, warnings
+}
+
+// invalidPattern returns the first malformed glob pattern of the set.
+func invalidPattern(f FilterSet) (string, bool) {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// SaveFilterSets saves filter sets to a TOML configuration file.
+// Preserves existing sections (keybindings, colors, bookmarks).
+func SaveFilterSets(path string, sets []FilterSet) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	var existingContent string
+	if data, err := os.ReadFile(path); err == nil {
+		existingContent = string(data)
+	}
+
+	newContent := removeArraySection(existingContent, "filters")
+
+	var filtersTOML strings.Builder
+	for _, f := range sets {
+		filtersTOML.WriteString("[[filters]]\n")
+		filtersTOML.WriteString(fmt.Sprintf("name = %q\n", f.Name))
+		if len(f.Include) > 0 {
+			filtersTOML.WriteString(fmt.Sprintf("include = %s\n", tomlStringArray(f.Include)))
+		}
+		if len(f.Exclude) > 0 {
+			filtersTOML.WriteString(fmt.Sprintf("exclude = %s\n", tomlStringArray(f.Exclude)))
+		}
+		filtersTOML.WriteString("\n")
+	}
+
+	if newContent != "" && !strings.HasSuffix(newContent, "\n\n") {
+		if !strings.HasSuffix(newContent, "\n") {
+			newContent += "\n"
+		}
+		newContent += "\n"
+	}
+	newContent += filtersTOML.String()
+
+	return os.WriteFile(path, []byte(newContent), 0644)
+}
+
+// tomlStringArray formats values as a TOML array of strings.
+func tomlStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// SetFilterSet adds a filter set, replacing an existing set with the same name.
+// Returns ErrEmptyAlias if name is empty.
+func SetFilterSet(sets []FilterSet, set FilterSet) ([]FilterSet, error) {
+	if set.Name == "" {
+		return sets, ErrEmptyAlias
+	}
+	result := make([]FilterSet, 0, len(sets)+1)
+	for _, f := range sets {
+		if f.Name != set.Name {
+			result = append(result, f)
+		}
+	}
+	return append(result, set), nil
+}
+
+// RemoveFilterSet removes the filter set at the given index.
+func RemoveFilterSet(sets []FilterSet, index int) ([]FilterSet, error) {
+	if index < 0 || index >= len(sets) {
+		return sets, ErrInvalidIndex
+	}
+
+	result := make([]FilterSet, 0, len(sets)-1)
+	result = append(result, sets[:index]...)
+	result = append(result, sets[index+1:]...)
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFilterSets(t *testing.T) {
+	t.Run("returns empty slice when config file does not exist", func(t *testing.T) {
+		sets, warnings := LoadFilterSets("/nonexistent/config.toml")
+		if len(sets) != 0 || len(warnings) != 0 {
+			t.Errorf("expected no sets and warnings, got %v, %v", sets, warnings)
+		}
+	})
+
+	t.Run("parses valid filter sets and skips invalid ones", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.toml")
+		content := `[[filters]]
+name = "Sources"
+include = ["*.go"]
+exclude = ["vendor"]
+
+[[filters]]
+name = "Empty"
+
+[[filters]]
+name = "Broken"
+include = ["[abc"]
+
+[[filters]]
+include = ["*.txt"]
+`
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		sets, warnings := LoadFilterSets(configPath)
+		if len(sets) != 1 {
+			t.Fatalf("expected 1 filter set, got %v", sets)
+		}
+		if sets[0].Name != "Sources" || sets[0].Include[0] != "*.go" || sets[0].Exclude[0] != "vendor" {
+			t.Errorf("unexpected filter set %+v", sets[0])
+		}
+		if len(warnings) != 3 {
+			t.Errorf("expected 3 warnings, got %v", warnings)
+		}
+	})
+}
+
+func TestSaveFilterSets(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	initial := `[keybindings]
+quit = ["Q"]
+
+[[bookmarks]]
+name = "Home"
+path = "/home/user"
+
+[[filters]]
+name = "Old"
+include = ["*.old"]
+`
+	if err := os.WriteFile(configPath, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sets := []FilterSet{
+		{Name: "Sources", Include: []string{"*.go", "*.md"}, Exclude: []string{"vendor"}},
+		{Name: "No backups", Exclude: []string{"*~"}},
+	}
+	if err := SaveFilterSets(configPath, sets); err != nil {
+		t.Fatalf("SaveFilterSets() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	content := string(data)
+	for _, want := range []string{`quit = ["Q"]`, `name = "Home"`, `include = ["*.go", "*.md"]`, `exclude = ["*~"]`} {
+		if !strings.Contains(content, want) {
+			t.Errorf("saved config should contain %s:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "Old") {
+		t.Errorf("old filter sets should be replaced:\n%s", content)
+	}
+
+	loaded, warnings := LoadFilterSets(configPath)
+	if len(warnings) != 0 || len(loaded) != 2 || loaded[1].Name != "No backups" {
+		t.Errorf("LoadFilterSets() = %v, %v", loaded, warnings)
+	}
+	if bookmarks, _ := LoadBookmarks(configPath); len(bookmarks) != 1 {
+		t.Errorf("bookmarks should be preserved, got %v", bookmarks)
+	}
+}
+
+func TestSetFilterSet(t *testing.T) {
+	sets := []FilterSet{{Name: "A", Include: []string{"*.a"}}, {Name: "B", Include: []string{"*.b"}}}
+
+	sets, err := SetFilterSet(sets, FilterSet{Name: "A", Include: []string{"*.new"}})
+	if err != nil {
+		t.Fatalf("SetFilterSet() error = %v", err)
+	}
+	if len(sets) != 2 || sets[1].Name != "A" || sets[1].Include[0] != "*.new" {
+		t.Errorf("SetFilterSet() should replace the set with the same name, got %v", sets)
+	}
+
+	if _, err := SetFilterSet(sets, FilterSet{Include: []string{"*"}}); err != ErrEmptyAlias {
+		t.Errorf("SetFilterSet() error = %v, want ErrEmptyAlias", err)
+	}
+
+	sets, err = RemoveFilterSet(sets, 0)
+	if err != nil || len(sets) != 1 || sets[0].Name != "A" {
+		t.Errorf("RemoveFilterSet() = %v, %v", sets, err)
+	}
+	if _, err := RemoveFilterSet(sets, 5); err != ErrInvalidIndex {
+		t.Errorf("RemoveFilterSet() error = %v, want ErrInvalidIndex", err)
+	}
+}
@@ -56,6 +56,24 @@ context_menu = ["@"]
 quit = ["Q"]
 escape = ["Esc"]
 
+# Copy and move operations
+[copy]
+# Keep permissions, timestamps, ownership (as root) and user.* xattrs
+# Can be toggled for the next operations with Shift+P
+# preserve_metadata = false
+# Copy the files symlinks point to instead of the links themselves
+# dereference_symlinks = false
+# Re-read copied files and compare checksums ("sha256" or "xxhash")
+# Can be cycled for the next operations with Shift+V
+# verify = "sha256"
+
+# Named include/exclude filter sets for copy, move and delete
+# Choose the active filter with Shift+F; patterns without "/" match file names
+# [[filters]]
+# name = "Sources"
+# include = ["*.go", "*.md"]
+# exclude = ["vendor", "*_test.go"]
+
 # Color Theme Configuration
 # Colors are specified as ANSI 256-color codes (0-255)
 # Use ? key in duofm to see the color palette reference
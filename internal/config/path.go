@@ -1,13 +1,45 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 )
 
+// ErrConfigDisabled is returned by GetConfigPath when the configuration file
+// has been turned off with DisableConfig
+var ErrConfigDisabled = errors.New("configuration file is disabled")
+
+// configPathOverride replaces the default location when set
+var configPathOverride string
+
+// configDisabled makes GetConfigPath fail with ErrConfigDisabled
+var configDisabled bool
+
+// SetConfigPath makes GetConfigPath return path instead of the default location.
+// Bookmarks and filter sets are read from and saved to the same file.
+func SetConfigPath(path string) {
+	configPathOverride = path
+	configDisabled = false
+}
+
+// DisableConfig makes GetConfigPath return ErrConfigDisabled, so that the
+// defaults are used and nothing is written to a configuration file.
+func DisableConfig() {
+	configPathOverride = ""
+	configDisabled = true
+}
+
 // GetConfigPath returns the path to the configuration file.
 // It respects XDG_CONFIG_HOME if set, otherwise uses ~/.config/duofm/config.toml
 func GetConfigPath() (string, error) {
+	if configDisabled {
+		return "", ErrConfigDisabled
+	}
+	if configPathOverride != "" {
+		return configPathOverride, nil
+	}
+
 	configDir := os.Getenv("XDG_CONFIG_HOME")
 	if configDir == "" {
 		home, err := os.UserHomeDir()
@@ -19,6 +51,21 @@ func GetConfigPath() (string, error) {
 	return filepath.Join(configDir, "duofm", "config.toml"), nil
 }
 
+// StatePath returns the path of the state file name, such as the undo journal
+// or the saved session. It respects XDG_STATE_HOME if set, otherwise uses
+// ~/.local/state/duofm/name
+func StatePath(name string) (string, error) {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateDir, "duofm", name), nil
+}
+
 // GetConfigDir returns the directory containing the configuration file.
 func GetConfigDir() (string, error) {
 	configPath, err := GetConfigPath()
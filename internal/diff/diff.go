@@ -0,0 +1,238 @@
+// Package diff computes line based differences between two text files.
+package diff
+
+// Kind is the kind of one step of an edit script
+type Kind int
+
+const (
+	// Equal keeps a line that is in both files
+	Equal Kind = iota
+	// Delete removes a line of the first file
+	Delete
+	// Insert adds a line of the second file
+	Insert
+)
+
+// Edit is one line of the edit script that turns a into b. A and B are the
+// line indexes in a and b; A is -1 for Insert and B is -1 for Delete.
+type Edit struct {
+	Kind Kind
+	A    int
+	B    int
+}
+
+// Hunk is a run of changed lines. The ranges are half-open, so a pure
+// insertion has AStart == AEnd and a pure deletion BStart == BEnd.
+type Hunk struct {
+	AStart, AEnd int
+	BStart, BEnd int
+}
+
+// Row is one line of a side-by-side view. A and B are the line indexes shown
+// on the left and right (-1 for an empty side), Hunk is the index of the hunk
+// the row belongs to (-1 for unchanged lines).
+type Row struct {
+	A, B int
+	Hunk int
+}
+
+// Lines returns the shortest edit script that turns a into b, computed with
+// Myers' algorithm after stripping the common prefix and suffix.
+func Lines(a, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, Edit{Kind: Equal, A: i, B: i})
+	}
+	for _, e := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if e.A >= 0 {
+			e.A += prefix
+		}
+		if e.B >= 0 {
+			e.B += prefix
+		}
+		edits = append(edits, e)
+	}
+	for i := 0; i < suffix; i++ {
+		edits = append(edits, Edit{Kind: Equal, A: len(a) - suffix + i, B: len(b) - suffix + i})
+	}
+	return edits
+}
+
+// myers runs the greedy O(ND) algorithm. Only the diagonals reachable after
+// d steps are kept for each d, so memory grows with the square of the number
+// of differences rather than with the file sizes.
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the saved diagonals back from the end to build the script
+func backtrack(trace [][]int, n, m int) []Edit {
+	var edits []Edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, Edit{Kind: Equal, A: x, B: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, Edit{Kind: Insert, A: -1, B: y})
+			} else {
+				x--
+				edits = append(edits, Edit{Kind: Delete, A: x, B: -1})
+			}
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// Hunks groups the changed lines of an edit script
+func Hunks(edits []Edit) []Hunk {
+	var hunks []Hunk
+	a, b := 0, 0
+	inHunk := false
+	for _, e := range edits {
+		if e.Kind == Equal {
+			if inHunk {
+				hunks[len(hunks)-1].AEnd = a
+				hunks[len(hunks)-1].BEnd = b
+				inHunk = false
+			}
+			a++
+			b++
+			continue
+		}
+		if !inHunk {
+			hunks = append(hunks, Hunk{AStart: a, BStart: b})
+			inHunk = true
+		}
+		if e.Kind == Delete {
+			a++
+		} else {
+			b++
+		}
+	}
+	if inHunk {
+		hunks[len(hunks)-1].AEnd = a
+		hunks[len(hunks)-1].BEnd = b
+	}
+	return hunks
+}
+
+// SideBySide lays out an edit script as rows of a two column view. Within a
+// hunk the deleted and inserted lines are paired up in order, the longer side
+// continues next to empty rows.
+func SideBySide(edits []Edit) []Row {
+	var rows []Row
+	hunk := -1
+	var deleted, inserted []int
+
+	flush := func() {
+		for i := 0; i < len(deleted) || i < len(inserted); i++ {
+			row := Row{A: -1, B: -1, Hunk: hunk}
+			if i < len(deleted) {
+				row.A = deleted[i]
+			}
+			if i < len(inserted) {
+				row.B = inserted[i]
+			}
+			rows = append(rows, row)
+		}
+		deleted, inserted = nil, nil
+	}
+
+	inHunk := false
+	for _, e := range edits {
+		switch e.Kind {
+		case Equal:
+			if inHunk {
+				flush()
+				inHunk = false
+			}
+			rows = append(rows, Row{A: e.A, B: e.B, Hunk: -1})
+		case Delete, Insert:
+			if !inHunk {
+				hunk++
+				inHunk = true
+			}
+			if e.Kind == Delete {
+				deleted = append(deleted, e.A)
+			} else {
+				inserted = append(inserted, e.B)
+			}
+		}
+	}
+	flush()
+	return rows
+}
+
+// CopyHunk returns dst with the lines of the hunk replaced by those of src.
+// toB tells whether the hunk is copied from a to b (dst is b) or from b to a.
+func CopyHunk(src, dst []string, h Hunk, toB bool) []string {
+	srcStart, srcEnd, dstStart, dstEnd := h.BStart, h.BEnd, h.AStart, h.AEnd
+	if toB {
+		srcStart, srcEnd, dstStart, dstEnd = h.AStart, h.AEnd, h.BStart, h.BEnd
+	}
+	result := make([]string, 0, len(dst)-(dstEnd-dstStart)+(srcEnd-srcStart))
+	result = append(result, dst[:dstStart]...)
+	result = append(result, src[srcStart:srcEnd]...)
+	result = append(result, dst[dstEnd:]...)
+	return result
+}
@@ -0,0 +1,115 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// apply rebuilds both files from an edit script
+func apply(edits []Edit, a, b []string) ([]string, []string) {
+	var gotA, gotB []string
+	for _, e := range edits {
+		switch e.Kind {
+		case Equal:
+			gotA = append(gotA, a[e.A])
+			gotB = append(gotB, b[e.B])
+		case Delete:
+			gotA = append(gotA, a[e.A])
+		case Insert:
+			gotB = append(gotB, b[e.B])
+		}
+	}
+	return gotA, gotB
+}
+
+// lcsLength is the reference length of the longest common subsequence
+func lcsLength(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return dp[0][0]
+}
+
+func TestLinesIsShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "d"}
+	random := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = words[rng.Intn(len(words))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		edits := Lines(a, b)
+		gotA, gotB := apply(edits, a, b)
+		if strings.Join(gotA, ",") != strings.Join(a, ",") || strings.Join(gotB, ",") != strings.Join(b, ",") {
+			t.Fatalf("Lines(%v, %v) = %v does not rebuild the inputs", a, b, edits)
+		}
+		equal := 0
+		for _, e := range edits {
+			if e.Kind == Equal {
+				equal++
+			}
+		}
+		if want := lcsLength(a, b); equal != want {
+			t.Fatalf("Lines(%v, %v) keeps %d lines, want %d", a, b, equal, want)
+		}
+	}
+}
+
+func TestHunksAndSideBySide(t *testing.T) {
+	a := []string{"one", "two", "three", "four", "five"}
+	b := []string{"one", "TWO", "three", "five", "six"}
+	edits := Lines(a, b)
+
+	hunks := Hunks(edits)
+	want := []Hunk{
+		{AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{AStart: 3, AEnd: 4, BStart: 3, BEnd: 3},
+		{AStart: 5, AEnd: 5, BStart: 4, BEnd: 5},
+	}
+	if !reflect.DeepEqual(hunks, want) {
+		t.Errorf("Hunks() = %+v, want %+v", hunks, want)
+	}
+
+	rows := SideBySide(edits)
+	wantRows := []Row{
+		{A: 0, B: 0, Hunk: -1},
+		{A: 1, B: 1, Hunk: 0},
+		{A: 2, B: 2, Hunk: -1},
+		{A: 3, B: -1, Hunk: 1},
+		{A: 4, B: 3, Hunk: -1},
+		{A: -1, B: 4, Hunk: 2},
+	}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("SideBySide() = %+v, want %+v", rows, wantRows)
+	}
+}
+
+func TestCopyHunk(t *testing.T) {
+	a := []string{"one", "two", "three"}
+	b := []string{"one", "2", "2b", "three"}
+	h := Hunks(Lines(a, b))[0]
+
+	if got := CopyHunk(a, b, h, true); !reflect.DeepEqual(got, a) {
+		t.Errorf("CopyHunk(to b) = %v, want %v", got, a)
+	}
+	if got := CopyHunk(b, a, h, false); !reflect.DeepEqual(got, b) {
+		t.Errorf("CopyHunk(to a) = %v, want %v", got, b)
+	}
+}
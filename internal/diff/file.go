@@ -0,0 +1,73 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrBinary is returned when a file does not look like text
+var ErrBinary = errors.New("binary file")
+
+// ErrTooLarge is returned when a file exceeds the size limit
+var ErrTooLarge = errors.New("file too large")
+
+// binarySniffSize is how much of a file is checked for NUL bytes
+const binarySniffSize = 8000
+
+// File is a text file split into lines
+type File struct {
+	Path           string
+	Lines          []string // Without line terminators ("\r" of CRLF is kept)
+	NoFinalNewline bool     // The last line is not terminated
+}
+
+// ReadFile reads a regular text file of at most maxSize bytes
+func ReadFile(path string, maxSize int64) (*File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	if info.Size() > maxSize {
+		return nil, fmt.Errorf("%s: %w", path, ErrTooLarge)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), binarySniffSize)], 0) >= 0 {
+		return nil, fmt.Errorf("%s: %w", path, ErrBinary)
+	}
+
+	f := &File{Path: path}
+	if len(data) == 0 {
+		return f, nil
+	}
+	content := string(data)
+	if strings.HasSuffix(content, "\n") {
+		content = content[:len(content)-1]
+	} else {
+		f.NoFinalNewline = true
+	}
+	f.Lines = strings.Split(content, "\n")
+	return f, nil
+}
+
+// Write saves the lines back to the file, keeping its permissions
+func (f *File) Write() error {
+	content := strings.Join(f.Lines, "\n")
+	if len(f.Lines) > 0 && !f.NoFinalNewline {
+		content += "\n"
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(f.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return os.WriteFile(f.Path, []byte(content), mode)
+}
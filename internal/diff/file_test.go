@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		lines   []string
+		noFinal bool
+	}{
+		{"empty", "", nil, false},
+		{"terminated", "a\nb\n", []string{"a", "b"}, false},
+		{"unterminated", "a\nb", []string{"a", "b"}, true},
+		{"crlf", "a\r\nb\r\n", []string{"a\r", "b\r"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			os.WriteFile(path, []byte(tt.content), 0600)
+			f, err := ReadFile(path, 1024)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(f.Lines, tt.lines) || f.NoFinalNewline != tt.noFinal {
+				t.Errorf("ReadFile() = %q (no final newline %v)", f.Lines, f.NoFinalNewline)
+			}
+
+			// Writing unchanged lines gives back the same bytes and permissions
+			if err := f.Write(); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			data, _ := os.ReadFile(path)
+			if string(data) != tt.content {
+				t.Errorf("Write() wrote %q, want %q", data, tt.content)
+			}
+			if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+				t.Errorf("Write() changed the mode to %v", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestReadFileRejects(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "binary")
+	os.WriteFile(binary, []byte("text\x00more"), 0644)
+	if _, err := ReadFile(binary, 1024); !errors.Is(err, ErrBinary) {
+		t.Errorf("ReadFile(binary) error = %v, want ErrBinary", err)
+	}
+
+	large := filepath.Join(dir, "large")
+	os.WriteFile(large, make([]byte, 100), 0644)
+	if _, err := ReadFile(large, 10); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("ReadFile(large) error = %v, want ErrTooLarge", err)
+	}
+
+	if _, err := ReadFile(dir, 1024); err == nil {
+		t.Error("ReadFile(directory) should fail")
+	}
+}
@@ -0,0 +1,313 @@
+// Package frecency keeps a persistent record of the directories visited and
+// ranks them by frecency, a mix of how often and how recently they were
+// visited, in the way zoxide does.
+package frecency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sakura/duofm/internal/config"
+)
+
+// DefaultMaxAge is the total rank above which all ranks are scaled down, so
+// directories that are no longer visited eventually drop out
+const DefaultMaxAge = 10000
+
+// DefaultSaveDelay is how long changes are collected before the database is
+// written, so navigating through several directories writes the file once
+const DefaultSaveDelay = 2 * time.Second
+
+// Entry is a visited directory
+type Entry struct {
+	Path       string    `json:"path"`
+	Rank       float64   `json:"rank"` // Grows by one per visit and decays with aging
+	LastAccess time.Time `json:"last_access"`
+}
+
+// Score returns the frecency of the entry at now: the rank weighted by how
+// long ago the directory was last visited
+func (e Entry) Score(now time.Time) float64 {
+	age := now.Sub(e.LastAccess)
+	switch {
+	case age < time.Hour:
+		return e.Rank * 4
+	case age < 24*time.Hour:
+		return e.Rank * 2
+	case age < 7*24*time.Hour:
+		return e.Rank * 0.5
+	default:
+		return e.Rank * 0.25
+	}
+}
+
+// DB records directory visits. It is safe for concurrent use.
+//
+// Changes are written to the file in the background after DefaultSaveDelay;
+// call Flush before exiting to write pending changes.
+type DB struct {
+	mu        sync.Mutex
+	path      string // Database file ("" keeps the database in memory only)
+	maxAge    float64
+	entries   map[string]*Entry
+	now       func() time.Time
+	saveDelay time.Duration
+	saveTimer *time.Timer // Pending background save, nil if none
+	dirty     bool        // Changes not written yet
+	saveErr   error       // Error of the last background save
+}
+
+// DefaultPath returns the database file in the state directory (see config.StatePath)
+func DefaultPath() (string, error) {
+	return config.StatePath("directories.json")
+}
+
+// NewDB creates an empty in-memory database
+func NewDB() *DB {
+	return &DB{
+		maxAge:    DefaultMaxAge,
+		entries:   make(map[string]*Entry),
+		now:       time.Now,
+		saveDelay: DefaultSaveDelay,
+	}
+}
+
+// Open loads the database stored at path. A missing file yields an empty
+// database; changes are written back to path.
+func Open(path string) (*DB, error) {
+	db := NewDB()
+	db.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return db, nil
+	}
+	if err != nil {
+		return db, fmt.Errorf("failed to read directory history: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return db, fmt.Errorf("failed to parse directory history: %w", err)
+	}
+	for _, e := range entries {
+		if e.Path == "" {
+			continue
+		}
+		entry := e
+		db.entries[e.Path] = &entry
+	}
+	return db, nil
+}
+
+// Visit records a visit to the directory at path
+func (db *DB) Visit(path string) {
+	if path == "" {
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	entry, ok := db.entries[path]
+	if !ok {
+		entry = &Entry{Path: path}
+		db.entries[path] = entry
+	}
+	entry.Rank++
+	entry.LastAccess = db.now()
+	db.ageLocked()
+	db.scheduleSaveLocked()
+}
+
+// Remove forgets the directory at path, for example because it was deleted
+func (db *DB) Remove(path string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.entries[path]; !ok {
+		return
+	}
+	delete(db.entries, path)
+	db.scheduleSaveLocked()
+}
+
+// Flush writes pending changes to the file. It returns the error of the
+// write, or of the last background save when nothing was pending.
+func (db *DB) Flush() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.saveTimer != nil {
+		db.saveTimer.Stop()
+		db.saveTimer = nil
+	}
+	if !db.dirty {
+		return db.saveErr
+	}
+	db.saveErr = db.saveLocked()
+	return db.saveErr
+}
+
+// Query returns the directories that match keywords, highest frecency first.
+// Without keywords every directory matches.
+//
+// Keywords are matched like zoxide does: ignoring case, they must appear in
+// the path in order, and the last keyword must appear in the last path
+// component. So "proj api" matches /home/me/projects/shop/api but not
+// /home/me/projects/api/docs.
+func (db *DB) Query(keywords []string) []Entry {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	lower := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		if k != "" {
+			lower = append(lower, strings.ToLower(k))
+		}
+	}
+
+	now := db.now()
+	var result []Entry
+	for _, entry := range db.entries {
+		if Matches(entry.Path, lower) {
+			result = append(result, *entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		si, sj := result[i].Score(now), result[j].Score(now)
+		if si != sj {
+			return si > sj
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// Recent returns the visited directories, most recently visited first
+func (db *DB) Recent() []Entry {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	result := make([]Entry, 0, len(db.entries))
+	for _, entry := range db.entries {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].LastAccess.Equal(result[j].LastAccess) {
+			return result[i].LastAccess.After(result[j].LastAccess)
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// Matches reports whether path matches the lower case keywords (see Query).
+// A last keyword containing "/" may match anywhere after the others.
+func Matches(path string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+	path = strings.ToLower(path)
+
+	rest := path
+	for _, keyword := range keywords {
+		i := strings.Index(rest, keyword)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(keyword):]
+	}
+
+	last := keywords[len(keywords)-1]
+	if strings.Contains(last, "/") {
+		return true
+	}
+	return strings.Contains(filepath.Base(path), last)
+}
+
+// ageLocked scales all ranks down when their total exceeds maxAge, dropping
+// directories whose rank falls below one
+func (db *DB) ageLocked() {
+	var total float64
+	for _, entry := range db.entries {
+		total += entry.Rank
+	}
+	if total <= db.maxAge {
+		return
+	}
+	factor := 0.9 * db.maxAge / total
+	for path, entry := range db.entries {
+		entry.Rank *= factor
+		if entry.Rank < 1 {
+			delete(db.entries, path)
+		}
+	}
+}
+
+// scheduleSaveLocked marks the database as changed and starts a background
+// save unless one is already pending
+func (db *DB) scheduleSaveLocked() {
+	if db.path == "" {
+		return
+	}
+	db.dirty = true
+	if db.saveTimer == nil {
+		db.saveTimer = time.AfterFunc(db.saveDelay, db.backgroundSave)
+	}
+}
+
+// backgroundSave writes the changes collected since the save was scheduled
+func (db *DB) backgroundSave() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.saveTimer = nil
+	if db.dirty {
+		db.saveErr = db.saveLocked()
+	}
+}
+
+// saveLocked writes the database to its file, sorted by path so the file
+// stays stable between runs
+func (db *DB) saveLocked() error {
+	if db.path == "" {
+		return nil
+	}
+
+	entries := make([]Entry, 0, len(db.entries))
+	for _, entry := range db.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode directory history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(db.path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory history directory: %w", err)
+	}
+
+	// A unique temporary file keeps other running instances from writing to it
+	tmp, err := os.CreateTemp(filepath.Dir(db.path), filepath.Base(db.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write directory history: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), db.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write directory history: %w", err)
+	}
+	db.dirty = false
+	return nil
+}
@@ -0,0 +1,220 @@
+package frecency
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestDB returns an in-memory database with a controllable clock
+func newTestDB(now *time.Time) *DB {
+	db := NewDB()
+	db.now = func() time.Time { return *now }
+	return db
+}
+
+func paths(entries []Entry) []string {
+	var result []string
+	for _, e := range entries {
+		result = append(result, e.Path)
+	}
+	return result
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Run("XDG_STATE_HOME", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/custom/state")
+		path, err := DefaultPath()
+		if err != nil {
+			t.Fatalf("DefaultPath() error = %v", err)
+		}
+		if path != "/custom/state/duofm/directories.json" {
+			t.Errorf("DefaultPath() = %q", path)
+		}
+	})
+
+	t.Run("fallback to ~/.local/state", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "")
+		t.Setenv("HOME", "/home/test")
+		path, err := DefaultPath()
+		if err != nil {
+			t.Fatalf("DefaultPath() error = %v", err)
+		}
+		if path != "/home/test/.local/state/duofm/directories.json" {
+			t.Errorf("DefaultPath() = %q", path)
+		}
+	})
+}
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		age  time.Duration
+		want float64
+	}{
+		{time.Minute, 8},
+		{3 * time.Hour, 4},
+		{3 * 24 * time.Hour, 1},
+		{30 * 24 * time.Hour, 0.5},
+	}
+	for _, tt := range tests {
+		e := Entry{Rank: 2, LastAccess: now.Add(-tt.age)}
+		if got := e.Score(now); got != tt.want {
+			t.Errorf("Score() after %v = %v, want %v", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		path     string
+		keywords []string
+		want     bool
+	}{
+		{"/home/me/projects/shop/api", nil, true},
+		{"/home/me/projects/shop/api", []string{"proj", "api"}, true},
+		{"/home/me/Projects/Shop/API", []string{"proj", "api"}, true},
+		{"/home/me/projects/api/docs", []string{"proj", "api"}, false}, // last keyword not in the last component
+		{"/home/me/api/projects", []string{"api", "proj"}, true},
+		{"/home/me/api/projects", []string{"proj", "api"}, false}, // out of order
+		{"/home/me/projects/api/docs", []string{"proj", "api/"}, true},
+		{"/srv/www", []string{"nothing"}, false},
+	}
+	for _, tt := range tests {
+		if got := Matches(tt.path, tt.keywords); got != tt.want {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.path, tt.keywords, got, tt.want)
+		}
+	}
+}
+
+func TestQueryRanksByFrecency(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	db := newTestDB(&now)
+
+	// Often visited, but a week ago
+	now = now.Add(-8 * 24 * time.Hour)
+	for i := 0; i < 6; i++ {
+		db.Visit("/home/me/projects/old/api")
+	}
+	now = now.Add(8 * 24 * time.Hour)
+	// Visited a few times just now
+	db.Visit("/home/me/projects/shop/api")
+	db.Visit("/home/me/projects/shop/api")
+	db.Visit("/home/me/projects/shop")
+
+	if got, want := paths(db.Query([]string{"proj", "API"})), []string{"/home/me/projects/shop/api", "/home/me/projects/old/api"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query(proj API) = %v, want %v", got, want)
+	}
+	if got, want := paths(db.Query([]string{"shop"})), []string{"/home/me/projects/shop"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query(shop) = %v, want %v", got, want)
+	}
+	if got := db.Query(nil); len(got) != 3 {
+		t.Errorf("Query() = %v, want all 3 directories", paths(got))
+	}
+
+	db.Remove("/home/me/projects/shop/api")
+	if got, want := paths(db.Query([]string{"api"})), []string{"/home/me/projects/old/api"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query(api) after Remove = %v, want %v", got, want)
+	}
+}
+
+func TestAging(t *testing.T) {
+	now := time.Now()
+	db := newTestDB(&now)
+	db.maxAge = 10
+
+	db.Visit("/rare")
+	for i := 0; i < 10; i++ {
+		db.Visit("/often")
+	}
+	// The total went over 10, so the ranks were scaled down and /rare fell
+	// below one
+	entries := db.Query(nil)
+	if got := paths(entries); !reflect.DeepEqual(got, []string{"/often"}) {
+		t.Fatalf("Query() after aging = %v, want [/often]", got)
+	}
+	if entries[0].Rank >= 10 {
+		t.Errorf("rank = %v, want it scaled down", entries[0].Rank)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "directories.json")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	db.Visit("/a")
+	db.Visit("/b")
+	db.Visit("/b")
+
+	// Visits are written in the background, not on every visit
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Visit() should not write the file right away, Stat() error = %v", err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(tmps) != 0 {
+		t.Errorf("temporary files left behind: %v", tmps)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	entries := reopened.Query(nil)
+	if got := paths(entries); !reflect.DeepEqual(got, []string{"/b", "/a"}) {
+		t.Errorf("Query() after reopening = %v, want [/b /a]", got)
+	}
+	if entries[0].Rank != 2 {
+		t.Errorf("rank of /b = %v, want 2", entries[0].Rank)
+	}
+
+	os.WriteFile(path, []byte("not json"), 0600)
+	if _, err := Open(path); err == nil {
+		t.Error("Open() should fail on a corrupt file")
+	}
+}
+
+func TestBackgroundSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "directories.json")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	db.saveDelay = 10 * time.Millisecond
+	db.Visit("/a")
+	db.Visit("/b")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		reopened, err := Open(path)
+		if err == nil && len(reopened.Query(nil)) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("visits were not saved in the background")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := db.Flush(); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	now := time.Now()
+	db := newTestDB(&now)
+	for i := 0; i < 5; i++ {
+		db.Visit("/often")
+	}
+	now = now.Add(time.Minute)
+	db.Visit("/latest")
+
+	if got, want := paths(db.Recent()), []string{"/latest", "/often"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Recent() = %v, want %v", got, want)
+	}
+}
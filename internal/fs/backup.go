@@ -0,0 +1,26 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+)
+
+// BackupPath は既存のファイルを退避するための番号付きバックアップ名（file.~1~, file.~2~, ...）を返す
+// 既に使われている番号は飛ばす
+func BackupPath(path string) string {
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s.~%d~", path, n)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// Backup は既存のファイルまたはディレクトリを番号付きバックアップ名にリネームし、その名前を返す
+func Backup(path string) (string, error) {
+	backup := BackupPath(path)
+	if err := os.Rename(path, backup); err != nil {
+		return "", fmt.Errorf("failed to create backup: %w", err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,43 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "file.txt")
+
+	for i, want := range []string{"file.txt.~1~", "file.txt.~2~"} {
+		os.WriteFile(path, []byte{byte('a' + i)}, 0644)
+
+		backup, err := Backup(path)
+		if err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+		if filepath.Base(backup) != want {
+			t.Errorf("Backup() = %q, want %q", filepath.Base(backup), want)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("original path should be free after Backup()")
+		}
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "file.txt.~1~"))
+	if string(data) != "a" {
+		t.Errorf("first backup = %q, want %q", data, "a")
+	}
+}
+
+func TestBackupPathSkipsUsedNumbers(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "file.txt")
+	os.WriteFile(path+".~1~", nil, 0644)
+	os.WriteFile(path+".~2~", nil, 0644)
+
+	if got := BackupPath(path); got != path+".~3~" {
+		t.Errorf("BackupPath() = %q, want %q", got, path+".~3~")
+	}
+}
@@ -0,0 +1,186 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CompareMode はディレクトリ比較で差分を判定する方法
+type CompareMode int
+
+const (
+	// CompareByName は名前だけを比較し、片側にしかないエントリを差分とする
+	CompareByName CompareMode = iota
+	// CompareBySizeTime はサイズと更新時刻（秒単位）も比較する
+	CompareBySizeTime
+	// CompareByChecksum はサイズが同じファイルの内容をハッシュ値で比較する
+	CompareByChecksum
+)
+
+// CompareStatus は比較で見つかった差分の種類
+type CompareStatus int
+
+const (
+	// CompareLeftOnly は左側にしかないエントリ
+	CompareLeftOnly CompareStatus = iota
+	// CompareRightOnly は右側にしかないエントリ
+	CompareRightOnly
+	// CompareLeftNewer は両側にあり左側の更新時刻が新しいエントリ
+	CompareLeftNewer
+	// CompareRightNewer は両側にあり右側の更新時刻が新しいエントリ
+	CompareRightNewer
+	// CompareDiffers は両側にあり更新時刻では新旧を決められない差分
+	CompareDiffers
+)
+
+// CompareDiff は比較で見つかった1つの差分
+type CompareDiff struct {
+	Rel    string // 比較したディレクトリからの相対パス
+	Status CompareStatus
+}
+
+// CompareResult はディレクトリ比較の結果
+type CompareResult struct {
+	Diffs []CompareDiff // 相対パス順
+}
+
+// CompareDirs は左右のディレクトリを比較して差分を返す
+// recursiveがfalseの場合、両側にあるディレクトリは同じものとみなす
+func CompareDirs(ctx context.Context, left, right string, mode CompareMode, recursive bool) (*CompareResult, error) {
+	for _, dir := range []string{left, right} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+	}
+
+	result := &CompareResult{}
+	if err := compareDir(ctx, left, right, "", mode, recursive, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// compareDir は同じ相対パスにある2つのディレクトリの中身を比較する
+func compareDir(ctx context.Context, leftRoot, rightRoot, rel string, mode CompareMode, recursive bool, result *CompareResult) error {
+	leftEntries, err := readEntryInfos(filepath.Join(leftRoot, rel))
+	if err != nil {
+		return err
+	}
+	rightEntries, err := readEntryInfos(filepath.Join(rightRoot, rel))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(leftEntries)+len(rightEntries))
+	for name := range leftEntries {
+		names = append(names, name)
+	}
+	for name := range rightEntries {
+		if _, ok := leftEntries[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entryRel := filepath.Join(rel, name)
+		leftInfo, inLeft := leftEntries[name]
+		rightInfo, inRight := rightEntries[name]
+
+		switch {
+		case !inRight:
+			result.Diffs = append(result.Diffs, CompareDiff{Rel: entryRel, Status: CompareLeftOnly})
+		case !inLeft:
+			result.Diffs = append(result.Diffs, CompareDiff{Rel: entryRel, Status: CompareRightOnly})
+		case leftInfo.IsDir() && rightInfo.IsDir():
+			if recursive {
+				if err := compareDir(ctx, leftRoot, rightRoot, entryRel, mode, recursive, result); err != nil {
+					return err
+				}
+			}
+		case mode == CompareByName:
+			if leftInfo.IsDir() != rightInfo.IsDir() {
+				result.Diffs = append(result.Diffs, CompareDiff{Rel: entryRel, Status: CompareDiffers})
+			}
+		default:
+			compare := SyncCompareSizeTime
+			if mode == CompareByChecksum {
+				compare = SyncCompareChecksum
+			}
+			differs, err := syncDiffers(ctx, filepath.Join(leftRoot, entryRel), filepath.Join(rightRoot, entryRel), leftInfo, rightInfo, compare)
+			if err != nil {
+				return err
+			}
+			if differs {
+				result.Diffs = append(result.Diffs, CompareDiff{Rel: entryRel, Status: newerSide(leftInfo, rightInfo)})
+			}
+		}
+	}
+	return nil
+}
+
+// newerSide は内容が異なるエントリの新しい側を更新時刻で判定する
+func newerSide(leftInfo, rightInfo os.FileInfo) CompareStatus {
+	leftTime := leftInfo.ModTime().Truncate(time.Second)
+	rightTime := rightInfo.ModTime().Truncate(time.Second)
+	switch {
+	case leftInfo.IsDir() != rightInfo.IsDir() || leftTime.Equal(rightTime):
+		return CompareDiffers
+	case leftTime.After(rightTime):
+		return CompareLeftNewer
+	default:
+		return CompareRightNewer
+	}
+}
+
+// Count は指定した種類の差分の数を返す
+func (r *CompareResult) Count(status CompareStatus) int {
+	count := 0
+	for _, diff := range r.Diffs {
+		if diff.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Marks は反対側へコピーすべきエントリの名前を左右それぞれ返す
+// 片側にしかないもの・新しい側のものをマークし、新旧を決められない差分は両側をマークする
+// 再帰比較で見つかった差分は、それを含む直下のディレクトリの名前になる
+func (r *CompareResult) Marks() (left, right []string) {
+	seenLeft := make(map[string]bool)
+	seenRight := make(map[string]bool)
+	add := func(names *[]string, seen map[string]bool, rel string) {
+		name := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+		if !seen[name] {
+			seen[name] = true
+			*names = append(*names, name)
+		}
+	}
+
+	for _, diff := range r.Diffs {
+		switch diff.Status {
+		case CompareLeftOnly, CompareLeftNewer:
+			add(&left, seenLeft, diff.Rel)
+		case CompareRightOnly, CompareRightNewer:
+			add(&right, seenRight, diff.Rel)
+		case CompareDiffers:
+			add(&left, seenLeft, diff.Rel)
+			add(&right, seenRight, diff.Rel)
+		}
+	}
+	return left, right
+}
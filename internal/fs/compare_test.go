@@ -0,0 +1,108 @@
+package fs
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// setupCompareDirs creates two directories with every kind of difference
+func setupCompareDirs(t *testing.T) (string, string) {
+	t.Helper()
+	left, right := t.TempDir(), t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	writeSyncFile(t, left, "same.txt", "same", now)
+	writeSyncFile(t, right, "same.txt", "same", now)
+	writeSyncFile(t, left, "only-left.txt", "l", now)
+	writeSyncFile(t, right, "only-right.txt", "r", now)
+	writeSyncFile(t, left, "newer-left.txt", "new", now)
+	writeSyncFile(t, right, "newer-left.txt", "old", now.Add(-time.Hour))
+	writeSyncFile(t, left, "edited.txt", "abcd", now)
+	writeSyncFile(t, right, "edited.txt", "abce", now)
+	writeSyncFile(t, left, "sub/a.txt", "a", now)
+	writeSyncFile(t, right, "sub/a.txt", "a", now)
+	writeSyncFile(t, right, "sub/b.txt", "b", now)
+	return left, right
+}
+
+func compareStatuses(result *CompareResult) map[string]CompareStatus {
+	statuses := make(map[string]CompareStatus)
+	for _, diff := range result.Diffs {
+		statuses[filepath.ToSlash(diff.Rel)] = diff.Status
+	}
+	return statuses
+}
+
+func TestCompareDirs(t *testing.T) {
+	left, right := setupCompareDirs(t)
+
+	tests := []struct {
+		name      string
+		mode      CompareMode
+		recursive bool
+		want      map[string]CompareStatus
+	}{
+		{
+			name: "by name",
+			mode: CompareByName,
+			want: map[string]CompareStatus{"only-left.txt": CompareLeftOnly, "only-right.txt": CompareRightOnly},
+		},
+		{
+			name: "by size and mtime",
+			mode: CompareBySizeTime,
+			want: map[string]CompareStatus{
+				"only-left.txt":  CompareLeftOnly,
+				"only-right.txt": CompareRightOnly,
+				"newer-left.txt": CompareLeftNewer,
+			},
+		},
+		{
+			name:      "by checksum, recursive",
+			mode:      CompareByChecksum,
+			recursive: true,
+			want: map[string]CompareStatus{
+				"only-left.txt":  CompareLeftOnly,
+				"only-right.txt": CompareRightOnly,
+				"newer-left.txt": CompareLeftNewer,
+				"edited.txt":     CompareDiffers,
+				"sub/b.txt":      CompareRightOnly,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompareDirs(context.Background(), left, right, tt.mode, tt.recursive)
+			if err != nil {
+				t.Fatalf("CompareDirs() error = %v", err)
+			}
+			if got := compareStatuses(result); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareDirs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareResultMarks(t *testing.T) {
+	left, right := setupCompareDirs(t)
+	result, err := CompareDirs(context.Background(), left, right, CompareByChecksum, true)
+	if err != nil {
+		t.Fatalf("CompareDirs() error = %v", err)
+	}
+
+	leftMarks, rightMarks := result.Marks()
+	sort.Strings(leftMarks)
+	sort.Strings(rightMarks)
+	if want := []string{"edited.txt", "newer-left.txt", "only-left.txt"}; !reflect.DeepEqual(leftMarks, want) {
+		t.Errorf("left marks = %v, want %v", leftMarks, want)
+	}
+	// Differences inside sub mark the directory itself
+	if want := []string{"edited.txt", "only-right.txt", "sub"}; !reflect.DeepEqual(rightMarks, want) {
+		t.Errorf("right marks = %v, want %v", rightMarks, want)
+	}
+	if result.Count(CompareRightOnly) != 2 {
+		t.Errorf("Count(CompareRightOnly) = %d, want 2", result.Count(CompareRightOnly))
+	}
+}
@@ -0,0 +1,166 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyRangeChunk は copy_file_range 1回あたりの最大バイト数
+// ユーザー空間でのコピーと同じ間隔でキャンセル・一時停止・進捗を確認する
+const copyRangeChunk = copyBufferSize
+
+// seekFile は SEEK_DATA/SEEK_HOLE に使う lseek（テストで差し替える）
+var seekFile = unix.Seek
+
+// copyFileData はファイルの内容をコピーする
+// FICLONE によるリフリンク、copy_file_range、ユーザー空間でのコピーの順に試し、
+// スパースファイルの穴は SEEK_DATA/SEEK_HOLE で検出して宛先でも穴のまま残す
+func copyFileData(ctx context.Context, dst, src *os.File, size int64, opts CopyOptions) error {
+	// btrfs/xfs などではデータブロックを共有するだけで済む
+	if size > 0 && unix.IoctlFileClone(int(dst.Fd()), int(src.Fd())) == nil {
+		opts.Progress.AddBytes(size)
+		return nil
+	}
+
+	segments, err := dataSegments(src, size)
+	if err != nil {
+		// 穴の検出が途中で失敗するとファイル位置がずれたままなので、先頭に戻してからコピーする
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := dst.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return copyStream(ctx, dst, src, opts)
+	}
+
+	useCopyRange := true
+	var offset int64
+	for _, seg := range segments {
+		// 穴の部分は書き込まずに進捗だけ進める
+		opts.Progress.AddBytes(seg.offset - offset)
+		offset = seg.offset
+
+		end := seg.offset + seg.length
+		for offset < end {
+			if err := opts.checkpoint(ctx); err != nil {
+				return err
+			}
+			n := min(end-offset, copyRangeChunk)
+
+			if useCopyRange {
+				written, err := copyRange(dst, src, offset, n)
+				if err == nil && written > 0 {
+					opts.Progress.AddBytes(written)
+					offset += written
+					continue
+				}
+				if err != nil && !isCopyRangeUnsupported(err) {
+					return err
+				}
+				// 未対応のファイルシステム（またはサイズ0を返すファイル）では以降ユーザー空間でコピーする
+				useCopyRange = false
+			}
+
+			written, err := copyRangeStream(ctx, dst, src, offset, n, opts)
+			offset += written
+			if err != nil {
+				return err
+			}
+			if written < n {
+				// ソースが途中で縮んだ
+				return dst.Truncate(offset)
+			}
+		}
+	}
+
+	// 末尾の穴も含めて元のサイズに揃える
+	opts.Progress.AddBytes(size - offset)
+	return dst.Truncate(size)
+}
+
+// dataSegment はファイル中のデータが存在する範囲
+type dataSegment struct {
+	offset int64
+	length int64
+}
+
+// dataSegments はソースファイルのデータ範囲を返す
+// 穴を含まないファイルではファイル全体を1つの範囲として返す
+func dataSegments(f *os.File, size int64) ([]dataSegment, error) {
+	if size == 0 {
+		return nil, nil
+	}
+	if !isSparse(f, size) {
+		return []dataSegment{{offset: 0, length: size}}, nil
+	}
+
+	var segments []dataSegment
+	fd := int(f.Fd())
+	for offset := int64(0); offset < size; {
+		data, err := seekFile(fd, offset, unix.SEEK_DATA)
+		if errors.Is(err, unix.ENXIO) {
+			// 以降はすべて穴
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		hole, err := seekFile(fd, data, unix.SEEK_HOLE)
+		if err != nil {
+			return nil, err
+		}
+		hole = min(hole, size)
+		if hole > data {
+			segments = append(segments, dataSegment{offset: data, length: hole - data})
+		}
+		offset = hole
+	}
+
+	// 以降の読み込みに備えてファイル位置を戻す
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return segments, nil
+}
+
+// isSparse は割り当て済みブロックがファイルサイズより少ない（穴がある）かを判定する
+func isSparse(f *os.File, size int64) bool {
+	var st unix.Stat_t
+	if err := unix.Fstat(int(f.Fd()), &st); err != nil {
+		return false
+	}
+	return st.Blocks*512 < size
+}
+
+// copyRange は copy_file_range でカーネル内コピーを行う
+func copyRange(dst, src *os.File, offset, length int64) (int64, error) {
+	srcOff, dstOff := offset, offset
+	n, err := unix.CopyFileRange(int(src.Fd()), &srcOff, int(dst.Fd()), &dstOff, int(length), 0)
+	return int64(n), err
+}
+
+// isCopyRangeUnsupported は copy_file_range が使えずフォールバックすべきエラーかを判定する
+func isCopyRangeUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOSYS) ||
+		errors.Is(err, unix.EXDEV) ||
+		errors.Is(err, unix.EOPNOTSUPP) ||
+		errors.Is(err, unix.EINVAL) ||
+		errors.Is(err, unix.EBADF) ||
+		errors.Is(err, unix.EPERM)
+}
+
+// copyRangeStream は指定範囲をユーザー空間のバッファ経由でコピーする
+func copyRangeStream(ctx context.Context, dst, src *os.File, offset, length int64, opts CopyOptions) (int64, error) {
+	writer := &progressWriter{ctx: ctx, w: io.NewOffsetWriter(dst, offset), opts: opts}
+	return io.CopyBuffer(writer, io.NewSectionReader(src, offset, length), make([]byte, copyBufferSize))
+}
+
+// dropPageCache はファイルのページキャッシュを破棄し、次の読み込みをディスクから行わせる
+func dropPageCache(f *os.File) {
+	unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
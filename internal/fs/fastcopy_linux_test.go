@@ -0,0 +1,217 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// createSparseFile は size バイトのうち指定したオフセットにだけデータを持つスパースファイルを作成する
+func createSparseFile(t testing.TB, path string, size int64, offsets ...int64) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := f.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+	for _, off := range offsets {
+		if _, err := f.WriteAt(bytes.Repeat([]byte{0xAB}, 4096), off); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// allocatedBytes はファイルに実際に割り当てられているバイト数を返す
+func allocatedBytes(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Sys().(*syscall.Stat_t).Blocks * 512
+}
+
+func TestCopyFilePreservesHoles(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "disk.img")
+	const size = 64 * 1024 * 1024
+	createSparseFile(t, src, size, 1024*1024, 32*1024*1024)
+	if allocatedBytes(t, src) >= size {
+		t.Skip("filesystem does not support sparse files")
+	}
+
+	dst := filepath.Join(tmpDir, "copy.img")
+	progress := NewProgress(size, 1)
+	if err := CopyContext(context.Background(), src, dst, CopyOptions{Progress: progress}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	srcData, _ := os.ReadFile(src)
+	dstData, _ := os.ReadFile(dst)
+	if !bytes.Equal(srcData, dstData) {
+		t.Fatal("copied content differs from the source")
+	}
+	if got := allocatedBytes(t, dst); got >= size/2 {
+		t.Errorf("copy allocates %d bytes, holes should be preserved", got)
+	}
+	if snap := progress.Snapshot(); snap.DoneBytes != size {
+		t.Errorf("DoneBytes = %d, want %d", snap.DoneBytes, size)
+	}
+}
+
+func TestCopyFileTrailingHole(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "tail.img")
+	createSparseFile(t, src, 8*1024*1024, 0)
+
+	dst := filepath.Join(tmpDir, "copy.img")
+	if err := CopyFile(src, dst); err != nil {
+		t.Fatalf("CopyFile() error = %v", err)
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 8*1024*1024 {
+		t.Errorf("size = %d, want %d", info.Size(), 8*1024*1024)
+	}
+}
+
+func TestDataSegments(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("dense file is one segment", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "dense.bin")
+		os.WriteFile(path, make([]byte, 10000), 0644)
+		f, _ := os.Open(path)
+		defer f.Close()
+
+		segments, err := dataSegments(f, 10000)
+		if err != nil {
+			t.Fatalf("dataSegments() error = %v", err)
+		}
+		if len(segments) != 1 || segments[0] != (dataSegment{offset: 0, length: 10000}) {
+			t.Errorf("segments = %+v, want the whole file", segments)
+		}
+	})
+
+	t.Run("sparse file skips holes", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "sparse.bin")
+		createSparseFile(t, path, 16*1024*1024, 4*1024*1024)
+		f, _ := os.Open(path)
+		defer f.Close()
+		if !isSparse(f, 16*1024*1024) {
+			t.Skip("filesystem does not support sparse files")
+		}
+
+		segments, err := dataSegments(f, 16*1024*1024)
+		if err != nil {
+			t.Fatalf("dataSegments() error = %v", err)
+		}
+		var total int64
+		for _, seg := range segments {
+			total += seg.length
+			if seg.offset > 4*1024*1024 || seg.offset+seg.length < 4*1024*1024+4096 {
+				t.Errorf("segment %+v does not cover the written data", seg)
+			}
+		}
+		if len(segments) == 0 || total >= 16*1024*1024 {
+			t.Errorf("segments = %+v, want only the data region", segments)
+		}
+	})
+}
+
+// benchmarkCopy は copy で src を繰り返しコピーする
+func benchmarkCopy(b *testing.B, src string, size int64, copy func(dst, src *os.File) error) {
+	dstPath := filepath.Join(b.TempDir(), "copy.bin")
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		srcFile, err := os.Open(src)
+		if err != nil {
+			b.Fatal(err)
+		}
+		dstFile, err := os.Create(dstPath)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := copy(dstFile, srcFile); err != nil {
+			b.Fatal(err)
+		}
+		srcFile.Close()
+		dstFile.Close()
+	}
+}
+
+// BenchmarkCopyFile は従来のユーザー空間コピー（stream）と高速コピー（fast）を比較する
+func BenchmarkCopyFile(b *testing.B) {
+	ctx := context.Background()
+	stream := func(dst, src *os.File) error {
+		return copyStream(ctx, dst, src, CopyOptions{})
+	}
+
+	tmpDir := b.TempDir()
+	const size = 64 * 1024 * 1024
+	dense := filepath.Join(tmpDir, "dense.bin")
+	if err := os.WriteFile(dense, bytes.Repeat([]byte("duofm"), size/5), 0644); err != nil {
+		b.Fatal(err)
+	}
+	sparse := filepath.Join(tmpDir, "sparse.img")
+	createSparseFile(b, sparse, size, 0, size/2)
+
+	for _, file := range []struct {
+		name string
+		path string
+	}{
+		{"dense", dense},
+		{"sparse", sparse},
+	} {
+		info, err := os.Stat(file.path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		fast := func(dst, src *os.File) error {
+			return copyFileData(ctx, dst, src, info.Size(), CopyOptions{})
+		}
+		b.Run(file.name+"/stream", func(b *testing.B) { benchmarkCopy(b, file.path, info.Size(), stream) })
+		b.Run(file.name+"/fast", func(b *testing.B) { benchmarkCopy(b, file.path, info.Size(), fast) })
+	}
+}
+
+func TestCopyFileFallbackAfterPartialSeek(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "sparse.img")
+	const size = 8 * 1024 * 1024
+	createSparseFile(t, src, size, 1024*1024, 4*1024*1024)
+	if allocatedBytes(t, src) >= size {
+		t.Skip("filesystem does not support sparse files")
+	}
+
+	// SEEK_DATA moves the file offset to the first data, then SEEK_HOLE fails
+	defer func(orig func(int, int64, int) (int64, error)) { seekFile = orig }(seekFile)
+	seekFile = func(fd int, offset int64, whence int) (int64, error) {
+		if whence == unix.SEEK_HOLE {
+			return 0, unix.EIO
+		}
+		return unix.Seek(fd, offset, whence)
+	}
+
+	dst := filepath.Join(tmpDir, "copy.img")
+	if err := CopyFile(src, dst); err != nil {
+		t.Fatalf("CopyFile() error = %v", err)
+	}
+	srcData, _ := os.ReadFile(src)
+	dstData, _ := os.ReadFile(dst)
+	if !bytes.Equal(srcData, dstData) {
+		t.Fatalf("fallback copy differs from the source (%d of %d bytes)", len(dstData), len(srcData))
+	}
+}
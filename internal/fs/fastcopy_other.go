@@ -0,0 +1,16 @@
+//go:build !linux
+
+package fs
+
+import (
+	"context"
+	"os"
+)
+
+// copyFileData はファイルの内容をコピーする
+func copyFileData(ctx context.Context, dst, src *os.File, size int64, opts CopyOptions) error {
+	return copyStream(ctx, dst, src, opts)
+}
+
+// dropPageCache はページキャッシュを破棄する（Linux以外では何もしない）
+func dropPageCache(f *os.File) {}
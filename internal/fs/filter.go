@@ -0,0 +1,287 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Filter はコピー・移動・削除の対象を glob パターンで絞り込む
+//
+// スラッシュを含まないパターンは任意の階層のファイル名に、
+// スラッシュを含むパターンは操作対象からの相対パスにマッチする。
+// Exclude にマッチしたファイルとディレクトリ（中身ごと）は対象外になる。
+// Include が指定されている場合、いずれかにマッチするファイルだけが対象になる
+// （ディレクトリは中身を調べるために常にたどる）。
+type Filter struct {
+	Include []string
+	Exclude []string
+}
+
+// NewFilter はパターンを検証してフィルタを作成する
+// パターンが1つもない場合はnil（すべてが対象）を返す
+func NewFilter(include, exclude []string) (*Filter, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	return &Filter{Include: include, Exclude: exclude}, nil
+}
+
+// ParseFilter は空白区切りのパターン列からフィルタを作成する
+// "!" で始まるパターンは除外、それ以外は対象とするパターンになる
+// 例: "*.go !vendor !*_test.go"
+func ParseFilter(spec string) (*Filter, error) {
+	var include, exclude []string
+	for _, field := range strings.Fields(spec) {
+		if strings.HasPrefix(field, "!") {
+			if pattern := field[1:]; pattern != "" {
+				exclude = append(exclude, pattern)
+			}
+			continue
+		}
+		include = append(include, field)
+	}
+	return NewFilter(include, exclude)
+}
+
+// String は ParseFilter で読み戻せる形式でフィルタを返す
+func (f *Filter) String() string {
+	if f == nil {
+		return ""
+	}
+	fields := append([]string{}, f.Include...)
+	for _, pattern := range f.Exclude {
+		fields = append(fields, "!"+pattern)
+	}
+	return strings.Join(fields, " ")
+}
+
+// Excludes はエントリを対象外にするかを判定する
+// relは操作対象（コピー元など）から見た相対パス（操作対象自身の場合はその名前）
+// nilのフィルタは何も除外しない
+func (f *Filter) Excludes(rel string, isDir bool) bool {
+	if f == nil {
+		return false
+	}
+	if matchAny(f.Exclude, rel) {
+		return true
+	}
+	if isDir || len(f.Include) == 0 {
+		return false
+	}
+	return !matchAny(f.Include, rel)
+}
+
+// HasIncludes は対象とするパターンが指定されているかを返す
+// この場合、マッチするファイルを含まないディレクトリは作成されない
+func (f *Filter) HasIncludes() bool {
+	return f != nil && len(f.Include) > 0
+}
+
+// matchAny は相対パスがいずれかのパターンにマッチするかを判定する
+func matchAny(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	name := rel[strings.LastIndex(rel, "/")+1:]
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		if ok, _ := filepath.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRel はフィルタと照合するrootからの相対パスを返す
+// root自身の場合はその名前を返す
+func filterRel(root, path string) string {
+	if path == root {
+		return filepath.Base(root)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return rel
+}
+
+// WalkFiltered は root 以下のフィルタの対象となるファイルを順に fn に渡す
+// 除外されたディレクトリの中はたどらない。ディレクトリ自身は渡さない
+func WalkFiltered(root string, filter *Filter, fn func(path string, d fs.DirEntry) error) error {
+	return walkFiltered(root, root, filter, fn)
+}
+
+// walkFiltered は filterRoot からの相対パスでフィルタと照合しながら root 以下をたどる
+func walkFiltered(root, filterRoot string, filter *Filter, fn func(path string, d fs.DirEntry) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filter.Excludes(filterRel(filterRoot, path), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		return fn(path, d)
+	})
+}
+
+// FilterPaths はパスの一覧をフィルタの対象となるファイルの一覧に展開する
+// フィルタがnilの場合はそのまま返す
+func FilterPaths(paths []string, filter *Filter) ([]string, error) {
+	if filter == nil {
+		return paths, nil
+	}
+	var files []string
+	for _, path := range paths {
+		err := WalkFiltered(path, filter, func(path string, d fs.DirEntry) error {
+			files = append(files, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// CalculateFilteredSize はフィルタの対象となるファイルの総サイズと数を計算する
+func CalculateFilteredSize(paths []string, filter *Filter) (int64, int, error) {
+	if filter == nil {
+		return CalculateSize(paths)
+	}
+	var total int64
+	var count int
+	for _, path := range paths {
+		size, files, err := filteredSize(path, path, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		total += size
+		count += files
+	}
+	return total, count, nil
+}
+
+// filteredSize は filterRoot からの相対パスでフィルタと照合して path 以下の対象ファイルの総サイズと数を計算する
+func filteredSize(path, filterRoot string, filter *Filter) (int64, int, error) {
+	if filter == nil {
+		return CalculateSize([]string{path})
+	}
+	var total int64
+	var count int
+	err := walkFiltered(path, filterRoot, filter, func(path string, d fs.DirEntry) error {
+		count++
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, count, err
+}
+
+// moveFiltered はフィルタの対象となるエントリだけを移動する
+// ディレクトリは宛先に作成し、対象外のエントリが残らなかったソースディレクトリだけを削除する
+func moveFiltered(ctx context.Context, src, dst string, opts CopyOptions) error {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("source not found: %w", err)
+	}
+	if opts.Filter.Excludes(filepath.Base(src), srcInfo.IsDir()) {
+		return nil
+	}
+	filter := opts.Filter
+	opts.Filter = nil
+	if !srcInfo.IsDir() {
+		return MoveContext(ctx, src, dst, opts)
+	}
+	return moveFilteredDir(ctx, src, src, ResolveDestPath(src, dst), srcInfo, filter, opts)
+}
+
+// moveFilteredDir はディレクトリの中身をフィルタに従って再帰的に移動する
+func moveFilteredDir(ctx context.Context, root, src, dstPath string, srcInfo os.FileInfo, filter *Filter, opts CopyOptions) error {
+	_, statErr := os.Lstat(dstPath)
+	created := os.IsNotExist(statErr)
+	if err := os.MkdirAll(dstPath, srcInfo.Mode().Perm()|0700); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := opts.checkpoint(ctx); err != nil {
+			return err
+		}
+
+		srcPath := filepath.Join(src, entry.Name())
+		destPath := filepath.Join(dstPath, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", srcPath, err)
+		}
+		if filter.Excludes(filterRel(root, srcPath), info.IsDir()) {
+			continue
+		}
+
+		if info.IsDir() {
+			err = moveFilteredDir(ctx, root, srcPath, destPath, info, filter, opts)
+		} else if _, existsErr := os.Lstat(destPath); opts.SkipExisting && existsErr == nil {
+			if info.Mode().IsRegular() {
+				opts.Progress.AddBytes(info.Size())
+			}
+			opts.Progress.FileDone()
+			err = ErrDestinationExists
+		} else {
+			err = MoveContext(ctx, srcPath, destPath, opts)
+		}
+		if err == nil {
+			continue
+		}
+		switch {
+		case opts.OnSkip != nil && IsSkippable(err):
+			opts.OnSkip(srcPath, err)
+		case opts.OnVerifyFailed != nil && errors.Is(err, ErrChecksumMismatch):
+			opts.OnVerifyFailed(srcPath, err)
+		case errors.Is(err, ErrDestinationExists):
+			// SkipExistingでOnSkipがない場合は報告せずにスキップする
+		default:
+			return err
+		}
+	}
+
+	if created && filter.HasIncludes() && isEmptyDir(dstPath) {
+		os.Remove(dstPath)
+	} else if err := preserveMetadata(src, dstPath, srcInfo); err != nil {
+		return err
+	}
+
+	// 対象外のエントリが残ったソースディレクトリはそのまま残す
+	if isEmptyDir(src) {
+		os.Remove(src)
+	}
+	return nil
+}
+
+// isEmptyDir はディレクトリが空かどうかを判定する
+func isEmptyDir(path string) bool {
+	entries, err := os.ReadDir(path)
+	return err == nil && len(entries) == 0
+}
@@ -0,0 +1,197 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter("  *.go !vendor   !*_test.go ")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	if len(filter.Include) != 1 || filter.Include[0] != "*.go" {
+		t.Errorf("Include = %v, want [*.go]", filter.Include)
+	}
+	if len(filter.Exclude) != 2 || filter.Exclude[0] != "vendor" || filter.Exclude[1] != "*_test.go" {
+		t.Errorf("Exclude = %v, want [vendor *_test.go]", filter.Exclude)
+	}
+	if got := filter.String(); got != "*.go !vendor !*_test.go" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if filter, err := ParseFilter("   "); err != nil || filter != nil {
+		t.Errorf("ParseFilter(blank) = %v, %v, want nil, nil", filter, err)
+	}
+	if _, err := ParseFilter("[abc"); err == nil {
+		t.Error("ParseFilter() should reject a malformed pattern")
+	}
+}
+
+func TestFilterExcludes(t *testing.T) {
+	filter, _ := ParseFilter("*.go docs/*.md !vendor !*_test.go")
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"main.go", false, false},
+		{"pkg/util.go", false, false},
+		{"pkg/util_test.go", false, true},
+		{"README.md", false, true},
+		{"docs/guide.md", false, false},
+		{"pkg/docs/guide.md", false, true},
+		{"vendor", true, true},
+		{"pkg", true, false},
+	}
+	for _, tt := range tests {
+		if got := filter.Excludes(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("Excludes(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+
+	var none *Filter
+	if none.Excludes("anything", false) {
+		t.Error("nil filter should not exclude anything")
+	}
+}
+
+// createFilterTree creates src/{a.go,a_test.go,notes.txt,pkg/b.go,pkg/c.txt,vendor/d.go,docs/e.txt}
+func createFilterTree(t *testing.T) string {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "src")
+	for _, name := range []string{"a.go", "a_test.go", "notes.txt", "pkg/b.go", "pkg/c.txt", "vendor/d.go", "docs/e.txt"} {
+		path := filepath.Join(src, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(name), 0644)
+	}
+	return src
+}
+
+// listFiles returns the files below root as sorted slash separated relative paths
+func listFiles(t *testing.T, root string) []string {
+	t.Helper()
+	var files []string
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func assertFiles(t *testing.T, root string, want ...string) {
+	t.Helper()
+	got := listFiles(t, root)
+	if len(got) != len(want) {
+		t.Fatalf("files in %s = %v, want %v", root, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("files in %s = %v, want %v", root, got, want)
+		}
+	}
+}
+
+func TestCopyContextWithFilter(t *testing.T) {
+	src := createFilterTree(t)
+	dest := filepath.Join(t.TempDir(), "dest")
+	filter, _ := ParseFilter("*.go !vendor !*_test.go")
+
+	if err := CopyContext(context.Background(), src, dest, CopyOptions{Filter: filter}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+	assertFiles(t, dest, "a.go", "pkg/b.go")
+
+	// Directories without matching files are not created
+	if _, err := os.Stat(filepath.Join(dest, "docs")); !os.IsNotExist(err) {
+		t.Error("empty directory docs should not be created")
+	}
+}
+
+func TestCopyContextExcludedSource(t *testing.T) {
+	src := createFilterTree(t)
+	dest := t.TempDir()
+	filter, _ := ParseFilter("!src")
+
+	if err := CopyContext(context.Background(), src, dest, CopyOptions{Filter: filter}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "src")); !os.IsNotExist(err) {
+		t.Error("excluded source should not be copied")
+	}
+}
+
+func TestMoveContextWithFilter(t *testing.T) {
+	src := createFilterTree(t)
+	dest := filepath.Join(t.TempDir(), "dest")
+	filter, _ := ParseFilter("*.txt !docs")
+
+	if err := MoveContext(context.Background(), src, dest, CopyOptions{Filter: filter}); err != nil {
+		t.Fatalf("MoveContext() error = %v", err)
+	}
+	assertFiles(t, dest, "notes.txt", "pkg/c.txt")
+	// Files that were not moved stay in the source
+	assertFiles(t, src, "a.go", "a_test.go", "docs/e.txt", "pkg/b.go", "vendor/d.go")
+}
+
+func TestMoveContextWithFilterRemovesEmptiedSource(t *testing.T) {
+	src := createFilterTree(t)
+	dest := filepath.Join(t.TempDir(), "dest")
+	filter, _ := ParseFilter("!nothing")
+
+	if err := MoveContext(context.Background(), src, dest, CopyOptions{Filter: filter}); err != nil {
+		t.Fatalf("MoveContext() error = %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("source directory should be removed once everything was moved")
+	}
+	assertFiles(t, dest, "a.go", "a_test.go", "docs/e.txt", "notes.txt", "pkg/b.go", "pkg/c.txt", "vendor/d.go")
+}
+
+func TestFilterPaths(t *testing.T) {
+	src := createFilterTree(t)
+	filter, _ := ParseFilter("*.txt")
+
+	files, err := FilterPaths([]string{src}, filter)
+	if err != nil {
+		t.Fatalf("FilterPaths() error = %v", err)
+	}
+	want := []string{filepath.Join(src, "docs", "e.txt"), filepath.Join(src, "notes.txt"), filepath.Join(src, "pkg", "c.txt")}
+	if len(files) != len(want) {
+		t.Fatalf("FilterPaths() = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("FilterPaths()[%d] = %q, want %q", i, files[i], want[i])
+		}
+	}
+
+	if files, _ := FilterPaths([]string{src}, nil); len(files) != 1 || files[0] != src {
+		t.Errorf("FilterPaths(nil filter) = %v, want [%s]", files, src)
+	}
+}
+
+func TestPlanOperationWithFilter(t *testing.T) {
+	src := createFilterTree(t)
+	dest := t.TempDir()
+	filter, _ := ParseFilter("*.go !vendor")
+
+	plan, err := PlanOperation([]string{src}, dest, false, filter)
+	if err != nil {
+		t.Fatalf("PlanOperation() error = %v", err)
+	}
+	if plan.TotalFiles != 3 || plan.ExcludedFiles != 4 {
+		t.Errorf("TotalFiles, ExcludedFiles = %d, %d, want 3, 4", plan.TotalFiles, plan.ExcludedFiles)
+	}
+	if want := int64(len("a.go") + len("a_test.go") + len("pkg/b.go")); plan.TotalBytes != want {
+		t.Errorf("TotalBytes = %d, want %d", plan.TotalBytes, want)
+	}
+}
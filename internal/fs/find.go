@@ -0,0 +1,285 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FindType は検索するエントリの種類
+type FindType int
+
+const (
+	// FindAny はすべての種類を対象にする
+	FindAny FindType = iota
+	// FindFiles は通常ファイルだけを対象にする
+	FindFiles
+	// FindDirs はディレクトリだけを対象にする
+	FindDirs
+	// FindSymlinks はシンボリックリンクだけを対象にする
+	FindSymlinks
+)
+
+// FindCriteria は再帰検索の条件
+// 名前の条件はいずれかに一致すればよく、それ以外の条件はすべて満たす必要がある
+type FindCriteria struct {
+	Names     []string       // 名前のグロブパターン（メタ文字がなければ部分一致）
+	Regex     *regexp.Regexp // 名前の正規表現（nil = 条件なし）
+	Type      FindType
+	MinSize   int64     // このサイズ以上（0 = 下限なし）
+	SizeBelow int64     // このサイズ未満（0 = 上限なし）
+	NewerThan time.Time // この時刻より後に更新されたもの（ゼロ値 = 条件なし）
+	OlderThan time.Time // この時刻より前に更新されたもの（ゼロ値 = 条件なし）
+	Hidden    bool      // 隠しファイル・隠しディレクトリも検索する
+}
+
+// ErrEmptyQuery は検索条件が指定されていないことを示す
+var ErrEmptyQuery = errors.New("empty search query")
+
+// ParseFindQuery は空白区切りの検索クエリを解析する
+//
+//	*.go report      名前（グロブ、またはメタ文字なしの部分一致。小文字だけなら大文字小文字を区別しない）
+//	re:^test_.*\.py$ 名前の正規表現
+//	type:f|d|l       ファイル / ディレクトリ / シンボリックリンク
+//	size:>10M size:<1k
+//	mtime:<7d        7日以内に更新（>7d は7日より前）。単位は s, m, h, d, w
+func ParseFindQuery(query string, now time.Time) (*FindCriteria, error) {
+	c := &FindCriteria{}
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	for _, field := range fields {
+		key, value, hasKey := strings.Cut(field, ":")
+		switch {
+		case hasKey && key == "re":
+			if c.Regex != nil {
+				return nil, fmt.Errorf("only one re: pattern is allowed")
+			}
+			pattern := value
+			if !hasUpper(pattern) {
+				pattern = "(?i)" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex: %w", err)
+			}
+			c.Regex = re
+		case hasKey && key == "type":
+			switch value {
+			case "f", "file":
+				c.Type = FindFiles
+			case "d", "dir":
+				c.Type = FindDirs
+			case "l", "link":
+				c.Type = FindSymlinks
+			default:
+				return nil, fmt.Errorf("unknown type %q (use f, d or l)", value)
+			}
+		case hasKey && key == "size":
+			op, amount, err := parseComparison(value, ParseByteSize)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size %q: %w", value, err)
+			}
+			switch {
+			case op == '>':
+				c.MinSize = amount + 1
+			case amount == 0:
+				return nil, fmt.Errorf("invalid size %q: nothing is smaller than 0", value)
+			default:
+				c.SizeBelow = amount
+			}
+		case hasKey && key == "mtime":
+			op, seconds, err := parseComparison(value, parseAge)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mtime %q: %w", value, err)
+			}
+			limit := now.Add(-time.Duration(seconds) * time.Second)
+			if op == '<' {
+				c.NewerThan = limit
+			} else {
+				c.OlderThan = limit
+			}
+		default:
+			if _, err := filepath.Match(field, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", field, err)
+			}
+			c.Names = append(c.Names, field)
+		}
+	}
+	return c, nil
+}
+
+// parseComparison は "<値" または ">値" を解析する
+func parseComparison(value string, parse func(string) (int64, error)) (byte, int64, error) {
+	if len(value) < 2 || (value[0] != '<' && value[0] != '>') {
+		return 0, 0, errors.New("use < or > followed by a value")
+	}
+	amount, err := parse(value[1:])
+	if err != nil {
+		return 0, 0, err
+	}
+	return value[0], amount, nil
+}
+
+// ParseByteSize は "10", "4k", "1.5M", "2G" のようなサイズを解析する（1024倍単位）
+func ParseByteSize(s string) (int64, error) {
+	multiplier := int64(1)
+	switch unicode.ToUpper(rune(s[len(s)-1])) {
+	case 'K':
+		multiplier = 1 << 10
+	case 'M':
+		multiplier = 1 << 20
+	case 'G':
+		multiplier = 1 << 30
+	case 'T':
+		multiplier = 1 << 40
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("not a size")
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// parseAge は "30m", "12h", "7d", "2w" のような期間を秒数で返す
+func parseAge(s string) (int64, error) {
+	units := map[byte]int64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 7 * 86400}
+	unit, ok := units[s[len(s)-1]]
+	if !ok {
+		return 0, errors.New("use a unit of s, m, h, d or w")
+	}
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("not a number")
+	}
+	return n * unit, nil
+}
+
+// hasUpper は文字列に大文字が含まれるかを返す（スマートケース判定用）
+func hasUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches はエントリが検索条件を満たすかを判定する
+func (c *FindCriteria) Matches(name string, info os.FileInfo) bool {
+	switch c.Type {
+	case FindFiles:
+		if !info.Mode().IsRegular() {
+			return false
+		}
+	case FindDirs:
+		if !info.IsDir() {
+			return false
+		}
+	case FindSymlinks:
+		if info.Mode()&os.ModeSymlink == 0 {
+			return false
+		}
+	}
+
+	if c.MinSize > 0 || c.SizeBelow > 0 {
+		if info.IsDir() {
+			return false
+		}
+		if info.Size() < c.MinSize || (c.SizeBelow > 0 && info.Size() >= c.SizeBelow) {
+			return false
+		}
+	}
+	if !c.NewerThan.IsZero() && !info.ModTime().After(c.NewerThan) {
+		return false
+	}
+	if !c.OlderThan.IsZero() && !info.ModTime().Before(c.OlderThan) {
+		return false
+	}
+	if c.Regex != nil && !c.Regex.MatchString(name) {
+		return false
+	}
+	if len(c.Names) == 0 {
+		return true
+	}
+	for _, pattern := range c.Names {
+		if matchName(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchName は名前のパターンをスマートケースで照合する
+func matchName(pattern, name string) bool {
+	if !hasUpper(pattern) {
+		name = strings.ToLower(name)
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		return strings.Contains(name, pattern)
+	}
+	matched, _ := filepath.Match(pattern, name)
+	return matched
+}
+
+// Find は root 以下を再帰的に検索し、条件に一致したエントリを見つけた順に fn へ渡す
+// エントリの Name は root からの相対パスになる。シンボリックリンクはたどらず、
+// 読めないディレクトリは飛ばす。ctx がキャンセルされると ctx.Err() を返す
+func Find(ctx context.Context, root string, c *FindCriteria, fn func(entry FileEntry)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if path == root {
+			return err
+		}
+		if err != nil {
+			// 権限のないディレクトリなどは飛ばして続ける
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := d.Name()
+		if !c.Hidden && strings.HasPrefix(name, ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if c.Matches(name, info) {
+			rel, _ := filepath.Rel(root, path)
+			fn(newFileEntry(rel, path, info))
+		}
+		return nil
+	})
+}
+
+// ReadEntry は dir 内の name（相対パス可）のエントリ情報を読み込む
+func ReadEntry(dir, name string) (FileEntry, error) {
+	path := filepath.Join(dir, name)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return FileEntry{}, err
+	}
+	return newFileEntry(name, path, info), nil
+}
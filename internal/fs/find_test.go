@@ -0,0 +1,119 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFindQuery(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	c, err := ParseFindQuery("*.go report type:f size:>1k size:<2M mtime:<7d re:^main", now)
+	if err != nil {
+		t.Fatalf("ParseFindQuery() error = %v", err)
+	}
+	if strings.Join(c.Names, ",") != "*.go,report" || c.Type != FindFiles {
+		t.Errorf("names/type = %v %v", c.Names, c.Type)
+	}
+	if c.MinSize != 1025 || c.SizeBelow != 2<<20 {
+		t.Errorf("size = %d..%d", c.MinSize, c.SizeBelow)
+	}
+	if !c.NewerThan.Equal(now.Add(-7*24*time.Hour)) || !c.OlderThan.IsZero() {
+		t.Errorf("mtime = %v %v", c.NewerThan, c.OlderThan)
+	}
+	if c.Regex == nil || !c.Regex.MatchString("MAIN.go") {
+		t.Error("lowercase regex should ignore case")
+	}
+
+	for _, query := range []string{"", "type:x", "size:10", "size:<0", "mtime:<7y", "re:(", "[abc"} {
+		if _, err := ParseFindQuery(query, now); err == nil {
+			t.Errorf("ParseFindQuery(%q) should fail", query)
+		}
+	}
+	if _, err := ParseFindQuery("  ", now); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("blank query error = %v, want ErrEmptyQuery", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	writeSyncFile(t, root, "main.go", "package main", time.Now())
+	writeSyncFile(t, root, "README.md", strings.Repeat("x", 2048), time.Now())
+	writeSyncFile(t, root, "cmd/tool/Tool.go", "package tool", old)
+	writeSyncFile(t, root, ".git/config", "x", time.Now())
+	os.Mkdir(filepath.Join(root, "docs"), 0755)
+	os.Symlink("main.go", filepath.Join(root, "link.go"))
+
+	tests := []struct {
+		query  string
+		hidden bool
+		want   string
+	}{
+		{query: "*.go", want: "cmd/tool/Tool.go,link.go,main.go"},
+		{query: "*.go type:f", want: "cmd/tool/Tool.go,main.go"},
+		{query: "tool", want: "cmd/tool,cmd/tool/Tool.go"},
+		{query: "Tool", want: "cmd/tool/Tool.go"},
+		{query: "type:d", want: "cmd,cmd/tool,docs"},
+		{query: "type:l", want: "link.go"},
+		{query: "size:>1k", want: "README.md"},
+		{query: "*.go mtime:>7d", want: "cmd/tool/Tool.go"},
+		{query: "re:^(main|readme)", want: "README.md,main.go"},
+		{query: "config", hidden: true, want: ".git/config"},
+		{query: "config", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, err := ParseFindQuery(tt.query, time.Now())
+			if err != nil {
+				t.Fatalf("ParseFindQuery() error = %v", err)
+			}
+			c.Hidden = tt.hidden
+			var found []string
+			err = Find(context.Background(), root, c, func(entry FileEntry) {
+				found = append(found, filepath.ToSlash(entry.Name))
+			})
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			sort.Strings(found)
+			if got := strings.Join(found, ","); got != tt.want {
+				t.Errorf("Find(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCancelled(t *testing.T) {
+	root := t.TempDir()
+	writeSyncFile(t, root, "a.txt", "a", time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c, _ := ParseFindQuery("a", time.Now())
+	if err := Find(ctx, root, c, func(FileEntry) {}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Find() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestReadEntry(t *testing.T) {
+	root := t.TempDir()
+	writeSyncFile(t, root, "sub/a.txt", "abc", time.Now())
+
+	entry, err := ReadEntry(root, filepath.Join("sub", "a.txt"))
+	if err != nil {
+		t.Fatalf("ReadEntry() error = %v", err)
+	}
+	if entry.Name != filepath.Join("sub", "a.txt") || entry.Size != 3 || entry.IsDir {
+		t.Errorf("ReadEntry() = %+v", entry)
+	}
+	if _, err := ReadEntry(root, "missing"); err == nil {
+		t.Error("ReadEntry() of a missing file should fail")
+	}
+}
@@ -0,0 +1,120 @@
+package fs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule は .gitignore の1行分のルール
+type ignoreRule struct {
+	base     string   // .gitignore があるディレクトリ（検索の起点からの相対パス、起点は ""）
+	segments []string // "/" で区切ったパターン
+	negate   bool     // "!" で始まる（無視を取り消す）
+	dirOnly  bool     // "/" で終わる（ディレクトリだけに一致）
+	anchored bool     // 途中に "/" を含む（base からのパスに一致させる）
+}
+
+// gitIgnore は検索中に読み込んだ .gitignore のルールを保持する
+// 対応しているのは空行・コメント・"!"・先頭と末尾の "/"・"**" で、
+// 検索の起点より上のディレクトリの .gitignore は読まない
+type gitIgnore struct {
+	rules []ignoreRule
+}
+
+// load は dir（起点からの相対パス relDir）の .gitignore を読み込んでルールに加える
+// ファイルがなければ何もしない
+func (g *gitIgnore) load(dir, relDir string) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: filepath.ToSlash(relDir)}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // "\#" や "\!" で始まる名前
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.segments = strings.Split(line, "/")
+		g.rules = append(g.rules, rule)
+	}
+}
+
+// ignored は起点からの相対パス rel が無視されるかを返す（最後に一致したルールが優先）
+func (g *gitIgnore) ignored(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	ignored := false
+	for _, rule := range g.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			sub = rel[len(rule.base)+1:]
+		}
+
+		var matched bool
+		if rule.anchored {
+			matched = matchSegments(rule.segments, strings.Split(sub, "/"))
+		} else {
+			// "/" を含まないパターンはどの階層の名前にも一致する
+			// 親ディレクトリが無視されれば中は走査しないので、名前だけを見ればよい
+			matched, _ = filepath.Match(rule.segments[0], filepath.Base(sub))
+		}
+		if matched {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments は "**" を含むパターンをパスの要素ごとに照合する
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" && len(pattern) == 1 {
+		// 末尾の "**" は中身だけに一致する（"dir/**" は dir 自体には一致しない）
+		return len(path) > 0
+	}
+	if pattern[0] == "**" {
+		// 0個以上の要素に一致する
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}
@@ -0,0 +1,230 @@
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// grepSniffSize はバイナリ判定のために先頭から調べるバイト数
+const grepSniffSize = 8000
+
+// grepMaxLineLength はこれより長い行を含むファイルを読み飛ばす長さ
+const grepMaxLineLength = 1 << 20
+
+// grepMaxMatchText は一致した行として保持する最大バイト数
+const grepMaxMatchText = 512
+
+// ErrMatchLimit は一致数が上限に達して検索を打ち切ったことを示す
+var ErrMatchLimit = errors.New("match limit reached")
+
+// GrepOptions は内容検索の条件
+type GrepOptions struct {
+	Pattern     string // 検索する文字列（Regex なら正規表現）。小文字だけなら大文字小文字を区別しない
+	Regex       bool   // Pattern を正規表現として扱う
+	MaxFileSize int64  // これより大きいファイルは読まない（0 = 制限なし）
+	GitIgnore   bool   // .gitignore で無視されるファイルと .git ディレクトリを飛ばす
+	Hidden      bool   // 隠しファイル・隠しディレクトリも検索する
+	MaxMatches  int    // 一致数の上限（0 = 制限なし）
+	Workers     int    // ファイルを読む並列数（0 = CPU数）
+}
+
+// GrepMatch は内容検索で一致した1行
+type GrepMatch struct {
+	Path string // 検索の起点からの相対パス
+	Line int    // 1 から始まる行番号
+	Text string // 一致した行（長い行は切り詰める）
+}
+
+// GrepProgress は内容検索の進捗（検索中に別の goroutine から読める）
+type GrepProgress struct {
+	Files   atomic.Int64 // 読み込んだファイル数
+	Skipped atomic.Int64 // バイナリやサイズ超過で飛ばしたファイル数
+	Matches atomic.Int64 // 一致した行数
+}
+
+// compileGrepPattern は検索条件を行に一致する関数に変換する
+func compileGrepPattern(opts GrepOptions) (func(line []byte) bool, error) {
+	if opts.Pattern == "" {
+		return nil, ErrEmptyQuery
+	}
+	if opts.Regex {
+		pattern := opts.Pattern
+		if !hasUpper(pattern) {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.Match, nil
+	}
+	if hasUpper(opts.Pattern) {
+		literal := []byte(opts.Pattern)
+		return func(line []byte) bool { return bytes.Contains(line, literal) }, nil
+	}
+	literal := []byte(strings.ToLower(opts.Pattern))
+	return func(line []byte) bool {
+		return bytes.Contains(bytes.ToLower(line), literal)
+	}, nil
+}
+
+// ValidateGrepPattern は検索条件のパターンが使えるかを確認する
+func ValidateGrepPattern(opts GrepOptions) error {
+	_, err := compileGrepPattern(opts)
+	return err
+}
+
+// Grep は root 以下のファイルの内容を検索し、一致した行を fn へ渡す
+// ディレクトリの走査とファイルの読み込みは並列に行い、fn は1つずつ呼び出される
+// 1つのファイル内の一致は行番号順に続けて渡すが、ファイルの順序は決まらない
+// バイナリファイル（先頭に NUL を含む）、サイズ超過のファイル、読めないファイルは飛ばす
+// ctx がキャンセルされると ctx.Err() を、一致数が上限に達すると ErrMatchLimit を返す
+func Grep(ctx context.Context, root string, opts GrepOptions, progress *GrepProgress, fn func(match GrepMatch)) error {
+	match, err := compileGrepPattern(opts)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = &GrepProgress{}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu         sync.Mutex
+		count      int
+		limitError error
+	)
+	// report は1ファイル分の一致をまとめて渡す
+	report := func(matches []GrepMatch) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, m := range matches {
+			if limitError != nil {
+				return
+			}
+			fn(m)
+			count++
+			progress.Matches.Add(1)
+			if opts.MaxMatches > 0 && count >= opts.MaxMatches {
+				limitError = ErrMatchLimit
+				cancel()
+			}
+		}
+	}
+
+	paths := make(chan string, workers*4)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rel := range paths {
+				matches, ok := grepFile(searchCtx, filepath.Join(root, rel), rel, opts.MaxFileSize, match)
+				if !ok {
+					progress.Skipped.Add(1)
+					continue
+				}
+				progress.Files.Add(1)
+				if len(matches) > 0 {
+					report(matches)
+				}
+			}
+		}()
+	}
+
+	walkErr := walkGrepFiles(searchCtx, root, opts, paths)
+	close(paths)
+	wg.Wait()
+
+	if limitError != nil {
+		return limitError
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return walkErr
+}
+
+// walkGrepFiles は検索対象のファイルの相対パスを paths へ送る
+func walkGrepFiles(ctx context.Context, root string, opts GrepOptions, paths chan<- string) error {
+	return walkTree(ctx, root, opts.Hidden, opts.GitIgnore, func(rel string, d fs.DirEntry) error {
+		// シンボリックリンクなどの特殊ファイルはたどらない
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		select {
+		case paths <- rel:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// grepFile は1つのファイルから一致する行を探す
+// バイナリ、サイズ超過、長すぎる行、読み込みエラーの場合は ok = false を返す
+func grepFile(ctx context.Context, path, rel string, maxSize int64, match func([]byte) bool) (matches []GrepMatch, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	if maxSize > 0 {
+		info, err := f.Stat()
+		if err != nil || info.Size() > maxSize {
+			return nil, false
+		}
+	}
+
+	reader := bufio.NewReaderSize(f, 64*1024)
+	head, err := reader.Peek(grepSniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, false
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, false
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), grepMaxLineLength)
+	line := 0
+	for scanner.Scan() {
+		line++
+		// 大きなファイルの途中でもキャンセルに応じる
+		if line%4096 == 0 && ctx.Err() != nil {
+			return nil, false
+		}
+		text := scanner.Bytes()
+		if !match(text) {
+			continue
+		}
+		text = bytes.TrimSuffix(text, []byte("\r"))
+		if len(text) > grepMaxMatchText {
+			// 途中で切れた文字は取り除く
+			text = bytes.ToValidUTF8(text[:grepMaxMatchText], nil)
+		}
+		matches = append(matches, GrepMatch{Path: rel, Line: line, Text: string(text)})
+	}
+	if scanner.Err() != nil {
+		return nil, false
+	}
+	return matches, true
+}
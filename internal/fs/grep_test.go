@@ -0,0 +1,148 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGrep(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	writeSyncFile(t, root, "main.go", "package main\n\nfunc main() {\n\tTODO()\n}\n", now)
+	writeSyncFile(t, root, "docs/notes.txt", "todo: write docs\r\nnothing here\n", now)
+	writeSyncFile(t, root, "image.bin", "todo\x00binary", now)
+	writeSyncFile(t, root, "big.txt", "todo "+strings.Repeat("x", 2048), now)
+	writeSyncFile(t, root, ".hidden/todo.txt", "todo", now)
+	writeSyncFile(t, root, "build/out.txt", "todo generated", now)
+	writeSyncFile(t, root, "vendor/keep.txt", "todo kept", now)
+	writeSyncFile(t, root, ".gitignore", "# build output\nbuild/\n*.log\nvendor/*\n!vendor/keep.txt\n", now)
+	writeSyncFile(t, root, "debug.log", "todo log", now)
+	writeSyncFile(t, root, "docs/.gitignore", "notes.txt\n", now)
+
+	tests := []struct {
+		name string
+		opts GrepOptions
+		want string
+	}{
+		{
+			name: "smart case literal",
+			opts: GrepOptions{Pattern: "todo", MaxFileSize: 1024},
+			want: "build/out.txt:1,debug.log:1,docs/notes.txt:1,main.go:4,vendor/keep.txt:1",
+		},
+		{
+			name: "upper case is exact",
+			opts: GrepOptions{Pattern: "TODO"},
+			want: "main.go:4",
+		},
+		{
+			name: "regex",
+			opts: GrepOptions{Pattern: `^func \w+\(`, Regex: true},
+			want: "main.go:3",
+		},
+		{
+			name: "gitignore",
+			opts: GrepOptions{Pattern: "todo", MaxFileSize: 1024, GitIgnore: true},
+			want: "main.go:4,vendor/keep.txt:1",
+		},
+		{
+			name: "hidden and no size limit",
+			opts: GrepOptions{Pattern: "todo", Hidden: true, GitIgnore: true},
+			want: ".hidden/todo.txt:1,big.txt:1,main.go:4,vendor/keep.txt:1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var found []string
+			progress := &GrepProgress{}
+			err := Grep(context.Background(), root, tt.opts, progress, func(m GrepMatch) {
+				found = append(found, fmt.Sprintf("%s:%d", filepath.ToSlash(m.Path), m.Line))
+			})
+			if err != nil {
+				t.Fatalf("Grep() error = %v", err)
+			}
+			sort.Strings(found)
+			if got := strings.Join(found, ","); got != tt.want {
+				t.Errorf("Grep() = %s, want %s", got, tt.want)
+			}
+			if progress.Matches.Load() != int64(len(found)) {
+				t.Errorf("progress matches = %d, want %d", progress.Matches.Load(), len(found))
+			}
+		})
+	}
+}
+
+func TestGrepMatchText(t *testing.T) {
+	root := t.TempDir()
+	writeSyncFile(t, root, "a.txt", "first\r\nsecond needle\r\n"+strings.Repeat("あ", 300)+"needle\n", time.Now())
+
+	var matches []GrepMatch
+	Grep(context.Background(), root, GrepOptions{Pattern: "needle", Workers: 1}, nil, func(m GrepMatch) {
+		matches = append(matches, m)
+	})
+	if len(matches) != 2 || matches[0].Text != "second needle" || matches[0].Line != 2 {
+		t.Fatalf("matches = %+v", matches)
+	}
+	if len(matches[1].Text) > grepMaxMatchText || !strings.HasPrefix(matches[1].Text, "あ") || strings.ContainsRune(matches[1].Text, '�') {
+		t.Errorf("long line should be cut at a character boundary, got %d bytes", len(matches[1].Text))
+	}
+}
+
+func TestGrepLimitAndCancel(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 20; i++ {
+		writeSyncFile(t, root, fmt.Sprintf("f%02d.txt", i), "hit\nhit\n", time.Now())
+	}
+
+	count := 0
+	err := Grep(context.Background(), root, GrepOptions{Pattern: "hit", MaxMatches: 5}, nil, func(GrepMatch) { count++ })
+	if !errors.Is(err, ErrMatchLimit) || count != 5 {
+		t.Errorf("Grep() = %v with %d matches, want ErrMatchLimit after 5", err, count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Grep(ctx, root, GrepOptions{Pattern: "hit"}, nil, func(GrepMatch) {}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Grep() error = %v, want context.Canceled", err)
+	}
+
+	if err := ValidateGrepPattern(GrepOptions{Pattern: "(", Regex: true}); err == nil {
+		t.Error("an invalid regex should be rejected")
+	}
+	if err := ValidateGrepPattern(GrepOptions{}); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("empty pattern error = %v, want ErrEmptyQuery", err)
+	}
+}
+
+func TestGitIgnore(t *testing.T) {
+	root := t.TempDir()
+	writeSyncFile(t, root, ".gitignore", "/root-only.txt\n**/cache/**\ndocs/*.tmp\n\\#hash\n", time.Now())
+
+	var ignore gitIgnore
+	ignore.load(root, "")
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"a/b/cache/x.bin", false, true},
+		{"cache", true, false},
+		{"a/cache", true, false},
+		{"a/cache/sub", true, true},
+		{"docs/a.tmp", false, true},
+		{"other/docs/a.tmp", false, false},
+		{"#hash", false, true},
+	}
+	for _, tt := range tests {
+		if got := ignore.ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
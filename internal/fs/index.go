@@ -0,0 +1,81 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// ErrIndexLimit は登録数が上限に達して走査を打ち切ったことを示す
+var ErrIndexLimit = errors.New("index limit reached")
+
+// IndexOptions は索引を作る条件
+type IndexOptions struct {
+	Hidden     bool // 隠しファイル・隠しディレクトリも登録する
+	GitIgnore  bool // .gitignore で無視されるものと .git ディレクトリを飛ばす
+	MaxEntries int  // 登録数の上限（0 = 制限なし）
+}
+
+// IndexEntry は索引に登録されたエントリ
+type IndexEntry struct {
+	Path  string // 走査の起点からの相対パス
+	IsDir bool
+}
+
+// Index は root 以下のファイルとディレクトリを走査し、見つけた順に fn へ渡す
+// エントリの情報は読み込まない（stat しない）ので、Find より速く大きなツリーを走査できる
+// ctx がキャンセルされると ctx.Err() を、登録数が上限に達すると ErrIndexLimit を返す
+func Index(ctx context.Context, root string, opts IndexOptions, fn func(entry IndexEntry)) error {
+	count := 0
+	return walkTree(ctx, root, opts.Hidden, opts.GitIgnore, func(rel string, d fs.DirEntry) error {
+		fn(IndexEntry{Path: rel, IsDir: d.IsDir()})
+		count++
+		if opts.MaxEntries > 0 && count >= opts.MaxEntries {
+			return ErrIndexLimit
+		}
+		return nil
+	})
+}
+
+// walkTree は root 以下を走査し、隠しファイルと .gitignore の設定で飛ばさなかった
+// エントリを起点からの相対パスとともに fn へ渡す（root 自体は渡さない）
+// 飛ばしたディレクトリの中は走査しない
+func walkTree(ctx context.Context, root string, hidden, useGitIgnore bool, fn func(rel string, d fs.DirEntry) error) error {
+	var ignore gitIgnore
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if path == root {
+			if err == nil && useGitIgnore {
+				ignore.load(root, "")
+			}
+			return err
+		}
+		if err != nil {
+			// 権限のないディレクトリなどは飛ばして続ける
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := d.Name()
+		rel, _ := filepath.Rel(root, path)
+		skip := (!hidden && strings.HasPrefix(name, ".")) ||
+			(useGitIgnore && (name == ".git" || ignore.ignored(rel, d.IsDir())))
+		if d.IsDir() {
+			if skip {
+				return filepath.SkipDir
+			}
+			if useGitIgnore {
+				ignore.load(path, rel)
+			}
+		} else if skip {
+			return nil
+		}
+		return fn(rel, d)
+	})
+}
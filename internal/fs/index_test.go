@@ -0,0 +1,68 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndex(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	writeSyncFile(t, root, "main.go", "", now)
+	writeSyncFile(t, root, "cmd/app/app.go", "", now)
+	writeSyncFile(t, root, ".config/settings.toml", "", now)
+	writeSyncFile(t, root, "build/out.bin", "", now)
+	writeSyncFile(t, root, ".gitignore", "build/\n", now)
+
+	index := func(opts IndexOptions) string {
+		var got []string
+		err := Index(context.Background(), root, opts, func(entry IndexEntry) {
+			path := filepath.ToSlash(entry.Path)
+			if entry.IsDir {
+				path += "/"
+			}
+			got = append(got, path)
+		})
+		if err != nil {
+			t.Fatalf("Index(%+v) error = %v", opts, err)
+		}
+		sort.Strings(got)
+		return strings.Join(got, ",")
+	}
+
+	if got, want := index(IndexOptions{}), "build/,build/out.bin,cmd/,cmd/app/,cmd/app/app.go,main.go"; got != want {
+		t.Errorf("Index() = %s, want %s", got, want)
+	}
+	if got, want := index(IndexOptions{GitIgnore: true}), "cmd/,cmd/app/,cmd/app/app.go,main.go"; got != want {
+		t.Errorf("Index(GitIgnore) = %s, want %s", got, want)
+	}
+	want := ".config/,.config/settings.toml,.gitignore,cmd/,cmd/app/,cmd/app/app.go,main.go"
+	if got := index(IndexOptions{Hidden: true, GitIgnore: true}); got != want {
+		t.Errorf("Index(Hidden, GitIgnore) = %s, want %s", got, want)
+	}
+}
+
+func TestIndexLimitAndCancel(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 10; i++ {
+		writeSyncFile(t, root, filepath.Join("dir", string(rune('a'+i))+".txt"), "", time.Now())
+	}
+
+	count := 0
+	err := Index(context.Background(), root, IndexOptions{MaxEntries: 4}, func(IndexEntry) { count++ })
+	if !errors.Is(err, ErrIndexLimit) || count != 4 {
+		t.Errorf("Index(MaxEntries: 4) = %v with %d entries, want ErrIndexLimit with 4", err, count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Index(ctx, root, IndexOptions{}, func(IndexEntry) { t.Error("entry reported after cancel") })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Index(cancelled) error = %v, want context.Canceled", err)
+	}
+}
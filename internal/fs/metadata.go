@@ -0,0 +1,43 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// preservedModeBits はメタデータ保持時に復元するモードビット
+const preservedModeBits = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+
+// preserveMetadata はsrcのメタデータ（所有者・拡張属性・パーミッション・タイムスタンプ）をdstに反映する
+// 所有者はroot実行時のみ復元する。拡張属性はuser.*名前空間のみを対象とし、
+// 宛先のファイルシステムが拡張属性に対応していない場合は無視する
+func preserveMetadata(src, dst string, srcInfo os.FileInfo) error {
+	// chownはsetuid/setgidビットを落とすため、パーミッションより先に設定する
+	if os.Geteuid() == 0 {
+		if stat, ok := srcInfo.Sys().(*syscall.Stat_t); ok {
+			if err := os.Lchown(dst, int(stat.Uid), int(stat.Gid)); err != nil {
+				return fmt.Errorf("failed to preserve ownership: %w", err)
+			}
+		}
+	}
+
+	// user.*拡張属性は通常ファイルとディレクトリにしか設定できない
+	if srcInfo.Mode().IsRegular() || srcInfo.IsDir() {
+		if err := copyXattrs(src, dst); err != nil {
+			return fmt.Errorf("failed to preserve extended attributes: %w", err)
+		}
+	}
+
+	// umaskの影響を受けないようにパーミッションを明示的に設定
+	if err := os.Chmod(dst, srcInfo.Mode()&preservedModeBits); err != nil {
+		return fmt.Errorf("failed to preserve permissions: %w", err)
+	}
+
+	// タイムスタンプは内容の書き込み後に設定しないと上書きされる
+	if err := os.Chtimes(dst, fileAtime(srcInfo), srcInfo.ModTime()); err != nil {
+		return fmt.Errorf("failed to preserve timestamps: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// userXattrPrefix は保持対象の拡張属性の名前空間
+const userXattrPrefix = "user."
+
+// fileAtime はファイルの最終アクセス時刻を返す
+func fileAtime(info os.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Atim.Sec, stat.Atim.Nsec)
+	}
+	return info.ModTime()
+}
+
+// copyXattrs はsrcのuser.*拡張属性をdstにコピーする
+func copyXattrs(src, dst string) error {
+	names, err := listXattrs(src)
+	if err != nil {
+		if isXattrUnsupported(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, userXattrPrefix) {
+			continue
+		}
+		value, err := getXattr(src, name)
+		if err != nil {
+			return err
+		}
+		if err := syscall.Setxattr(dst, name, value, 0); err != nil {
+			if isXattrUnsupported(err) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// listXattrs は拡張属性名の一覧を返す
+func listXattrs(path string) ([]string, error) {
+	buf, err := readXattr(func(dest []byte) (int, error) {
+		return syscall.Listxattr(path, dest)
+	})
+	if err != nil || len(buf) == 0 {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range bytes.Split(bytes.TrimRight(buf, "\x00"), []byte{0}) {
+		names = append(names, string(name))
+	}
+	return names, nil
+}
+
+// getXattr は拡張属性の値を返す
+func getXattr(path, name string) ([]byte, error) {
+	return readXattr(func(dest []byte) (int, error) {
+		return syscall.Getxattr(path, name, dest)
+	})
+}
+
+// readXattr は必要なサイズを問い合わせてから値を読み込む
+// 問い合わせと読み込みの間に値が大きくなった場合は再試行する
+func readXattr(read func(dest []byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := read(nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size)
+		n, err := read(buf)
+		if errors.Is(err, syscall.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+}
+
+// isXattrUnsupported はファイルシステムが拡張属性に対応していないことを示すエラーか判定
+func isXattrUnsupported(err error) bool {
+	return errors.Is(err, syscall.ENOTSUP) || errors.Is(err, syscall.EOPNOTSUPP)
+}
@@ -0,0 +1,36 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestCopyContextPreserveXattrs(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "file.txt")
+	os.WriteFile(src, []byte("content"), 0644)
+
+	if err := syscall.Setxattr(src, "user.duofm.test", []byte("value"), 0); err != nil {
+		if errors.Is(err, syscall.ENOTSUP) || errors.Is(err, syscall.ENOSYS) {
+			t.Skip("extended attributes are not supported on this filesystem")
+		}
+		t.Fatalf("Setxattr() error = %v", err)
+	}
+
+	dst := filepath.Join(tmpDir, "copy.txt")
+	if err := CopyContext(context.Background(), src, dst, CopyOptions{PreserveMetadata: true}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	value, err := getXattr(dst, "user.duofm.test")
+	if err != nil {
+		t.Fatalf("getXattr() error = %v", err)
+	}
+	if string(value) != "value" {
+		t.Errorf("xattr = %q, want %q", value, "value")
+	}
+}
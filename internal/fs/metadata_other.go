@@ -0,0 +1,18 @@
+//go:build !linux
+
+package fs
+
+import (
+	"os"
+	"time"
+)
+
+// fileAtime はファイルの最終アクセス時刻を返す（取得できないため更新時刻で代用）
+func fileAtime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
+
+// copyXattrs は拡張属性のコピーに対応していないプラットフォームでは何もしない
+func copyXattrs(src, dst string) error {
+	return nil
+}
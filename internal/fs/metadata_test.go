@@ -0,0 +1,96 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyContextPreserveMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	srcFile := filepath.Join(srcDir, "file.txt")
+	os.Mkdir(srcDir, 0755)
+	os.WriteFile(srcFile, []byte("content"), 0644)
+
+	// umaskで落ちるビットを含むモードと過去のタイムスタンプを設定
+	os.Chmod(srcFile, 0666)
+	atime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	dirMtime := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	os.Chtimes(srcFile, atime, mtime)
+	os.Chtimes(srcDir, dirMtime, dirMtime)
+
+	dst := filepath.Join(tmpDir, "dst")
+	if err := CopyContext(context.Background(), srcDir, dst, CopyOptions{PreserveMetadata: true}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0666 {
+		t.Errorf("mode = %v, want 0666", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("mtime = %v, want %v", info.ModTime(), mtime)
+	}
+	if got := fileAtime(info); !got.Equal(atime) {
+		t.Errorf("atime = %v, want %v", got, atime)
+	}
+
+	dirInfo, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dirInfo.ModTime().Equal(dirMtime) {
+		t.Errorf("directory mtime = %v, want %v", dirInfo.ModTime(), dirMtime)
+	}
+}
+
+func TestCopyContextPreserveReadOnlyDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "readonly")
+	os.Mkdir(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "file.txt"), []byte("content"), 0644)
+	os.Chmod(srcDir, 0555)
+	defer os.Chmod(srcDir, 0755)
+
+	dst := filepath.Join(tmpDir, "dst")
+	if err := CopyContext(context.Background(), srcDir, dst, CopyOptions{PreserveMetadata: true}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+	defer os.Chmod(dst, 0755)
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0555 {
+		t.Errorf("directory mode = %v, want 0555", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(dst, "file.txt")); err != nil {
+		t.Error("contents of a read-only directory should be copied")
+	}
+}
+
+func TestCopyContextWithoutPreserveKeepsCurrentTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "file.txt")
+	os.WriteFile(src, []byte("content"), 0644)
+	old := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	os.Chtimes(src, old, old)
+
+	dst := filepath.Join(tmpDir, "copy.txt")
+	if err := CopyContext(context.Background(), src, dst, CopyOptions{}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	info, _ := os.Stat(dst)
+	if info.ModTime().Equal(old) {
+		t.Error("mtime should not be preserved without PreserveMetadata")
+	}
+}
@@ -1,6 +1,8 @@
 package fs
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -8,8 +10,235 @@ import (
 	"strings"
 )
 
+// copyBufferSize はファイルコピー時のバッファサイズ
+const copyBufferSize = 256 * 1024
+
+// Pauser はコピー処理を一時停止させるための待機ポイントを提供する
+type Pauser interface {
+	// WaitIfPaused は一時停止中であれば再開またはctxのキャンセルまでブロックする
+	WaitIfPaused(ctx context.Context) error
+}
+
+// CopyOptions はコピー/移動操作の動作を制御するオプション
+type CopyOptions struct {
+	// Progress は進捗の記録先（nilの場合は記録しない）
+	Progress *Progress
+	// Pauser はチャンク書き込みごとに参照される一時停止ゲート（nilの場合は停止しない）
+	Pauser Pauser
+	// PreserveMetadata はパーミッション・タイムスタンプ・所有者（root実行時）・
+	// user.*拡張属性をコピー先に保持する
+	PreserveMetadata bool
+	// Dereference はシンボリックリンクをリンク先の内容としてコピーする
+	// falseの場合はシンボリックリンクをそのまま再作成する
+	Dereference bool
+	// SkipExisting は既に存在する宛先ファイルを上書きせずにスキップする
+	// （ディレクトリは既存のものにマージする）
+	SkipExisting bool
+	// OnSkip はディレクトリ内の特殊ファイル・リンクループ・リンク切れ・既存の宛先をスキップした際に呼ばれる
+	// nilの場合はスキップせずにエラーを返す（既存の宛先は何も報告せずにスキップする）
+	OnSkip func(path string, err error)
+	// Verify はファイルを書き込むたびにソースとコピー先を読み直してハッシュ値を比較する
+	// 一致しないコピー先は削除され ErrChecksumMismatch を返す
+	Verify VerifyAlgorithm
+	// OnVerifyFailed はディレクトリ内のファイルの検証に失敗した際に呼ばれ、コピーは続行される
+	// nilの場合はエラーを返してコピーを中断する
+	OnVerifyFailed func(path string, err error)
+	// Filter はディレクトリを再帰的にたどる際にコピー・移動するエントリを絞り込む
+	// （nilの場合はすべてを対象にする）
+	Filter *Filter
+
+	// filterRoot はフィルタの相対パスの基準となるコピー元（空の場合はコピー元自身）
+	filterRoot string
+}
+
+// checkpoint はキャンセルを確認し、一時停止中であれば再開まで待機する
+func (o CopyOptions) checkpoint(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if o.Pauser != nil {
+		return o.Pauser.WaitIfPaused(ctx)
+	}
+	return nil
+}
+
 // CopyFile はファイルをコピー
 func CopyFile(src, dst string) error {
+	return copyFileContext(context.Background(), src, dst, CopyOptions{})
+}
+
+// CopyDirectory はディレクトリを再帰的にコピー
+func CopyDirectory(src, dst string) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat source directory: %w", err)
+	}
+	return copyDirectoryContext(context.Background(), src, dst, srcInfo, CopyOptions{}, nil)
+}
+
+// Copy はファイルまたはディレクトリをコピー
+func Copy(src, dst string) error {
+	return CopyContext(context.Background(), src, dst, CopyOptions{})
+}
+
+// MoveFile はファイルを移動
+func MoveFile(src, dst string) error {
+	return MoveContext(context.Background(), src, dst, CopyOptions{})
+}
+
+// CopyContext はキャンセル可能なコピーを行う
+// ctxがキャンセルされた場合は書き込み途中の宛先ファイルを削除し、ctx.Err()を含むエラーを返す
+// シンボリックリンクはopts.Dereferenceがfalseの場合そのまま再作成し、名前付きパイプは再作成する
+func CopyContext(ctx context.Context, src, dst string, opts CopyOptions) error {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("source not found: %w", err)
+	}
+	if opts.filterRoot == "" {
+		opts.filterRoot = src
+	}
+	if opts.Filter.Excludes(filterRel(opts.filterRoot, src), srcInfo.IsDir()) {
+		return nil
+	}
+	return copyEntry(ctx, src, dst, srcInfo, opts, nil)
+}
+
+// copyEntry はエントリの種類に応じたコピーを行う
+// ancestorsはループ検出のためにたどってきたディレクトリの識別子
+func copyEntry(ctx context.Context, src, dst string, info os.FileInfo, opts CopyOptions, ancestors []fileID) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		if !opts.Dereference {
+			return copySymlink(src, dst, opts)
+		}
+		target, err := os.Stat(src)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrBrokenSymlink, err)
+		}
+		info = target
+	}
+
+	if opts.SkipExisting && !info.IsDir() {
+		if _, err := os.Lstat(ResolveDestPath(src, dst)); err == nil {
+			if info.Mode().IsRegular() {
+				opts.Progress.AddBytes(info.Size())
+			}
+			opts.Progress.FileDone()
+			if opts.OnSkip == nil {
+				return nil
+			}
+			return ErrDestinationExists
+		}
+	}
+
+	switch mode := info.Mode(); {
+	case mode.IsDir():
+		return copyDirectoryContext(ctx, src, dst, info, opts, ancestors)
+	case mode.IsRegular():
+		return copyFileContext(ctx, src, dst, opts)
+	case mode&os.ModeNamedPipe != 0:
+		return copyFifo(src, dst, info, opts)
+	default:
+		return specialFileError(mode)
+	}
+}
+
+// MoveContext はキャンセル可能な移動を行う
+// 同一ファイルシステム内ではリネーム、それ以外はコピー後にソースを削除する
+// コピーが失敗またはキャンセルされた場合、作成途中の宛先を削除しソースは残す
+func MoveContext(ctx context.Context, src, dst string, opts CopyOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if opts.Filter != nil {
+		return moveFiltered(ctx, src, dst, opts)
+	}
+
+	// 宛先パスを決定
+	dstPath := ResolveDestPath(src, dst)
+
+	// os.Rename を試す（同一ファイルシステム内）
+	if err := os.Rename(src, dstPath); err == nil {
+		if opts.Progress != nil {
+			if size, files, err := CalculateSize([]string{dstPath}); err == nil {
+				opts.Progress.AddBytes(size)
+				for i := 0; i < files; i++ {
+					opts.Progress.FileDone()
+				}
+			}
+		}
+		return nil
+	}
+
+	// クロスデバイス移動の場合はコピー→削除
+	// 移動は元のファイルを置き換えるため、メタデータは常に保持しシンボリックリンクはたどらない
+	_, statErr := os.Lstat(dstPath)
+	dstExisted := statErr == nil
+	opts.PreserveMetadata = true
+	opts.Dereference = false
+
+	// スキップされた項目があればソースを削除しない
+	var skipped int
+	var firstSkipErr error
+	onSkip := opts.OnSkip
+	opts.OnSkip = func(path string, err error) {
+		if skipped == 0 {
+			firstSkipErr = fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		skipped++
+		if onSkip != nil {
+			onSkip(path, err)
+		}
+	}
+
+	// 検証に失敗したファイルがあればソースを削除しない
+	var mismatched int
+	var firstMismatchErr error
+	onVerifyFailed := opts.OnVerifyFailed
+	opts.OnVerifyFailed = func(path string, err error) {
+		if mismatched == 0 {
+			firstMismatchErr = fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		mismatched++
+		if onVerifyFailed != nil {
+			onVerifyFailed(path, err)
+		}
+	}
+
+	err := CopyContext(ctx, src, dst, opts)
+	if err == nil && skipped > 0 {
+		err = fmt.Errorf("%d items could not be moved, source kept: %w", skipped, firstSkipErr)
+	}
+	if err == nil && mismatched > 0 {
+		err = fmt.Errorf("%d files failed verification, source kept: %w", mismatched, firstMismatchErr)
+	}
+	if err != nil {
+		if !dstExisted {
+			os.RemoveAll(dstPath)
+		}
+		return fmt.Errorf("failed to copy during move: %w", err)
+	}
+
+	if err := Delete(src); err != nil {
+		return fmt.Errorf("failed to delete source after copy: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveDestPath は宛先が既存ディレクトリの場合にソース名を連結したパスを返す
+func ResolveDestPath(src, dst string) string {
+	if dstInfo, err := os.Stat(dst); err == nil && dstInfo.IsDir() {
+		return filepath.Join(dst, filepath.Base(src))
+	}
+	return dst
+}
+
+// copyFileContext はファイルをチャンク単位でコピーし、進捗とキャンセルを反映する
+func copyFileContext(ctx context.Context, src, dst string, opts CopyOptions) error {
+	if err := opts.checkpoint(ctx); err != nil {
+		return err
+	}
+
 	// ソースファイルを開く
 	sourceFile, err := os.Open(src)
 	if err != nil {
@@ -24,43 +253,77 @@ func CopyFile(src, dst string) error {
 	}
 
 	// 宛先パスを決定
-	dstPath := dst
-	if dstInfo, err := os.Stat(dst); err == nil && dstInfo.IsDir() {
-		dstPath = filepath.Join(dst, filepath.Base(src))
-	}
+	dstPath := ResolveDestPath(src, dst)
+
+	opts.Progress.SetCurrentFile(src)
 
 	// 宛先ファイルを作成
 	destFile, err := os.OpenFile(dstPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, sourceInfo.Mode())
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
-	defer destFile.Close()
 
 	// コピー実行
-	_, err = io.Copy(destFile, sourceFile)
+	err = copyFileData(ctx, destFile, sourceFile, sourceInfo.Size(), opts)
+	if err == nil && opts.Verify != VerifyNone {
+		// 検証でディスク上の内容を読めるように書き出しを完了させる
+		err = destFile.Sync()
+	}
+	closeErr := destFile.Close()
+	if err == nil {
+		err = closeErr
+	}
 	if err != nil {
+		// 書き込み途中の宛先ファイルを残さない
+		os.Remove(dstPath)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		return fmt.Errorf("failed to copy file: %w", err)
 	}
 
+	if opts.Verify != VerifyNone {
+		if err := verifyCopy(ctx, src, dstPath, opts.Verify); err != nil {
+			// 壊れたコピーを残さない
+			os.Remove(dstPath)
+			return err
+		}
+	}
+
+	if opts.PreserveMetadata {
+		if err := preserveMetadata(src, dstPath, sourceInfo); err != nil {
+			return err
+		}
+	}
+
+	opts.Progress.FileDone()
 	return nil
 }
 
-// CopyDirectory はディレクトリを再帰的にコピー
-func CopyDirectory(src, dst string) error {
-	// ソースディレクトリの情報を取得
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return fmt.Errorf("failed to stat source directory: %w", err)
+// copyDirectoryContext はディレクトリを再帰的にコピーする
+func copyDirectoryContext(ctx context.Context, src, dst string, srcInfo os.FileInfo, opts CopyOptions, ancestors []fileID) error {
+	// シンボリックリンクをたどって親ディレクトリに戻る場合は無限再帰になるため中断
+	if id, ok := fileIDOf(srcInfo); ok {
+		for _, ancestor := range ancestors {
+			if ancestor == id {
+				return ErrSymlinkLoop
+			}
+		}
+		ancestors = append(ancestors[:len(ancestors):len(ancestors)], id)
 	}
 
 	// 宛先パスを決定
-	dstPath := dst
-	if dstInfo, err := os.Stat(dst); err == nil && dstInfo.IsDir() {
-		dstPath = filepath.Join(dst, filepath.Base(src))
-	}
+	dstPath := ResolveDestPath(src, dst)
 
 	// 宛先ディレクトリを作成
-	if err := os.MkdirAll(dstPath, srcInfo.Mode()); err != nil {
+	// メタデータ保持時は中身をコピーできるよう書き込み権限を付けて作成し、最後に正確なモードを設定する
+	mode := srcInfo.Mode()
+	if opts.PreserveMetadata {
+		mode = srcInfo.Mode().Perm() | 0700
+	}
+	_, statErr := os.Lstat(dstPath)
+	created := os.IsNotExist(statErr)
+	if err := os.MkdirAll(dstPath, mode); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
@@ -72,60 +335,69 @@ func CopyDirectory(src, dst string) error {
 
 	// 各エントリを再帰的にコピー
 	for _, entry := range entries {
+		if err := opts.checkpoint(ctx); err != nil {
+			return err
+		}
+
 		srcPath := filepath.Join(src, entry.Name())
 		destPath := filepath.Join(dstPath, entry.Name())
 
-		if entry.IsDir() {
-			if err := CopyDirectory(srcPath, destPath); err != nil {
-				return err
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", srcPath, err)
+		}
+		if opts.Filter != nil && opts.Filter.Excludes(filterRel(opts.filterRoot, srcPath), info.IsDir()) {
+			continue
+		}
+
+		if err := copyEntry(ctx, srcPath, destPath, info, opts, ancestors); err != nil {
+			if opts.OnSkip != nil && IsSkippable(err) {
+				opts.OnSkip(srcPath, err)
+				continue
 			}
-		} else {
-			if err := CopyFile(srcPath, destPath); err != nil {
-				return err
+			if opts.OnVerifyFailed != nil && errors.Is(err, ErrChecksumMismatch) {
+				opts.OnVerifyFailed(srcPath, err)
+				continue
 			}
+			return err
 		}
 	}
 
-	return nil
-}
-
-// Copy はファイルまたはディレクトリをコピー
-func Copy(src, dst string) error {
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return fmt.Errorf("source not found: %w", err)
+	// 対象のファイルを1つも含まないディレクトリは作成しない
+	if created && opts.Filter.HasIncludes() && isEmptyDir(dstPath) {
+		return os.Remove(dstPath)
 	}
 
-	if srcInfo.IsDir() {
-		return CopyDirectory(src, dst)
+	// 中身のコピーで更新されたディレクトリの更新時刻を最後に復元する
+	if opts.PreserveMetadata {
+		return preserveMetadata(src, dstPath, srcInfo)
 	}
-	return CopyFile(src, dst)
-}
 
-// MoveFile はファイルを移動
-func MoveFile(src, dst string) error {
-	// 宛先パスを決定
-	dstPath := dst
-	if dstInfo, err := os.Stat(dst); err == nil && dstInfo.IsDir() {
-		dstPath = filepath.Join(dst, filepath.Base(src))
-	}
+	return nil
+}
 
-	// os.Rename を試す（同一ファイルシステム内）
-	err := os.Rename(src, dstPath)
-	if err == nil {
-		return nil
-	}
+// copyStream はユーザー空間のバッファを経由してファイルの内容をコピーする
+// 高速なコピー手段が使えない場合のフォールバック
+func copyStream(ctx context.Context, dst, src *os.File, opts CopyOptions) error {
+	writer := &progressWriter{ctx: ctx, w: dst, opts: opts}
+	_, err := io.CopyBuffer(writer, src, make([]byte, copyBufferSize))
+	return err
+}
 
-	// クロスデバイス移動の場合はコピー→削除
-	if err := Copy(src, dst); err != nil {
-		return fmt.Errorf("failed to copy during move: %w", err)
-	}
+// progressWriter は書き込みごとにキャンセルと一時停止を確認し、書き込んだバイト数を進捗に加算する
+type progressWriter struct {
+	ctx  context.Context
+	w    io.Writer
+	opts CopyOptions
+}
 
-	if err := Delete(src); err != nil {
-		return fmt.Errorf("failed to delete source after copy: %w", err)
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	if err := pw.opts.checkpoint(pw.ctx); err != nil {
+		return 0, err
 	}
-
-	return nil
+	n, err := pw.w.Write(p)
+	pw.opts.Progress.AddBytes(int64(n))
+	return n, err
 }
 
 // DeleteFile はファイルを削除
@@ -145,8 +417,9 @@ func DeleteDirectory(path string) error {
 }
 
 // Delete はファイルまたはディレクトリを削除
+// シンボリックリンクはリンク先ではなくリンク自体を削除する
 func Delete(path string) error {
-	info, err := os.Stat(path)
+	info, err := os.Lstat(path)
 	if err != nil {
 		return fmt.Errorf("path not found: %w", err)
 	}
@@ -1,6 +1,8 @@
 package fs
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -620,3 +622,103 @@ func TestValidateFilename(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyContextProgress(t *testing.T) {
+	t.Run("ディレクトリコピーで進捗が記録される", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		srcDir := filepath.Join(tmpDir, "source")
+		os.MkdirAll(filepath.Join(srcDir, "sub"), 0755)
+		os.WriteFile(filepath.Join(srcDir, "a.txt"), make([]byte, 1000), 0644)
+		os.WriteFile(filepath.Join(srcDir, "sub", "b.txt"), make([]byte, 500), 0644)
+		dstDir := filepath.Join(tmpDir, "dest")
+		os.Mkdir(dstDir, 0755)
+
+		progress := NewProgress(1500, 2)
+		if err := CopyContext(context.Background(), srcDir, dstDir, CopyOptions{Progress: progress}); err != nil {
+			t.Fatalf("CopyContext() error = %v", err)
+		}
+
+		snap := progress.Snapshot()
+		if snap.DoneBytes != 1500 {
+			t.Errorf("DoneBytes = %d, want 1500", snap.DoneBytes)
+		}
+		if snap.DoneFiles != 2 {
+			t.Errorf("DoneFiles = %d, want 2", snap.DoneFiles)
+		}
+		if snap.Percentage() != 100 {
+			t.Errorf("Percentage() = %d, want 100", snap.Percentage())
+		}
+	})
+}
+
+func TestCopyContextCancelled(t *testing.T) {
+	t.Run("キャンセル時に書き込み途中のファイルが削除される", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		srcFile := filepath.Join(tmpDir, "large.bin")
+		os.WriteFile(srcFile, make([]byte, copyBufferSize*4), 0644)
+		dstFile := filepath.Join(tmpDir, "copy.bin")
+
+		// 最初のチャンクを書き込んだ後にキャンセルされるコンテキスト
+		ctx := &cancelAfterContext{Context: context.Background(), after: 2}
+		progress := NewProgress(copyBufferSize*4, 1)
+		err := copyFileContext(ctx, srcFile, dstFile, CopyOptions{Progress: progress})
+		if err == nil {
+			t.Fatal("copyFileContext() should return error when cancelled")
+		}
+		if progress.Snapshot().DoneBytes == 0 {
+			t.Error("Some bytes should have been written before cancellation")
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+		if _, err := os.Stat(dstFile); !os.IsNotExist(err) {
+			t.Error("Partially written destination file should be removed")
+		}
+	})
+
+	t.Run("キャンセル済みのコンテキストでは何もコピーしない", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		srcFile := filepath.Join(tmpDir, "a.txt")
+		os.WriteFile(srcFile, []byte("content"), 0644)
+		dstFile := filepath.Join(tmpDir, "b.txt")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := CopyContext(ctx, srcFile, dstFile, CopyOptions{}); !errors.Is(err, context.Canceled) {
+			t.Errorf("CopyContext() error = %v, want context.Canceled", err)
+		}
+		if _, err := os.Stat(dstFile); !os.IsNotExist(err) {
+			t.Error("Destination file should not be created")
+		}
+	})
+}
+
+func TestMoveContextCancelledKeepsSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcFile := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(srcFile, []byte("content"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := MoveContext(ctx, srcFile, filepath.Join(tmpDir, "b.txt"), CopyOptions{}); err == nil {
+		t.Error("MoveContext() should return error when cancelled")
+	}
+	if _, err := os.Stat(srcFile); err != nil {
+		t.Error("Source file should remain after cancelled move")
+	}
+}
+
+// cancelAfterContext は Err() が指定回数呼ばれた後にキャンセル扱いになるコンテキスト
+type cancelAfterContext struct {
+	context.Context
+	calls int
+	after int
+}
+
+func (c *cancelAfterContext) Err() error {
+	c.calls++
+	if c.calls > c.after {
+		return context.Canceled
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSameFile はコピー/移動の宛先がソース自身であることを示す
+var ErrSameFile = errors.New("source and destination are the same")
+
+// ErrDestinationInsideSource は宛先がソースディレクトリの内部にあることを示す
+var ErrDestinationInsideSource = errors.New("destination is inside the source directory")
+
+// ErrInsufficientSpace は宛先の空き容量が足りないことを示す
+var ErrInsufficientSpace = errors.New("not enough disk space")
+
+// Plan はコピー/移動を開始する前にソースを走査した結果
+type Plan struct {
+	TotalBytes    int64    // ソースの通常ファイルの総バイト数
+	TotalFiles    int      // ソースのファイル数（ディレクトリを除く）
+	RequiredBytes int64    // 宛先に必要な空き容量（同一ファイルシステム内の移動では0）
+	FreeBytes     uint64   // 宛先の空き容量（取得できない場合は0）
+	Conflicts     []string // 既に存在する宛先パス（マージされるディレクトリを除く）
+	ExcludedFiles int      // フィルタで対象外になったファイル数（TotalFilesには含まない）
+}
+
+// HasConflicts は既存の宛先があるかどうかを返す
+func (p *Plan) HasConflicts() bool {
+	return len(p.Conflicts) > 0
+}
+
+// PlanOperation はファイルを変更せずにコピー/移動の事前確認を行う
+// destは宛先ディレクトリ、またはソースが1つの場合は新しい名前を含むパス
+// 宛先がソース自身またはその内部にある場合と空き容量が足りない場合はエラーを返す
+// （空き容量エラーの場合もPlanは返す）
+// filterを指定した場合は対象となるファイルだけを集計する
+func PlanOperation(sources []string, dest string, move bool, filter *Filter) (*Plan, error) {
+	plan := &Plan{}
+	destDir := dest
+	if info, err := os.Stat(dest); err != nil || !info.IsDir() {
+		destDir = filepath.Dir(dest)
+	}
+	spaceDir := existingDir(destDir)
+
+	var reclaimed int64
+	for _, src := range sources {
+		srcInfo, err := os.Lstat(src)
+		if err != nil {
+			return plan, fmt.Errorf("source not found: %w", err)
+		}
+
+		dstPath := ResolveDestPath(src, dest)
+		if err := checkDestination(src, dstPath, srcInfo); err != nil {
+			return plan, err
+		}
+
+		bytes, files, excluded, conflicts, freed, err := scanSource(src, dstPath, filter)
+		if err != nil {
+			return plan, err
+		}
+		plan.ExcludedFiles += excluded
+		plan.TotalBytes += bytes
+		plan.TotalFiles += files
+		plan.Conflicts = append(plan.Conflicts, conflicts...)
+
+		// 同一ファイルシステム内の移動はリネームで済むため空き容量は不要
+		if move && sameDevice(src, spaceDir) {
+			continue
+		}
+		plan.RequiredBytes += bytes
+		reclaimed += freed
+	}
+
+	// 上書きされるファイルの分は空き容量が戻る
+	plan.RequiredBytes -= reclaimed
+	if plan.RequiredBytes < 0 {
+		plan.RequiredBytes = 0
+	}
+
+	free, _, err := GetDiskSpace(spaceDir)
+	if err != nil {
+		// 空き容量の確認はベストエフォート
+		return plan, nil
+	}
+	plan.FreeBytes = free
+	if uint64(plan.RequiredBytes) > free {
+		return plan, ErrInsufficientSpace
+	}
+	return plan, nil
+}
+
+// checkDestination は宛先がソース自身やソースの内部でないことを確認する
+func checkDestination(src, dstPath string, srcInfo os.FileInfo) error {
+	srcReal, err := filepath.EvalSymlinks(src)
+	if err != nil || srcInfo.Mode()&os.ModeSymlink != 0 {
+		// シンボリックリンク自体をコピーする場合はリンク先をたどらない
+		srcReal, _ = filepath.Abs(src)
+	}
+	dstReal := realPath(dstPath)
+
+	if dstReal == srcReal {
+		return fmt.Errorf("%w: %s", ErrSameFile, src)
+	}
+	if srcInfo.IsDir() && isWithin(dstReal, srcReal) {
+		return fmt.Errorf("%w: %s", ErrDestinationInsideSource, src)
+	}
+	return nil
+}
+
+// scanSource はソースを走査して総量と宛先の競合を調べる
+// freedは上書きされる既存ファイルの総バイト数、excludedはフィルタで対象外になったファイル数
+func scanSource(src, dstPath string, filter *Filter) (bytes int64, files, excluded int, conflicts []string, freed int64, err error) {
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filter.Excludes(filterRel(src, path), d.IsDir()) {
+			if !d.IsDir() {
+				excluded++
+				return nil
+			}
+			_, count, err := CalculateSize([]string{path})
+			if err != nil {
+				return err
+			}
+			excluded += count
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dstPath, rel)
+
+		if !d.IsDir() {
+			files++
+			if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+				bytes += info.Size()
+			}
+		}
+
+		targetInfo, statErr := os.Lstat(target)
+		if statErr != nil {
+			return nil
+		}
+		// 既存のディレクトリにはマージされるため、中身だけを確認する
+		if d.IsDir() && targetInfo.IsDir() {
+			return nil
+		}
+		conflicts = append(conflicts, target)
+		if targetInfo.Mode().IsRegular() {
+			freed += targetInfo.Size()
+		}
+		return nil
+	})
+	return bytes, files, excluded, conflicts, freed, err
+}
+
+// realPath はシンボリックリンクを解決した絶対パスを返す
+// パスが存在しない場合は存在する親ディレクトリまでを解決する
+func realPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	parent := filepath.Dir(abs)
+	if parent == abs {
+		return abs
+	}
+	return filepath.Join(realPath(parent), filepath.Base(abs))
+}
+
+// isWithin はpathがdirの内部にあるかどうかを判定する
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// existingDir はpathまたは存在する最も近い親ディレクトリを返す
+func existingDir(path string) string {
+	for {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// sameDevice は2つのパスが同じファイルシステム上にあるかどうかを判定する
+func sameDevice(a, b string) bool {
+	infoA, errA := os.Lstat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	idA, okA := fileIDOf(infoA)
+	idB, okB := fileIDOf(infoB)
+	return okA && okB && idA.dev == idB.dev
+}
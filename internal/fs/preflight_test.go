@@ -0,0 +1,147 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanOperation(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.MkdirAll(filepath.Join(srcDir, "sub"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "a.txt"), []byte("12345"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "sub", "b.txt"), []byte("123"), 0644)
+	single := filepath.Join(tmpDir, "single.txt")
+	os.WriteFile(single, []byte("12"), 0644)
+
+	destDir := filepath.Join(tmpDir, "dest")
+	os.MkdirAll(filepath.Join(destDir, "src", "sub"), 0755)
+	os.WriteFile(filepath.Join(destDir, "src", "sub", "b.txt"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(destDir, "single.txt"), []byte("old"), 0644)
+
+	plan, err := PlanOperation([]string{srcDir, single}, destDir, false, nil)
+	if err != nil {
+		t.Fatalf("PlanOperation() error = %v", err)
+	}
+	if plan.TotalBytes != 10 || plan.TotalFiles != 3 {
+		t.Errorf("TotalBytes, TotalFiles = %d, %d, want 10, 3", plan.TotalBytes, plan.TotalFiles)
+	}
+	// Existing files are overwritten, so their size is not required again
+	if plan.RequiredBytes != 4 {
+		t.Errorf("RequiredBytes = %d, want 4", plan.RequiredBytes)
+	}
+
+	want := []string{
+		filepath.Join(destDir, "src", "sub", "b.txt"),
+		filepath.Join(destDir, "single.txt"),
+	}
+	if len(plan.Conflicts) != len(want) {
+		t.Fatalf("Conflicts = %v, want %v", plan.Conflicts, want)
+	}
+	for i := range want {
+		if plan.Conflicts[i] != want[i] {
+			t.Errorf("Conflicts[%d] = %q, want %q", i, plan.Conflicts[i], want[i])
+		}
+	}
+}
+
+func TestPlanOperationRejectsDestinationInsideSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.MkdirAll(filepath.Join(srcDir, "sub"), 0755)
+
+	tests := []struct {
+		name string
+		dest string
+		want error
+	}{
+		{"subdirectory", filepath.Join(srcDir, "sub"), ErrDestinationInsideSource},
+		{"source itself", srcDir, ErrDestinationInsideSource},
+		{"same path", tmpDir, ErrSameFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PlanOperation([]string{srcDir}, tt.dest, false, nil)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("PlanOperation() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("through a symlink", func(t *testing.T) {
+		link := filepath.Join(tmpDir, "link")
+		os.Symlink(filepath.Join(srcDir, "sub"), link)
+		_, err := PlanOperation([]string{srcDir}, link, false, nil)
+		if !errors.Is(err, ErrDestinationInsideSource) {
+			t.Errorf("PlanOperation() error = %v, want ErrDestinationInsideSource", err)
+		}
+	})
+
+	t.Run("sibling with common prefix", func(t *testing.T) {
+		sibling := filepath.Join(tmpDir, "src2")
+		os.Mkdir(sibling, 0755)
+		if _, err := PlanOperation([]string{srcDir}, sibling, false, nil); err != nil {
+			t.Errorf("PlanOperation() error = %v", err)
+		}
+	})
+}
+
+func TestPlanOperationMoveOnSameFilesystem(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "file.txt")
+	os.WriteFile(src, []byte("content"), 0644)
+	destDir := filepath.Join(tmpDir, "dest")
+	os.Mkdir(destDir, 0755)
+
+	plan, err := PlanOperation([]string{src}, destDir, true, nil)
+	if err != nil {
+		t.Fatalf("PlanOperation() error = %v", err)
+	}
+	if plan.RequiredBytes != 0 {
+		t.Errorf("RequiredBytes = %d, want 0 for a rename", plan.RequiredBytes)
+	}
+	if plan.HasConflicts() {
+		t.Errorf("Conflicts = %v, want none", plan.Conflicts)
+	}
+}
+
+func TestCopyContextSkipExisting(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.Mkdir(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "new.txt"), []byte("new"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "old.txt"), []byte("replacement"), 0644)
+
+	// The source directory is merged into the existing dst/src
+	dstRoot := filepath.Join(tmpDir, "dst")
+	dst := filepath.Join(dstRoot, "src")
+	os.MkdirAll(dst, 0755)
+	os.WriteFile(filepath.Join(dst, "old.txt"), []byte("keep"), 0644)
+
+	var skipped []string
+	opts := CopyOptions{
+		SkipExisting: true,
+		OnSkip: func(path string, err error) {
+			if !errors.Is(err, ErrDestinationExists) {
+				t.Errorf("OnSkip(%s) error = %v", path, err)
+			}
+			skipped = append(skipped, filepath.Base(path))
+		},
+	}
+	if err := CopyContext(context.Background(), srcDir, dstRoot, opts); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dst, "old.txt")); string(data) != "keep" {
+		t.Errorf("existing file was overwritten: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "new.txt")); err != nil {
+		t.Error("new files should be copied")
+	}
+	if len(skipped) != 1 || skipped[0] != "old.txt" {
+		t.Errorf("skipped = %v, want [old.txt]", skipped)
+	}
+}
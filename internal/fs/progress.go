@@ -0,0 +1,229 @@
+package fs
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Progress はコピー/移動操作のバイト単位の進捗を保持する
+// 操作を実行するゴルーチンが更新し、UIはSnapshotで読み取るため並行アクセスに安全
+type Progress struct {
+	mu          sync.Mutex
+	totalBytes  int64
+	doneBytes   int64
+	totalFiles  int
+	doneFiles   int
+	currentFile string
+	startTime   time.Time
+	pausedAt    time.Time     // 一時停止した時刻（停止中でなければゼロ値）
+	pausedFor   time.Duration // 再開済みの一時停止の合計時間
+}
+
+// ProgressSnapshot はある時点の進捗情報のコピー
+type ProgressSnapshot struct {
+	TotalBytes  int64         // 総バイト数
+	DoneBytes   int64         // 処理済みバイト数
+	TotalFiles  int           // 総ファイル数
+	DoneFiles   int           // 処理済みファイル数
+	CurrentFile string        // 処理中のファイル名
+	StartTime   time.Time     // 開始時刻（未開始の場合はゼロ値）
+	PausedTime  time.Duration // 一時停止していた合計時間
+}
+
+// NewProgress は新しい進捗トラッカーを作成
+// 経過時間の計測はStartまたは最初の進捗更新から始まる
+func NewProgress(totalBytes int64, totalFiles int) *Progress {
+	return &Progress{
+		totalBytes: totalBytes,
+		totalFiles: totalFiles,
+	}
+}
+
+// Start は経過時間の計測を開始する（開始済みの場合は何もしない）
+// キューで待機していた時間を経過時間に含めないよう、ジョブの実行開始時に呼ぶ
+func (p *Progress) Start() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.startLocked()
+	p.mu.Unlock()
+}
+
+// Pause は経過時間の計測を一時停止する
+func (p *Progress) Pause() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if !p.startTime.IsZero() && p.pausedAt.IsZero() {
+		p.pausedAt = time.Now()
+	}
+	p.mu.Unlock()
+}
+
+// Resume は一時停止した経過時間の計測を再開する
+func (p *Progress) Resume() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if !p.pausedAt.IsZero() {
+		p.pausedFor += time.Since(p.pausedAt)
+		p.pausedAt = time.Time{}
+	}
+	p.mu.Unlock()
+}
+
+// startLocked は未開始であれば開始時刻を記録する（p.muを保持して呼ぶ）
+func (p *Progress) startLocked() {
+	if p.startTime.IsZero() {
+		p.startTime = time.Now()
+	}
+}
+
+// AddBytes は処理済みバイト数を加算する（nilの場合は何もしない）
+func (p *Progress) AddBytes(n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.startLocked()
+	p.doneBytes += n
+	p.mu.Unlock()
+}
+
+// SetCurrentFile は処理中のファイル名を設定する
+func (p *Progress) SetCurrentFile(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.startLocked()
+	p.currentFile = name
+	p.mu.Unlock()
+}
+
+// FileDone はファイル1件の処理完了を記録する
+func (p *Progress) FileDone() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.startLocked()
+	p.doneFiles++
+	p.mu.Unlock()
+}
+
+// Update は総量と処理済み量をまとめて設定する
+// 別の仕組みで計測された進捗（アーカイブ処理など）を反映する場合に使用
+func (p *Progress) Update(doneBytes, totalBytes int64, doneFiles, totalFiles int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.startLocked()
+	p.doneBytes = doneBytes
+	p.totalBytes = totalBytes
+	p.doneFiles = doneFiles
+	p.totalFiles = totalFiles
+	p.mu.Unlock()
+}
+
+// Snapshot は現在の進捗のコピーを返す
+func (p *Progress) Snapshot() ProgressSnapshot {
+	if p == nil {
+		return ProgressSnapshot{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	paused := p.pausedFor
+	if !p.pausedAt.IsZero() {
+		paused += time.Since(p.pausedAt)
+	}
+	return ProgressSnapshot{
+		TotalBytes:  p.totalBytes,
+		DoneBytes:   p.doneBytes,
+		TotalFiles:  p.totalFiles,
+		DoneFiles:   p.doneFiles,
+		CurrentFile: p.currentFile,
+		StartTime:   p.startTime,
+		PausedTime:  paused,
+	}
+}
+
+// Percentage はバイト数ベースの進捗率（0-100）を返す
+func (s ProgressSnapshot) Percentage() int {
+	if s.TotalBytes <= 0 {
+		if s.TotalFiles == 0 {
+			return 0
+		}
+		return (s.DoneFiles * 100) / s.TotalFiles
+	}
+	percent := int((s.DoneBytes * 100) / s.TotalBytes)
+	if percent > 100 {
+		percent = 100
+	}
+	return percent
+}
+
+// ElapsedTime は開始からの経過時間を返す（一時停止していた時間は含めない）
+func (s ProgressSnapshot) ElapsedTime() time.Duration {
+	if s.StartTime.IsZero() {
+		return 0
+	}
+	elapsed := time.Since(s.StartTime) - s.PausedTime
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed
+}
+
+// BytesPerSecond は平均スループット（バイト/秒）を返す
+func (s ProgressSnapshot) BytesPerSecond() float64 {
+	elapsed := s.ElapsedTime().Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.DoneBytes) / elapsed
+}
+
+// EstimatedRemaining は平均スループットから残り時間を推定する
+func (s ProgressSnapshot) EstimatedRemaining() time.Duration {
+	rate := s.BytesPerSecond()
+	if rate <= 0 || s.DoneBytes >= s.TotalBytes {
+		return 0
+	}
+	remaining := float64(s.TotalBytes-s.DoneBytes) / rate
+	return time.Duration(remaining * float64(time.Second))
+}
+
+// CalculateSize は指定されたパス群の総バイト数とファイル数を計算する
+// ディレクトリは再帰的に走査し、通常ファイルのサイズのみを合計する
+func CalculateSize(paths []string) (totalBytes int64, totalFiles int, err error) {
+	for _, root := range paths {
+		walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			totalFiles++
+			if info.Mode().IsRegular() {
+				totalBytes += info.Size()
+			}
+			return nil
+		})
+		if walkErr != nil {
+			return totalBytes, totalFiles, walkErr
+		}
+	}
+	return totalBytes, totalFiles, nil
+}
@@ -0,0 +1,143 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProgressSnapshot(t *testing.T) {
+	p := NewProgress(200, 2)
+	p.SetCurrentFile("a.txt")
+	p.AddBytes(50)
+	p.FileDone()
+
+	snap := p.Snapshot()
+	if snap.TotalBytes != 200 || snap.DoneBytes != 50 {
+		t.Errorf("bytes = %d/%d, want 50/200", snap.DoneBytes, snap.TotalBytes)
+	}
+	if snap.DoneFiles != 1 || snap.TotalFiles != 2 {
+		t.Errorf("files = %d/%d, want 1/2", snap.DoneFiles, snap.TotalFiles)
+	}
+	if snap.CurrentFile != "a.txt" {
+		t.Errorf("CurrentFile = %q, want %q", snap.CurrentFile, "a.txt")
+	}
+	if snap.Percentage() != 25 {
+		t.Errorf("Percentage() = %d, want 25", snap.Percentage())
+	}
+}
+
+func TestProgressUpdate(t *testing.T) {
+	p := NewProgress(0, 0)
+	p.Update(30, 120, 1, 4)
+
+	snap := p.Snapshot()
+	if snap.DoneBytes != 30 || snap.TotalBytes != 120 || snap.DoneFiles != 1 || snap.TotalFiles != 4 {
+		t.Errorf("Snapshot() = %+v, want 30/120 bytes and 1/4 files", snap)
+	}
+}
+
+func TestProgressNilSafe(t *testing.T) {
+	var p *Progress
+	p.AddBytes(10)
+	p.SetCurrentFile("x")
+	p.FileDone()
+	if snap := p.Snapshot(); snap.DoneBytes != 0 {
+		t.Errorf("nil Progress snapshot should be empty, got %+v", snap)
+	}
+}
+
+func TestProgressClock(t *testing.T) {
+	p := NewProgress(100, 1)
+	if snap := p.Snapshot(); !snap.StartTime.IsZero() || snap.ElapsedTime() != 0 {
+		t.Errorf("progress should not start before Start, got %+v", snap)
+	}
+
+	p.Start()
+	p.Pause()
+	time.Sleep(30 * time.Millisecond)
+	if elapsed := p.Snapshot().ElapsedTime(); elapsed >= 20*time.Millisecond {
+		t.Errorf("ElapsedTime() while paused = %v, want paused time excluded", elapsed)
+	}
+	p.Resume()
+	if snap := p.Snapshot(); snap.PausedTime < 30*time.Millisecond || snap.ElapsedTime() >= 20*time.Millisecond {
+		t.Errorf("after Resume PausedTime = %v, ElapsedTime() = %v", snap.PausedTime, snap.ElapsedTime())
+	}
+}
+
+func TestProgressStartsOnFirstUpdate(t *testing.T) {
+	p := NewProgress(100, 1)
+	p.AddBytes(10)
+	if p.Snapshot().StartTime.IsZero() {
+		t.Error("AddBytes should start the clock")
+	}
+}
+
+func TestProgressSnapshotPercentage(t *testing.T) {
+	tests := []struct {
+		name string
+		snap ProgressSnapshot
+		want int
+	}{
+		{"空", ProgressSnapshot{}, 0},
+		{"バイト数ベース", ProgressSnapshot{TotalBytes: 1000, DoneBytes: 333}, 33},
+		{"上限100", ProgressSnapshot{TotalBytes: 10, DoneBytes: 20}, 100},
+		{"空ファイルのみの場合はファイル数ベース", ProgressSnapshot{TotalFiles: 4, DoneFiles: 1}, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.snap.Percentage(); got != tt.want {
+				t.Errorf("Percentage() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressSnapshotEstimates(t *testing.T) {
+	snap := ProgressSnapshot{
+		TotalBytes: 2000,
+		DoneBytes:  1000,
+		StartTime:  time.Now().Add(-10 * time.Second),
+	}
+
+	rate := snap.BytesPerSecond()
+	if rate < 90 || rate > 110 {
+		t.Errorf("BytesPerSecond() = %f, want about 100", rate)
+	}
+	remaining := snap.EstimatedRemaining()
+	if remaining < 9*time.Second || remaining > 11*time.Second {
+		t.Errorf("EstimatedRemaining() = %v, want about 10s", remaining)
+	}
+
+	done := ProgressSnapshot{TotalBytes: 10, DoneBytes: 10, StartTime: time.Now().Add(-time.Second)}
+	if done.EstimatedRemaining() != 0 {
+		t.Error("EstimatedRemaining() should be 0 when complete")
+	}
+}
+
+func TestCalculateSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, "dir")
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 100), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "b.txt"), make([]byte, 50), 0644)
+	single := filepath.Join(tmpDir, "c.txt")
+	os.WriteFile(single, make([]byte, 7), 0644)
+
+	bytes, files, err := CalculateSize([]string{dir, single})
+	if err != nil {
+		t.Fatalf("CalculateSize() error = %v", err)
+	}
+	if bytes != 157 {
+		t.Errorf("bytes = %d, want 157", bytes)
+	}
+	if files != 3 {
+		t.Errorf("files = %d, want 3", files)
+	}
+
+	if _, _, err := CalculateSize([]string{filepath.Join(tmpDir, "missing")}); err == nil {
+		t.Error("CalculateSize() should return error for missing path")
+	}
+}
@@ -56,43 +56,47 @@ func ReadDirectory(path string) ([]FileEntry, error) {
 			continue // エラーは無視して次へ
 		}
 
-		entryPath := filepath.Join(absPath, entry.Name())
-
-		// 基本情報
-		fileEntry := FileEntry{
-			Name:        entry.Name(),
-			IsDir:       entry.IsDir(),
-			Size:        info.Size(),
-			ModTime:     info.ModTime(),
-			Permissions: info.Mode(),
-		}
+		fileEntry := newFileEntry(entry.Name(), filepath.Join(absPath, entry.Name()), info)
+		fileEntries = append(fileEntries, fileEntry)
+	}
 
-		// 所有者・グループ情報を取得
-		owner, group, err := GetFileOwnerGroup(entryPath)
-		if err == nil {
-			fileEntry.Owner = owner
-			fileEntry.Group = group
-		} else {
-			fileEntry.Owner = "unknown"
-			fileEntry.Group = "unknown"
-		}
+	return fileEntries, nil
+}
 
-		// シンボリックリンク情報を取得
-		isLink, target, isBroken, isTargetDir, err := GetSymlinkInfo(entryPath)
-		if err == nil {
-			fileEntry.IsSymlink = isLink
-			fileEntry.LinkTarget = target
-			fileEntry.LinkBroken = isBroken
-			// シンボリックリンクがディレクトリを指している場合、IsDirを更新
-			if isLink && !isBroken && isTargetDir {
-				fileEntry.IsDir = true
-			}
-		}
+// newFileEntry はファイル情報から表示用のエントリを作成する
+func newFileEntry(name, entryPath string, info os.FileInfo) FileEntry {
+	// 基本情報
+	fileEntry := FileEntry{
+		Name:        name,
+		IsDir:       info.IsDir(),
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		Permissions: info.Mode(),
+	}
 
-		fileEntries = append(fileEntries, fileEntry)
+	// 所有者・グループ情報を取得
+	owner, group, err := GetFileOwnerGroup(entryPath)
+	if err == nil {
+		fileEntry.Owner = owner
+		fileEntry.Group = group
+	} else {
+		fileEntry.Owner = "unknown"
+		fileEntry.Group = "unknown"
 	}
 
-	return fileEntries, nil
+	// シンボリックリンク情報を取得
+	isLink, target, isBroken, isTargetDir, err := GetSymlinkInfo(entryPath)
+	if err == nil {
+		fileEntry.IsSymlink = isLink
+		fileEntry.LinkTarget = target
+		fileEntry.LinkBroken = isBroken
+		// シンボリックリンクがディレクトリを指している場合、IsDirを更新
+		if isLink && !isBroken && isTargetDir {
+			fileEntry.IsDir = true
+		}
+	}
+
+	return fileEntry
 }
 
 // HomeDirectory はホームディレクトリのパスを返す
@@ -0,0 +1,121 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// ErrSpecialFile はコピーできない特殊ファイル（デバイス・ソケットなど）を示す
+var ErrSpecialFile = errors.New("cannot copy special file")
+
+// ErrSymlinkLoop はシンボリックリンクをたどった先が親ディレクトリを指していることを示す
+var ErrSymlinkLoop = errors.New("symlink loop detected")
+
+// ErrBrokenSymlink はリンク先が存在しないシンボリックリンクをたどろうとしたことを示す
+var ErrBrokenSymlink = errors.New("broken symlink")
+
+// ErrDestinationExists はSkipExisting指定時に既存の宛先をスキップしたことを示す
+var ErrDestinationExists = errors.New("destination already exists")
+
+// IsSkippable はディレクトリのコピー中にスキップして続行できるエラーかを判定
+func IsSkippable(err error) bool {
+	return errors.Is(err, ErrSpecialFile) || errors.Is(err, ErrSymlinkLoop) ||
+		errors.Is(err, ErrBrokenSymlink) || errors.Is(err, ErrDestinationExists)
+}
+
+// fileID はループ検出に使うファイルの識別子（デバイス番号とinode番号）
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// fileIDOf はファイル情報から識別子を取得する（取得できない場合はfalse）
+func fileIDOf(info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
+
+// copySymlink はシンボリックリンクをリンク先をたどらずにそのまま再作成する
+func copySymlink(src, dst string, opts CopyOptions) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return fmt.Errorf("failed to read symlink: %w", err)
+	}
+
+	dstPath := ResolveDestPath(src, dst)
+	opts.Progress.SetCurrentFile(src)
+	if err := removeExistingFile(dstPath); err != nil {
+		return err
+	}
+
+	if err := os.Symlink(target, dstPath); err != nil {
+		return fmt.Errorf("failed to create symlink: %w", err)
+	}
+
+	// シンボリックリンク自体はパーミッションやタイムスタンプを持たないため所有者のみ復元
+	if opts.PreserveMetadata && os.Geteuid() == 0 {
+		if info, err := os.Lstat(src); err == nil {
+			if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+				if err := os.Lchown(dstPath, int(stat.Uid), int(stat.Gid)); err != nil {
+					return fmt.Errorf("failed to preserve ownership: %w", err)
+				}
+			}
+		}
+	}
+
+	opts.Progress.FileDone()
+	return nil
+}
+
+// copyFifo は名前付きパイプを読み込まずに再作成する
+func copyFifo(src, dst string, srcInfo os.FileInfo, opts CopyOptions) error {
+	dstPath := ResolveDestPath(src, dst)
+	opts.Progress.SetCurrentFile(src)
+	if err := removeExistingFile(dstPath); err != nil {
+		return err
+	}
+
+	if err := syscall.Mkfifo(dstPath, uint32(srcInfo.Mode().Perm())); err != nil {
+		return fmt.Errorf("failed to create named pipe: %w", err)
+	}
+
+	if opts.PreserveMetadata {
+		if err := preserveMetadata(src, dstPath, srcInfo); err != nil {
+			return err
+		}
+	}
+
+	opts.Progress.FileDone()
+	return nil
+}
+
+// removeExistingFile は上書きのために既存の宛先（ディレクトリ以外）を削除する
+func removeExistingFile(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to replace destination: %w", err)
+	}
+	return nil
+}
+
+// specialFileError は特殊ファイルの種類を含むエラーを返す
+func specialFileError(mode os.FileMode) error {
+	kind := "special file"
+	switch {
+	case mode&os.ModeCharDevice != 0:
+		kind = "character device"
+	case mode&os.ModeDevice != 0:
+		kind = "block device"
+	case mode&os.ModeSocket != 0:
+		kind = "socket"
+	}
+	return fmt.Errorf("%w (%s)", ErrSpecialFile, kind)
+}
@@ -0,0 +1,201 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestCopyContextSymlinkVerbatim(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.MkdirAll(filepath.Join(srcDir, "sub"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "file.txt"), []byte("content"), 0644)
+	os.Symlink("file.txt", filepath.Join(srcDir, "file-link"))
+	os.Symlink("sub", filepath.Join(srcDir, "dir-link"))
+	os.Symlink("missing", filepath.Join(srcDir, "broken-link"))
+
+	dst := filepath.Join(tmpDir, "dst")
+	if err := CopyContext(context.Background(), srcDir, dst, CopyOptions{}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	for link, want := range map[string]string{"file-link": "file.txt", "dir-link": "sub", "broken-link": "missing"} {
+		path := filepath.Join(dst, link)
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Errorf("%s was not copied: %v", link, err)
+			continue
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("%s should be a symlink, got mode %v", link, info.Mode())
+			continue
+		}
+		if target, _ := os.Readlink(path); target != want {
+			t.Errorf("%s target = %q, want %q", link, target, want)
+		}
+	}
+}
+
+func TestCopyContextTopLevelSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "target")
+	os.Mkdir(target, 0755)
+	link := filepath.Join(tmpDir, "link")
+	os.Symlink(target, link)
+	destDir := filepath.Join(tmpDir, "dest")
+	os.Mkdir(destDir, 0755)
+
+	if err := CopyContext(context.Background(), link, destDir, CopyOptions{}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	info, err := os.Lstat(filepath.Join(destDir, "link"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("a symlink to a directory should be copied as a symlink")
+	}
+}
+
+func TestCopyContextDereference(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.MkdirAll(filepath.Join(srcDir, "sub"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "sub", "file.txt"), []byte("content"), 0644)
+	os.Symlink("sub", filepath.Join(srcDir, "dir-link"))
+
+	dst := filepath.Join(tmpDir, "dst")
+	if err := CopyContext(context.Background(), srcDir, dst, CopyOptions{Dereference: true}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	info, err := os.Lstat(filepath.Join(dst, "dir-link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsDir() {
+		t.Errorf("dereferenced link should be a directory, got mode %v", info.Mode())
+	}
+	if _, err := os.Stat(filepath.Join(dst, "dir-link", "file.txt")); err != nil {
+		t.Error("contents of the linked directory should be copied")
+	}
+}
+
+func TestCopyContextSymlinkLoop(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.Mkdir(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "file.txt"), []byte("content"), 0644)
+	os.Symlink("..", filepath.Join(srcDir, "parent"))
+	os.Symlink(".", filepath.Join(srcDir, "self"))
+
+	t.Run("skipped with OnSkip", func(t *testing.T) {
+		var skipped []string
+		opts := CopyOptions{
+			Dereference: true,
+			OnSkip: func(path string, err error) {
+				if !errors.Is(err, ErrSymlinkLoop) {
+					t.Errorf("OnSkip(%s) error = %v, want ErrSymlinkLoop", path, err)
+				}
+				skipped = append(skipped, filepath.Base(path))
+			},
+		}
+		dst := filepath.Join(t.TempDir(), "dst")
+		if err := CopyContext(context.Background(), srcDir, dst, opts); err != nil {
+			t.Fatalf("CopyContext() error = %v", err)
+		}
+		// "parent" is followed once into tmpDir, where src is found again
+		if len(skipped) == 0 {
+			t.Error("loops should be reported through OnSkip")
+		}
+		if _, err := os.Stat(filepath.Join(dst, "file.txt")); err != nil {
+			t.Error("regular files should still be copied")
+		}
+	})
+
+	t.Run("error without OnSkip", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "dst")
+		err := CopyContext(context.Background(), srcDir, dst, CopyOptions{Dereference: true})
+		if !errors.Is(err, ErrSymlinkLoop) {
+			t.Errorf("CopyContext() error = %v, want ErrSymlinkLoop", err)
+		}
+	})
+}
+
+func TestCopyContextFifo(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.Mkdir(srcDir, 0755)
+	if err := syscall.Mkfifo(filepath.Join(srcDir, "pipe"), 0640); err != nil {
+		t.Skipf("cannot create FIFO: %v", err)
+	}
+
+	dst := filepath.Join(tmpDir, "dst")
+	if err := CopyContext(context.Background(), srcDir, dst, CopyOptions{PreserveMetadata: true}); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	info, err := os.Lstat(filepath.Join(dst, "pipe"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		t.Errorf("FIFO should be recreated, got mode %v", info.Mode())
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("FIFO mode = %v, want 0640", info.Mode().Perm())
+	}
+}
+
+func TestCopyContextSkipsSocket(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	os.Mkdir(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "file.txt"), []byte("content"), 0644)
+	listener, err := net.Listen("unix", filepath.Join(srcDir, "sock"))
+	if err != nil {
+		t.Skipf("cannot create socket: %v", err)
+	}
+	defer listener.Close()
+
+	var skipped []string
+	opts := CopyOptions{OnSkip: func(path string, err error) {
+		if !errors.Is(err, ErrSpecialFile) {
+			t.Errorf("OnSkip(%s) error = %v, want ErrSpecialFile", path, err)
+		}
+		skipped = append(skipped, filepath.Base(path))
+	}}
+	dst := filepath.Join(tmpDir, "dst")
+	if err := CopyContext(context.Background(), srcDir, dst, opts); err != nil {
+		t.Fatalf("CopyContext() error = %v", err)
+	}
+
+	if len(skipped) != 1 || skipped[0] != "sock" {
+		t.Errorf("skipped = %v, want [sock]", skipped)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "file.txt")); err != nil {
+		t.Error("regular files should still be copied")
+	}
+
+	// A special file selected directly is reported as an error
+	err = CopyContext(context.Background(), filepath.Join(srcDir, "sock"), t.TempDir(), CopyOptions{})
+	if !errors.Is(err, ErrSpecialFile) {
+		t.Errorf("CopyContext(socket) error = %v, want ErrSpecialFile", err)
+	}
+}
+
+func TestDeleteBrokenSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	link := filepath.Join(tmpDir, "broken")
+	os.Symlink("missing", link)
+
+	if err := Delete(link); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Error("broken symlink should be deleted")
+	}
+}
@@ -0,0 +1,296 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrSyncConflict は双方向同期で両側が変更されていて方向を決められないことを示す
+var ErrSyncConflict = errors.New("changed on both sides")
+
+// SyncCompare は同期で差分を判定する方法
+type SyncCompare int
+
+const (
+	// SyncCompareSizeTime はサイズと更新時刻（秒単位）で比較する
+	SyncCompareSizeTime SyncCompare = iota
+	// SyncCompareChecksum はサイズが同じファイルの内容をハッシュ値で比較する
+	SyncCompareChecksum
+)
+
+// SyncOptions は同期計画の作成方法
+type SyncOptions struct {
+	// Compare は差分の判定方法
+	Compare SyncCompare
+	// TwoWay は片側にしかないエントリを反対側へコピーし、異なるファイルは新しい方で上書きする
+	TwoWay bool
+	// DeleteExtraneous はソースにないエントリを宛先から削除する（一方向同期のみ）
+	DeleteExtraneous bool
+	// Filter は同期の対象を絞り込む（nilの場合はすべてを対象にする）
+	Filter *Filter
+}
+
+// SyncOp は同期で行う操作の種類
+type SyncOp int
+
+const (
+	// SyncCopy は宛先にないエントリをコピーする
+	SyncCopy SyncOp = iota
+	// SyncUpdate は内容が異なる宛先を上書きする
+	SyncUpdate
+	// SyncDelete はソースにない宛先のエントリを削除する
+	SyncDelete
+	// SyncConflict は双方向同期で方向を決められないエントリ（適用されない）
+	SyncConflict
+)
+
+// SyncAction は同期計画の1項目
+// Src から Dst へコピーする（SyncDelete では Dst を削除する）
+// SyncConflict では Src が1つ目、Dst が2つ目のディレクトリ側のパス
+type SyncAction struct {
+	Op      SyncOp
+	Rel     string // 同期するディレクトリからの相対パス
+	Src     string // コピー元のパス（SyncDeleteでは空）
+	Dst     string // コピー先または削除するパス
+	IsDir   bool   // ディレクトリごとコピー/削除する
+	Size    int64  // コピーするバイト数
+	Files   int    // コピーまたは削除するファイル数
+	Reverse bool   // 2つ目のディレクトリから1つ目へコピーする（双方向同期）
+}
+
+// PlanSync はファイルを変更せずに src から dst への同期計画を作成する
+// 双方向同期では dst から src へのコピーも含む。結果は相対パス順
+func PlanSync(ctx context.Context, src, dst string, opts SyncOptions) ([]SyncAction, error) {
+	for _, dir := range []string{src, dst} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+	}
+	if realPath(src) == realPath(dst) {
+		return nil, fmt.Errorf("%w: %s", ErrSameFile, src)
+	}
+
+	var actions []SyncAction
+	if err := planSyncDir(ctx, src, dst, "", opts, &actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// planSyncDir は同じ相対パスにある2つのディレクトリの中身を比較する
+func planSyncDir(ctx context.Context, srcRoot, dstRoot, rel string, opts SyncOptions, actions *[]SyncAction) error {
+	srcEntries, err := readEntryInfos(filepath.Join(srcRoot, rel))
+	if err != nil {
+		return err
+	}
+	dstEntries, err := readEntryInfos(filepath.Join(dstRoot, rel))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(srcEntries)+len(dstEntries))
+	for name := range srcEntries {
+		names = append(names, name)
+	}
+	for name := range dstEntries {
+		if _, ok := srcEntries[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entryRel := filepath.Join(rel, name)
+		srcPath := filepath.Join(srcRoot, entryRel)
+		dstPath := filepath.Join(dstRoot, entryRel)
+		srcInfo, inSrc := srcEntries[name]
+		dstInfo, inDst := dstEntries[name]
+
+		isDir := (inSrc && srcInfo.IsDir()) || (!inSrc && dstInfo.IsDir())
+		if opts.Filter.Excludes(entryRel, isDir) {
+			continue
+		}
+
+		switch {
+		case inSrc && !inDst:
+			*actions = append(*actions, newCopyAction(SyncCopy, entryRel, srcPath, dstPath, srcInfo, false, opts.Filter))
+		case !inSrc && opts.TwoWay:
+			*actions = append(*actions, newCopyAction(SyncCopy, entryRel, dstPath, srcPath, dstInfo, true, opts.Filter))
+		case !inSrc:
+			if opts.DeleteExtraneous {
+				_, files, _ := CalculateSize([]string{dstPath})
+				*actions = append(*actions, SyncAction{Op: SyncDelete, Rel: entryRel, Dst: dstPath, IsDir: dstInfo.IsDir(), Files: files})
+			}
+		case srcInfo.IsDir() && dstInfo.IsDir():
+			if err := planSyncDir(ctx, srcRoot, dstRoot, entryRel, opts, actions); err != nil {
+				return err
+			}
+		default:
+			differs, err := syncDiffers(ctx, srcPath, dstPath, srcInfo, dstInfo, opts.Compare)
+			if err != nil {
+				return err
+			}
+			if !differs {
+				continue
+			}
+			*actions = append(*actions, planSyncUpdate(entryRel, srcPath, dstPath, srcInfo, dstInfo, opts))
+		}
+	}
+	return nil
+}
+
+// planSyncUpdate は両側にあり内容が異なるエントリの操作を決める
+// 双方向同期では更新時刻が新しい方を残し、判断できない場合は競合とする
+func planSyncUpdate(rel, srcPath, dstPath string, srcInfo, dstInfo os.FileInfo, opts SyncOptions) SyncAction {
+	if !opts.TwoWay {
+		return newCopyAction(SyncUpdate, rel, srcPath, dstPath, srcInfo, false, opts.Filter)
+	}
+
+	srcTime := srcInfo.ModTime().Truncate(time.Second)
+	dstTime := dstInfo.ModTime().Truncate(time.Second)
+	switch {
+	case srcInfo.IsDir() != dstInfo.IsDir() || srcTime.Equal(dstTime):
+		return SyncAction{Op: SyncConflict, Rel: rel, Src: srcPath, Dst: dstPath, IsDir: srcInfo.IsDir(), Size: srcInfo.Size(), Files: 1}
+	case srcTime.After(dstTime):
+		return newCopyAction(SyncUpdate, rel, srcPath, dstPath, srcInfo, false, opts.Filter)
+	default:
+		return newCopyAction(SyncUpdate, rel, dstPath, srcPath, dstInfo, true, opts.Filter)
+	}
+}
+
+// newCopyAction はコピーする量を数えてコピー/上書きの操作を作成する
+func newCopyAction(op SyncOp, rel, from, to string, info os.FileInfo, reverse bool, filter *Filter) SyncAction {
+	action := SyncAction{Op: op, Rel: rel, Src: from, Dst: to, IsDir: info.IsDir(), Reverse: reverse, Files: 1}
+	if info.IsDir() {
+		// 計画と同じく同期するディレクトリからの相対パスでフィルタと照合する
+		action.Size, action.Files, _ = filteredSize(from, syncRoot(from, rel), filter)
+	} else if info.Mode().IsRegular() {
+		action.Size = info.Size()
+	}
+	return action
+}
+
+// ResolveConflict は競合を指定した方向の上書きに変える
+// reverseがtrueの場合は2つ目のディレクトリ側（Dst）で1つ目を上書きする
+func (a SyncAction) ResolveConflict(reverse bool) SyncAction {
+	if a.Op != SyncConflict {
+		return a
+	}
+	a.Op = SyncUpdate
+	a.Reverse = reverse
+	if reverse {
+		a.Src, a.Dst = a.Dst, a.Src
+	}
+	if info, err := os.Lstat(a.Src); err == nil {
+		a.IsDir = info.IsDir()
+		if info.IsDir() {
+			a.Size, a.Files, _ = CalculateSize([]string{a.Src})
+		} else if info.Mode().IsRegular() {
+			a.Size = info.Size()
+		}
+	}
+	return a
+}
+
+// readEntryInfos はディレクトリ内のエントリ情報を名前で引けるように読み込む
+// シンボリックリンクはたどらない
+func readEntryInfos(dir string) (map[string]os.FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	infos := make(map[string]os.FileInfo, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		infos[entry.Name()] = info
+	}
+	return infos, nil
+}
+
+// syncDiffers は両側にあるエントリの内容が異なるかを判定する
+func syncDiffers(ctx context.Context, srcPath, dstPath string, srcInfo, dstInfo os.FileInfo, compare SyncCompare) (bool, error) {
+	srcLink := srcInfo.Mode()&os.ModeSymlink != 0
+	dstLink := dstInfo.Mode()&os.ModeSymlink != 0
+	if srcLink || dstLink {
+		if srcLink != dstLink {
+			return true, nil
+		}
+		srcTarget, err1 := os.Readlink(srcPath)
+		dstTarget, err2 := os.Readlink(dstPath)
+		return err1 != nil || err2 != nil || srcTarget != dstTarget, nil
+	}
+
+	if srcInfo.IsDir() != dstInfo.IsDir() || srcInfo.Size() != dstInfo.Size() {
+		return true, nil
+	}
+	if !srcInfo.Mode().IsRegular() || !dstInfo.Mode().IsRegular() {
+		return srcInfo.Mode().Type() != dstInfo.Mode().Type(), nil
+	}
+
+	if compare == SyncCompareSizeTime {
+		return !srcInfo.ModTime().Truncate(time.Second).Equal(dstInfo.ModTime().Truncate(time.Second)), nil
+	}
+
+	srcSum, err := FileChecksum(ctx, srcPath, VerifyXXHash)
+	if err != nil {
+		return false, err
+	}
+	dstSum, err := FileChecksum(ctx, dstPath, VerifyXXHash)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(srcSum, dstSum), nil
+}
+
+// ApplySyncAction は同期計画の1項目を実行する
+// 更新時刻で比較し直せるよう、コピーは常にメタデータを保持する
+func ApplySyncAction(ctx context.Context, action SyncAction, opts CopyOptions) error {
+	switch action.Op {
+	case SyncDelete:
+		return Delete(action.Dst)
+	case SyncConflict:
+		return fmt.Errorf("%s: %w", action.Rel, ErrSyncConflict)
+	}
+
+	if action.Op == SyncUpdate {
+		// 通常ファイル同士以外（ディレクトリやリンクの置き換え）は先に削除する
+		dstInfo, err := os.Lstat(action.Dst)
+		if err == nil && (!dstInfo.Mode().IsRegular() || action.IsDir) {
+			if err := os.RemoveAll(action.Dst); err != nil {
+				return fmt.Errorf("failed to remove existing destination: %w", err)
+			}
+		}
+		if srcInfo, err := os.Lstat(action.Src); err == nil && srcInfo.Mode()&os.ModeSymlink != 0 {
+			os.Remove(action.Dst)
+		}
+	}
+
+	opts.PreserveMetadata = true
+	opts.Dereference = false
+	// フィルタは計画と同じく同期するディレクトリからの相対パスと照合する
+	opts.filterRoot = syncRoot(action.Src, action.Rel)
+	return CopyContext(ctx, action.Src, action.Dst, opts)
+}
+
+// syncRoot は相対パス rel にある path から同期するディレクトリを求める
+func syncRoot(path, rel string) string {
+	return filepath.Clean(strings.TrimSuffix(path, rel))
+}
@@ -0,0 +1,213 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSyncFile writes content to root/rel with the given modification time
+func writeSyncFile(t *testing.T, root, rel, content string, mtime time.Time) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, mtime, mtime)
+}
+
+// syncOps returns the planned operations keyed by relative path
+func syncOps(actions []SyncAction) map[string]SyncAction {
+	ops := make(map[string]SyncAction)
+	for _, a := range actions {
+		ops[filepath.ToSlash(a.Rel)] = a
+	}
+	return ops
+}
+
+func TestPlanSyncOneWay(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	writeSyncFile(t, src, "same.txt", "same", now)
+	writeSyncFile(t, dst, "same.txt", "same", now)
+	writeSyncFile(t, src, "changed.txt", "new", now)
+	writeSyncFile(t, dst, "changed.txt", "old", now.Add(-time.Hour))
+	writeSyncFile(t, src, "new/a.txt", "a", now)
+	writeSyncFile(t, src, "new/b.txt", "bb", now)
+	writeSyncFile(t, dst, "extra.txt", "extra", now)
+
+	actions, err := PlanSync(context.Background(), src, dst, SyncOptions{})
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	ops := syncOps(actions)
+	if len(ops) != 2 {
+		t.Fatalf("actions = %+v, want changed.txt and new", actions)
+	}
+	if ops["changed.txt"].Op != SyncUpdate {
+		t.Errorf("changed.txt op = %v, want SyncUpdate", ops["changed.txt"].Op)
+	}
+	if a := ops["new"]; a.Op != SyncCopy || !a.IsDir || a.Files != 2 || a.Size != 3 {
+		t.Errorf("new = %+v, want a directory copy of 2 files, 3 bytes", a)
+	}
+
+	actions, _ = PlanSync(context.Background(), src, dst, SyncOptions{DeleteExtraneous: true})
+	if a := syncOps(actions)["extra.txt"]; a.Op != SyncDelete || a.Dst != filepath.Join(dst, "extra.txt") {
+		t.Errorf("extra.txt = %+v, want SyncDelete", a)
+	}
+}
+
+func TestPlanSyncChecksum(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	// Same content, different times: only size+mtime reports a difference
+	writeSyncFile(t, src, "touched.txt", "data", now)
+	writeSyncFile(t, dst, "touched.txt", "data", now.Add(-time.Hour))
+	// Same size and time, different content: only the checksum finds it
+	writeSyncFile(t, src, "edited.txt", "abcd", now)
+	writeSyncFile(t, dst, "edited.txt", "abce", now)
+
+	actions, _ := PlanSync(context.Background(), src, dst, SyncOptions{Compare: SyncCompareSizeTime})
+	if ops := syncOps(actions); len(ops) != 1 || ops["touched.txt"].Op != SyncUpdate {
+		t.Errorf("size+mtime actions = %+v, want touched.txt", actions)
+	}
+
+	actions, _ = PlanSync(context.Background(), src, dst, SyncOptions{Compare: SyncCompareChecksum})
+	if ops := syncOps(actions); len(ops) != 1 || ops["edited.txt"].Op != SyncUpdate {
+		t.Errorf("checksum actions = %+v, want edited.txt", actions)
+	}
+}
+
+func TestPlanSyncTwoWay(t *testing.T) {
+	left, right := t.TempDir(), t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	writeSyncFile(t, left, "only-left.txt", "l", now)
+	writeSyncFile(t, right, "only-right.txt", "r", now)
+	writeSyncFile(t, left, "newer-left.txt", "new", now)
+	writeSyncFile(t, right, "newer-left.txt", "old", now.Add(-time.Hour))
+	writeSyncFile(t, left, "newer-right.txt", "old", now.Add(-time.Hour))
+	writeSyncFile(t, right, "newer-right.txt", "new", now)
+	writeSyncFile(t, left, "both.txt", "one", now)
+	writeSyncFile(t, right, "both.txt", "two", now)
+
+	actions, err := PlanSync(context.Background(), left, right, SyncOptions{TwoWay: true, Compare: SyncCompareChecksum})
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	ops := syncOps(actions)
+	tests := []struct {
+		rel     string
+		op      SyncOp
+		reverse bool
+	}{
+		{"only-left.txt", SyncCopy, false},
+		{"only-right.txt", SyncCopy, true},
+		{"newer-left.txt", SyncUpdate, false},
+		{"newer-right.txt", SyncUpdate, true},
+		{"both.txt", SyncConflict, false},
+	}
+	for _, tt := range tests {
+		if a := ops[tt.rel]; a.Op != tt.op || a.Reverse != tt.reverse {
+			t.Errorf("%s = %+v, want op %v reverse %v", tt.rel, a, tt.op, tt.reverse)
+		}
+	}
+	if a := ops["only-right.txt"]; a.Src != filepath.Join(right, "only-right.txt") || a.Dst != filepath.Join(left, "only-right.txt") {
+		t.Errorf("reverse copy should go from right to left, got %+v", a)
+	}
+
+	resolved := ops["both.txt"].ResolveConflict(true)
+	if resolved.Op != SyncUpdate || resolved.Src != filepath.Join(right, "both.txt") {
+		t.Errorf("ResolveConflict(true) = %+v", resolved)
+	}
+}
+
+func TestPlanSyncFilter(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	now := time.Now()
+	writeSyncFile(t, src, "a.go", "a", now)
+	writeSyncFile(t, src, "a.txt", "a", now)
+	writeSyncFile(t, src, "vendor/b.go", "b", now)
+	filter, _ := ParseFilter("*.go !vendor")
+
+	actions, _ := PlanSync(context.Background(), src, dst, SyncOptions{Filter: filter})
+	if len(actions) != 1 || actions[0].Rel != "a.go" {
+		t.Errorf("actions = %+v, want only a.go", actions)
+	}
+}
+
+func TestApplySyncActionFilterWithSlash(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	now := time.Now()
+	writeSyncFile(t, src, "build/main.o", "object", now)
+	writeSyncFile(t, src, "build/main.c", "source", now)
+	filter, _ := ParseFilter("!build/*.o")
+
+	actions, err := PlanSync(context.Background(), src, dst, SyncOptions{Filter: filter})
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	if len(actions) != 1 || actions[0].Rel != "build" || actions[0].Files != 1 {
+		t.Fatalf("actions = %+v, want build with one file", actions)
+	}
+
+	// The pattern is matched against the path from the synced directory, as in the plan
+	if err := ApplySyncAction(context.Background(), actions[0], CopyOptions{Filter: filter}); err != nil {
+		t.Fatalf("ApplySyncAction() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "build", "main.c")); err != nil {
+		t.Errorf("build/main.c should be copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "build", "main.o")); !os.IsNotExist(err) {
+		t.Error("build/main.o should be excluded")
+	}
+}
+
+func TestPlanSyncRejectsSameDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := PlanSync(context.Background(), dir, dir, SyncOptions{}); !errors.Is(err, ErrSameFile) {
+		t.Errorf("PlanSync() error = %v, want ErrSameFile", err)
+	}
+}
+
+func TestApplySyncAction(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeSyncFile(t, src, "changed.txt", "new", old)
+	writeSyncFile(t, dst, "changed.txt", "stale", old.Add(-time.Hour))
+	writeSyncFile(t, src, "dir/a.txt", "a", old)
+	writeSyncFile(t, dst, "extra/x.txt", "x", old)
+
+	actions, err := PlanSync(context.Background(), src, dst, SyncOptions{DeleteExtraneous: true})
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	for _, a := range actions {
+		if err := ApplySyncAction(context.Background(), a, CopyOptions{}); err != nil {
+			t.Fatalf("ApplySyncAction(%s) error = %v", a.Rel, err)
+		}
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dst, "changed.txt")); string(data) != "new" {
+		t.Errorf("changed.txt = %q, want new", data)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "dir", "a.txt")); err != nil {
+		t.Errorf("dir/a.txt should be copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "extra")); !os.IsNotExist(err) {
+		t.Error("extra should be deleted")
+	}
+
+	// Modification times are kept, so the directories are now in sync
+	if actions, _ := PlanSync(context.Background(), src, dst, SyncOptions{DeleteExtraneous: true}); len(actions) != 0 {
+		t.Errorf("second plan = %+v, want nothing to do", actions)
+	}
+
+	conflict := SyncAction{Op: SyncConflict, Rel: "x"}
+	if err := ApplySyncAction(context.Background(), conflict, CopyOptions{}); !errors.Is(err, ErrSyncConflict) {
+		t.Errorf("ApplySyncAction(conflict) error = %v, want ErrSyncConflict", err)
+	}
+}
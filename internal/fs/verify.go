@@ -0,0 +1,96 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// VerifyAlgorithm はコピー後の検証に使うハッシュアルゴリズム
+type VerifyAlgorithm string
+
+const (
+	// VerifyNone は検証を行わない
+	VerifyNone VerifyAlgorithm = ""
+	// VerifySHA256 はSHA-256で検証する
+	VerifySHA256 VerifyAlgorithm = "sha256"
+	// VerifyXXHash はxxHash（XXH64）で検証する（SHA-256より高速）
+	VerifyXXHash VerifyAlgorithm = "xxhash"
+)
+
+// ErrChecksumMismatch はコピー先の内容がソースと一致しないことを示す
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ParseVerifyAlgorithm は設定値を検証アルゴリズムに変換する（"off"と空文字は検証なし）
+func ParseVerifyAlgorithm(s string) (VerifyAlgorithm, error) {
+	switch s {
+	case "", "off":
+		return VerifyNone, nil
+	case string(VerifySHA256):
+		return VerifySHA256, nil
+	case string(VerifyXXHash):
+		return VerifyXXHash, nil
+	}
+	return VerifyNone, fmt.Errorf("unknown verify algorithm: %q", s)
+}
+
+// newHash はアルゴリズムに対応するハッシュを生成する
+func (a VerifyAlgorithm) newHash() hash.Hash {
+	if a == VerifyXXHash {
+		return xxhash.New()
+	}
+	return sha256.New()
+}
+
+// FileChecksum はファイルのハッシュ値を計算する
+// ページキャッシュを破棄してから読み込むため、可能な限りディスク上の内容を検証する
+func FileChecksum(ctx context.Context, path string, algo VerifyAlgorithm) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dropPageCache(f)
+
+	h := algo.newHash()
+	if _, err := io.CopyBuffer(h, &contextReader{ctx: ctx, r: f}, make([]byte, copyBufferSize)); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// verifyCopy はソースとコピー先を読み直してハッシュ値を比較する
+func verifyCopy(ctx context.Context, src, dst string, algo VerifyAlgorithm) error {
+	srcSum, err := FileChecksum(ctx, src, algo)
+	if err != nil {
+		return fmt.Errorf("failed to verify source: %w", err)
+	}
+	dstSum, err := FileChecksum(ctx, dst, algo)
+	if err != nil {
+		return fmt.Errorf("failed to verify destination: %w", err)
+	}
+	if !bytes.Equal(srcSum, dstSum) {
+		return fmt.Errorf("%w (%s)", ErrChecksumMismatch, algo)
+	}
+	return nil
+}
+
+// contextReader は読み込みごとにキャンセルを確認する
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
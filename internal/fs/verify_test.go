@@ -0,0 +1,129 @@
+package fs
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileChecksum(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "file.txt")
+	os.WriteFile(path, []byte("abc"), 0644)
+
+	tests := []struct {
+		algo VerifyAlgorithm
+		want string
+	}{
+		{VerifySHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{VerifyXXHash, "44bc2cf5ad770999"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.algo), func(t *testing.T) {
+			sum, err := FileChecksum(context.Background(), path, tt.algo)
+			if err != nil {
+				t.Fatalf("FileChecksum() error = %v", err)
+			}
+			if got := hex.EncodeToString(sum); got != tt.want {
+				t.Errorf("FileChecksum() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVerifyAlgorithm(t *testing.T) {
+	for input, want := range map[string]VerifyAlgorithm{"": VerifyNone, "off": VerifyNone, "sha256": VerifySHA256, "xxhash": VerifyXXHash} {
+		got, err := ParseVerifyAlgorithm(input)
+		if err != nil || got != want {
+			t.Errorf("ParseVerifyAlgorithm(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseVerifyAlgorithm("md5"); err == nil {
+		t.Error("ParseVerifyAlgorithm(\"md5\") should return error")
+	}
+}
+
+// modifySourcePauser は指定回数目の待機ポイントでソースファイルの先頭を書き換え、
+// コピー中にソースが変更された状況を再現する
+type modifySourcePauser struct {
+	path  string
+	calls int
+	at    int
+}
+
+func (p *modifySourcePauser) WaitIfPaused(ctx context.Context) error {
+	p.calls++
+	if p.calls == p.at {
+		f, err := os.OpenFile(p.path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.WriteAt([]byte("changed"), 0)
+		return err
+	}
+	return nil
+}
+
+func TestCopyContextVerify(t *testing.T) {
+	t.Run("一致するコピーは成功する", func(t *testing.T) {
+		for _, algo := range []VerifyAlgorithm{VerifySHA256, VerifyXXHash} {
+			tmpDir := t.TempDir()
+			src := filepath.Join(tmpDir, "a.bin")
+			os.WriteFile(src, make([]byte, copyBufferSize*2), 0644)
+
+			dst := filepath.Join(tmpDir, "b.bin")
+			if err := CopyContext(context.Background(), src, dst, CopyOptions{Verify: algo}); err != nil {
+				t.Errorf("CopyContext(%s) error = %v", algo, err)
+			}
+		}
+	})
+
+	t.Run("不一致の場合はコピー先を削除してエラーを返す", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		src := filepath.Join(tmpDir, "a.bin")
+		os.WriteFile(src, make([]byte, copyBufferSize*2), 0644)
+		dst := filepath.Join(tmpDir, "b.bin")
+
+		// 最初のチャンクをコピーした後にソースを書き換える
+		pauser := &modifySourcePauser{path: src, at: 3}
+		err := CopyContext(context.Background(), src, dst, CopyOptions{Pauser: pauser, Verify: VerifySHA256})
+		if !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("CopyContext() error = %v, want ErrChecksumMismatch", err)
+		}
+		if _, err := os.Stat(dst); !os.IsNotExist(err) {
+			t.Error("Destination that failed verification should be removed")
+		}
+	})
+
+	t.Run("ディレクトリ内の不一致はOnVerifyFailedに報告して続行する", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		srcDir := filepath.Join(tmpDir, "src")
+		os.Mkdir(srcDir, 0755)
+		bad := filepath.Join(srcDir, "a.bin")
+		os.WriteFile(bad, make([]byte, copyBufferSize*2), 0644)
+		os.WriteFile(filepath.Join(srcDir, "b.txt"), []byte("ok"), 0644)
+		dstDir := filepath.Join(tmpDir, "dst")
+
+		var failed []string
+		opts := CopyOptions{
+			Pauser: &modifySourcePauser{path: bad, at: 4},
+			Verify: VerifyXXHash,
+			OnVerifyFailed: func(path string, err error) {
+				failed = append(failed, path)
+			},
+		}
+		if err := CopyContext(context.Background(), srcDir, dstDir, opts); err != nil {
+			t.Fatalf("CopyContext() error = %v", err)
+		}
+		if len(failed) != 1 || failed[0] != bad {
+			t.Errorf("OnVerifyFailed called for %v, want [%s]", failed, bad)
+		}
+		if _, err := os.Stat(filepath.Join(dstDir, "b.txt")); err != nil {
+			t.Error("Other files should still be copied")
+		}
+	})
+}
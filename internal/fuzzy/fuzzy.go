@@ -0,0 +1,302 @@
+// Package fuzzy ranks strings by how well they contain a query as a
+// subsequence, in the spirit of fzf.
+//
+// Every query character must appear in the candidate in order. Among the
+// possible alignments the best scoring one is chosen: characters at the start
+// of a path segment or word, and runs of consecutive characters, score higher;
+// gaps between the characters cost a little. A query without upper case
+// letters ignores case.
+package fuzzy
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	// bonusPath is given to a character right after "/" or at the start
+	bonusPath = 9
+	// bonusBoundary is given to a character after a word delimiter
+	bonusBoundary = 8
+	// bonusCamel is given to an upper case letter after a lower case one and
+	// to a digit after a non-digit
+	bonusCamel = 7
+	// bonusConsecutive is the least bonus a character that directly follows
+	// the previous match gets
+	bonusConsecutive = 4
+	// bonusBasename is given to every character in the last path segment, so
+	// matches in the file name win over matches in the directories
+	bonusBasename = 2
+	// bonusFirstCharMultiplier weights the bonus of the first query character
+	bonusFirstCharMultiplier = 2
+)
+
+// unmatched marks alignments that are not possible
+const unmatched = -1 << 30
+
+// Result is a candidate that matches the query
+type Result struct {
+	Index int // Index of the candidate
+	Score int
+}
+
+// scratch holds the buffers of one alignment so ranking many candidates
+// does not allocate for each of them
+type scratch struct {
+	runes []rune
+	bonus []int
+	prev  []int
+	cur   []int
+}
+
+// grow returns the buffers resized to n runes
+func (s *scratch) grow(n int) {
+	if cap(s.runes) < n {
+		s.runes = make([]rune, n)
+		s.bonus = make([]int, n)
+		s.prev = make([]int, n)
+		s.cur = make([]int, n)
+	}
+	s.runes = s.runes[:n]
+	s.bonus = s.bonus[:n]
+	s.prev = s.prev[:n]
+	s.cur = s.cur[:n]
+}
+
+// Score returns the score of the best alignment of pattern in text.
+// ok is false when text does not contain pattern as a subsequence.
+func Score(pattern, text string) (score int, ok bool) {
+	score, _, ok = match(pattern, text, false, &scratch{})
+	return score, ok
+}
+
+// Match is like Score and also returns the rune indexes of text that the
+// pattern characters are aligned to, for highlighting.
+func Match(pattern, text string) (score int, positions []int, ok bool) {
+	return match(pattern, text, true, &scratch{})
+}
+
+// Rank scores the candidates with the given indexes (all candidates when
+// indexes is nil) and returns the matching ones, best first. Narrowing a
+// query only drops matches, so the indexes of the results of a shorter query
+// can be passed to rank an extended one.
+func Rank(pattern string, candidates []string, indexes []int) []Result {
+	var results []Result
+	var buf scratch
+	add := func(i int) {
+		if score, _, ok := match(pattern, candidates[i], false, &buf); ok {
+			results = append(results, Result{Index: i, Score: score})
+		}
+	}
+	if indexes == nil {
+		for i := range candidates {
+			add(i)
+		}
+	} else {
+		for _, i := range indexes {
+			add(i)
+		}
+	}
+	Sort(results, candidates)
+	return results
+}
+
+// Sort orders results best first: higher score, then shorter candidate, then
+// earlier index.
+func Sort(results []Result, candidates []string) {
+	sort.Slice(results, func(a, b int) bool {
+		ra, rb := results[a], results[b]
+		if ra.Score != rb.Score {
+			return ra.Score > rb.Score
+		}
+		la, lb := len(candidates[ra.Index]), len(candidates[rb.Index])
+		if la != lb {
+			return la < lb
+		}
+		return ra.Index < rb.Index
+	})
+}
+
+// match aligns pattern to text. An empty pattern matches everything with a
+// score of 0.
+func match(pattern, text string, withPositions bool, buf *scratch) (int, []int, bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+	caseSensitive := false
+	for _, r := range pattern {
+		if unicode.IsUpper(r) {
+			caseSensitive = true
+			break
+		}
+	}
+	fold := func(r rune) rune {
+		if caseSensitive {
+			return r
+		}
+		return unicode.ToLower(r)
+	}
+
+	p := []rune(pattern)
+	for i := range p {
+		p[i] = fold(p[i])
+	}
+
+	// Reject candidates that do not contain the pattern without allocating,
+	// and find where the first pattern character first occurs.
+	start, pi, runeIndex := -1, 0, 0
+	for _, r := range text {
+		if fold(r) == p[pi] {
+			if pi == 0 {
+				start = runeIndex
+			}
+			pi++
+			if pi == len(p) {
+				break
+			}
+		}
+		runeIndex++
+	}
+	if pi < len(p) {
+		return 0, nil, false
+	}
+
+	buf.grow(utf8.RuneCountInString(text))
+	runes := buf.runes
+	i := 0
+	for _, r := range text {
+		runes[i] = r
+		i++
+	}
+	bonus := bonuses(runes, buf.bonus)
+	for i := range runes {
+		runes[i] = fold(runes[i])
+	}
+
+	// Alignments start at or after the first occurrence of p[0] and end at
+	// or before the last occurrence of the last pattern character
+	end := len(runes) - 1
+	for runes[end] != p[len(p)-1] {
+		end--
+	}
+	t := runes[start : end+1]
+	bonus = bonus[start : end+1]
+
+	n, m := len(t), len(p)
+	// After row i, prev[j] is the best score of p[:i+1] with p[i] at t[j];
+	// from[i][j] is where p[i-1] sits in that alignment (for the positions).
+	prev := buf.prev[:n]
+	cur := buf.cur[:n]
+	var from [][]int
+	if withPositions {
+		from = make([][]int, m)
+	}
+
+	for j := 0; j < n; j++ {
+		prev[j] = unmatched
+		if t[j] == p[0] {
+			prev[j] = scoreMatch + bonus[j]*bonusFirstCharMultiplier
+		}
+	}
+	for i := 1; i < m; i++ {
+		var row []int
+		if withPositions {
+			row = make([]int, n)
+			from[i] = row
+		}
+		// gap is the best score of p[:i] at some k <= j-2, including the
+		// gap penalty up to j, and gapFrom is that k
+		gap, gapFrom := unmatched, -1
+		for j := 0; j < n; j++ {
+			cur[j] = unmatched
+			if j >= 2 && prev[j-2] != unmatched && prev[j-2]+scoreGapStart >= gap+scoreGapExtension {
+				gap, gapFrom = prev[j-2]+scoreGapStart, j-2
+			} else if gap != unmatched {
+				gap += scoreGapExtension
+			}
+			if t[j] != p[i] {
+				continue
+			}
+
+			best, bestFrom := unmatched, -1
+			if gap != unmatched {
+				best, bestFrom = gap+scoreMatch+bonus[j], gapFrom
+			}
+			if j >= 1 && prev[j-1] != unmatched {
+				consecutive := prev[j-1] + scoreMatch + max(bonus[j], bonusConsecutive)
+				if consecutive >= best {
+					best, bestFrom = consecutive, j-1
+				}
+			}
+			cur[j] = best
+			if withPositions {
+				row[j] = bestFrom
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	score, last := unmatched, -1
+	for j := 0; j < n; j++ {
+		if prev[j] > score {
+			score, last = prev[j], j
+		}
+	}
+	if last < 0 {
+		return 0, nil, false
+	}
+	if !withPositions {
+		return score, nil, true
+	}
+
+	positions := make([]int, m)
+	for i := m - 1; i >= 0; i-- {
+		positions[i] = start + last
+		if i > 0 {
+			last = from[i][last]
+		}
+	}
+	return score, positions, true
+}
+
+// bonuses fills result with the position bonus of every rune of text.
+// A trailing "/" (as in "dir/") does not end the last path segment.
+func bonuses(text []rune, result []int) []int {
+	lastSlash := -1
+	for i, r := range text[:len(text)-1] {
+		if r == '/' {
+			lastSlash = i
+		}
+	}
+
+	for i := range text {
+		r := text[i]
+		b := 0
+		switch {
+		case i == 0:
+			b = bonusPath
+		default:
+			prevRune := text[i-1]
+			switch {
+			case prevRune == '/':
+				b = bonusPath
+			case prevRune == ' ' || prevRune == '_' || prevRune == '-' || prevRune == '.':
+				b = bonusBoundary
+			case unicode.IsLower(prevRune) && unicode.IsUpper(r):
+				b = bonusCamel
+			case unicode.IsDigit(r) && !unicode.IsDigit(prevRune):
+				b = bonusCamel
+			}
+		}
+		if i > lastSlash {
+			b += bonusBasename
+		}
+		result[i] = b
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"abc", "abc", true, []int{0, 1, 2}},
+		{"abc", "acb", false, nil},
+		{"mgo", "cmd/main.go", true, []int{4, 9, 10}},
+		{"fb", "foo_bar", true, []int{0, 4}},
+		{"fb", "fooBar", true, []int{0, 3}},
+		{"FB", "foo_bar", false, nil},
+		{"FB", "FooBar", true, []int{0, 3}},
+		{"readme", "docs/README.md", true, []int{5, 6, 7, 8, 9, 10}},
+		{"日本", "ファイル/日本語.txt", true, []int{5, 6}},
+		{"toolong", "tool", false, nil},
+	}
+
+	for _, tt := range tests {
+		_, positions, ok := Match(tt.pattern, tt.text)
+		if ok != tt.ok || !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("Match(%q, %q) = %v, %v; want %v, %v", tt.pattern, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+		if score, ok := Score(tt.pattern, tt.text); ok != tt.ok {
+			t.Errorf("Score(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.ok)
+		} else if matchScore, _, _ := Match(tt.pattern, tt.text); score != matchScore {
+			t.Errorf("Score(%q, %q) = %d, Match score = %d", tt.pattern, tt.text, score, matchScore)
+		}
+	}
+}
+
+func TestScoreOrdering(t *testing.T) {
+	// Each pair: the first text should score higher than the second
+	tests := []struct {
+		pattern, better, worse string
+	}{
+		{"main", "cmd/main.go", "cmd/domain.go"},           // segment start beats mid-word
+		{"abc", "xabcx", "xaxbxcx"},                        // consecutive beats scattered
+		{"fb", "foo_bar", "foobar"},                        // word boundary
+		{"ui", "internal/ui/model.go", "internal/quit.go"}, // whole segment
+		{"model", "ui/model.go", "model/ui.go"},            // file name beats directory
+	}
+
+	for _, tt := range tests {
+		better, ok1 := Score(tt.pattern, tt.better)
+		worse, ok2 := Score(tt.pattern, tt.worse)
+		if !ok1 || !ok2 {
+			t.Fatalf("Score(%q) did not match %q or %q", tt.pattern, tt.better, tt.worse)
+		}
+		if better <= worse {
+			t.Errorf("Score(%q): %q = %d, want more than %q = %d", tt.pattern, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	candidates := []string{
+		"internal/ui/model_test.go",
+		"README.md",
+		"internal/ui/model.go",
+		"internal/fs/reader.go",
+		"docs/model.md",
+	}
+
+	indexesOf := func(results []Result) []int {
+		var indexes []int
+		for _, r := range results {
+			indexes = append(indexes, r.Index)
+		}
+		return indexes
+	}
+
+	if got, want := indexesOf(Rank("", candidates, nil)), []int{1, 4, 2, 3, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank(\"\") = %v, want %v (shortest first)", got, want)
+	}
+
+	results := Rank("model", candidates, nil)
+	if got, want := indexesOf(results), []int{4, 2, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank(model) = %v, want %v", got, want)
+	}
+
+	// Extending the query ranks only the previous matches
+	narrowed := Rank("modelgo", candidates, indexesOf(results))
+	if got, want := indexesOf(narrowed), []int{2, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank(modelgo) = %v, want %v", got, want)
+	}
+}
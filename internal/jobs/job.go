@@ -0,0 +1,173 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// ErrNotPausable is returned when pausing a job that has no pause points
+var ErrNotPausable = errors.New("job cannot be paused")
+
+// Func performs the work of a job. It should return ctx.Err() when the
+// context is cancelled and call job.WaitIfPaused between units of work.
+type Func func(ctx context.Context, job *Job) error
+
+// State represents the lifecycle state of a job
+type State int
+
+const (
+	StateQueued State = iota
+	StateRunning
+	StatePaused
+	StateCompleted
+	StateFailed
+	StateCancelled
+)
+
+// String returns a short label for the state
+func (s State) String() string {
+	switch s {
+	case StateQueued:
+		return "queued"
+	case StateRunning:
+		return "running"
+	case StatePaused:
+		return "paused"
+	case StateCompleted:
+		return "done"
+	case StateFailed:
+		return "failed"
+	case StateCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// IsFinished reports whether the state is terminal
+func (s State) IsFinished() bool {
+	return s == StateCompleted || s == StateFailed || s == StateCancelled
+}
+
+// Job is a unit of background work managed by a Manager
+type Job struct {
+	id       int
+	kind     string // "copy", "move", "delete", "compress", "extract"
+	title    string
+	progress *fs.Progress
+	fn       Func
+	pausable bool
+	unqueued bool
+
+	mu      sync.Mutex
+	state   State
+	paused  bool
+	resume  chan struct{} // closed when the job is resumed
+	err     error
+	errors  []string // non-fatal per-file errors
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	ready   bool // eligible to be scheduled
+	started bool
+}
+
+// Snapshot is a point-in-time copy of a job's status
+type Snapshot struct {
+	ID       int
+	Kind     string
+	Title    string
+	State    State
+	Progress fs.ProgressSnapshot
+	Err      error
+	Errors   []string
+	Pausable bool
+}
+
+// ID returns the job ID
+func (j *Job) ID() int {
+	return j.id
+}
+
+// Kind returns the job kind
+func (j *Job) Kind() string {
+	return j.kind
+}
+
+// Progress returns the progress tracker shared with the job function
+func (j *Job) Progress() *fs.Progress {
+	return j.progress
+}
+
+// State returns the current state
+func (j *Job) State() State {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.state
+}
+
+// AddError records a non-fatal error, e.g. a single file that failed in a batch
+func (j *Job) AddError(msg string) {
+	j.mu.Lock()
+	j.errors = append(j.errors, msg)
+	j.mu.Unlock()
+}
+
+// Errors returns the non-fatal errors recorded so far
+func (j *Job) Errors() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string(nil), j.errors...)
+}
+
+// WaitIfPaused blocks while the job is paused. It returns ctx.Err() if the
+// job is cancelled while waiting. Job implements fs.Pauser.
+func (j *Job) WaitIfPaused(ctx context.Context) error {
+	for {
+		j.mu.Lock()
+		if !j.paused {
+			j.mu.Unlock()
+			return ctx.Err()
+		}
+		resume := j.resume
+		j.mu.Unlock()
+
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Wait blocks until the job finishes and returns its error
+func (j *Job) Wait() error {
+	<-j.done
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Done returns a channel that is closed when the job finishes
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Snapshot returns a copy of the job's current status
+func (j *Job) Snapshot() Snapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return Snapshot{
+		ID:       j.id,
+		Kind:     j.kind,
+		Title:    j.title,
+		State:    j.state,
+		Progress: j.progress.Snapshot(),
+		Err:      j.err,
+		Errors:   append([]string(nil), j.errors...),
+		Pausable: j.pausable,
+	}
+}
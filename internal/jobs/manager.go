@@ -0,0 +1,285 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// DefaultMaxConcurrent is the number of jobs that run at the same time
+const DefaultMaxConcurrent = 2
+
+// Options configures a submitted job
+type Options struct {
+	Kind     string       // "copy", "move", "delete", "compress", "extract"
+	Title    string       // Short description shown in the jobs panel
+	Progress *fs.Progress // Progress tracker (a new one is created if nil)
+	// Pausable marks jobs whose function calls WaitIfPaused
+	Pausable bool
+	// Unqueued starts the job immediately without taking a slot. Use it for
+	// jobs that only track work already scheduled elsewhere (e.g. archive tasks).
+	Unqueued bool
+}
+
+// Manager runs jobs in the background with a concurrency limit.
+// Jobs beyond the limit wait in the queue in submission order.
+type Manager struct {
+	mu            sync.Mutex
+	jobs          []*Job
+	nextID        int
+	maxConcurrent int
+	running       int
+}
+
+// NewManager creates a new Manager. maxConcurrent <= 0 uses DefaultMaxConcurrent.
+func NewManager(maxConcurrent int) *Manager {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+	return &Manager{
+		nextID:        1,
+		maxConcurrent: maxConcurrent,
+	}
+}
+
+// Submit queues a new job and starts it when a slot is free
+func (m *Manager) Submit(opts Options, fn Func) *Job {
+	job := m.Add(opts, fn)
+	m.Start(job.ID())
+	return job
+}
+
+// Add registers a job in the queued state without scheduling it. Call Start
+// to let it run; until then it is listed (and can be cancelled) but never runs.
+func (m *Manager) Add(opts Options, fn Func) *Job {
+	progress := opts.Progress
+	if progress == nil {
+		progress = fs.NewProgress(0, 0)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	job := &Job{
+		id:       m.nextID,
+		kind:     opts.Kind,
+		title:    opts.Title,
+		progress: progress,
+		fn:       fn,
+		pausable: opts.Pausable,
+		unqueued: opts.Unqueued,
+		state:    StateQueued,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	m.nextID++
+	m.jobs = append(m.jobs, job)
+	m.mu.Unlock()
+
+	return job
+}
+
+// Start makes a job added with Add eligible to run
+func (m *Manager) Start(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		if job.id == id {
+			job.mu.Lock()
+			job.ready = true
+			job.mu.Unlock()
+		}
+	}
+	m.scheduleLocked()
+}
+
+// scheduleLocked starts queued jobs while slots are available. m.mu must be held.
+func (m *Manager) scheduleLocked() {
+	for _, job := range m.jobs {
+		if m.running >= m.maxConcurrent && !job.unqueued {
+			continue
+		}
+		job.mu.Lock()
+		startable := job.ready && !job.started && !job.state.IsFinished()
+		if startable {
+			job.started = true
+			// Time spent queued does not count towards the elapsed time
+			job.progress.Start()
+			if job.paused {
+				job.state = StatePaused
+				job.progress.Pause()
+			} else {
+				job.state = StateRunning
+			}
+		}
+		job.mu.Unlock()
+
+		if startable {
+			if !job.unqueued {
+				m.running++
+			}
+			go m.run(job)
+		}
+	}
+}
+
+// run executes the job function and records its result
+func (m *Manager) run(job *Job) {
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job panicked: %v", r)
+			}
+		}()
+		err = job.fn(job.ctx, job)
+	}()
+
+	job.mu.Lock()
+	switch {
+	case errors.Is(err, context.Canceled) || (err != nil && job.ctx.Err() != nil):
+		job.state = StateCancelled
+		job.err = context.Canceled
+	case err != nil:
+		job.state = StateFailed
+		job.err = err
+	default:
+		job.state = StateCompleted
+	}
+	job.mu.Unlock()
+	job.cancel()
+
+	m.mu.Lock()
+	if !job.unqueued {
+		m.running--
+	}
+	m.scheduleLocked()
+	m.mu.Unlock()
+
+	close(job.done)
+}
+
+// find returns the job with the given ID
+func (m *Manager) find(id int) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		if job.id == id {
+			return job, nil
+		}
+	}
+	return nil, fmt.Errorf("job %d not found", id)
+}
+
+// Pause pauses a queued or running job at its next pause point
+func (m *Manager) Pause(id int) error {
+	job, err := m.find(id)
+	if err != nil {
+		return err
+	}
+	if !job.pausable {
+		return ErrNotPausable
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.state.IsFinished() || job.paused {
+		return nil
+	}
+	job.paused = true
+	job.resume = make(chan struct{})
+	if job.started {
+		job.state = StatePaused
+		job.progress.Pause()
+	}
+	return nil
+}
+
+// Resume resumes a paused job
+func (m *Manager) Resume(id int) error {
+	job, err := m.find(id)
+	if err != nil {
+		return err
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if !job.paused {
+		return nil
+	}
+	job.paused = false
+	close(job.resume)
+	if job.started && !job.state.IsFinished() {
+		job.state = StateRunning
+	}
+	job.progress.Resume()
+	return nil
+}
+
+// Cancel cancels a job. Queued jobs are finished immediately.
+func (m *Manager) Cancel(id int) error {
+	job, err := m.find(id)
+	if err != nil {
+		return err
+	}
+
+	job.cancel()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job.mu.Lock()
+	if job.started || job.state.IsFinished() {
+		job.mu.Unlock()
+		return nil
+	}
+	// Never started: finish it here since run() will not
+	job.started = true
+	job.state = StateCancelled
+	job.err = context.Canceled
+	job.mu.Unlock()
+	close(job.done)
+	return nil
+}
+
+// Jobs returns snapshots of all jobs in submission order
+func (m *Manager) Jobs() []Snapshot {
+	m.mu.Lock()
+	jobs := append([]*Job(nil), m.jobs...)
+	m.mu.Unlock()
+
+	snapshots := make([]Snapshot, len(jobs))
+	for i, job := range jobs {
+		snapshots[i] = job.Snapshot()
+	}
+	return snapshots
+}
+
+// ActiveCount returns the number of queued, running or paused jobs
+func (m *Manager) ActiveCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, job := range m.jobs {
+		if !job.State().IsFinished() {
+			count++
+		}
+	}
+	return count
+}
+
+// ClearFinished removes finished jobs from the list
+func (m *Manager) ClearFinished() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.jobs[:0]
+	for _, job := range m.jobs {
+		if !job.State().IsFinished() {
+			kept = append(kept, job)
+		}
+	}
+	m.jobs = kept
+}
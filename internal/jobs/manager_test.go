@@ -0,0 +1,314 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingJob returns a job function that waits until release is closed,
+// honouring pause and cancellation.
+func blockingJob(started chan<- struct{}, release <-chan struct{}) Func {
+	return func(ctx context.Context, job *Job) error {
+		if started != nil {
+			close(started)
+		}
+		for {
+			if err := job.WaitIfPaused(ctx); err != nil {
+				return err
+			}
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+}
+
+func waitState(t *testing.T, job *Job, want State) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job.State() == want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job state = %v, want %v", job.State(), want)
+}
+
+func TestManagerCompletesJob(t *testing.T) {
+	m := NewManager(1)
+	job := m.Submit(Options{Kind: "copy", Title: "a"}, func(ctx context.Context, job *Job) error {
+		job.Progress().AddBytes(10)
+		return nil
+	})
+
+	if err := job.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if job.State() != StateCompleted {
+		t.Errorf("State() = %v, want %v", job.State(), StateCompleted)
+	}
+
+	jobs := m.Jobs()
+	if len(jobs) != 1 || jobs[0].ID != job.ID() {
+		t.Fatalf("Jobs() = %+v, want job %d", jobs, job.ID())
+	}
+	if jobs[0].Progress.DoneBytes != 10 {
+		t.Errorf("DoneBytes = %d, want 10", jobs[0].Progress.DoneBytes)
+	}
+}
+
+func TestManagerFailedJob(t *testing.T) {
+	m := NewManager(1)
+	want := errors.New("boom")
+	job := m.Submit(Options{Kind: "delete"}, func(ctx context.Context, job *Job) error {
+		job.AddError("x.txt: permission denied")
+		return want
+	})
+
+	if err := job.Wait(); !errors.Is(err, want) {
+		t.Errorf("Wait() error = %v, want %v", err, want)
+	}
+	snap := job.Snapshot()
+	if snap.State != StateFailed {
+		t.Errorf("State = %v, want %v", snap.State, StateFailed)
+	}
+	if len(snap.Errors) != 1 {
+		t.Errorf("Errors = %v, want one entry", snap.Errors)
+	}
+}
+
+func TestManagerQueuesBeyondLimit(t *testing.T) {
+	m := NewManager(1)
+	release := make(chan struct{})
+	first := m.Submit(Options{Kind: "copy"}, blockingJob(nil, release))
+	second := m.Submit(Options{Kind: "copy"}, blockingJob(nil, release))
+
+	waitState(t, first, StateRunning)
+	if second.State() != StateQueued {
+		t.Errorf("second job state = %v, want %v", second.State(), StateQueued)
+	}
+	if m.ActiveCount() != 2 {
+		t.Errorf("ActiveCount() = %d, want 2", m.ActiveCount())
+	}
+
+	close(release)
+	first.Wait()
+	second.Wait()
+	if m.ActiveCount() != 0 {
+		t.Errorf("ActiveCount() = %d, want 0", m.ActiveCount())
+	}
+}
+
+func TestManagerAddWaitsForStart(t *testing.T) {
+	m := NewManager(1)
+	ran := make(chan struct{})
+	job := m.Add(Options{Kind: "copy"}, func(ctx context.Context, job *Job) error {
+		close(ran)
+		return nil
+	})
+
+	select {
+	case <-ran:
+		t.Fatal("job should not run before Start")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if job.State() != StateQueued {
+		t.Errorf("State() = %v, want %v", job.State(), StateQueued)
+	}
+
+	m.Start(job.ID())
+	if err := job.Wait(); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+}
+
+func TestManagerProgressClock(t *testing.T) {
+	m := NewManager(1)
+	release := make(chan struct{})
+	first := m.Submit(Options{Kind: "copy"}, blockingJob(nil, release))
+	second := m.Submit(Options{Kind: "copy", Pausable: true}, blockingJob(nil, release))
+	waitState(t, first, StateRunning)
+
+	time.Sleep(30 * time.Millisecond)
+	if snap := second.Progress().Snapshot(); !snap.StartTime.IsZero() {
+		t.Errorf("queued job progress StartTime = %v, want zero", snap.StartTime)
+	}
+	if err := m.Pause(second.ID()); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+
+	close(release)
+	first.Wait()
+	waitState(t, second, StatePaused)
+	time.Sleep(30 * time.Millisecond)
+	snap := second.Progress().Snapshot()
+	if snap.StartTime.IsZero() {
+		t.Error("progress should start when the job is scheduled")
+	}
+	if elapsed := snap.ElapsedTime(); elapsed >= 20*time.Millisecond {
+		t.Errorf("ElapsedTime() of paused job = %v, want paused time excluded", elapsed)
+	}
+
+	if err := m.Resume(second.ID()); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if err := second.Wait(); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+}
+
+func TestManagerUnqueuedBypassesLimit(t *testing.T) {
+	m := NewManager(1)
+	release := make(chan struct{})
+	defer close(release)
+	first := m.Submit(Options{Kind: "copy"}, blockingJob(nil, release))
+	tracked := m.Submit(Options{Kind: "compress", Unqueued: true}, blockingJob(nil, release))
+
+	waitState(t, first, StateRunning)
+	waitState(t, tracked, StateRunning)
+}
+
+func TestManagerPauseResume(t *testing.T) {
+	m := NewManager(1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	job := m.Submit(Options{Kind: "copy", Pausable: true}, func(ctx context.Context, job *Job) error {
+		close(started)
+		<-release
+		// Pause point after the first unit of work
+		return job.WaitIfPaused(ctx)
+	})
+	<-started
+
+	if err := m.Pause(job.ID()); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if job.State() != StatePaused {
+		t.Errorf("State() = %v, want %v", job.State(), StatePaused)
+	}
+
+	close(release)
+	select {
+	case <-job.Done():
+		t.Fatal("paused job should not finish")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := m.Resume(job.ID()); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if err := job.Wait(); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+}
+
+func TestManagerPauseNotPausable(t *testing.T) {
+	m := NewManager(1)
+	release := make(chan struct{})
+	job := m.Submit(Options{Kind: "compress"}, blockingJob(nil, release))
+	defer close(release)
+
+	if err := m.Pause(job.ID()); !errors.Is(err, ErrNotPausable) {
+		t.Errorf("Pause() error = %v, want ErrNotPausable", err)
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	t.Run("running job", func(t *testing.T) {
+		m := NewManager(1)
+		started := make(chan struct{})
+		job := m.Submit(Options{Kind: "copy"}, blockingJob(started, nil))
+		<-started
+
+		m.Cancel(job.ID())
+		if err := job.Wait(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait() error = %v, want context.Canceled", err)
+		}
+		if job.State() != StateCancelled {
+			t.Errorf("State() = %v, want %v", job.State(), StateCancelled)
+		}
+	})
+
+	t.Run("paused job", func(t *testing.T) {
+		m := NewManager(1)
+		started := make(chan struct{})
+		job := m.Submit(Options{Kind: "copy", Pausable: true}, blockingJob(started, nil))
+		<-started
+		m.Pause(job.ID())
+
+		m.Cancel(job.ID())
+		if err := job.Wait(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait() error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("queued job", func(t *testing.T) {
+		m := NewManager(1)
+		release := make(chan struct{})
+		first := m.Submit(Options{Kind: "copy"}, blockingJob(nil, release))
+		queued := m.Submit(Options{Kind: "copy"}, blockingJob(nil, release))
+
+		m.Cancel(queued.ID())
+		if err := queued.Wait(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait() error = %v, want context.Canceled", err)
+		}
+
+		close(release)
+		if err := first.Wait(); err != nil {
+			t.Errorf("first job error = %v", err)
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		m := NewManager(1)
+		if err := m.Cancel(42); err == nil {
+			t.Error("Cancel() should return error for unknown job")
+		}
+	})
+}
+
+func TestManagerClearFinished(t *testing.T) {
+	m := NewManager(1)
+	done := m.Submit(Options{Kind: "copy"}, func(ctx context.Context, job *Job) error { return nil })
+	done.Wait()
+
+	release := make(chan struct{})
+	defer close(release)
+	m.Submit(Options{Kind: "copy"}, blockingJob(nil, release))
+
+	m.ClearFinished()
+	jobs := m.Jobs()
+	if len(jobs) != 1 {
+		t.Fatalf("Jobs() length = %d, want 1", len(jobs))
+	}
+	if jobs[0].ID == done.ID() {
+		t.Error("finished job should be removed")
+	}
+}
+
+func TestStateString(t *testing.T) {
+	tests := []struct {
+		state State
+		want  string
+	}{
+		{StateQueued, "queued"},
+		{StateRunning, "running"},
+		{StatePaused, "paused"},
+		{StateCompleted, "done"},
+		{StateFailed, "failed"},
+		{StateCancelled, "cancelled"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("State(%d).String() = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
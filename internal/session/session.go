@@ -0,0 +1,180 @@
+// Package session saves the layout of the panes when duofm exits so it can
+// be restored on the next start, and keeps named sessions that can be
+// loaded at any time.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sakura/duofm/internal/config"
+)
+
+// ErrInvalidName is returned for a session name that cannot be a file name
+var ErrInvalidName = errors.New("invalid session name")
+
+// Tab is a tab of a pane
+type Tab struct {
+	Path       string `json:"path"`
+	SortField  string `json:"sort_field,omitempty"`  // "name", "size" or "date"
+	SortOrder  string `json:"sort_order,omitempty"`  // "asc" or "desc"
+	Hidden     bool   `json:"hidden,omitempty"`      // Hidden files are shown
+	Filter     string `json:"filter,omitempty"`      // Filter pattern ("" = none)
+	FilterMode string `json:"filter_mode,omitempty"` // "incremental" or "regex"
+}
+
+// Pane is the state of one pane
+type Pane struct {
+	Tabs        []Tab  `json:"tabs"`
+	ActiveTab   int    `json:"active_tab"`             // Index into Tabs
+	DisplayMode string `json:"display_mode,omitempty"` // "basic" or "detail"
+}
+
+// State is the saved state of both panes
+type State struct {
+	Left       Pane   `json:"left"`
+	Right      Pane   `json:"right"`
+	ActivePane string `json:"active_pane,omitempty"` // "left" or "right"
+}
+
+// Named is a session saved under a name
+type Named struct {
+	Name    string
+	SavedAt time.Time
+}
+
+// DefaultPath returns the session file in the state directory (see config.StatePath)
+func DefaultPath() (string, error) {
+	return config.StatePath("session.json")
+}
+
+// Load reads the state saved at path. A missing file yields nil and no error.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+	return &state, nil
+}
+
+// Save writes state to path, replacing the file atomically
+func Save(path string, state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	// A unique temporary file keeps concurrent saves from replacing each
+	// other's half-written file
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// NamedDir returns the directory of the named sessions that belong with the
+// session file at path
+func NamedDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "sessions")
+}
+
+// namedPath returns the file of the session called name in dir
+func namedPath(dir, name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// ListNamed returns the sessions saved in dir, sorted by name. A missing
+// directory yields no sessions.
+func ListNamed(dir string) ([]Named, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	var sessions []Named
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, Named{Name: name, SavedAt: info.ModTime()})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+	return sessions, nil
+}
+
+// SaveNamed saves state in dir under name, replacing a session of that name
+func SaveNamed(dir, name string, state *State) error {
+	path, err := namedPath(dir, name)
+	if err != nil {
+		return err
+	}
+	return Save(path, state)
+}
+
+// LoadNamed reads the session called name from dir
+func LoadNamed(dir, name string) (*State, error) {
+	path, err := namedPath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	state, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("session %q not found", name)
+	}
+	return state, nil
+}
+
+// DeleteNamed removes the session called name from dir
+func DeleteNamed(dir, name string) error {
+	path, err := namedPath(dir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/custom/state")
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("DefaultPath() error = %v", err)
+	}
+	if path != "/custom/state/duofm/session.json" {
+		t.Errorf("DefaultPath() = %q", path)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "duofm", "session.json")
+
+	state, err := Load(path)
+	if err != nil || state != nil {
+		t.Fatalf("Load() of a missing file = %v, %v; want nil, nil", state, err)
+	}
+
+	want := &State{
+		Left:  Pane{Tabs: []Tab{{Path: "/tmp"}, {Path: "/usr"}}, ActiveTab: 1},
+		Right: Pane{Tabs: []Tab{{Path: "/home"}}},
+	}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got.Left.Tabs) != 2 || got.Left.Tabs[1].Path != "/usr" || got.Left.ActiveTab != 1 {
+		t.Errorf("Left = %+v", got.Left)
+	}
+	if len(got.Right.Tabs) != 1 || got.Right.Tabs[0].Path != "/home" {
+		t.Errorf("Right = %+v", got.Right)
+	}
+
+	os.WriteFile(path, []byte("{"), 0600)
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of a corrupt file should fail")
+	}
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = Save(path, &State{Left: Pane{Tabs: []Tab{{Path: "/tmp"}}}})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("Save() error = %v", err)
+		}
+	}
+	if state, err := Load(path); err != nil || state == nil || len(state.Left.Tabs) != 1 {
+		t.Errorf("Load() = %+v, %v", state, err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(tmps) != 0 {
+		t.Errorf("temporary files left behind: %v", tmps)
+	}
+}
+
+func TestNamedSessions(t *testing.T) {
+	dir := NamedDir(filepath.Join(t.TempDir(), "session.json"))
+
+	if sessions, err := ListNamed(dir); err != nil || len(sessions) != 0 {
+		t.Fatalf("ListNamed() of a missing directory = %v, %v", sessions, err)
+	}
+
+	for _, name := range []string{"work", "home"} {
+		state := &State{Left: Pane{Tabs: []Tab{{Path: "/" + name}}}, ActivePane: "right"}
+		if err := SaveNamed(dir, name, state); err != nil {
+			t.Fatalf("SaveNamed(%q) error = %v", name, err)
+		}
+	}
+	sessions, err := ListNamed(dir)
+	if err != nil || len(sessions) != 2 || sessions[0].Name != "home" || sessions[1].Name != "work" {
+		t.Fatalf("ListNamed() = %v, %v", sessions, err)
+	}
+
+	state, err := LoadNamed(dir, "work")
+	if err != nil || state.Left.Tabs[0].Path != "/work" || state.ActivePane != "right" {
+		t.Errorf("LoadNamed() = %+v, %v", state, err)
+	}
+
+	if err := DeleteNamed(dir, "work"); err != nil {
+		t.Fatalf("DeleteNamed() error = %v", err)
+	}
+	if _, err := LoadNamed(dir, "work"); err == nil {
+		t.Error("LoadNamed() of a deleted session should fail")
+	}
+
+	for _, name := range []string{"", "../escape", ".hidden", `a\b`} {
+		if err := SaveNamed(dir, name, &State{}); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("SaveNamed(%q) error = %v, want ErrInvalidName", name, err)
+		}
+	}
+}
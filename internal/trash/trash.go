@@ -0,0 +1,545 @@
+// Package trash implements the FreeDesktop.org Trash specification.
+//
+// Files are moved into $XDG_DATA_HOME/Trash when they live on the same
+// filesystem as the home trash, or into a per-mount $topdir/.Trash/$uid or
+// $topdir/.Trash-$uid directory otherwise. Each trashed item has a matching
+// info/<name>.trashinfo file recording its original path and deletion date.
+package trash
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// ErrRestoreConflict is returned when the original path of an item is occupied
+var ErrRestoreConflict = errors.New("original location already exists")
+
+const (
+	infoHeader = "[Trash Info]"
+	infoSuffix = ".trashinfo"
+	dateLayout = "2006-01-02T15:04:05"
+	// maxNameAttempts bounds the search for a free name in the trash
+	maxNameAttempts = 10000
+)
+
+// Item is a single entry in a trash directory
+type Item struct {
+	Name         string    // File name inside the trash "files" directory
+	OriginalPath string    // Absolute path the item was deleted from
+	DeletionDate time.Time // Time the item was moved to the trash
+	IsDir        bool
+	Size         int64 // Size in bytes (0 for directories)
+	trashDir     string
+}
+
+// TrashDir returns the trash directory holding the item
+func (i Item) TrashDir() string {
+	return i.trashDir
+}
+
+// FilesPath returns the path of the trashed file or directory
+func (i Item) FilesPath() string {
+	return filepath.Join(i.trashDir, "files", i.Name)
+}
+
+// InfoPath returns the path of the item's .trashinfo file
+func (i Item) InfoPath() string {
+	return filepath.Join(i.trashDir, "info", i.Name+infoSuffix)
+}
+
+// Trash moves files to and from the trash directories of the current user
+type Trash struct {
+	home   string                   // Home trash directory
+	uid    int                      // Used for per-mount trash directory names
+	mounts func() ([]string, error) // Lists mount points for per-mount trash lookup
+}
+
+// HomeTrashDir returns $XDG_DATA_HOME/Trash, falling back to ~/.local/share/Trash
+func HomeTrashDir() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataDir, "Trash"), nil
+}
+
+// New creates a Trash using the home trash of the current user
+func New() (*Trash, error) {
+	dir, err := HomeTrashDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate trash directory: %w", err)
+	}
+	return NewWithHome(dir), nil
+}
+
+// NewWithHome creates a Trash using dir as the home trash directory
+func NewWithHome(dir string) *Trash {
+	return &Trash{
+		home:   dir,
+		uid:    os.Getuid(),
+		mounts: mountPoints,
+	}
+}
+
+// HomeDir returns the home trash directory
+func (t *Trash) HomeDir() string {
+	return t.home
+}
+
+// Put moves path into the trash and returns the created item
+func (t *Trash) Put(path string) (Item, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Item{}, err
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return Item{}, fmt.Errorf("path not found: %w", err)
+	}
+
+	trashDir, err := t.trashDirFor(absPath)
+	if err == nil {
+		item, putErr := t.putIn(trashDir, absPath, info)
+		if putErr == nil {
+			return item, nil
+		}
+		if trashDir == t.home {
+			return Item{}, putErr
+		}
+	}
+
+	// The per-mount trash is unusable: fall back to the home trash, which
+	// may require copying across filesystems
+	return t.putIn(t.home, absPath, info)
+}
+
+// putIn moves absPath into trashDir and writes its info file
+func (t *Trash) putIn(trashDir, absPath string, info os.FileInfo) (Item, error) {
+	for _, sub := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(trashDir, sub), 0700); err != nil {
+			return Item{}, fmt.Errorf("failed to create trash directory: %w", err)
+		}
+	}
+
+	now := time.Now()
+	item := Item{
+		OriginalPath: absPath,
+		DeletionDate: now.Truncate(time.Second),
+		IsDir:        info.IsDir(),
+		trashDir:     trashDir,
+	}
+	if !info.IsDir() {
+		item.Size = info.Size()
+	}
+
+	// Reserve a unique name by creating the info file exclusively
+	infoFile, name, err := reserveName(trashDir, filepath.Base(absPath))
+	if err != nil {
+		return Item{}, err
+	}
+	item.Name = name
+
+	_, writeErr := infoFile.WriteString(formatInfo(infoPathValue(trashDir, absPath), now))
+	closeErr := infoFile.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		os.Remove(item.InfoPath())
+		return Item{}, fmt.Errorf("failed to write trash info: %w", err)
+	}
+
+	if err := moveItem(absPath, item.FilesPath()); err != nil {
+		os.Remove(item.InfoPath())
+		return Item{}, fmt.Errorf("failed to move to trash: %w", err)
+	}
+	return item, nil
+}
+
+// reserveName creates info/<name>.trashinfo for the first free name derived from base
+func reserveName(trashDir, base string) (*os.File, string, error) {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		// Dotfiles such as ".bashrc" have no stem
+		stem, ext = base, ""
+	}
+
+	for i := 1; i <= maxNameAttempts; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+		if _, err := os.Lstat(filepath.Join(trashDir, "files", name)); err == nil {
+			continue
+		}
+		infoPath := filepath.Join(trashDir, "info", name+infoSuffix)
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create trash info: %w", err)
+		}
+		return f, name, nil
+	}
+	return nil, "", fmt.Errorf("no free name in trash for %s", base)
+}
+
+// trashDirFor selects the trash directory for an absolute path
+func (t *Trash) trashDirFor(absPath string) (string, error) {
+	dev, err := deviceOf(filepath.Dir(absPath))
+	if err != nil {
+		return "", err
+	}
+	homeDev, err := deviceOf(existingAncestor(t.home))
+	if err != nil {
+		return "", err
+	}
+	if dev == homeDev {
+		return t.home, nil
+	}
+
+	topDir := mountTop(filepath.Dir(absPath), dev)
+	uid := strconv.Itoa(t.uid)
+
+	// $topdir/.Trash/$uid is only used when .Trash is a sticky, non-symlink directory
+	admin := filepath.Join(topDir, ".Trash")
+	if fi, err := os.Lstat(admin); err == nil && fi.IsDir() && fi.Mode()&os.ModeSticky != 0 {
+		dir := filepath.Join(admin, uid)
+		if err := os.MkdirAll(dir, 0700); err == nil {
+			return dir, nil
+		}
+	}
+
+	dir := filepath.Join(topDir, ".Trash-"+uid)
+	if err := os.Mkdir(dir, 0700); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	if fi, err := os.Lstat(dir); err != nil || !fi.IsDir() {
+		return "", fmt.Errorf("invalid trash directory: %s", dir)
+	}
+	return dir, nil
+}
+
+// Dirs returns the existing trash directories: the home trash first, then
+// per-mount trash directories of the current user
+func (t *Trash) Dirs() []string {
+	dirs := []string{t.home}
+	seen := map[string]bool{t.home: true}
+
+	mounts, err := t.mounts()
+	if err != nil {
+		return dirs
+	}
+	uid := strconv.Itoa(t.uid)
+	for _, mount := range mounts {
+		for _, dir := range []string{
+			filepath.Join(mount, ".Trash", uid),
+			filepath.Join(mount, ".Trash-"+uid),
+		} {
+			if seen[dir] {
+				continue
+			}
+			if fi, err := os.Stat(filepath.Join(dir, "info")); err == nil && fi.IsDir() {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// List returns the items of all trash directories, most recently deleted first.
+// Entries with a missing or malformed info file are skipped.
+func (t *Trash) List() ([]Item, error) {
+	var items []Item
+	for _, dir := range t.Dirs() {
+		dirItems, err := listDir(dir)
+		if err != nil {
+			if dir == t.home && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		items = append(items, dirItems...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletionDate.After(items[j].DeletionDate)
+	})
+	return items, nil
+}
+
+// listDir reads the items of one trash directory
+func listDir(trashDir string) ([]Item, error) {
+	entries, err := os.ReadDir(filepath.Join(trashDir, "info"))
+	if err != nil {
+		return nil, err
+	}
+
+	topDir := topDirOf(trashDir)
+	var items []Item
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), infoSuffix) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), infoSuffix)
+		item := Item{Name: name, trashDir: trashDir}
+
+		data, err := os.ReadFile(item.InfoPath())
+		if err != nil {
+			continue
+		}
+		item.OriginalPath, item.DeletionDate, err = parseInfo(string(data), topDir)
+		if err != nil {
+			continue
+		}
+
+		fi, err := os.Lstat(item.FilesPath())
+		if err != nil {
+			continue
+		}
+		item.IsDir = fi.IsDir()
+		if !fi.IsDir() {
+			item.Size = fi.Size()
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Restore moves an item back to its original path. Missing parent
+// directories are recreated; an existing file at the path is not overwritten.
+func (t *Trash) Restore(item Item) error {
+	if _, err := os.Lstat(item.OriginalPath); err == nil {
+		return fmt.Errorf("%w: %s", ErrRestoreConflict, item.OriginalPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+		return fmt.Errorf("failed to restore: %w", err)
+	}
+	if err := moveItem(item.FilesPath(), item.OriginalPath); err != nil {
+		return fmt.Errorf("failed to restore: %w", err)
+	}
+	if err := os.Remove(item.InfoPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove trash info: %w", err)
+	}
+	return nil
+}
+
+// Remove permanently deletes an item from the trash
+func (t *Trash) Remove(item Item) error {
+	if err := os.RemoveAll(item.FilesPath()); err != nil {
+		return fmt.Errorf("failed to delete from trash: %w", err)
+	}
+	if err := os.Remove(item.InfoPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove trash info: %w", err)
+	}
+	return nil
+}
+
+// Empty permanently deletes everything in all trash directories, including
+// files left without an info file. It stops early when ctx is cancelled.
+func (t *Trash) Empty(ctx context.Context) error {
+	var errs []error
+	for _, dir := range t.Dirs() {
+		for _, sub := range []string{"files", "info"} {
+			entries, err := os.ReadDir(filepath.Join(dir, sub))
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			for _, entry := range entries {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := os.RemoveAll(filepath.Join(dir, sub, entry.Name())); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		// Cached directory sizes refer to removed entries
+		os.Remove(filepath.Join(dir, "directorysizes"))
+	}
+	return errors.Join(errs...)
+}
+
+// moveItem renames src to dst, copying when they are on different filesystems
+func moveItem(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	return fs.MoveFile(src, dst)
+}
+
+// formatInfo returns the contents of a .trashinfo file
+func formatInfo(path string, deletedAt time.Time) string {
+	return fmt.Sprintf("%s\nPath=%s\nDeletionDate=%s\n",
+		infoHeader, (&url.URL{Path: path}).EscapedPath(), deletedAt.Format(dateLayout))
+}
+
+// parseInfo parses the contents of a .trashinfo file. Relative paths are
+// resolved against topDir.
+func parseInfo(data, topDir string) (string, time.Time, error) {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	inSection := false
+	var path, date string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == infoHeader
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			path = value
+		case "DeletionDate":
+			date = value
+		}
+	}
+
+	if path == "" {
+		return "", time.Time{}, fmt.Errorf("trash info has no Path")
+	}
+	original, err := url.PathUnescape(path)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid Path in trash info: %w", err)
+	}
+	if !filepath.IsAbs(original) {
+		if topDir == "" {
+			return "", time.Time{}, fmt.Errorf("relative Path in home trash: %s", original)
+		}
+		original = filepath.Join(topDir, original)
+	}
+
+	// A missing or malformed date is tolerated; the item is still restorable
+	deletedAt, _ := time.ParseInLocation(dateLayout, date, time.Local)
+	return original, deletedAt, nil
+}
+
+// infoPathValue returns the Path value for an item: relative to the mount
+// point for per-mount trash directories, absolute for the home trash
+func infoPathValue(trashDir, absPath string) string {
+	topDir := topDirOf(trashDir)
+	if topDir == "" {
+		return absPath
+	}
+	if rel, err := filepath.Rel(topDir, absPath); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return absPath
+}
+
+// topDirOf returns the mount point a per-mount trash directory belongs to,
+// or "" for the home trash
+func topDirOf(trashDir string) string {
+	base := filepath.Base(trashDir)
+	parent := filepath.Dir(trashDir)
+	switch {
+	case strings.HasPrefix(base, ".Trash-"):
+		return parent
+	case filepath.Base(parent) == ".Trash":
+		return filepath.Dir(parent)
+	default:
+		return ""
+	}
+}
+
+// deviceOf returns the device ID of the filesystem containing path
+func deviceOf(path string) (uint64, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return uint64(stat.Dev), nil
+}
+
+// existingAncestor returns path or its closest existing parent
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// mountTop returns the top directory of the filesystem with device dev
+// containing dir
+func mountTop(dir string, dev uint64) string {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		parentDev, err := deviceOf(parent)
+		if err != nil || parentDev != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// mountPoints lists mount points from /proc/self/mounts
+func mountPoints() ([]string, error) {
+	data, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+
+	var mounts []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		mounts = append(mounts, unescapeMountField(fields[1]))
+	}
+	return mounts, nil
+}
+
+// unescapeMountField decodes the octal escapes (e.g. \040 for space) used in /proc/self/mounts
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
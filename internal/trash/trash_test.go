@@ -0,0 +1,356 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestTrash returns a Trash rooted in a temp dir that ignores real mounts
+func newTestTrash(t *testing.T, mounts ...string) *Trash {
+	t.Helper()
+	tr := NewWithHome(filepath.Join(t.TempDir(), "Trash"))
+	tr.mounts = func() ([]string, error) { return mounts, nil }
+	return tr
+}
+
+func TestHomeTrashDir(t *testing.T) {
+	t.Run("XDG_DATA_HOME", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", "/custom/data")
+		dir, err := HomeTrashDir()
+		if err != nil {
+			t.Fatalf("HomeTrashDir() error = %v", err)
+		}
+		if dir != "/custom/data/Trash" {
+			t.Errorf("HomeTrashDir() = %q, want /custom/data/Trash", dir)
+		}
+	})
+
+	t.Run("fallback to ~/.local/share", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", "")
+		t.Setenv("HOME", "/home/test")
+		dir, err := HomeTrashDir()
+		if err != nil {
+			t.Fatalf("HomeTrashDir() error = %v", err)
+		}
+		if dir != "/home/test/.local/share/Trash" {
+			t.Errorf("HomeTrashDir() = %q, want /home/test/.local/share/Trash", dir)
+		}
+	})
+}
+
+func TestPut(t *testing.T) {
+	tr := newTestTrash(t)
+	srcDir := t.TempDir()
+	src := filepath.Join(srcDir, "my file.txt")
+	os.WriteFile(src, []byte("hello"), 0644)
+
+	item, err := tr.Put(src)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("source should be moved to the trash")
+	}
+	if item.TrashDir() != tr.HomeDir() {
+		t.Errorf("TrashDir() = %q, want home trash %q", item.TrashDir(), tr.HomeDir())
+	}
+	data, err := os.ReadFile(item.FilesPath())
+	if err != nil || string(data) != "hello" {
+		t.Errorf("trashed file content = %q, %v", data, err)
+	}
+
+	info, err := os.ReadFile(item.InfoPath())
+	if err != nil {
+		t.Fatalf("info file not created: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(info)), "\n")
+	if len(lines) != 3 || lines[0] != "[Trash Info]" {
+		t.Fatalf("info file = %q", info)
+	}
+	wantPath := "Path=" + strings.ReplaceAll(src, " ", "%20")
+	if lines[1] != wantPath {
+		t.Errorf("Path line = %q, want %q", lines[1], wantPath)
+	}
+	if _, err := time.Parse(dateLayout, strings.TrimPrefix(lines[2], "DeletionDate=")); err != nil {
+		t.Errorf("DeletionDate line = %q: %v", lines[2], err)
+	}
+}
+
+func TestPutNameCollision(t *testing.T) {
+	tr := newTestTrash(t)
+	srcDir := t.TempDir()
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		src := filepath.Join(srcDir, "report.txt")
+		os.WriteFile(src, []byte(strconv.Itoa(i)), 0644)
+		item, err := tr.Put(src)
+		if err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		names = append(names, item.Name)
+	}
+
+	want := []string{"report.txt", "report.2.txt", "report.3.txt"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("names[%d] = %q, want %q", i, names[i], want[i])
+		}
+	}
+}
+
+func TestPutDirectory(t *testing.T) {
+	tr := newTestTrash(t)
+	src := filepath.Join(t.TempDir(), "project")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "a.txt"), []byte("a"), 0644)
+
+	item, err := tr.Put(src)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if !item.IsDir {
+		t.Error("IsDir should be true")
+	}
+	if _, err := os.Stat(filepath.Join(item.FilesPath(), "sub", "a.txt")); err != nil {
+		t.Error("directory contents should be moved to the trash")
+	}
+}
+
+func TestPutNonexistent(t *testing.T) {
+	tr := newTestTrash(t)
+	if _, err := tr.Put(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Put() should return error for nonexistent path")
+	}
+}
+
+func TestListAndRestore(t *testing.T) {
+	tr := newTestTrash(t)
+	srcDir := t.TempDir()
+	first := filepath.Join(srcDir, "first.txt")
+	second := filepath.Join(srcDir, "nested", "second.txt")
+	os.WriteFile(first, []byte("1"), 0644)
+	os.MkdirAll(filepath.Dir(second), 0755)
+	os.WriteFile(second, []byte("22"), 0644)
+
+	if _, err := tr.Put(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Put(second); err != nil {
+		t.Fatal(err)
+	}
+	// The parent of the second file no longer exists when restoring
+	os.RemoveAll(filepath.Dir(second))
+
+	items, err := tr.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("List() returned %d items, want 2", len(items))
+	}
+
+	byPath := map[string]Item{}
+	for _, item := range items {
+		byPath[item.OriginalPath] = item
+	}
+	if byPath[second].Size != 2 {
+		t.Errorf("Size = %d, want 2", byPath[second].Size)
+	}
+
+	if err := tr.Restore(byPath[second]); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	data, err := os.ReadFile(second)
+	if err != nil || string(data) != "22" {
+		t.Errorf("restored content = %q, %v", data, err)
+	}
+	if _, err := os.Stat(byPath[second].InfoPath()); !os.IsNotExist(err) {
+		t.Error("info file should be removed after restore")
+	}
+
+	items, _ = tr.List()
+	if len(items) != 1 || items[0].OriginalPath != first {
+		t.Errorf("List() after restore = %+v", items)
+	}
+}
+
+func TestRestoreConflict(t *testing.T) {
+	tr := newTestTrash(t)
+	src := filepath.Join(t.TempDir(), "file.txt")
+	os.WriteFile(src, []byte("old"), 0644)
+	item, err := tr.Put(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(src, []byte("new"), 0644)
+
+	if err := tr.Restore(item); !errors.Is(err, ErrRestoreConflict) {
+		t.Errorf("Restore() error = %v, want ErrRestoreConflict", err)
+	}
+	data, _ := os.ReadFile(src)
+	if string(data) != "new" {
+		t.Error("existing file should not be overwritten")
+	}
+}
+
+func TestRemoveAndEmpty(t *testing.T) {
+	tr := newTestTrash(t)
+	srcDir := t.TempDir()
+	var items []Item
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		src := filepath.Join(srcDir, name)
+		os.WriteFile(src, []byte(name), 0644)
+		item, err := tr.Put(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+
+	if err := tr.Remove(items[0]); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(items[0].FilesPath()); !os.IsNotExist(err) {
+		t.Error("removed item should be deleted")
+	}
+	list, _ := tr.List()
+	if len(list) != 2 {
+		t.Errorf("List() returned %d items after Remove, want 2", len(list))
+	}
+
+	// Orphaned files without an info file are also removed
+	os.WriteFile(filepath.Join(tr.HomeDir(), "files", "orphan"), []byte("x"), 0644)
+
+	if err := tr.Empty(context.Background()); err != nil {
+		t.Fatalf("Empty() error = %v", err)
+	}
+	for _, sub := range []string{"files", "info"} {
+		entries, _ := os.ReadDir(filepath.Join(tr.HomeDir(), sub))
+		if len(entries) != 0 {
+			t.Errorf("%s should be empty, has %d entries", sub, len(entries))
+		}
+	}
+}
+
+func TestListEmptyTrash(t *testing.T) {
+	tr := newTestTrash(t)
+	items, err := tr.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("List() returned %d items, want 0", len(items))
+	}
+}
+
+func TestPerMountTrash(t *testing.T) {
+	mount := t.TempDir()
+	trashDir := filepath.Join(mount, ".Trash-"+strconv.Itoa(os.Getuid()))
+	os.MkdirAll(filepath.Join(trashDir, "files"), 0700)
+	os.MkdirAll(filepath.Join(trashDir, "info"), 0700)
+	os.WriteFile(filepath.Join(trashDir, "files", "photo.jpg"), []byte("jpg"), 0644)
+	os.WriteFile(filepath.Join(trashDir, "info", "photo.jpg.trashinfo"),
+		[]byte("[Trash Info]\nPath=pictures/photo.jpg\nDeletionDate=2024-05-01T10:20:30\n"), 0600)
+
+	tr := newTestTrash(t, mount)
+	items, err := tr.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("List() returned %d items, want 1", len(items))
+	}
+
+	want := filepath.Join(mount, "pictures", "photo.jpg")
+	if items[0].OriginalPath != want {
+		t.Errorf("OriginalPath = %q, want %q", items[0].OriginalPath, want)
+	}
+	if items[0].DeletionDate.Year() != 2024 {
+		t.Errorf("DeletionDate = %v", items[0].DeletionDate)
+	}
+
+	if err := tr.Restore(items[0]); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Error("item should be restored relative to the mount point")
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		topDir  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "absolute path with escapes",
+			data: "[Trash Info]\nPath=/home/user/my%20file.txt\nDeletionDate=2024-01-02T03:04:05\n",
+			want: "/home/user/my file.txt",
+		},
+		{
+			name:   "relative path in per-mount trash",
+			data:   "[Trash Info]\nPath=docs/a.txt\nDeletionDate=2024-01-02T03:04:05\n",
+			topDir: "/mnt/usb",
+			want:   "/mnt/usb/docs/a.txt",
+		},
+		{
+			name:    "relative path in home trash",
+			data:    "[Trash Info]\nPath=docs/a.txt\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing header",
+			data:    "Path=/a.txt\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := parseInfo(tt.data, tt.topDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseInfo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopDirOf(t *testing.T) {
+	tests := []struct {
+		trashDir string
+		want     string
+	}{
+		{"/home/user/.local/share/Trash", ""},
+		{"/mnt/usb/.Trash-1000", "/mnt/usb"},
+		{"/mnt/usb/.Trash/1000", "/mnt/usb"},
+	}
+
+	for _, tt := range tests {
+		if got := topDirOf(tt.trashDir); got != tt.want {
+			t.Errorf("topDirOf(%q) = %q, want %q", tt.trashDir, got, tt.want)
+		}
+	}
+}
+
+func TestUnescapeMountField(t *testing.T) {
+	if got := unescapeMountField(`/mnt/my\040disk`); got != "/mnt/my disk" {
+		t.Errorf("unescapeMountField() = %q, want %q", got, "/mnt/my disk")
+	}
+	if got := unescapeMountField("/mnt/usb"); got != "/mnt/usb" {
+		t.Errorf("unescapeMountField() = %q, want /mnt/usb", got)
+	}
+}
@@ -30,11 +30,16 @@ const (
 	ActionPrevDir
 	ActionHistoryBack
 	ActionHistoryForward
+	ActionHistoryList
 	ActionRefresh
 	ActionSyncPane
+	ActionJump
 	// Search
 	ActionSearch
 	ActionRegexSearch
+	ActionFindFiles
+	ActionGrep
+	ActionFuzzyFind
 	// External applications
 	ActionView
 	ActionEdit
@@ -46,79 +51,151 @@ const (
 	// Bookmarks
 	ActionBookmark
 	ActionAddBookmark
+	// Jobs
+	ActionJobs
+	// Trash
+	ActionDeletePermanently
+	ActionTrash
+	// Undo
+	ActionUndo
+	ActionRedo
+	// Copy options
+	ActionTogglePreserve
+	ActionToggleVerify
+	ActionOperationFilter
+	// Directory sync
+	ActionSyncDirectories
+	ActionCompareDirectories
+	ActionDiffFiles
+	// Tabs
+	ActionNewTab
+	ActionDuplicateTab
+	ActionCloseTab
+	ActionNextTab
+	ActionPrevTab
+	// Sessions
+	ActionSessions
+	// Chooser mode
+	ActionChoose
 )
 
 // actionNames maps Action values to their string names.
 var actionNames = map[Action]string{
-	ActionNone:           "none",
-	ActionMoveDown:       "move_down",
-	ActionMoveUp:         "move_up",
-	ActionMoveLeft:       "move_left",
-	ActionMoveRight:      "move_right",
-	ActionEnter:          "enter",
-	ActionCopy:           "copy",
-	ActionMove:           "move",
-	ActionDelete:         "delete",
-	ActionRename:         "rename",
-	ActionNewFile:        "new_file",
-	ActionNewDirectory:   "new_directory",
-	ActionMark:           "mark",
-	ActionToggleInfo:     "toggle_info",
-	ActionToggleHidden:   "toggle_hidden",
-	ActionSort:           "sort",
-	ActionHelp:           "help",
-	ActionHome:           "home",
-	ActionPrevDir:        "prev_dir",
-	ActionHistoryBack:    "history_back",
-	ActionHistoryForward: "history_forward",
-	ActionRefresh:        "refresh",
-	ActionSyncPane:       "sync_pane",
-	ActionSearch:         "search",
-	ActionRegexSearch:    "regex_search",
-	ActionView:           "view",
-	ActionEdit:           "edit",
-	ActionShellCommand:   "shell_command",
-	ActionContextMenu:    "context_menu",
-	ActionQuit:           "quit",
-	ActionEscape:         "escape",
-	ActionBookmark:       "bookmark",
-	ActionAddBookmark:    "add_bookmark",
+	ActionNone:               "none",
+	ActionMoveDown:           "move_down",
+	ActionMoveUp:             "move_up",
+	ActionMoveLeft:           "move_left",
+	ActionMoveRight:          "move_right",
+	ActionEnter:              "enter",
+	ActionCopy:               "copy",
+	ActionMove:               "move",
+	ActionDelete:             "delete",
+	ActionRename:             "rename",
+	ActionNewFile:            "new_file",
+	ActionNewDirectory:       "new_directory",
+	ActionMark:               "mark",
+	ActionToggleInfo:         "toggle_info",
+	ActionToggleHidden:       "toggle_hidden",
+	ActionSort:               "sort",
+	ActionHelp:               "help",
+	ActionHome:               "home",
+	ActionPrevDir:            "prev_dir",
+	ActionHistoryBack:        "history_back",
+	ActionHistoryForward:     "history_forward",
+	ActionHistoryList:        "history_list",
+	ActionRefresh:            "refresh",
+	ActionSyncPane:           "sync_pane",
+	ActionJump:               "jump",
+	ActionSearch:             "search",
+	ActionRegexSearch:        "regex_search",
+	ActionFindFiles:          "find_files",
+	ActionGrep:               "grep",
+	ActionFuzzyFind:          "fuzzy_find",
+	ActionView:               "view",
+	ActionEdit:               "edit",
+	ActionShellCommand:       "shell_command",
+	ActionContextMenu:        "context_menu",
+	ActionQuit:               "quit",
+	ActionEscape:             "escape",
+	ActionBookmark:           "bookmark",
+	ActionAddBookmark:        "add_bookmark",
+	ActionJobs:               "jobs",
+	ActionDeletePermanently:  "delete_permanently",
+	ActionTrash:              "trash",
+	ActionUndo:               "undo",
+	ActionRedo:               "redo",
+	ActionTogglePreserve:     "toggle_preserve",
+	ActionToggleVerify:       "toggle_verify",
+	ActionOperationFilter:    "operation_filter",
+	ActionSyncDirectories:    "sync_directories",
+	ActionCompareDirectories: "compare_directories",
+	ActionDiffFiles:          "diff_files",
+	ActionNewTab:             "new_tab",
+	ActionDuplicateTab:       "duplicate_tab",
+	ActionCloseTab:           "close_tab",
+	ActionNextTab:            "next_tab",
+	ActionPrevTab:            "prev_tab",
+	ActionSessions:           "sessions",
+	ActionChoose:             "choose",
 }
 
 // nameToAction maps string names to Action values.
 var nameToAction = map[string]Action{
-	"move_down":       ActionMoveDown,
-	"move_up":         ActionMoveUp,
-	"move_left":       ActionMoveLeft,
-	"move_right":      ActionMoveRight,
-	"enter":           ActionEnter,
-	"copy":            ActionCopy,
-	"move":            ActionMove,
-	"delete":          ActionDelete,
-	"rename":          ActionRename,
-	"new_file":        ActionNewFile,
-	"new_directory":   ActionNewDirectory,
-	"mark":            ActionMark,
-	"toggle_info":     ActionToggleInfo,
-	"toggle_hidden":   ActionToggleHidden,
-	"sort":            ActionSort,
-	"help":            ActionHelp,
-	"home":            ActionHome,
-	"prev_dir":        ActionPrevDir,
-	"history_back":    ActionHistoryBack,
-	"history_forward": ActionHistoryForward,
-	"refresh":         ActionRefresh,
-	"sync_pane":       ActionSyncPane,
-	"search":          ActionSearch,
-	"regex_search":    ActionRegexSearch,
-	"view":            ActionView,
-	"edit":            ActionEdit,
-	"shell_command":   ActionShellCommand,
-	"context_menu":    ActionContextMenu,
-	"quit":            ActionQuit,
-	"escape":          ActionEscape,
-	"bookmark":        ActionBookmark,
-	"add_bookmark":    ActionAddBookmark,
+	"move_down":           ActionMoveDown,
+	"move_up":             ActionMoveUp,
+	"move_left":           ActionMoveLeft,
+	"move_right":          ActionMoveRight,
+	"enter":               ActionEnter,
+	"copy":                ActionCopy,
+	"move":                ActionMove,
+	"delete":              ActionDelete,
+	"rename":              ActionRename,
+	"new_file":            ActionNewFile,
+	"new_directory":       ActionNewDirectory,
+	"mark":                ActionMark,
+	"toggle_info":         ActionToggleInfo,
+	"toggle_hidden":       ActionToggleHidden,
+	"sort":                ActionSort,
+	"help":                ActionHelp,
+	"home":                ActionHome,
+	"prev_dir":            ActionPrevDir,
+	"history_back":        ActionHistoryBack,
+	"history_forward":     ActionHistoryForward,
+	"history_list":        ActionHistoryList,
+	"refresh":             ActionRefresh,
+	"sync_pane":           ActionSyncPane,
+	"jump":                ActionJump,
+	"search":              ActionSearch,
+	"regex_search":        ActionRegexSearch,
+	"find_files":          ActionFindFiles,
+	"grep":                ActionGrep,
+	"fuzzy_find":          ActionFuzzyFind,
+	"view":                ActionView,
+	"edit":                ActionEdit,
+	"shell_command":       ActionShellCommand,
+	"context_menu":        ActionContextMenu,
+	"quit":                ActionQuit,
+	"escape":              ActionEscape,
+	"bookmark":            ActionBookmark,
+	"add_bookmark":        ActionAddBookmark,
+	"jobs":                ActionJobs,
+	"delete_permanently":  ActionDeletePermanently,
+	"trash":               ActionTrash,
+	"undo":                ActionUndo,
+	"redo":                ActionRedo,
+	"toggle_preserve":     ActionTogglePreserve,
+	"toggle_verify":       ActionToggleVerify,
+	"operation_filter":    ActionOperationFilter,
+	"sync_directories":    ActionSyncDirectories,
+	"compare_directories": ActionCompareDirectories,
+	"diff_files":          ActionDiffFiles,
+	"new_tab":             ActionNewTab,
+	"duplicate_tab":       ActionDuplicateTab,
+	"close_tab":           ActionCloseTab,
+	"next_tab":            ActionNextTab,
+	"prev_tab":            ActionPrevTab,
+	"sessions":            ActionSessions,
+	"choose":              ActionChoose,
 }
 
 // String returns the string name of the action.
@@ -61,6 +61,13 @@ func (d *ArchiveProgressDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
 				d.onCancel()
 			}
 			return d, nil
+		case tea.KeyRunes:
+			if string(msg.Runes) == "b" {
+				// バックグラウンドで継続
+				return d, func() tea.Msg {
+					return sendToBackgroundMsg{}
+				}
+			}
 		}
 	}
 
@@ -151,7 +158,7 @@ func (d *ArchiveProgressDialog) View() string {
 	}
 
 	content += "\n"
-	content += helpStyle.Render("[Esc] Cancel")
+	content += helpStyle.Render("[Esc] Cancel  [b] Run in background")
 
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
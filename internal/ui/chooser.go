@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ChooseMode は選択結果を返して終了するモード（ファイルピッカーとして使う）
+type ChooseMode int
+
+const (
+	ChooseNone  ChooseMode = iota // 通常のファイルマネージャ
+	ChooseFiles                   // マークしたファイル（なければカーソル位置）を選ぶ
+	ChooseDir                     // アクティブペインのディレクトリを選ぶ
+)
+
+// String はステータスバー用の名前を返す
+func (c ChooseMode) String() string {
+	switch c {
+	case ChooseFiles:
+		return "files"
+	case ChooseDir:
+		return "dir"
+	}
+	return "none"
+}
+
+// SetChooseMode は選択モードを設定する
+func (m *Model) SetChooseMode(mode ChooseMode) {
+	m.chooseMode = mode
+}
+
+// Chosen は選択モードで選ばれたパスを返す（nil = 選択せずに終了）
+func (m Model) Chosen() []string {
+	return m.chosen
+}
+
+// handleChoose は選択モードで現在の選択を確定して終了する
+func (m Model) handleChoose() (tea.Model, tea.Cmd) {
+	pane := m.getActivePane()
+	switch m.chooseMode {
+	case ChooseFiles:
+		paths := pane.GetMarkedFilePaths()
+		if len(paths) == 0 {
+			entry := pane.SelectedEntry()
+			if entry == nil || entry.IsParentDir() {
+				return m, nil
+			}
+			paths = []string{filepath.Join(pane.Path(), entry.Name)}
+		}
+		sort.Strings(paths)
+		m.chosen = paths
+	case ChooseDir:
+		m.chosen = []string{pane.Path()}
+	default:
+		m.statusMessage = "Not in chooser mode (start with --choose-files or --choose-dir)"
+		m.isStatusError = true
+		return m, statusMessageClearCmd(3 * time.Second)
+	}
+	return m, tea.Quit
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// compareModeLabels are the labels of the compare methods, indexed by fs.CompareMode
+var compareModeLabels = []string{"Name", "Size + mtime", "Content hash"}
+
+// compareModeShortLabels are used in the status bar legend
+var compareModeShortLabels = []string{"name", "size+mtime", "hash"}
+
+// compareRequestMsg is sent when the panes should be compared
+type compareRequestMsg struct {
+	mode      fs.CompareMode
+	recursive bool
+}
+
+// CompareDialog chooses how the directories of both panes are compared.
+type CompareDialog struct {
+	mode      fs.CompareMode
+	recursive bool
+	active    bool
+	width     int
+}
+
+// NewCompareDialog creates a compare dialog preset to the last used options.
+func NewCompareDialog(mode fs.CompareMode, recursive bool) *CompareDialog {
+	return &CompareDialog{
+		mode:      mode,
+		recursive: recursive,
+		active:    true,
+		width:     50,
+	}
+}
+
+// Update handles keyboard input.
+func (d *CompareDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	n := len(compareModeLabels)
+	switch keyMsg.String() {
+	case "j", "down":
+		d.mode = fs.CompareMode((int(d.mode) + 1) % n)
+	case "k", "up":
+		d.mode = fs.CompareMode((int(d.mode) + n - 1) % n)
+	case "r", " ":
+		d.recursive = !d.recursive
+	case "enter":
+		d.active = false
+		mode, recursive := d.mode, d.recursive
+		return d, func() tea.Msg {
+			return compareRequestMsg{mode: mode, recursive: recursive}
+		}
+	case "esc":
+		d.active = false
+		return d, func() tea.Msg {
+			return dialogResultMsg{result: DialogResult{Cancelled: true}}
+		}
+	}
+
+	return d, nil
+}
+
+// View renders the dialog.
+func (d *CompareDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render("Compare Directories"))
+	b.WriteString("\n\n")
+
+	for i, label := range compareModeLabels {
+		style := lipgloss.NewStyle().
+			Width(width-6).
+			Padding(0, 1)
+		if i == int(d.mode) {
+			style = style.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0")).
+				Bold(true)
+		}
+		b.WriteString(style.Render(label))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	check := "[ ]"
+	if d.recursive {
+		check = "[x]"
+	}
+	b.WriteString(lipgloss.NewStyle().Padding(0, 1).Render(check + " Recursive"))
+	b.WriteString("\n\n")
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("j/k:Method  r:Recursive  Enter:Compare  Esc:Cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// IsActive returns whether the dialog is active.
+func (d *CompareDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type.
+func (d *CompareDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayPane
+}
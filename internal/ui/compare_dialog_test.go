@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+func TestCompareDialog(t *testing.T) {
+	dialog := NewCompareDialog(fs.CompareBySizeTime, false)
+
+	if dialog.DisplayType() != DialogDisplayPane {
+		t.Error("expected DialogDisplayPane")
+	}
+	view := dialog.View()
+	for _, want := range []string{"Name", "Size + mtime", "Content hash", "[ ] Recursive"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q", want)
+		}
+	}
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(compareRequestMsg)
+	if !ok {
+		t.Fatal("Enter should request a comparison")
+	}
+	if msg.mode != fs.CompareByChecksum || !msg.recursive {
+		t.Errorf("request = %+v, want recursive checksum comparison", msg)
+	}
+	if dialog.IsActive() {
+		t.Error("dialog should close after Enter")
+	}
+}
+
+func TestCompareDialogCancel(t *testing.T) {
+	dialog := NewCompareDialog(fs.CompareByName, false)
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg, ok := cmd().(dialogResultMsg); !ok || !msg.result.Cancelled {
+		t.Errorf("Esc should cancel, got %#v", msg)
+	}
+}
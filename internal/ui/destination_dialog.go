@@ -0,0 +1,354 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxCompletionCandidates is the number of Tab completion candidates shown
+const maxCompletionCandidates = 6
+
+// destinationTarget is a resolved copy/move destination
+type destinationTarget struct {
+	Dir        string // Directory the sources are copied into
+	NewName    string // New name for a single source (copy-and-rename), empty otherwise
+	MissingDir string // Directory that has to be created first, empty if Dir exists
+	Exists     bool   // The renamed destination already exists and will be replaced
+}
+
+// Path returns the destination passed to the copy/move
+func (t destinationTarget) Path() string {
+	if t.NewName != "" {
+		return filepath.Join(t.Dir, t.NewName)
+	}
+	return t.Dir
+}
+
+// destinationDialogResultMsg is sent when a destination has been confirmed
+type destinationDialogResultMsg struct {
+	operation string
+	sources   []string
+	target    destinationTarget
+}
+
+// DestinationDialog asks where to copy or move the selected files.
+// The input is pre-filled with the other pane's path and accepts absolute,
+// relative (to the active pane) and ~ paths. A single source can be given a
+// new name. Missing directories are created after a second Enter.
+type DestinationDialog struct {
+	*InputDialog
+	operation  string   // "copy" or "move"
+	sources    []string // Full paths of the files to copy/move
+	baseDir    string   // Directory relative paths are resolved against
+	candidates []string // Tab completion candidates of the last ambiguous completion
+	confirm    string   // Input that is waiting for a second Enter
+	notice     string   // Message explaining what the second Enter does
+}
+
+// NewDestinationDialog creates a destination dialog pre-filled with initial
+func NewDestinationDialog(operation string, sources []string, initial, baseDir string) *DestinationDialog {
+	title := fmt.Sprintf("%s %s to:", capitalize(operation), describeSources(sources))
+	input := NewInputDialog(title, nil)
+	input.emptyErrorMsg = "Destination cannot be empty"
+	input.width = 70
+	if initial != "" && !strings.HasSuffix(initial, string(filepath.Separator)) {
+		initial += string(filepath.Separator)
+	}
+	input.input = initial
+	input.cursorPos = len([]rune(initial))
+
+	return &DestinationDialog{
+		InputDialog: input,
+		operation:   operation,
+		sources:     sources,
+		baseDir:     baseDir,
+	}
+}
+
+// describeSources returns "name" for one source and "N files" for several
+func describeSources(sources []string) string {
+	if len(sources) == 1 {
+		return fmt.Sprintf("\"%s\"", filepath.Base(sources[0]))
+	}
+	return fmt.Sprintf("%d files", len(sources))
+}
+
+// Update handles keyboard input
+func (d *DestinationDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyTab:
+		d.errorMsg = ""
+		d.complete()
+		return d, nil
+
+	case tea.KeyEnter:
+		return d, d.submit()
+
+	case tea.KeyEsc:
+		d.active = false
+		return d, nil
+	}
+
+	// Editing the path discards candidates and a pending confirmation
+	d.candidates = nil
+	d.confirm = ""
+	d.notice = ""
+	d.InputDialog.Update(keyMsg)
+	return d, nil
+}
+
+// submit validates the input. Creating directories and replacing an existing
+// file need a second Enter on the same input.
+func (d *DestinationDialog) submit() tea.Cmd {
+	d.errorMsg = ""
+	if strings.TrimSpace(d.input) == "" {
+		d.errorMsg = d.emptyErrorMsg
+		return nil
+	}
+
+	target, err := resolveDestination(d.input, d.baseDir, len(d.sources))
+	if err != nil {
+		d.errorMsg = err.Error()
+		return nil
+	}
+
+	if d.confirm != d.input {
+		switch {
+		case target.MissingDir != "":
+			d.confirm = d.input
+			d.notice = fmt.Sprintf("%s does not exist. Enter: create it", target.MissingDir)
+			return nil
+		case target.Exists:
+			d.confirm = d.input
+			d.notice = fmt.Sprintf("%s exists. Enter: overwrite it", target.Path())
+			return nil
+		}
+	}
+
+	d.active = false
+	operation, sources := d.operation, d.sources
+	return func() tea.Msg {
+		return destinationDialogResultMsg{operation: operation, sources: sources, target: target}
+	}
+}
+
+// complete completes the last path component of the input like a shell.
+// A unique match is completed fully (directories get a trailing slash),
+// several matches are completed to their common prefix and listed.
+func (d *DestinationDialog) complete() {
+	d.candidates = nil
+	dirPart, prefix := splitCompletionInput(d.input)
+
+	entries, err := os.ReadDir(resolveInputPath(dirPart, d.baseDir))
+	if err != nil {
+		return
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		// Hidden entries are only completed when explicitly asked for
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		if isDirEntry(filepath.Join(resolveInputPath(dirPart, d.baseDir), name), entry) {
+			name += string(filepath.Separator)
+		}
+		matches = append(matches, name)
+	}
+	if len(matches) == 0 {
+		return
+	}
+	sort.Strings(matches)
+
+	completed := matches[0]
+	if len(matches) > 1 {
+		completed = commonPrefix(matches)
+		d.candidates = matches
+	}
+	d.input = dirPart + completed
+	d.cursorPos = len([]rune(d.input))
+	d.confirm = ""
+	d.notice = ""
+}
+
+// isDirEntry reports whether entry is a directory or a symlink to one
+func isDirEntry(path string, entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink != 0 {
+		info, err := os.Stat(path)
+		return err == nil && info.IsDir()
+	}
+	return false
+}
+
+// splitCompletionInput splits input into the directory part (up to and
+// including the last slash) and the name prefix being completed
+func splitCompletionInput(input string) (dirPart, prefix string) {
+	i := strings.LastIndex(input, string(filepath.Separator))
+	if i < 0 {
+		return "", input
+	}
+	return input[:i+1], input[i+1:]
+}
+
+// commonPrefix returns the longest common prefix of names
+func commonPrefix(names []string) string {
+	prefix := names[0]
+	for _, name := range names[1:] {
+		for !strings.HasPrefix(name, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// resolveInputPath expands ~ and makes a relative path absolute against baseDir
+func resolveInputPath(input, baseDir string) string {
+	path := input
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if path == "" {
+		return baseDir
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// resolveDestination interprets the dialog input for count sources.
+// An existing directory receives the sources. Otherwise, for a single source
+// without a trailing slash, the last component is the new name; for several
+// sources (or a trailing slash) the whole path is a directory to create.
+func resolveDestination(input, baseDir string, count int) (destinationTarget, error) {
+	path := resolveInputPath(input, baseDir)
+
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		return destinationTarget{Dir: path}, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return destinationTarget{}, err
+	}
+
+	wantsDir := strings.HasSuffix(input, string(filepath.Separator))
+	if err == nil {
+		// An existing file can only be replaced by a single file
+		if count != 1 || wantsDir {
+			return destinationTarget{}, fmt.Errorf("%s is not a directory", path)
+		}
+		return destinationTarget{Dir: filepath.Dir(path), NewName: filepath.Base(path), Exists: true}, nil
+	}
+
+	if count != 1 || wantsDir {
+		return destinationTarget{Dir: path, MissingDir: path}, nil
+	}
+
+	target := destinationTarget{Dir: filepath.Dir(path), NewName: filepath.Base(path)}
+	parent, err := os.Stat(target.Dir)
+	switch {
+	case os.IsNotExist(err):
+		target.MissingDir = target.Dir
+	case err != nil:
+		return destinationTarget{}, err
+	case !parent.IsDir():
+		return destinationTarget{}, errors.New(target.Dir + " is not a directory")
+	}
+	return target, nil
+}
+
+// View renders the dialog
+func (d *DestinationDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render(d.title))
+	b.WriteString("\n\n")
+
+	b.WriteString(d.renderInputField(width - 8))
+	b.WriteString("\n")
+
+	textStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1)
+
+	if len(d.candidates) > 0 {
+		shown := d.candidates
+		if len(shown) > maxCompletionCandidates {
+			shown = shown[:maxCompletionCandidates]
+		}
+		line := strings.Join(shown, "  ")
+		if len(d.candidates) > len(shown) {
+			line += fmt.Sprintf("  (+%d more)", len(d.candidates)-len(shown))
+		}
+		b.WriteString("\n")
+		b.WriteString(textStyle.Foreground(lipgloss.Color("245")).Render(line))
+	}
+
+	if d.notice != "" {
+		b.WriteString("\n")
+		b.WriteString(textStyle.Foreground(lipgloss.Color("214")).Render(d.notice))
+	}
+
+	if d.errorMsg != "" {
+		b.WriteString("\n")
+		b.WriteString(textStyle.Foreground(lipgloss.Color("196")).Render(d.errorMsg))
+	}
+
+	b.WriteString("\n\n")
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("Tab: Complete  Enter: Confirm  Esc: Cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// DisplayType returns the dialog display type
+func (d *DestinationDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
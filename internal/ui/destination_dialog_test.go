@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewDestinationDialog(t *testing.T) {
+	d := NewDestinationDialog("copy", []string{"/src/a.txt"}, "/dest", "/src")
+
+	if d.input != "/dest/" {
+		t.Errorf("input = %q, want the other pane with a trailing slash", d.input)
+	}
+	if d.cursorPos != len("/dest/") {
+		t.Errorf("cursorPos = %d, want end of input", d.cursorPos)
+	}
+	if !strings.Contains(d.View(), `Copy "a.txt" to:`) {
+		t.Error("View() should name the source")
+	}
+	if d.DisplayType() != DialogDisplayScreen {
+		t.Error("DisplayType() should be DialogDisplayScreen")
+	}
+
+	d = NewDestinationDialog("move", []string{"/src/a.txt", "/src/b.txt"}, "/dest", "/src")
+	if !strings.Contains(d.View(), "Move 2 files to:") {
+		t.Error("View() should count several sources")
+	}
+}
+
+func TestResolveDestination(t *testing.T) {
+	base := t.TempDir()
+	os.Mkdir(filepath.Join(base, "dir"), 0755)
+	os.WriteFile(filepath.Join(base, "file.txt"), nil, 0644)
+
+	tests := []struct {
+		name    string
+		input   string
+		count   int
+		want    destinationTarget
+		wantErr bool
+	}{
+		{"existing directory", filepath.Join(base, "dir"), 2, destinationTarget{Dir: filepath.Join(base, "dir")}, false},
+		{"relative directory", "dir", 1, destinationTarget{Dir: filepath.Join(base, "dir")}, false},
+		{"new name", "dir/new.txt", 1, destinationTarget{Dir: filepath.Join(base, "dir"), NewName: "new.txt"}, false},
+		{"new name in missing directory", "x/y/new.txt", 1,
+			destinationTarget{Dir: filepath.Join(base, "x", "y"), NewName: "new.txt", MissingDir: filepath.Join(base, "x", "y")}, false},
+		{"missing directory for several files", "out", 2, destinationTarget{Dir: filepath.Join(base, "out"), MissingDir: filepath.Join(base, "out")}, false},
+		{"trailing slash is a directory", "out/", 1, destinationTarget{Dir: filepath.Join(base, "out"), MissingDir: filepath.Join(base, "out")}, false},
+		{"existing file is replaced", "file.txt", 1, destinationTarget{Dir: base, NewName: "file.txt", Exists: true}, false},
+		{"existing file for several files", "file.txt", 2, destinationTarget{}, true},
+		{"file as parent", "file.txt/new.txt", 1, destinationTarget{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDestination(tt.input, base, tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveDestination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveDestination() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDestinationDialogTabCompletion(t *testing.T) {
+	base := t.TempDir()
+	os.Mkdir(filepath.Join(base, "photos"), 0755)
+	os.Mkdir(filepath.Join(base, "projects"), 0755)
+	os.Mkdir(filepath.Join(base, ".private"), 0755)
+	os.WriteFile(filepath.Join(base, "notes.txt"), nil, 0644)
+
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+
+	t.Run("unique match completes a directory", func(t *testing.T) {
+		d := NewDestinationDialog("copy", []string{"/src/a.txt"}, "", base)
+		d.input, d.cursorPos = "ph", 2
+		d.Update(tab)
+		if d.input != "photos/" {
+			t.Errorf("input = %q, want %q", d.input, "photos/")
+		}
+	})
+
+	t.Run("several matches complete the common prefix", func(t *testing.T) {
+		d := NewDestinationDialog("copy", []string{"/src/a.txt"}, base, base)
+		d.input += "p"
+		d.Update(tab)
+		if d.input != base+"/p" {
+			t.Errorf("input = %q, want %q", d.input, base+"/p")
+		}
+		view := d.View()
+		if !strings.Contains(view, "photos/") || !strings.Contains(view, "projects/") {
+			t.Error("View() should list the candidates")
+		}
+		if strings.Contains(view, ".private") {
+			t.Error("hidden entries should not be offered without a leading dot")
+		}
+	})
+
+	t.Run("hidden entries with a leading dot", func(t *testing.T) {
+		d := NewDestinationDialog("copy", []string{"/src/a.txt"}, "", base)
+		d.input, d.cursorPos = ".p", 2
+		d.Update(tab)
+		if d.input != ".private/" {
+			t.Errorf("input = %q, want %q", d.input, ".private/")
+		}
+	})
+}
+
+func TestDestinationDialogConfirmsMissingDirectory(t *testing.T) {
+	base := t.TempDir()
+	d := NewDestinationDialog("copy", []string{"/src/a.txt", "/src/b.txt"}, filepath.Join(base, "new"), base)
+
+	_, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !d.IsActive() {
+		t.Fatal("first Enter should ask before creating the directory")
+	}
+	if !strings.Contains(d.View(), "does not exist") {
+		t.Error("View() should explain that the directory will be created")
+	}
+
+	_, cmd = d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("second Enter should confirm")
+	}
+	result := cmd().(destinationDialogResultMsg)
+	if result.target.MissingDir != filepath.Join(base, "new") {
+		t.Errorf("MissingDir = %q", result.target.MissingDir)
+	}
+}
+
+func TestDestinationDialogEscCancels(t *testing.T) {
+	d := NewDestinationDialog("copy", []string{"/src/a.txt"}, "/dest", "/src")
+	if _, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil || d.IsActive() {
+		t.Error("Esc should close the dialog without a result")
+	}
+}
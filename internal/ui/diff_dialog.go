@@ -0,0 +1,360 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/diff"
+)
+
+// maxDiffFileSize is the largest file the diff viewer opens
+const maxDiffFileSize = 4 * 1024 * 1024
+
+// diffGutterWidth is the width of the hunk marker and line number column
+const diffGutterWidth = 7
+
+// diffLoadedMsg is sent when both files of a diff were read
+type diffLoadedMsg struct {
+	left, right *diff.File
+	err         error
+}
+
+// diffCloseMsg is sent when the diff viewer is closed
+type diffCloseMsg struct {
+	saved bool // A file was written, so the panes need to be reloaded
+}
+
+// DiffDialog shows two text files side by side over both panes. Changed
+// lines are highlighted, n/N jump between hunks and a hunk can be copied to
+// the other side; edits stay in memory until they are saved with w.
+type DiffDialog struct {
+	left, right *diff.File
+	rows        []diff.Row
+	hunks       []diff.Hunk
+	hunk        int // Selected hunk
+	offset      int // First visible row
+
+	modifiedLeft  bool
+	modifiedRight bool
+	saved         bool
+	confirmClose  bool
+	message       string
+
+	active bool
+	width  int
+	height int
+}
+
+// NewDiffDialog creates a diff viewer for two files
+func NewDiffDialog(left, right *diff.File) *DiffDialog {
+	d := &DiffDialog{
+		left:   left,
+		right:  right,
+		active: true,
+		width:  80,
+		height: 24,
+	}
+	d.recompute()
+	if len(d.hunks) > 0 {
+		d.scrollToHunk()
+	}
+	return d
+}
+
+// loadDiffCmd reads both files off the UI goroutine
+func loadDiffCmd(leftPath, rightPath string) tea.Cmd {
+	return func() tea.Msg {
+		left, err := diff.ReadFile(leftPath, maxDiffFileSize)
+		if err != nil {
+			return diffLoadedMsg{err: err}
+		}
+		right, err := diff.ReadFile(rightPath, maxDiffFileSize)
+		if err != nil {
+			return diffLoadedMsg{err: err}
+		}
+		return diffLoadedMsg{left: left, right: right}
+	}
+}
+
+// SetSize sets the area the viewer covers
+func (d *DiffDialog) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+	d.clampOffset()
+}
+
+// recompute diffs the current contents of both sides
+func (d *DiffDialog) recompute() {
+	edits := diff.Lines(d.left.Lines, d.right.Lines)
+	d.rows = diff.SideBySide(edits)
+	d.hunks = diff.Hunks(edits)
+	if d.hunk >= len(d.hunks) {
+		d.hunk = len(d.hunks) - 1
+	}
+	if d.hunk < 0 {
+		d.hunk = 0
+	}
+	d.clampOffset()
+}
+
+// visibleRows is the number of diff rows that fit between header and footer
+func (d *DiffDialog) visibleRows() int {
+	// Border (2), header and footer
+	rows := d.height - 4
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// clampOffset keeps the scroll position inside the rows
+func (d *DiffDialog) clampOffset() {
+	maxOffset := len(d.rows) - d.visibleRows()
+	if d.offset > maxOffset {
+		d.offset = maxOffset
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+}
+
+// scrollToHunk shows the selected hunk a little below the top
+func (d *DiffDialog) scrollToHunk() {
+	for i, row := range d.rows {
+		if row.Hunk == d.hunk {
+			d.offset = i - d.visibleRows()/3
+			break
+		}
+	}
+	d.clampOffset()
+}
+
+// Update handles keyboard input
+func (d *DiffDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	key := keyMsg.String()
+	if key != "q" && key != "esc" {
+		d.confirmClose = false
+	}
+	d.message = ""
+
+	page := d.visibleRows()
+	switch key {
+	case "j", "down":
+		d.offset++
+	case "k", "up":
+		d.offset--
+	case "ctrl+d", "pgdown", " ":
+		d.offset += page / 2
+	case "ctrl+u", "pgup":
+		d.offset -= page / 2
+	case "g", "home":
+		d.offset = 0
+	case "G", "end":
+		d.offset = len(d.rows)
+	case "n", "]":
+		if d.hunk < len(d.hunks)-1 {
+			d.hunk++
+		}
+		d.scrollToHunk()
+		return d, nil
+	case "N", "p", "[":
+		if d.hunk > 0 {
+			d.hunk--
+		}
+		d.scrollToHunk()
+		return d, nil
+	case ">", "l":
+		d.copyHunk(true)
+		return d, nil
+	case "<", "h":
+		d.copyHunk(false)
+		return d, nil
+	case "w":
+		d.save()
+		return d, nil
+	case "q", "esc":
+		if (d.modifiedLeft || d.modifiedRight) && !d.confirmClose {
+			d.confirmClose = true
+			d.message = "Unsaved changes: w to save, q again to discard"
+			return d, nil
+		}
+		d.active = false
+		saved := d.saved
+		return d, func() tea.Msg { return diffCloseMsg{saved: saved} }
+	}
+
+	d.clampOffset()
+	return d, nil
+}
+
+// copyHunk replaces the selected hunk on one side with the other side's lines
+func (d *DiffDialog) copyHunk(toRight bool) {
+	if len(d.hunks) == 0 {
+		return
+	}
+	h := d.hunks[d.hunk]
+	if toRight {
+		d.right.Lines = diff.CopyHunk(d.left.Lines, d.right.Lines, h, true)
+		d.modifiedRight = true
+	} else {
+		d.left.Lines = diff.CopyHunk(d.right.Lines, d.left.Lines, h, false)
+		d.modifiedLeft = true
+	}
+	// The next hunk takes the index of the one that was resolved
+	d.recompute()
+	if len(d.hunks) > 0 {
+		d.scrollToHunk()
+	}
+}
+
+// save writes the modified sides back to disk
+func (d *DiffDialog) save() {
+	var saved []string
+	for _, side := range []struct {
+		file     *diff.File
+		modified *bool
+	}{{d.left, &d.modifiedLeft}, {d.right, &d.modifiedRight}} {
+		if !*side.modified {
+			continue
+		}
+		if err := side.file.Write(); err != nil {
+			d.message = fmt.Sprintf("Failed to save: %v", err)
+			return
+		}
+		*side.modified = false
+		d.saved = true
+		saved = append(saved, filepath.Base(side.file.Path))
+	}
+	if len(saved) == 0 {
+		d.message = "No changes to save"
+		return
+	}
+	d.message = "Saved " + strings.Join(saved, ", ")
+}
+
+// View renders the viewer
+func (d *DiffDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	innerWidth := d.width - 4 // Border and padding
+	if innerWidth < 20 {
+		innerWidth = 20
+	}
+	colWidth := (innerWidth - 1) / 2
+	textWidth := colWidth - diffGutterWidth
+	if textWidth < 1 {
+		textWidth = 1
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	separator := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("│")
+
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(d.header(d.left.Path, d.modifiedLeft, colWidth)))
+	b.WriteString(separator)
+	b.WriteString(headerStyle.Render(d.header(d.right.Path, d.modifiedRight, colWidth)))
+	b.WriteString("\n")
+
+	end := d.offset + d.visibleRows()
+	for i := d.offset; i < end; i++ {
+		if i >= len(d.rows) {
+			b.WriteString(strings.Repeat(" ", colWidth) + separator + strings.Repeat(" ", colWidth))
+		} else {
+			row := d.rows[i]
+			b.WriteString(d.renderCell(row, row.A, d.left.Lines, true, textWidth))
+			b.WriteString(separator)
+			b.WriteString(d.renderCell(row, row.B, d.right.Lines, false, textWidth))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(
+		runewidth.FillRight(runewidth.Truncate(d.footer(), innerWidth, "..."), innerWidth)))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(0, 1)
+
+	return boxStyle.Render(b.String())
+}
+
+// header renders the file name of one column
+func (d *DiffDialog) header(path string, modified bool, width int) string {
+	title := path
+	if modified {
+		title += " [modified]"
+	}
+	return runewidth.FillRight(" "+truncatePath(title, width-2), width)
+}
+
+// renderCell renders the left or right side of a row
+func (d *DiffDialog) renderCell(row diff.Row, line int, lines []string, left bool, textWidth int) string {
+	marker := " "
+	if row.Hunk >= 0 && row.Hunk == d.hunk {
+		marker = ">"
+	}
+	if line < 0 {
+		style := lipgloss.NewStyle().Background(lipgloss.Color("236"))
+		return marker + style.Render(strings.Repeat(" ", diffGutterWidth-1+textWidth))
+	}
+
+	text := strings.ReplaceAll(strings.TrimSuffix(lines[line], "\r"), "\t", "    ")
+	text = runewidth.FillRight(runewidth.Truncate(text, textWidth, "…"), textWidth)
+	gutter := fmt.Sprintf("%5d ", line+1)
+
+	// A changed line without a counterpart was removed (left) or added (right)
+	otherEmpty := row.B < 0
+	if !left {
+		otherEmpty = row.A < 0
+	}
+	style := lipgloss.NewStyle()
+	switch {
+	case row.Hunk < 0:
+	case otherEmpty && left:
+		style = style.Background(lipgloss.Color("52"))
+	case otherEmpty:
+		style = style.Background(lipgloss.Color("22"))
+	default:
+		style = style.Background(lipgloss.Color("58"))
+	}
+	gutterStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	return marker + gutterStyle.Render(gutter) + style.Render(text)
+}
+
+// footer renders the position and key hints
+func (d *DiffDialog) footer() string {
+	position := "no differences"
+	if len(d.hunks) > 0 {
+		position = fmt.Sprintf("hunk %d/%d", d.hunk+1, len(d.hunks))
+	}
+	if d.message != "" {
+		return position + "  " + d.message
+	}
+	return position + "  n/N:next/prev hunk  >/<:copy hunk right/left  w:save  q:close"
+}
+
+// IsActive returns whether the viewer is open
+func (d *DiffDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type
+func (d *DiffDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sakura/duofm/internal/diff"
+)
+
+func newTestDiffDialog(t *testing.T, left, right string) (*DiffDialog, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.txt")
+	rightPath := filepath.Join(dir, "right.txt")
+	os.WriteFile(leftPath, []byte(left), 0644)
+	os.WriteFile(rightPath, []byte(right), 0644)
+	msg := loadDiffCmd(leftPath, rightPath)().(diffLoadedMsg)
+	if msg.err != nil {
+		t.Fatalf("loadDiffCmd() error = %v", msg.err)
+	}
+	dialog := NewDiffDialog(msg.left, msg.right)
+	dialog.SetSize(100, 20)
+	return dialog, leftPath, rightPath
+}
+
+func TestDiffDialogView(t *testing.T) {
+	dialog, _, _ := newTestDiffDialog(t, "one\ntwo\nthree\n", "one\nTWO\nthree\nfour\n")
+
+	if dialog.DisplayType() != DialogDisplayScreen {
+		t.Error("expected DialogDisplayScreen")
+	}
+	view := dialog.View()
+	for _, want := range []string{"left.txt", "right.txt", "two", "TWO", "four", "hunk 1/2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q", want)
+		}
+	}
+	// The viewer covers the area it was given
+	if w, h := lipgloss.Width(view), lipgloss.Height(view); w != 100 || h != 20 {
+		t.Errorf("View() size = %dx%d, want 100x20", w, h)
+	}
+}
+
+func TestDiffDialogHunkNavigation(t *testing.T) {
+	dialog, _, _ := newTestDiffDialog(t, "a\nb\nc\nd\n", "A\nb\nc\nD\n")
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if dialog.hunk != 1 {
+		t.Errorf("hunk = %d after n, want 1", dialog.hunk)
+	}
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if dialog.hunk != 1 {
+		t.Errorf("n should stop at the last hunk, got %d", dialog.hunk)
+	}
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	if dialog.hunk != 0 {
+		t.Errorf("hunk = %d after N, want 0", dialog.hunk)
+	}
+}
+
+func TestDiffDialogCopyHunkAndSave(t *testing.T) {
+	dialog, leftPath, rightPath := newTestDiffDialog(t, "a\nb\nc\nd\n", "A\nb\nc\nD\n")
+
+	// Copy the first hunk to the right, the second to the left
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'>'}})
+	if len(dialog.hunks) != 1 || !dialog.modifiedRight {
+		t.Fatalf("hunks = %+v after copying one to the right", dialog.hunks)
+	}
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'<'}})
+	if len(dialog.hunks) != 0 || !strings.Contains(dialog.View(), "no differences") {
+		t.Errorf("hunks = %+v, want none", dialog.hunks)
+	}
+
+	// Closing with unsaved changes asks first
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if cmd != nil || !dialog.IsActive() {
+		t.Fatal("q with unsaved changes should ask before closing")
+	}
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	for _, path := range []string{leftPath, rightPath} {
+		if data, _ := os.ReadFile(path); string(data) != "a\nb\nc\nD\n" {
+			t.Errorf("%s = %q after saving", filepath.Base(path), data)
+		}
+	}
+
+	_, cmd = dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if msg, ok := cmd().(diffCloseMsg); !ok || !msg.saved {
+		t.Errorf("closing should report the saved files, got %#v", msg)
+	}
+}
+
+func TestDiffFilesRequiresFiles(t *testing.T) {
+	left, right := t.TempDir(), t.TempDir()
+	os.Mkdir(filepath.Join(left, "dir"), 0755)
+	os.WriteFile(filepath.Join(left, "a.txt"), []byte("a\n"), 0644)
+	os.WriteFile(filepath.Join(right, "b.txt"), []byte("b\n"), 0644)
+	m := setupPreflightModel(t, left, right)
+	m.leftPane.MoveCursorDown() // dir
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'X'}})
+	m = updatedModel.(Model)
+	if !m.isStatusError || m.dialog != nil {
+		t.Errorf("diff of a directory should fail, status %q", m.statusMessage)
+	}
+
+	m.leftPane.MoveCursorDown() // a.txt
+	m.rightPane.MoveCursorDown()
+	updatedModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'X'}})
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	dialog, ok := m.dialog.(*DiffDialog)
+	if !ok {
+		t.Fatalf("dialog should be DiffDialog, got %T", m.dialog)
+	}
+	if dialog.width != 120 || dialog.height != 38 {
+		t.Errorf("viewer size = %dx%d, want the pane area", dialog.width, dialog.height)
+	}
+
+	updatedModel, _ = m.Update(diffLoadedMsg{err: diff.ErrBinary})
+	if m = updatedModel.(Model); !strings.Contains(m.statusMessage, "binary") {
+		t.Errorf("status message = %q", m.statusMessage)
+	}
+}
@@ -74,6 +74,32 @@ func (dh *DirectoryHistory) NavigateForward() (string, bool) {
 	return dh.paths[dh.currentIndex], true
 }
 
+// NavigateTo moves the current position to index, any number of steps back
+// or forward. Returns the path at index and true if successful.
+// Returns empty string and false if index is out of range.
+func (dh *DirectoryHistory) NavigateTo(index int) (string, bool) {
+	if index < 0 || index >= len(dh.paths) {
+		return "", false
+	}
+
+	dh.currentIndex = index
+	return dh.paths[index], true
+}
+
+// Entries returns a copy of the history paths, oldest first, and the index
+// of the current position (-1 = no history).
+func (dh *DirectoryHistory) Entries() ([]string, int) {
+	return append([]string(nil), dh.paths...), dh.currentIndex
+}
+
+// Clone returns an independent copy of the history, used when a tab is
+// duplicated.
+func (dh *DirectoryHistory) Clone() DirectoryHistory {
+	clone := *dh
+	clone.paths = append([]string(nil), dh.paths...)
+	return clone
+}
+
 // CanGoBack returns whether backward navigation is possible.
 func (dh *DirectoryHistory) CanGoBack() bool {
 	return dh.currentIndex > 0
@@ -479,3 +479,49 @@ func TestComplexStateTransitions(t *testing.T) {
 		t.Errorf("Expected currentIndex to be 2, got %d", dh.currentIndex)
 	}
 }
+
+// TestNavigateTo tests jumping several steps at once
+func TestNavigateTo(t *testing.T) {
+	dh := NewDirectoryHistory()
+	dh.AddToHistory("/a")
+	dh.AddToHistory("/b")
+	dh.AddToHistory("/c")
+	dh.AddToHistory("/d")
+
+	path, ok := dh.NavigateTo(0)
+	if !ok || path != "/a" || dh.currentIndex != 0 {
+		t.Errorf("NavigateTo(0) = %q, %v; currentIndex %d", path, ok, dh.currentIndex)
+	}
+
+	// Forward history is kept, so the jump can be undone step by step
+	path, ok = dh.NavigateForward()
+	if !ok || path != "/b" {
+		t.Errorf("NavigateForward() after NavigateTo = %q, %v", path, ok)
+	}
+
+	for _, index := range []int{-1, 4} {
+		if _, ok := dh.NavigateTo(index); ok {
+			t.Errorf("NavigateTo(%d) should fail", index)
+		}
+	}
+	if dh.currentIndex != 1 {
+		t.Errorf("a failed NavigateTo should keep currentIndex, got %d", dh.currentIndex)
+	}
+}
+
+// TestEntries tests that Entries returns a copy
+func TestEntries(t *testing.T) {
+	dh := NewDirectoryHistory()
+	dh.AddToHistory("/a")
+	dh.AddToHistory("/b")
+	dh.NavigateBack()
+
+	paths, current := dh.Entries()
+	if len(paths) != 2 || paths[0] != "/a" || paths[1] != "/b" || current != 0 {
+		t.Errorf("Entries() = %v, %d", paths, current)
+	}
+	paths[0] = "/changed"
+	if dh.paths[0] != "/a" {
+		t.Error("Entries() should return a copy")
+	}
+}
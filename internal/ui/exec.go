@@ -1,8 +1,10 @@
 package ui
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -39,9 +41,27 @@ func openWithViewer(path, workDir string) tea.Cmd {
 	})
 }
 
-// openWithEditor opens the file with editor ($EDITOR or vim)
-func openWithEditor(path, workDir string) tea.Cmd {
-	c := exec.Command(getEditor(), path)
+// lineArgEditors are editors that open a file at a line with "+N file"
+var lineArgEditors = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "gvim": true, "view": true,
+	"nano": true, "emacs": true, "emacsclient": true, "micro": true,
+	"kak": true, "joe": true, "ne": true, "mg": true,
+}
+
+// editorArgs returns the arguments that open path in editor, at line if it
+// is positive and the editor understands "+N"
+func editorArgs(editor, path string, line int) []string {
+	if line > 0 && lineArgEditors[filepath.Base(editor)] {
+		return []string{fmt.Sprintf("+%d", line), path}
+	}
+	return []string{path}
+}
+
+// openWithEditor opens the file with editor ($EDITOR or vim), at line if it
+// is positive (0 opens the file as usual)
+func openWithEditor(path, workDir string, line int) tea.Cmd {
+	editor := getEditor()
+	c := exec.Command(editor, editorArgs(editor, path, line)...)
 	c.Dir = workDir
 	return tea.ExecProcess(c, func(err error) tea.Msg {
 		return execFinishedMsg{err: err}
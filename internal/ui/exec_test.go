@@ -2,6 +2,7 @@ package ui
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -96,7 +97,7 @@ func TestOpenWithEditorReturnsCmd(t *testing.T) {
 	f.Close()
 
 	// Test that openWithEditor returns a non-nil command
-	cmd := openWithEditor(f.Name(), tmpDir)
+	cmd := openWithEditor(f.Name(), tmpDir, 0)
 	if cmd == nil {
 		t.Error("openWithEditor() returned nil command")
 	}
@@ -319,8 +320,26 @@ func TestOpenWithEditorWithWorkDir(t *testing.T) {
 	f.Close()
 
 	// Test that openWithEditor returns a non-nil command with workDir
-	cmd := openWithEditor(f.Name(), tmpDir)
+	cmd := openWithEditor(f.Name(), tmpDir, 0)
 	if cmd == nil {
 		t.Error("openWithEditor() returned nil command")
 	}
 }
+
+func TestEditorArgs(t *testing.T) {
+	tests := []struct {
+		editor string
+		line   int
+		want   string
+	}{
+		{"vim", 12, "+12 f.go"},
+		{"/usr/bin/nvim", 3, "+3 f.go"},
+		{"nano", 0, "f.go"},
+		{"code", 12, "f.go"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(editorArgs(tt.editor, "f.go", tt.line), " "); got != tt.want {
+			t.Errorf("editorArgs(%q, %d) = %q, want %q", tt.editor, tt.line, got, tt.want)
+		}
+	}
+}
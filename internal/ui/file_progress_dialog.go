@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// FileProgressDialog はコピー/移動操作のバイト単位の進捗表示ダイアログ
+type FileProgressDialog struct {
+	operation string       // "copy", "move" or "sync"
+	destPath  string       // 宛先ディレクトリ
+	progress  *fs.Progress // 進捗情報（ワーカーと共有）
+	active    bool         // ダイアログがアクティブ
+	width     int          // ダイアログの幅
+	onCancel  func()       // キャンセル時のコールバック
+}
+
+// NewFileProgressDialog は新しいファイル操作進捗ダイアログを作成
+func NewFileProgressDialog(operation, destPath string, progress *fs.Progress) *FileProgressDialog {
+	return &FileProgressDialog{
+		operation: operation,
+		destPath:  destPath,
+		progress:  progress,
+		active:    true,
+		width:     70,
+	}
+}
+
+// SetOnCancel はキャンセルコールバックを設定
+func (d *FileProgressDialog) SetOnCancel(callback func()) {
+	d.onCancel = callback
+}
+
+// Update はメッセージを処理
+func (d *FileProgressDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			// Escapeでキャンセル
+			if d.onCancel != nil {
+				d.onCancel()
+			}
+		case "b":
+			// バックグラウンドで継続
+			return d, func() tea.Msg {
+				return sendToBackgroundMsg{}
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// View はダイアログを描画
+func (d *FileProgressDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		MarginBottom(1)
+
+	progressBarStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("62"))
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("246"))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		MarginTop(1)
+
+	title := "Copying"
+	switch d.operation {
+	case "move":
+		title = "Moving"
+	case "sync":
+		title = "Syncing"
+	}
+
+	var content string
+	content += titleStyle.Render(title) + "\n"
+	content += infoStyle.Render("To: "+truncatePath(d.destPath, 60)) + "\n\n"
+
+	snap := d.progress.Snapshot()
+
+	// プログレスバー
+	percentage := snap.Percentage()
+	barWidth := 50
+	filledWidth := (percentage * barWidth) / 100
+	bar := "[" + strings.Repeat("█", filledWidth) + strings.Repeat("░", barWidth-filledWidth) + "]"
+	content += progressBarStyle.Render(bar) + fmt.Sprintf(" %d%%\n\n", percentage)
+
+	// バイト数とファイル数
+	content += infoStyle.Render(
+		fmt.Sprintf("Bytes: %s / %s", FormatSize(snap.DoneBytes), FormatSize(snap.TotalBytes)),
+	) + "\n"
+	content += infoStyle.Render(
+		fmt.Sprintf("Files: %d/%d", snap.DoneFiles, snap.TotalFiles),
+	) + "\n"
+
+	// 現在処理中のファイル
+	if snap.CurrentFile != "" {
+		currentFile := filepath.Base(snap.CurrentFile)
+		if len(currentFile) > 50 {
+			currentFile = "..." + currentFile[len(currentFile)-47:]
+		}
+		content += infoStyle.Render(fmt.Sprintf("Current: %s", currentFile)) + "\n"
+	}
+
+	// スループット
+	content += infoStyle.Render(
+		fmt.Sprintf("Speed: %s/s", FormatSize(int64(snap.BytesPerSecond()))),
+	) + "\n"
+
+	// 経過時間と推定残り時間
+	content += infoStyle.Render(
+		fmt.Sprintf("Elapsed: %s", formatDuration(snap.ElapsedTime())),
+	) + "\n"
+	if snap.DoneBytes > 0 {
+		content += infoStyle.Render(
+			fmt.Sprintf("Remaining: %s", formatDuration(snap.EstimatedRemaining())),
+		) + "\n"
+	}
+
+	content += "\n"
+	content += helpStyle.Render("[Esc] Cancel  [b] Run in background")
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(d.width)
+
+	return boxStyle.Render(content)
+}
+
+// IsActive はダイアログがアクティブかを返す
+func (d *FileProgressDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType はダイアログの表示タイプを返す
+func (d *FileProgressDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+func TestNewFileProgressDialog(t *testing.T) {
+	progress := fs.NewProgress(1024, 1)
+	dialog := NewFileProgressDialog("copy", "/dest", progress)
+
+	if !dialog.IsActive() {
+		t.Error("NewFileProgressDialog() should be active by default")
+	}
+	if dialog.DisplayType() != DialogDisplayScreen {
+		t.Error("FileProgressDialog should be displayed on screen")
+	}
+}
+
+func TestFileProgressDialogView(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		wantTitle string
+	}{
+		{"copy", "copy", "Copying"},
+		{"move", "move", "Moving"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := fs.NewProgress(2048, 2)
+			progress.SetCurrentFile("/src/file1.txt")
+			progress.AddBytes(1024)
+			progress.FileDone()
+
+			view := NewFileProgressDialog(tt.operation, "/dest", progress).View()
+
+			for _, want := range []string{tt.wantTitle, "50%", "Files: 1/2", "file1.txt", "Speed:", "Elapsed:", "[Esc] Cancel"} {
+				if !strings.Contains(view, want) {
+					t.Errorf("View() should contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileProgressDialogEscCancels(t *testing.T) {
+	dialog := NewFileProgressDialog("copy", "/dest", fs.NewProgress(0, 0))
+	cancelled := false
+	dialog.SetOnCancel(func() { cancelled = true })
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if !cancelled {
+		t.Error("Esc should invoke the cancel callback")
+	}
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/config"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// FilterDialog chooses the include/exclude filter applied to copy, move and
+// delete. The first row clears the filter, the others are the filter sets
+// saved in the config file.
+type FilterDialog struct {
+	sets    []config.FilterSet
+	current string // Active filter, empty if none
+	cursor  int    // 0 = no filter, i = sets[i-1]
+	active  bool
+	width   int
+}
+
+// filterSelectMsg is sent when a filter was chosen (nil clears the filter).
+type filterSelectMsg struct {
+	filter *fs.Filter
+	name   string // Name of the saved set, empty for a typed filter
+}
+
+// filterCustomMsg is sent when user wants to type a filter.
+type filterCustomMsg struct{}
+
+// filterSaveMsg is sent when user wants to save the active filter as a set.
+type filterSaveMsg struct{}
+
+// filterDeleteMsg is sent when user wants to delete a saved set.
+type filterDeleteMsg struct {
+	index int
+}
+
+// filterCloseMsg is sent when dialog is closed without action.
+type filterCloseMsg struct{}
+
+// filterSetsSavedMsg is sent when the filter sets were written to the config file.
+type filterSetsSavedMsg struct {
+	sets []config.FilterSet
+	name string
+}
+
+// NewFilterDialog creates a new filter dialog. current is the active filter.
+func NewFilterDialog(sets []config.FilterSet, current string) *FilterDialog {
+	return &FilterDialog{
+		sets:    sets,
+		current: current,
+		active:  true,
+		width:   60,
+	}
+}
+
+// filterSetSpec returns the filter set in the syntax of fs.ParseFilter.
+func filterSetSpec(set config.FilterSet) string {
+	return (&fs.Filter{Include: set.Include, Exclude: set.Exclude}).String()
+}
+
+// Update handles keyboard input.
+func (d *FilterDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch keyMsg.String() {
+	case "j", "down":
+		d.cursor = (d.cursor + 1) % (len(d.sets) + 1)
+	case "k", "up":
+		d.cursor = (d.cursor + len(d.sets)) % (len(d.sets) + 1)
+	case "n":
+		d.active = false
+		return d, func() tea.Msg { return filterCustomMsg{} }
+	case "s":
+		d.active = false
+		return d, func() tea.Msg { return filterSaveMsg{} }
+	case "d":
+		if d.cursor == 0 {
+			return d, nil
+		}
+		index := d.cursor - 1
+		d.active = false
+		return d, func() tea.Msg { return filterDeleteMsg{index: index} }
+	case "enter":
+		d.active = false
+		if d.cursor == 0 {
+			return d, func() tea.Msg { return filterSelectMsg{} }
+		}
+		set := d.sets[d.cursor-1]
+		return d, func() tea.Msg {
+			filter, err := fs.NewFilter(set.Include, set.Exclude)
+			if err != nil {
+				return showStatusMsg{message: err.Error(), isError: true}
+			}
+			return filterSelectMsg{filter: filter, name: set.Name}
+		}
+	case "esc":
+		d.active = false
+		return d, func() tea.Msg { return filterCloseMsg{} }
+	}
+
+	return d, nil
+}
+
+// View renders the dialog.
+func (d *FilterDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render("Copy/Move/Delete Filter"))
+	b.WriteString("\n\n")
+
+	mutedStyle := lipgloss.NewStyle().
+		Width(width-6).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("245"))
+	current := d.current
+	if current == "" {
+		current = "none"
+	}
+	b.WriteString(mutedStyle.Render(runewidth.Truncate("Active: "+current, width-8, "...")))
+	b.WriteString("\n\n")
+
+	rows := []string{"No filter"}
+	for _, set := range d.sets {
+		rows = append(rows, set.Name+"  "+filterSetSpec(set))
+	}
+	for i, row := range rows {
+		style := lipgloss.NewStyle().
+			Width(width-6).
+			Padding(0, 1)
+		if i == d.cursor {
+			style = style.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0")).
+				Bold(true)
+		}
+		b.WriteString(style.Render(runewidth.Truncate(row, width-8, "...")))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("Enter:Use  n:New  s:Save active  d:Delete  Esc:Close"))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// IsActive returns whether the dialog is active.
+func (d *FilterDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type.
+func (d *FilterDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayPane
+}
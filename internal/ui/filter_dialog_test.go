@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/config"
+)
+
+func testFilterSets() []config.FilterSet {
+	return []config.FilterSet{
+		{Name: "Sources", Include: []string{"*.go"}, Exclude: []string{"vendor"}},
+		{Name: "No backups", Exclude: []string{"*~"}},
+	}
+}
+
+func TestFilterDialogView(t *testing.T) {
+	dialog := NewFilterDialog(testFilterSets(), "*.txt")
+
+	if dialog.DisplayType() != DialogDisplayPane {
+		t.Error("expected DialogDisplayPane")
+	}
+	view := dialog.View()
+	for _, want := range []string{"Active: *.txt", "No filter", "Sources  *.go !vendor", "No backups  !*~"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q", want)
+		}
+	}
+}
+
+func TestFilterDialogSelect(t *testing.T) {
+	dialog := NewFilterDialog(testFilterSets(), "")
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(filterSelectMsg)
+	if !ok {
+		t.Fatal("Enter should select the filter set")
+	}
+	if msg.name != "Sources" || msg.filter.String() != "*.go !vendor" {
+		t.Errorf("selected %q %q, want Sources", msg.name, msg.filter)
+	}
+	if dialog.IsActive() {
+		t.Error("dialog should close after selecting")
+	}
+
+	// The first row clears the filter
+	dialog = NewFilterDialog(testFilterSets(), "*.go")
+	_, cmd = dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg := cmd().(filterSelectMsg); msg.filter != nil {
+		t.Errorf("first row should clear the filter, got %v", msg.filter)
+	}
+}
+
+func TestFilterDialogDelete(t *testing.T) {
+	dialog := NewFilterDialog(testFilterSets(), "")
+
+	// "No filter" cannot be deleted
+	if _, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}); cmd != nil {
+		t.Error("d on the first row should do nothing")
+	}
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if msg, ok := cmd().(filterDeleteMsg); !ok || msg.index != 1 {
+		t.Errorf("expected filterDeleteMsg for the last set, got %+v", msg)
+	}
+}
+
+func TestOperationFilterWorkflow(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*FilterDialog); !ok {
+		t.Fatalf("dialog should be FilterDialog, got %T", m.dialog)
+	}
+
+	// Type a filter
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("*.go !vendor")})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.opFilter.String() != "*.go !vendor" {
+		t.Fatalf("opFilter = %q, want *.go !vendor", m.opFilter)
+	}
+	m.statusMessage = ""
+	if !strings.Contains(m.renderStatusBar(), "filter:*.go !vendor") {
+		t.Error("status bar should show the active filter")
+	}
+
+	// Save it as a named set
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	m = updatedModel.(Model)
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Go")})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if len(m.filterSets) != 1 || m.filterSets[0].Name != "Go" || m.opFilterName != "Go" {
+		t.Fatalf("filterSets = %v, opFilterName = %q", m.filterSets, m.opFilterName)
+	}
+	configPath, _ := config.GetConfigPath()
+	if sets, _ := config.LoadFilterSets(configPath); len(sets) != 1 || sets[0].Include[0] != "*.go" {
+		t.Errorf("saved filter sets = %v", sets)
+	}
+
+	// Clear it again
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	m = updatedModel.(Model)
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.opFilter != nil || m.statusMessage != "Filter cleared" {
+		t.Errorf("opFilter = %v, statusMessage = %q", m.opFilter, m.statusMessage)
+	}
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// findSyntaxHelp explains the query syntax of fs.ParseFindQuery
+var findSyntaxHelp = []string{
+	"*.go report     names (glob or substring)",
+	"re:^test_       name regex",
+	"type:f|d|l      file / directory / symlink",
+	"size:>10M       also size:<1k",
+	"mtime:<7d       changed within 7 days (>7d: before)",
+}
+
+// findRequestMsg is sent when a recursive find should start
+type findRequestMsg struct {
+	criteria *fs.FindCriteria
+	query    string
+}
+
+// FindDialog asks for the query of a recursive find from the pane directory.
+// The query is checked before the dialog closes, so a typo shows an error
+// instead of starting a search.
+type FindDialog struct {
+	input    *InputDialog // Line editing and rendering of the query field
+	root     string
+	errorMsg string
+	active   bool
+	width    int
+}
+
+// NewFindDialog creates a find dialog preset to the last query.
+func NewFindDialog(root, query string) *FindDialog {
+	input := NewInputDialog("", nil)
+	input.input = query
+	input.cursorPos = len([]rune(query))
+	return &FindDialog{
+		input:  input,
+		root:   root,
+		active: true,
+		width:  60,
+	}
+}
+
+// Update handles keyboard input.
+func (d *FindDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	d.errorMsg = ""
+	switch keyMsg.Type {
+	case tea.KeyEnter:
+		query := strings.TrimSpace(d.input.input)
+		criteria, err := fs.ParseFindQuery(query, time.Now())
+		if err != nil {
+			d.errorMsg = err.Error()
+			return d, nil
+		}
+		d.active = false
+		return d, func() tea.Msg {
+			return findRequestMsg{criteria: criteria, query: query}
+		}
+	case tea.KeyEsc:
+		d.active = false
+		return d, func() tea.Msg {
+			return dialogResultMsg{result: DialogResult{Cancelled: true}}
+		}
+	}
+
+	d.input.Update(keyMsg)
+	return d, nil
+}
+
+// View renders the dialog.
+func (d *FindDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render("Find in " + truncatePath(d.root, width-14)))
+	b.WriteString("\n\n")
+
+	b.WriteString(d.input.renderInputField(width - 8))
+	b.WriteString("\n")
+
+	if d.errorMsg != "" {
+		errorStyle := lipgloss.NewStyle().
+			Width(width-4).
+			Padding(0, 1).
+			Foreground(lipgloss.Color("196"))
+		b.WriteString(errorStyle.Render(d.errorMsg))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	helpStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("245"))
+	for _, line := range findSyntaxHelp {
+		b.WriteString(helpStyle.Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("Enter:Find  Esc:Cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// IsActive returns whether the dialog is active.
+func (d *FindDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type.
+func (d *FindDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayPane
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+func TestFindDialog(t *testing.T) {
+	dialog := NewFindDialog("/tmp/project", "*.go")
+
+	if dialog.DisplayType() != DialogDisplayPane {
+		t.Error("expected DialogDisplayPane")
+	}
+	if view := dialog.View(); !strings.Contains(view, "Find in /tmp/project") || !strings.Contains(view, "type:f|d|l") {
+		t.Errorf("View() should show the root and the syntax help: %s", view)
+	}
+
+	for _, r := range " type:f" {
+		dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(findRequestMsg)
+	if !ok {
+		t.Fatal("Enter should request a find")
+	}
+	if msg.query != "*.go type:f" || msg.criteria.Type != fs.FindFiles {
+		t.Errorf("request = %q %+v", msg.query, msg.criteria)
+	}
+	if dialog.IsActive() {
+		t.Error("dialog should close after Enter")
+	}
+}
+
+func TestFindDialogInvalidQuery(t *testing.T) {
+	dialog := NewFindDialog("/tmp", "size:10")
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Error("an invalid query should not start a find")
+	}
+	if !dialog.IsActive() || !strings.Contains(dialog.View(), "invalid size") {
+		t.Error("dialog should stay open and show the error")
+	}
+}
+
+func TestFindDialogCancel(t *testing.T) {
+	dialog := NewFindDialog("/tmp", "")
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg, ok := cmd().(dialogResultMsg); !ok || !msg.result.Cancelled {
+		t.Errorf("Esc should cancel, got %#v", msg)
+	}
+}
@@ -0,0 +1,423 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/fs"
+	"github.com/sakura/duofm/internal/fuzzy"
+)
+
+// fuzzyIndexLimit is the most entries the fuzzy finder indexes
+const fuzzyIndexLimit = 200000
+
+// fuzzyBatchInterval is how often newly indexed entries are ranked and shown
+const fuzzyBatchInterval = 100 * time.Millisecond
+
+// fuzzyIndex is an index of the files below the pane directory being built in
+// the background. Entries are sent over entries, which is closed when the
+// walk ends; err is set before that.
+type fuzzyIndex struct {
+	cancel  context.CancelFunc
+	entries chan fs.IndexEntry
+	err     error
+}
+
+// fuzzyIndexMsg carries the entries indexed since the last message
+type fuzzyIndexMsg struct {
+	index   *fuzzyIndex
+	entries []fs.IndexEntry
+	done    bool
+	err     error
+}
+
+// fuzzyRankMsg carries the candidates that match query, best first. count is
+// the number of indexed candidates the ranking covers.
+type fuzzyRankMsg struct {
+	dialog  *FuzzyFinderDialog
+	query   string
+	count   int
+	results []fuzzy.Result
+}
+
+// fuzzyCloseMsg is sent when the fuzzy finder is closed
+type fuzzyCloseMsg struct {
+	path string // Absolute path of the chosen entry ("" = cancelled)
+}
+
+// FuzzyFinderDialog finds files below the pane directory by typing a few
+// characters of their path. The directory is indexed in the background while
+// the query is typed, and the matches are ranked by subsequence score off the
+// UI goroutine so typing stays responsive in large trees.
+type FuzzyFinderDialog struct {
+	root   string
+	hidden bool
+	input  *InputDialog
+
+	index   *fuzzyIndex
+	paths   []string // Candidates: slash separated relative paths, directories end in "/"
+	status  string   // Outcome of the finished indexing
+	query   string   // Query the results are ranked for
+	ranked  int      // Number of candidates the results cover
+	results []fuzzy.Result
+	ranking bool // A ranking is running
+
+	cursor int
+	offset int
+	active bool
+	width  int
+	height int
+}
+
+// NewFuzzyFinderDialog creates a fuzzy finder for the files below root.
+// hidden tells whether hidden files are indexed too.
+func NewFuzzyFinderDialog(root string, hidden bool) *FuzzyFinderDialog {
+	return &FuzzyFinderDialog{
+		root:   root,
+		hidden: hidden,
+		input:  NewInputDialog("", nil),
+		active: true,
+		width:  80,
+		height: 24,
+	}
+}
+
+// SetSize sets the area the dialog covers
+func (d *FuzzyFinderDialog) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+	d.clampOffset()
+}
+
+// start begins indexing the directory
+func (d *FuzzyFinderDialog) start() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	index := &fuzzyIndex{
+		cancel:  cancel,
+		entries: make(chan fs.IndexEntry, 1024),
+	}
+	root := d.root
+	opts := fs.IndexOptions{Hidden: d.hidden, GitIgnore: true, MaxEntries: fuzzyIndexLimit}
+	go func() {
+		index.err = fs.Index(ctx, root, opts, func(entry fs.IndexEntry) {
+			select {
+			case index.entries <- entry:
+			case <-ctx.Done():
+			}
+		})
+		close(index.entries)
+	}()
+
+	d.index = index
+	return waitFuzzyIndex(index)
+}
+
+// waitFuzzyIndex waits for the next indexed entries. The first entry is
+// sent right away; more are collected for a short while so the candidates
+// are not ranked again for every entry.
+func waitFuzzyIndex(index *fuzzyIndex) tea.Cmd {
+	return func() tea.Msg {
+		entry, ok := <-index.entries
+		if !ok {
+			return fuzzyIndexMsg{index: index, done: true, err: index.err}
+		}
+		batch := []fs.IndexEntry{entry}
+		timeout := time.After(fuzzyBatchInterval)
+		for {
+			select {
+			case entry, ok := <-index.entries:
+				if !ok {
+					return fuzzyIndexMsg{index: index, entries: batch, done: true, err: index.err}
+				}
+				batch = append(batch, entry)
+			case <-timeout:
+				return fuzzyIndexMsg{index: index, entries: batch}
+			}
+		}
+	}
+}
+
+// handleIndex adds the indexed entries to the candidates
+func (d *FuzzyFinderDialog) handleIndex(msg fuzzyIndexMsg) tea.Cmd {
+	if !d.active || msg.index != d.index {
+		return nil
+	}
+	for _, entry := range msg.entries {
+		path := filepath.ToSlash(entry.Path)
+		if entry.IsDir {
+			path += "/"
+		}
+		d.paths = append(d.paths, path)
+	}
+	if !msg.done {
+		return tea.Batch(waitFuzzyIndex(msg.index), d.rank())
+	}
+
+	d.index = nil
+	switch {
+	case errors.Is(msg.err, fs.ErrIndexLimit):
+		d.status = fmt.Sprintf("Stopped indexing at %d entries", len(d.paths))
+	case msg.err != nil:
+		d.status = fmt.Sprintf("Indexing failed: %v", msg.err)
+	}
+	return d.rank()
+}
+
+// rank ranks the candidates for the current query in the background unless
+// a ranking is already running or the results are up to date. Only the new
+// candidates are scored when the query is unchanged, and only the previous
+// matches when the query was extended.
+func (d *FuzzyFinderDialog) rank() tea.Cmd {
+	query := d.input.input
+	if d.ranking || (query == d.query && d.ranked == len(d.paths)) {
+		return nil
+	}
+	d.ranking = true
+
+	dialog := d
+	paths := d.paths // Only appended to, so the goroutine can read it
+	previousQuery, previous, from := d.query, d.results, d.ranked
+	return func() tea.Msg {
+		var keep []fuzzy.Result
+		indexes := make([]int, 0, len(paths))
+		switch {
+		case query == previousQuery:
+			keep = previous
+		case strings.HasPrefix(query, previousQuery):
+			for _, r := range previous {
+				indexes = append(indexes, r.Index)
+			}
+		default:
+			from = 0
+		}
+		for i := from; i < len(paths); i++ {
+			indexes = append(indexes, i)
+		}
+
+		results := fuzzy.Rank(query, paths, indexes)
+		if len(keep) > 0 {
+			results = append(results, keep...)
+			fuzzy.Sort(results, paths)
+		}
+		return fuzzyRankMsg{dialog: dialog, query: query, count: len(paths), results: results}
+	}
+}
+
+// handleRank shows the ranked matches and ranks again if the query or the
+// candidates changed in the meantime
+func (d *FuzzyFinderDialog) handleRank(msg fuzzyRankMsg) tea.Cmd {
+	if !d.active || msg.dialog != d {
+		return nil
+	}
+	d.ranking = false
+	if msg.query != d.query {
+		d.cursor = 0
+		d.offset = 0
+	}
+	d.query = msg.query
+	d.ranked = msg.count
+	d.results = msg.results
+	d.clampCursor()
+	return d.rank()
+}
+
+// close closes the dialog, moving the pane to path if it is set
+func (d *FuzzyFinderDialog) close(path string) tea.Cmd {
+	if d.index != nil {
+		d.index.cancel()
+		d.index = nil
+	}
+	d.active = false
+	return func() tea.Msg { return fuzzyCloseMsg{path: path} }
+}
+
+// Update handles keyboard input
+func (d *FuzzyFinderDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	page := d.visibleRows()
+	switch keyMsg.String() {
+	case "enter":
+		if path := d.selected(); path != "" {
+			return d, d.close(filepath.Join(d.root, filepath.FromSlash(strings.TrimSuffix(path, "/"))))
+		}
+		return d, nil
+	case "esc":
+		return d, d.close("")
+	case "down", "ctrl+n":
+		d.cursor++
+	case "up", "ctrl+p":
+		d.cursor--
+	case "pgdown":
+		d.cursor += page
+	case "pgup":
+		d.cursor -= page
+	default:
+		d.input.Update(keyMsg)
+		return d, d.rank()
+	}
+	d.clampCursor()
+	return d, nil
+}
+
+// selected returns the candidate under the cursor
+func (d *FuzzyFinderDialog) selected() string {
+	if d.cursor < 0 || d.cursor >= len(d.results) {
+		return ""
+	}
+	return d.paths[d.results[d.cursor].Index]
+}
+
+// visibleRows is the number of result rows that fit in the dialog
+func (d *FuzzyFinderDialog) visibleRows() int {
+	// Border (2), title, input field (3), status and footer
+	rows := d.height - 8
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// clampCursor keeps the cursor on a result and on screen
+func (d *FuzzyFinderDialog) clampCursor() {
+	if d.cursor >= len(d.results) {
+		d.cursor = len(d.results) - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+	d.clampOffset()
+}
+
+// clampOffset keeps the cursor on screen
+func (d *FuzzyFinderDialog) clampOffset() {
+	rows := d.visibleRows()
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+rows {
+		d.offset = d.cursor - rows + 1
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+}
+
+// View renders the dialog
+func (d *FuzzyFinderDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	width := d.width - 4 // Border and padding
+	if width < 20 {
+		width = 20
+	}
+
+	var b strings.Builder
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render(runewidth.FillRight("Fuzzy find in "+truncatePath(d.root, width-14), width)))
+	b.WriteString("\n")
+	b.WriteString(d.input.renderInputField(width - 2))
+	b.WriteString("\n")
+
+	status := fmt.Sprintf("%d/%d", len(d.results), len(d.paths))
+	switch {
+	case d.index != nil:
+		status += "  Indexing..."
+	case d.status != "":
+		status += "  " + d.status
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Render(runewidth.FillRight(status, width)))
+	b.WriteString("\n")
+
+	end := d.offset + d.visibleRows()
+	for i := d.offset; i < end; i++ {
+		if i >= len(d.results) {
+			b.WriteString(strings.Repeat(" ", width))
+		} else {
+			b.WriteString(d.renderResult(d.paths[d.results[i].Index], width, i == d.cursor))
+		}
+		b.WriteString("\n")
+	}
+
+	footerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render(runewidth.FillRight("Enter:Go to file  ↑/↓ Ctrl+P/N:Select  Esc:Close", width)))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(0, 1)
+
+	return boxStyle.Render(b.String())
+}
+
+// renderResult renders one candidate with the matched characters
+// highlighted. Paths that do not fit lose their beginning, so the file name
+// stays visible.
+func (d *FuzzyFinderDialog) renderResult(path string, width int, selected bool) string {
+	runes := []rune(path)
+	matched := make(map[int]bool)
+	if _, positions, ok := fuzzy.Match(d.query, path); ok {
+		for _, pos := range positions {
+			matched[pos] = true
+		}
+	}
+
+	start := 0
+	prefix := ""
+	if runewidth.StringWidth(path) > width {
+		prefix = "…"
+		for start < len(runes) && runewidth.StringWidth(string(runes[start:])) > width-1 {
+			start++
+		}
+	}
+
+	normal := lipgloss.NewStyle()
+	if strings.HasSuffix(path, "/") {
+		normal = normal.Foreground(lipgloss.Color("39"))
+	}
+	highlight := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	if selected {
+		normal = lipgloss.NewStyle().Background(lipgloss.Color("39")).Foreground(lipgloss.Color("0"))
+		highlight = normal.Bold(true).Foreground(lipgloss.Color("52"))
+	}
+
+	var b strings.Builder
+	b.WriteString(normal.Render(prefix))
+	for i := start; i < len(runes); i++ {
+		if matched[i] {
+			b.WriteString(highlight.Render(string(runes[i])))
+		} else {
+			b.WriteString(normal.Render(string(runes[i])))
+		}
+	}
+	if pad := width - runewidth.StringWidth(prefix+string(runes[start:])); pad > 0 {
+		b.WriteString(normal.Render(strings.Repeat(" ", pad)))
+	}
+	return b.String()
+}
+
+// IsActive returns whether the dialog is open
+func (d *FuzzyFinderDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type
+func (d *FuzzyFinderDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runFuzzy runs the commands of the dialog until indexing and ranking settle
+func runFuzzy(t *testing.T, dialog *FuzzyFinderDialog, cmd tea.Cmd) {
+	t.Helper()
+	queue := []tea.Cmd{cmd}
+	for len(queue) > 0 {
+		cmd, queue = queue[0], queue[1:]
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		case fuzzyIndexMsg:
+			queue = append(queue, dialog.handleIndex(msg))
+		case fuzzyRankMsg:
+			queue = append(queue, dialog.handleRank(msg))
+		default:
+			t.Fatalf("unexpected message %T", msg)
+		}
+	}
+}
+
+// typeFuzzy types text into the dialog and waits for the ranking
+func typeFuzzy(t *testing.T, dialog *FuzzyFinderDialog, text string) {
+	t.Helper()
+	for _, r := range text {
+		_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		runFuzzy(t, dialog, cmd)
+	}
+}
+
+// fuzzyResults returns the ranked candidates
+func fuzzyResults(dialog *FuzzyFinderDialog) []string {
+	var paths []string
+	for _, r := range dialog.results {
+		paths = append(paths, dialog.paths[r.Index])
+	}
+	return paths
+}
+
+func setupFuzzyTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, path := range []string{
+		"internal/ui/model.go",
+		"internal/ui/model_test.go",
+		"internal/fs/reader.go",
+		"docs/model.md",
+		".hidden/model.txt",
+		"build/model.o",
+	} {
+		os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0755)
+		os.WriteFile(filepath.Join(root, path), nil, 0644)
+	}
+	os.WriteFile(filepath.Join(root, ".gitignore"), []byte("build/\n"), 0644)
+	return root
+}
+
+func TestFuzzyFinderDialogRanking(t *testing.T) {
+	root := setupFuzzyTree(t)
+	dialog := NewFuzzyFinderDialog(root, false)
+	if dialog.DisplayType() != DialogDisplayScreen {
+		t.Error("expected DialogDisplayScreen")
+	}
+	runFuzzy(t, dialog, dialog.start())
+
+	// Hidden and ignored entries are not indexed; directories end in "/"
+	if got, want := strings.Join(fuzzyResults(dialog), ","),
+		"docs/,internal/,internal/fs/,internal/ui/,docs/model.md,internal/ui/model.go,internal/fs/reader.go,internal/ui/model_test.go"; got != want {
+		t.Errorf("results without query = %s, want %s", got, want)
+	}
+
+	typeFuzzy(t, dialog, "model")
+	if got, want := strings.Join(fuzzyResults(dialog), ","), "docs/model.md,internal/ui/model.go,internal/ui/model_test.go"; got != want {
+		t.Errorf("results for model = %s, want %s", got, want)
+	}
+
+	typeFuzzy(t, dialog, "go")
+	if got, want := strings.Join(fuzzyResults(dialog), ","), "internal/ui/model.go,internal/ui/model_test.go"; got != want {
+		t.Errorf("results for modelgo = %s, want %s", got, want)
+	}
+	if view := dialog.View(); !strings.Contains(view, "2/8") {
+		t.Errorf("view should count the matches:\n%s", view)
+	}
+
+	// Deleting characters ranks all candidates again
+	for range "modelgo" {
+		_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		runFuzzy(t, dialog, cmd)
+	}
+	typeFuzzy(t, dialog, "rdr")
+	if got := fuzzyResults(dialog); len(got) != 1 || got[0] != "internal/fs/reader.go" {
+		t.Errorf("results for rdr = %v", got)
+	}
+}
+
+func TestFuzzyFinderDialogSelect(t *testing.T) {
+	root := setupFuzzyTree(t)
+	dialog := NewFuzzyFinderDialog(root, true)
+	runFuzzy(t, dialog, dialog.start())
+	typeFuzzy(t, dialog, "modeltxt")
+	if got := fuzzyResults(dialog); len(got) != 1 || got[0] != ".hidden/model.txt" {
+		t.Fatalf("hidden files should be indexed, got %v", got)
+	}
+
+	// The cursor stays on the results
+	dialog.Update(tea.KeyMsg{Type: tea.KeyDown})
+	dialog.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if dialog.cursor != 0 {
+		t.Errorf("cursor = %d, want 0", dialog.cursor)
+	}
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(fuzzyCloseMsg)
+	if !ok || msg.path != filepath.Join(root, ".hidden", "model.txt") {
+		t.Fatalf("Enter should close with the chosen path, got %#v", msg)
+	}
+	if dialog.IsActive() {
+		t.Error("dialog should be closed")
+	}
+}
+
+func TestFuzzyFinderDialogCancel(t *testing.T) {
+	dialog := NewFuzzyFinderDialog(setupFuzzyTree(t), false)
+	dialog.start()
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg, ok := cmd().(fuzzyCloseMsg); !ok || msg.path != "" {
+		t.Fatalf("Esc should close without a path, got %#v", msg)
+	}
+	if dialog.index != nil {
+		t.Error("Esc should stop indexing")
+	}
+
+	// Enter without results does nothing
+	dialog = NewFuzzyFinderDialog(t.TempDir(), false)
+	runFuzzy(t, dialog, dialog.start())
+	if _, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || !dialog.IsActive() {
+		t.Error("Enter without a result should keep the dialog open")
+	}
+}
@@ -0,0 +1,521 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// grepMatchLimit is the most matches one content search collects
+const grepMatchLimit = 10000
+
+// grepBatchInterval is how often results and progress are shown while searching
+const grepBatchInterval = 100 * time.Millisecond
+
+// grepField is a field of the content search form
+type grepField int
+
+const (
+	grepFieldPattern grepField = iota
+	grepFieldRegex
+	grepFieldGitIgnore
+	grepFieldMaxSize
+	grepFieldCount
+)
+
+// grepSettings are the form values, kept by the model for the next search
+type grepSettings struct {
+	pattern   string
+	regex     bool
+	gitIgnore bool
+	maxSize   string // Size as typed, e.g. "10M" (empty = no limit)
+}
+
+// defaultGrepSettings are used for the first search
+var defaultGrepSettings = grepSettings{gitIgnore: true, maxSize: "10M"}
+
+// grepSearch is a content search running in the background. Matches are sent
+// over results, which is closed when the search ends; err is set before that.
+type grepSearch struct {
+	cancel   context.CancelFunc
+	results  chan fs.GrepMatch
+	progress *fs.GrepProgress
+	err      error
+}
+
+// grepResultsMsg carries the matches found since the last message
+type grepResultsMsg struct {
+	search  *grepSearch
+	matches []fs.GrepMatch
+	done    bool
+	err     error
+}
+
+// grepCloseMsg is sent when the content search dialog is closed
+type grepCloseMsg struct {
+	settings grepSettings
+	root     string
+	jump     *fs.GrepMatch // Match whose file the pane should move to (nil = none)
+}
+
+// GrepDialog searches the contents of the files below the pane directory.
+// The form asks for a literal or regex pattern and the limits; the results
+// then stream in as file:line:match while the progress is counted, and a
+// match can be opened in the editor at its line or jumped to in the pane.
+type GrepDialog struct {
+	root      string
+	hidden    bool
+	pattern   *InputDialog
+	maxSize   *InputDialog
+	regex     bool
+	gitIgnore bool
+	field     grepField
+	errorMsg  string
+
+	showResults bool
+	search      *grepSearch
+	results     []fs.GrepMatch
+	cursor      int
+	offset      int
+	status      string // Outcome of the finished search
+
+	active bool
+	width  int
+	height int
+}
+
+// NewGrepDialog creates a content search dialog for root preset to settings.
+// hidden tells whether hidden files are searched too.
+func NewGrepDialog(root string, settings grepSettings, hidden bool) *GrepDialog {
+	newField := func(value string) *InputDialog {
+		input := NewInputDialog("", nil)
+		input.input = value
+		input.cursorPos = len([]rune(value))
+		return input
+	}
+	return &GrepDialog{
+		root:      root,
+		hidden:    hidden,
+		pattern:   newField(settings.pattern),
+		maxSize:   newField(settings.maxSize),
+		regex:     settings.regex,
+		gitIgnore: settings.gitIgnore,
+		active:    true,
+		width:     80,
+		height:    24,
+	}
+}
+
+// SetSize sets the area the dialog covers
+func (d *GrepDialog) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+	d.clampOffset()
+}
+
+// settings returns the current form values
+func (d *GrepDialog) settings() grepSettings {
+	return grepSettings{
+		pattern:   d.pattern.input,
+		regex:     d.regex,
+		gitIgnore: d.gitIgnore,
+		maxSize:   strings.TrimSpace(d.maxSize.input),
+	}
+}
+
+// options converts the form into search options. problem describes what
+// is wrong with the form, if anything.
+func (d *GrepDialog) options() (opts fs.GrepOptions, problem string) {
+	opts = fs.GrepOptions{
+		Pattern:    d.pattern.input,
+		Regex:      d.regex,
+		GitIgnore:  d.gitIgnore,
+		Hidden:     d.hidden,
+		MaxMatches: grepMatchLimit,
+	}
+	if err := fs.ValidateGrepPattern(opts); err != nil {
+		if errors.Is(err, fs.ErrEmptyQuery) {
+			return opts, "Pattern cannot be empty"
+		}
+		return opts, fmt.Sprintf("Invalid regex: %v", err)
+	}
+	if size := strings.TrimSpace(d.maxSize.input); size != "" {
+		n, err := fs.ParseByteSize(size)
+		if err != nil {
+			return opts, fmt.Sprintf("Invalid max size %q (e.g. 512k, 10M)", size)
+		}
+		opts.MaxFileSize = n
+	}
+	return opts, ""
+}
+
+// start begins a search with the form values
+func (d *GrepDialog) start() tea.Cmd {
+	opts, problem := d.options()
+	if problem != "" {
+		d.errorMsg = problem
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	search := &grepSearch{
+		cancel:   cancel,
+		results:  make(chan fs.GrepMatch, 1024),
+		progress: &fs.GrepProgress{},
+	}
+	root := d.root
+	go func() {
+		search.err = fs.Grep(ctx, root, opts, search.progress, func(match fs.GrepMatch) {
+			select {
+			case search.results <- match:
+			case <-ctx.Done():
+			}
+		})
+		close(search.results)
+	}()
+
+	d.search = search
+	d.results = nil
+	d.cursor = 0
+	d.offset = 0
+	d.status = ""
+	d.showResults = true
+	return waitGrepResults(search)
+}
+
+// waitGrepResults collects matches for a short while. It also returns
+// without matches so the progress keeps updating while nothing is found.
+func waitGrepResults(search *grepSearch) tea.Cmd {
+	return func() tea.Msg {
+		var batch []fs.GrepMatch
+		timeout := time.After(grepBatchInterval)
+		for {
+			select {
+			case match, ok := <-search.results:
+				if !ok {
+					return grepResultsMsg{search: search, matches: batch, done: true, err: search.err}
+				}
+				batch = append(batch, match)
+			case <-timeout:
+				return grepResultsMsg{search: search, matches: batch}
+			}
+		}
+	}
+}
+
+// handleResults adds the matches of the running search
+func (d *GrepDialog) handleResults(msg grepResultsMsg) tea.Cmd {
+	if !d.active || msg.search != d.search {
+		return nil
+	}
+	d.results = append(d.results, msg.matches...)
+	if !msg.done {
+		return waitGrepResults(msg.search)
+	}
+
+	d.search = nil
+	progress := msg.search.progress
+	switch {
+	case errors.Is(msg.err, fs.ErrMatchLimit):
+		d.status = fmt.Sprintf("Stopped at %d matches", len(d.results))
+	case msg.err != nil:
+		d.status = fmt.Sprintf("Search failed: %v", msg.err)
+	default:
+		d.status = fmt.Sprintf("%d matches in %d files", len(d.results), progress.Files.Load())
+	}
+	return nil
+}
+
+// stop cancels the running search, keeping the matches found so far
+func (d *GrepDialog) stop() {
+	if d.search == nil {
+		return
+	}
+	d.search.cancel()
+	d.status = fmt.Sprintf("Cancelled: %d matches", len(d.results))
+	d.search = nil
+}
+
+// close closes the dialog, moving the pane to jump if it is set
+func (d *GrepDialog) close(jump *fs.GrepMatch) tea.Cmd {
+	d.stop()
+	d.active = false
+	msg := grepCloseMsg{settings: d.settings(), root: d.root, jump: jump}
+	return func() tea.Msg { return msg }
+}
+
+// Update handles keyboard input
+func (d *GrepDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	if d.showResults {
+		return d, d.updateResults(keyMsg)
+	}
+	return d, d.updateForm(keyMsg)
+}
+
+// updateForm handles keys while the search form is shown
+func (d *GrepDialog) updateForm(msg tea.KeyMsg) tea.Cmd {
+	d.errorMsg = ""
+	switch msg.String() {
+	case "enter":
+		return d.start()
+	case "esc":
+		return d.close(nil)
+	case "tab", "down":
+		d.field = (d.field + 1) % grepFieldCount
+		return nil
+	case "shift+tab", "up":
+		d.field = (d.field + grepFieldCount - 1) % grepFieldCount
+		return nil
+	}
+
+	switch d.field {
+	case grepFieldPattern:
+		d.pattern.Update(msg)
+	case grepFieldMaxSize:
+		d.maxSize.Update(msg)
+	case grepFieldRegex:
+		if msg.String() == " " {
+			d.regex = !d.regex
+		}
+	case grepFieldGitIgnore:
+		if msg.String() == " " {
+			d.gitIgnore = !d.gitIgnore
+		}
+	}
+	return nil
+}
+
+// updateResults handles keys while the results are shown
+func (d *GrepDialog) updateResults(msg tea.KeyMsg) tea.Cmd {
+	page := d.visibleRows()
+	switch msg.String() {
+	case "j", "down":
+		d.cursor++
+	case "k", "up":
+		d.cursor--
+	case "ctrl+d", "pgdown":
+		d.cursor += page / 2
+	case "ctrl+u", "pgup":
+		d.cursor -= page / 2
+	case "g", "home":
+		d.cursor = 0
+	case "G", "end":
+		d.cursor = len(d.results) - 1
+	case "enter":
+		if match := d.selected(); match != nil {
+			return d.close(match)
+		}
+	case "e":
+		if match := d.selected(); match != nil {
+			return openWithEditor(filepath.Join(d.root, match.Path), d.root, match.Line)
+		}
+	case "/":
+		// Edit the search; the form keeps the values
+		d.stop()
+		d.showResults = false
+	case "esc":
+		if d.search != nil {
+			d.stop()
+			return nil
+		}
+		return d.close(nil)
+	case "q":
+		return d.close(nil)
+	}
+
+	if d.cursor >= len(d.results) {
+		d.cursor = len(d.results) - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+	d.clampOffset()
+	return nil
+}
+
+// selected returns the match under the cursor
+func (d *GrepDialog) selected() *fs.GrepMatch {
+	if d.cursor < 0 || d.cursor >= len(d.results) {
+		return nil
+	}
+	match := d.results[d.cursor]
+	return &match
+}
+
+// visibleRows is the number of result rows that fit between header and footer
+func (d *GrepDialog) visibleRows() int {
+	// Border (2), header, blank line and footer
+	rows := d.height - 5
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// clampOffset keeps the cursor on screen
+func (d *GrepDialog) clampOffset() {
+	rows := d.visibleRows()
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+rows {
+		d.offset = d.cursor - rows + 1
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+}
+
+// View renders the dialog
+func (d *GrepDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	innerWidth := d.width - 4 // Border and padding
+	if innerWidth < 20 {
+		innerWidth = 20
+	}
+
+	var content string
+	if d.showResults {
+		content = d.viewResults(innerWidth)
+	} else {
+		content = d.viewForm(innerWidth)
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(0, 1)
+
+	return boxStyle.Render(content)
+}
+
+// viewForm renders the search form
+func (d *GrepDialog) viewForm(width int) string {
+	var b strings.Builder
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render(runewidth.FillRight("Search contents in "+truncatePath(d.root, width-20), width)))
+	b.WriteString("\n\n")
+
+	labelStyle := func(field grepField) lipgloss.Style {
+		style := lipgloss.NewStyle().Width(16)
+		if d.field == field {
+			style = style.Bold(true).Foreground(lipgloss.Color("39"))
+		}
+		return style
+	}
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+
+	b.WriteString(labelStyle(grepFieldPattern).Render("Pattern"))
+	b.WriteString("\n")
+	b.WriteString(d.pattern.renderInputField(width - 4))
+	b.WriteString("\n")
+	b.WriteString(labelStyle(grepFieldRegex).Render(check(d.regex) + " Regex"))
+	b.WriteString("\n")
+	b.WriteString(labelStyle(grepFieldGitIgnore).Render(check(d.gitIgnore) + " .gitignore"))
+	b.WriteString("\n\n")
+	b.WriteString(labelStyle(grepFieldMaxSize).Render("Max file size"))
+	b.WriteString("\n")
+	b.WriteString(d.maxSize.renderInputField(20))
+	b.WriteString("\n")
+
+	if d.errorMsg != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(d.errorMsg))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	b.WriteString(hintStyle.Render("Lowercase patterns ignore case. Binary files are skipped."))
+	b.WriteString("\n")
+	footerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("Tab:Next field  Space:Toggle  Enter:Search  Esc:Cancel"))
+	return b.String()
+}
+
+// viewResults renders the matches and the progress
+func (d *GrepDialog) viewResults(width int) string {
+	var b strings.Builder
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	b.WriteString(headerStyle.Render(runewidth.FillRight(
+		runewidth.Truncate(fmt.Sprintf("grep %q in %s", d.pattern.input, d.root), width, "..."), width)))
+	b.WriteString("\n")
+
+	progress := d.status
+	if d.search != nil {
+		p := d.search.progress
+		progress = fmt.Sprintf("Searching... %d files, %d skipped, %d matches",
+			p.Files.Load(), p.Skipped.Load(), len(d.results))
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Render(runewidth.FillRight(progress, width)))
+	b.WriteString("\n")
+
+	pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	lineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	cursorStyle := lipgloss.NewStyle().Background(lipgloss.Color("39")).Foreground(lipgloss.Color("0"))
+
+	end := d.offset + d.visibleRows()
+	for i := d.offset; i < end; i++ {
+		if i >= len(d.results) {
+			b.WriteString(strings.Repeat(" ", width))
+			b.WriteString("\n")
+			continue
+		}
+		match := d.results[i]
+		location := fmt.Sprintf("%s:%d:", filepath.ToSlash(match.Path), match.Line)
+		text := strings.TrimSpace(strings.ReplaceAll(match.Text, "\t", "    "))
+		textWidth := width - runewidth.StringWidth(location) - 1
+		if textWidth < 0 {
+			textWidth = 0
+		}
+		text = runewidth.Truncate(text, textWidth, "…")
+		if i == d.cursor {
+			b.WriteString(cursorStyle.Render(runewidth.FillRight(location+" "+text, width)))
+		} else {
+			b.WriteString(pathStyle.Render(location) + " " + lineStyle.Render(runewidth.FillRight(text, textWidth)))
+		}
+		b.WriteString("\n")
+	}
+
+	hints := "Enter:Jump  e:Edit at line  /:New search  q:Close"
+	if d.search != nil {
+		hints = "Enter:Jump  e:Edit at line  Esc:Stop"
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(runewidth.FillRight(hints, width)))
+	return b.String()
+}
+
+// IsActive returns whether the dialog is open
+func (d *GrepDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type
+func (d *GrepDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runGrep drives the dialog until the search ends
+func runGrep(t *testing.T, dialog *GrepDialog, cmd tea.Cmd) {
+	t.Helper()
+	for cmd != nil {
+		msg, ok := cmd().(grepResultsMsg)
+		if !ok {
+			t.Fatalf("expected grepResultsMsg, got %T", msg)
+		}
+		cmd = dialog.handleResults(msg)
+	}
+}
+
+func typeText(dialog Dialog, text string) {
+	for _, r := range text {
+		dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestGrepDialogSearch(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "src"), 0755)
+	os.WriteFile(filepath.Join(root, "src", "a.go"), []byte("package a\n// TODO fix\n"), 0644)
+	os.WriteFile(filepath.Join(root, "b.txt"), []byte("nothing\n"), 0644)
+
+	dialog := NewGrepDialog(root, defaultGrepSettings, false)
+	if dialog.DisplayType() != DialogDisplayScreen {
+		t.Error("expected DialogDisplayScreen")
+	}
+	if view := dialog.View(); !strings.Contains(view, "[x] .gitignore") || !strings.Contains(view, "10M") {
+		t.Errorf("form should show the defaults:\n%s", view)
+	}
+
+	typeText(dialog, "todo")
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !dialog.showResults {
+		t.Fatal("Enter should start the search")
+	}
+	runGrep(t, dialog, cmd)
+
+	if len(dialog.results) != 1 || dialog.results[0].Line != 2 {
+		t.Fatalf("results = %+v", dialog.results)
+	}
+	view := dialog.View()
+	if !strings.Contains(view, filepath.Join("src", "a.go")+":2:") || !strings.Contains(view, "1 matches in 2 files") {
+		t.Errorf("results view:\n%s", view)
+	}
+
+	// e opens the editor and keeps the results
+	if _, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}}); cmd == nil || !dialog.IsActive() {
+		t.Error("e should open the editor and keep the dialog open")
+	}
+
+	_, cmd = dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(grepCloseMsg)
+	if !ok || msg.jump == nil || msg.jump.Path != filepath.Join("src", "a.go") {
+		t.Fatalf("Enter should close with the match to jump to, got %#v", msg)
+	}
+	if msg.settings.pattern != "todo" || !msg.settings.gitIgnore {
+		t.Errorf("settings = %+v", msg.settings)
+	}
+}
+
+func TestGrepDialogForm(t *testing.T) {
+	dialog := NewGrepDialog(t.TempDir(), defaultGrepSettings, false)
+
+	// Empty pattern
+	if _, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || !strings.Contains(dialog.View(), "Pattern cannot be empty") {
+		t.Error("an empty pattern should show an error")
+	}
+
+	// Tab to the regex checkbox, toggle it, and enter an invalid regex
+	typeText(dialog, "(")
+	dialog.Update(tea.KeyMsg{Type: tea.KeyTab})
+	dialog.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if !dialog.regex {
+		t.Fatal("Space should toggle the regex checkbox")
+	}
+	if _, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || !strings.Contains(dialog.View(), "Invalid regex") {
+		t.Error("an invalid regex should show an error")
+	}
+
+	// Invalid size
+	dialog.regex = false
+	dialog.field = grepFieldMaxSize
+	typeText(dialog, "x")
+	if _, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || !strings.Contains(dialog.View(), "Invalid max size") {
+		t.Error("an invalid size should show an error")
+	}
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg, ok := cmd().(grepCloseMsg); !ok || msg.jump != nil {
+		t.Errorf("Esc should close without a jump, got %#v", msg)
+	}
+}
+
+func TestGrepDialogStop(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("hit\n"), 0644)
+	dialog := NewGrepDialog(root, grepSettings{pattern: "hit"}, false)
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if dialog.search != nil || !dialog.IsActive() || !strings.HasPrefix(dialog.status, "Cancelled") {
+		t.Errorf("first Esc should stop the search, status %q", dialog.status)
+	}
+	// Results of the stopped search are dropped
+	if next := dialog.handleResults(cmd().(grepResultsMsg)); next != nil || len(dialog.results) != 0 {
+		t.Error("results of a stopped search should be ignored")
+	}
+
+	// / returns to the form with the pattern kept
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if dialog.showResults || dialog.pattern.input != "hit" {
+		t.Error("/ should return to the form")
+	}
+}
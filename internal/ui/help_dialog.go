@@ -169,9 +169,9 @@ func (d *HelpDialog) buildContent() []string {
 	lines = append(lines, "  Q              : quit")
 	lines = append(lines, "")
 	lines = append(lines, "File Operations")
-	lines = append(lines, "  C              : copy to opposite pane")
-	lines = append(lines, "  M              : move to opposite pane")
-	lines = append(lines, "  D              : delete (with confirmation)")
+	lines = append(lines, "  C              : copy (destination defaults to opposite pane)")
+	lines = append(lines, "  M              : move (destination defaults to opposite pane)")
+	lines = append(lines, "  D              : move to trash (with confirmation)")
 	lines = append(lines, "  R              : rename file/directory")
 	lines = append(lines, "  N              : create new file")
 	lines = append(lines, "  Shift+N        : create new directory")
@@ -185,19 +185,55 @@ func (d *HelpDialog) buildContent() []string {
 	lines = append(lines, "  S              : sort settings")
 	lines = append(lines, "  /              : incremental search")
 	lines = append(lines, "  Ctrl+F         : regex search")
+	lines = append(lines, "  F              : find recursively (results shown in the pane, Esc stops)")
+	lines = append(lines, "  G              : search file contents (e opens the editor at the line)")
+	lines = append(lines, "  Ctrl+P         : fuzzy find a file below the current directory")
 	lines = append(lines, "")
 	lines = append(lines, "External Apps")
 	lines = append(lines, "  V              : view file with pager")
 	lines = append(lines, "  E              : edit file with editor")
 	lines = append(lines, "")
-	lines = append(lines, "Bookmarks")
+	lines = append(lines, "Bookmarks & Jump")
 	lines = append(lines, "  B              : open bookmark manager")
 	lines = append(lines, "  Shift+B        : add current directory to bookmarks")
+	lines = append(lines, "  Z              : jump to a visited directory by keywords (frecency)")
+	lines = append(lines, "  Shift+H        : directory history of this pane and recent directories")
+	lines = append(lines, "")
+	lines = append(lines, "Tabs & Sessions")
+	lines = append(lines, "  Ctrl+T         : open the directory under the cursor in a new tab")
+	lines = append(lines, "  Alt+T          : duplicate the current tab")
+	lines = append(lines, "  Ctrl+W         : close the current tab")
+	lines = append(lines, "  Tab / Alt+L    : next tab")
+	lines = append(lines, "  Alt+H          : previous tab")
+	lines = append(lines, "  Shift+W        : named sessions (load/save/delete)")
+	lines = append(lines, "")
+	lines = append(lines, "Trash")
+	lines = append(lines, "  T              : open trash browser (restore/empty)")
+	lines = append(lines, "  Shift+D        : delete permanently (with confirmation)")
+	lines = append(lines, "")
+	lines = append(lines, "Copy Options")
+	lines = append(lines, "  Shift+P        : toggle preserving permissions/times/xattrs")
+	lines = append(lines, "  Shift+V        : cycle checksum verify (off/sha256/xxhash)")
+	lines = append(lines, "  Shift+F        : include/exclude filter for copy/move/delete")
+	lines = append(lines, "")
+	lines = append(lines, "Sync & Compare")
+	lines = append(lines, "  Shift+S        : sync both panes (one-way/two-way, dry-run preview)")
+	lines = append(lines, "  Shift+C        : compare both panes and mark the differences")
+	lines = append(lines, "  Shift+X        : side-by-side diff of the files under both cursors")
+	lines = append(lines, "")
+	lines = append(lines, "Undo")
+	lines = append(lines, "  U              : undo last rename/move/copy/create/trash")
+	lines = append(lines, "  Shift+U        : redo")
+	lines = append(lines, "")
+	lines = append(lines, "Jobs")
+	lines = append(lines, "  Shift+J        : show background jobs")
+	lines = append(lines, "  b (in progress): run operation in background")
 	lines = append(lines, "")
 	lines = append(lines, "Other")
 	lines = append(lines, "  F5 / Ctrl+R    : refresh view")
 	lines = append(lines, "  =              : sync opposite pane")
 	lines = append(lines, "  ?              : show this help")
+	lines = append(lines, "  Ctrl+O         : choose and exit (--choose-files/--choose-dir)")
 	lines = append(lines, "")
 	lines = append(lines, "")
 
@@ -0,0 +1,315 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/frecency"
+)
+
+// historyVisibleRows is the number of directories the history dialog shows at once
+const historyVisibleRows = 12
+
+// historyList is a list of the history dialog
+type historyList int
+
+const (
+	historyListPane   historyList = iota // Back/forward stack of the active pane
+	historyListRecent                    // Recently visited directories of both panes
+)
+
+// historyItem is one row of the history dialog
+type historyItem struct {
+	path  string
+	index int       // Position in the pane history (historyListPane only)
+	time  time.Time // Last visit (historyListRecent only)
+}
+
+// historySelectMsg is sent when a directory is chosen in the history dialog.
+// index is the position in the pane history, or -1 for a recent directory.
+type historySelectMsg struct {
+	path  string
+	index int
+}
+
+// HistoryDialog lists the back/forward history of the active pane and the
+// directories recently visited in either pane. Typing filters the list, and
+// choosing an entry jumps there directly instead of one step at a time.
+type HistoryDialog struct {
+	paneHistory []string // Pane history, oldest first
+	current     int      // Current position in paneHistory
+	recent      []frecency.Entry
+	list        historyList
+	input       *InputDialog
+	items       []historyItem // Rows of the current list that match the filter
+	cursor      int
+	offset      int
+	active      bool
+	width       int
+}
+
+// NewHistoryDialog creates a history dialog for the history of the active
+// pane (paths oldest first, current the current position) and the recently
+// visited directories.
+func NewHistoryDialog(paths []string, current int, recent []frecency.Entry) *HistoryDialog {
+	d := &HistoryDialog{
+		paneHistory: paths,
+		current:     current,
+		recent:      recent,
+		input:       NewInputDialog("", nil),
+		active:      true,
+		width:       60,
+	}
+	d.refresh()
+	return d
+}
+
+// refresh rebuilds the rows of the current list for the filter. Each word of
+// the filter must appear in the path (ignoring case unless it has upper case
+// letters). The cursor starts on the current directory of the pane history.
+func (d *HistoryDialog) refresh() {
+	words := strings.Fields(d.input.input)
+	d.items = nil
+	d.cursor = 0
+	d.offset = 0
+
+	switch d.list {
+	case historyListPane:
+		// Newest first, like the recent list
+		for i := len(d.paneHistory) - 1; i >= 0; i-- {
+			if matchHistoryFilter(d.paneHistory[i], words) {
+				if i == d.current {
+					d.cursor = len(d.items)
+				}
+				d.items = append(d.items, historyItem{path: d.paneHistory[i], index: i})
+			}
+		}
+	case historyListRecent:
+		for _, entry := range d.recent {
+			if matchHistoryFilter(entry.Path, words) {
+				d.items = append(d.items, historyItem{path: entry.Path, index: -1, time: entry.LastAccess})
+			}
+		}
+	}
+	d.clampOffset()
+}
+
+// matchHistoryFilter reports whether path contains every word, with smart case
+func matchHistoryFilter(path string, words []string) bool {
+	for _, word := range words {
+		target := path
+		if !isSmartCaseSensitive(word) {
+			target = strings.ToLower(path)
+		}
+		if !strings.Contains(target, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// Update handles keyboard input
+func (d *HistoryDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch keyMsg.String() {
+	case "enter":
+		if len(d.items) == 0 {
+			return d, nil
+		}
+		item := d.items[d.cursor]
+		d.active = false
+		return d, func() tea.Msg {
+			return historySelectMsg{path: item.path, index: item.index}
+		}
+	case "esc":
+		d.active = false
+		return d, func() tea.Msg {
+			return dialogResultMsg{result: DialogResult{Cancelled: true}}
+		}
+	case "tab", "shift+tab":
+		if d.list == historyListPane {
+			d.list = historyListRecent
+		} else {
+			d.list = historyListPane
+		}
+		d.refresh()
+		return d, nil
+	case "down", "ctrl+n":
+		d.moveCursor(1)
+		return d, nil
+	case "up", "ctrl+p":
+		d.moveCursor(-1)
+		return d, nil
+	case "pgdown":
+		d.moveCursor(historyVisibleRows)
+		return d, nil
+	case "pgup":
+		d.moveCursor(-historyVisibleRows)
+		return d, nil
+	}
+
+	before := d.input.input
+	d.input.Update(keyMsg)
+	if d.input.input != before {
+		d.refresh()
+	}
+	return d, nil
+}
+
+// moveCursor moves the cursor by delta rows, stopping at either end
+func (d *HistoryDialog) moveCursor(delta int) {
+	d.cursor += delta
+	if d.cursor >= len(d.items) {
+		d.cursor = len(d.items) - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+	d.clampOffset()
+}
+
+// clampOffset keeps the cursor on screen
+func (d *HistoryDialog) clampOffset() {
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+historyVisibleRows {
+		d.offset = d.cursor - historyVisibleRows + 1
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+}
+
+// View renders the dialog
+func (d *HistoryDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render("Directory History"))
+	b.WriteString("\n")
+
+	// Tabs: the current list is highlighted
+	tabStyle := lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("245"))
+	activeTabStyle := lipgloss.NewStyle().Padding(0, 1).Bold(true).
+		Background(lipgloss.Color("39")).Foreground(lipgloss.Color("0"))
+	tabs := []string{"This pane", "Recent"}
+	b.WriteString(" ")
+	for i, tab := range tabs {
+		if historyList(i) == d.list {
+			b.WriteString(activeTabStyle.Render(tab))
+		} else {
+			b.WriteString(tabStyle.Render(tab))
+		}
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(d.input.renderInputField(width - 8))
+	b.WriteString("\n\n")
+
+	rowWidth := width - 6
+	if len(d.items) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Width(rowWidth).
+			Padding(0, 1).
+			Foreground(lipgloss.Color("240"))
+		b.WriteString(emptyStyle.Render("No directories"))
+		b.WriteString("\n")
+	}
+
+	now := time.Now()
+	end := d.offset + historyVisibleRows
+	if end > len(d.items) {
+		end = len(d.items)
+	}
+	for i := d.offset; i < end; i++ {
+		item := d.items[i]
+		label := fmt.Sprintf("%10s", d.itemLabel(item, now))
+		pathWidth := rowWidth - 2 - runewidth.StringWidth(label) - 1
+		line := runewidth.FillRight(truncatePath(item.path, pathWidth), pathWidth) + " " + label
+
+		rowStyle := lipgloss.NewStyle().Width(rowWidth).Padding(0, 1)
+		if i == d.cursor {
+			rowStyle = rowStyle.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0"))
+		} else if d.list == historyListPane && item.index == d.current {
+			rowStyle = rowStyle.Bold(true)
+		}
+		b.WriteString(rowStyle.Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render(fmt.Sprintf("%d/%d  Tab:Switch list  Enter:Go  Esc:Close", len(d.items), d.listSize())))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// itemLabel returns the right-hand label of a row: the number of steps from
+// the current position for the pane history, or the time of the last visit
+func (d *HistoryDialog) itemLabel(item historyItem, now time.Time) string {
+	if d.list == historyListPane {
+		switch steps := item.index - d.current; {
+		case steps == 0:
+			return "current"
+		case steps > 0:
+			return fmt.Sprintf("+%d", steps)
+		default:
+			return fmt.Sprintf("%d", steps)
+		}
+	}
+	if item.time.Year() == now.Year() && item.time.YearDay() == now.YearDay() {
+		return item.time.Format("15:04")
+	}
+	return item.time.Format("2006-01-02")
+}
+
+// listSize returns the number of directories in the current list
+func (d *HistoryDialog) listSize() int {
+	if d.list == historyListPane {
+		return len(d.paneHistory)
+	}
+	return len(d.recent)
+}
+
+// IsActive returns whether the dialog is open
+func (d *HistoryDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type
+func (d *HistoryDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayPane
+}
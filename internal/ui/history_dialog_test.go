@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/frecency"
+)
+
+func historyPaths(d *HistoryDialog) []string {
+	var paths []string
+	for _, item := range d.items {
+		paths = append(paths, item.path)
+	}
+	return paths
+}
+
+func TestHistoryDialogPaneHistory(t *testing.T) {
+	paths := []string{"/home/me", "/home/me/src", "/home/me/src/app", "/tmp", "/home/me/Docs"}
+	d := NewHistoryDialog(paths, 3, nil)
+	if d.DisplayType() != DialogDisplayPane {
+		t.Error("expected DialogDisplayPane")
+	}
+
+	// Newest first, with the cursor on the current position
+	if got := historyPaths(d); len(got) != 5 || got[0] != "/home/me/Docs" || got[4] != "/home/me" {
+		t.Fatalf("items = %v", got)
+	}
+	if d.cursor != 1 || d.items[d.cursor].path != "/tmp" {
+		t.Errorf("cursor = %d, want it on the current directory", d.cursor)
+	}
+
+	// Filter words must all match; lower case ignores case
+	typeText(d, "me docs")
+	if got := historyPaths(d); len(got) != 1 || got[0] != "/home/me/Docs" {
+		t.Errorf("items for 'me docs' = %v", got)
+	}
+	for range "me docs" {
+		d.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	typeText(d, "src")
+	if got := historyPaths(d); len(got) != 2 {
+		t.Fatalf("items for 'src' = %v", got)
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(historySelectMsg)
+	if !ok || msg.path != "/home/me/src" || msg.index != 1 {
+		t.Errorf("Enter should choose /home/me/src at index 1, got %#v", msg)
+	}
+}
+
+func TestHistoryDialogRecentAndScrolling(t *testing.T) {
+	now := time.Now()
+	var recent []frecency.Entry
+	for i := 0; i < 30; i++ {
+		recent = append(recent, frecency.Entry{Path: "/dir/" + string(rune('a'+i%26)) + "x", Rank: 1, LastAccess: now.Add(-time.Duration(i) * time.Minute)})
+	}
+	d := NewHistoryDialog([]string{"/only"}, 0, recent)
+
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if d.list != historyListRecent || len(d.items) != 30 {
+		t.Fatalf("Tab should show the 30 recent directories, got %d", len(d.items))
+	}
+	for i := 0; i < 20; i++ {
+		d.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if d.cursor != 20 || d.offset != 20-historyVisibleRows+1 {
+		t.Errorf("cursor = %d, offset = %d", d.cursor, d.offset)
+	}
+	d.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if d.cursor != 29 {
+		t.Errorf("PgDown should stop at the last row, cursor = %d", d.cursor)
+	}
+
+	_, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(historySelectMsg); !ok || msg.index != -1 || msg.path != recent[29].Path {
+		t.Errorf("Enter on a recent directory = %#v", msg)
+	}
+
+	d = NewHistoryDialog(nil, -1, nil)
+	if _, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("Enter on an empty list should do nothing")
+	}
+	_, cmd = d.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg, ok := cmd().(dialogResultMsg); !ok || !msg.result.Cancelled {
+		t.Errorf("Esc should cancel, got %#v", msg)
+	}
+}
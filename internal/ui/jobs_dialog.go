@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/jobs"
+)
+
+// JobsDialog is a panel listing background jobs with pause/resume/cancel controls.
+// It reads the job list from the manager on every render so progress stays live.
+type JobsDialog struct {
+	manager *jobs.Manager
+	cursor  int
+	active  bool
+	width   int
+	message string // Feedback for the last action (e.g. job cannot be paused)
+}
+
+// jobsDialogCloseMsg is sent when the jobs panel is closed.
+type jobsDialogCloseMsg struct{}
+
+// NewJobsDialog creates a new jobs panel.
+func NewJobsDialog(manager *jobs.Manager) *JobsDialog {
+	return &JobsDialog{
+		manager: manager,
+		active:  true,
+		width:   76,
+	}
+}
+
+// Update handles keyboard input.
+func (d *JobsDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	list := d.manager.Jobs()
+	d.clampCursor(len(list))
+	d.message = ""
+
+	switch keyMsg.String() {
+	case "j", "down":
+		if len(list) > 0 {
+			d.cursor = (d.cursor + 1) % len(list)
+		}
+	case "k", "up":
+		if len(list) > 0 {
+			d.cursor = (d.cursor - 1 + len(list)) % len(list)
+		}
+	case "p", " ":
+		if len(list) > 0 {
+			d.togglePause(list[d.cursor])
+		}
+	case "x":
+		if len(list) > 0 && !list[d.cursor].State.IsFinished() {
+			d.manager.Cancel(list[d.cursor].ID)
+		}
+	case "c":
+		d.manager.ClearFinished()
+		d.clampCursor(len(d.manager.Jobs()))
+	case "esc", "q", "J":
+		d.active = false
+		return d, func() tea.Msg {
+			return jobsDialogCloseMsg{}
+		}
+	}
+
+	return d, nil
+}
+
+// togglePause pauses a running job or resumes a paused one.
+func (d *JobsDialog) togglePause(job jobs.Snapshot) {
+	if job.State.IsFinished() {
+		return
+	}
+	if job.State == jobs.StatePaused {
+		d.manager.Resume(job.ID)
+		return
+	}
+	if err := d.manager.Pause(job.ID); errors.Is(err, jobs.ErrNotPausable) {
+		d.message = fmt.Sprintf("%s jobs cannot be paused", job.Kind)
+	}
+}
+
+func (d *JobsDialog) clampCursor(count int) {
+	if d.cursor >= count {
+		d.cursor = count - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+}
+
+// View renders the dialog.
+func (d *JobsDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+	innerWidth := width - 6
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render("Jobs"))
+	b.WriteString("\n\n")
+
+	list := d.manager.Jobs()
+	d.clampCursor(len(list))
+
+	if len(list) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Width(width-4).
+			Padding(0, 1).
+			Foreground(lipgloss.Color("240"))
+		b.WriteString(emptyStyle.Render("No jobs"))
+		b.WriteString("\n")
+	}
+
+	errorStyle := lipgloss.NewStyle().
+		Width(innerWidth).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("196"))
+
+	for i, job := range list {
+		lineStyle := lipgloss.NewStyle().
+			Width(innerWidth).
+			Padding(0, 1)
+		detailStyle := lineStyle.Foreground(lipgloss.Color("245"))
+		if i == d.cursor {
+			lineStyle = lineStyle.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0")).
+				Bold(true)
+			detailStyle = detailStyle.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0"))
+		}
+
+		// Line 1: state and title
+		header := fmt.Sprintf("[%s] %s", job.State, job.Title)
+		b.WriteString(lineStyle.Render(runewidth.Truncate(header, innerWidth-2, "...")))
+		b.WriteString("\n")
+
+		// Line 2: progress
+		b.WriteString(detailStyle.Render(formatJobProgress(job)))
+		b.WriteString("\n")
+
+		// Errors
+		if job.Err != nil && job.State == jobs.StateFailed {
+			b.WriteString(errorStyle.Render(runewidth.Truncate(job.Err.Error(), innerWidth-2, "...")))
+			b.WriteString("\n")
+		}
+		for j, e := range job.Errors {
+			if j == 3 {
+				b.WriteString(errorStyle.Render(fmt.Sprintf("... and %d more errors", len(job.Errors)-3)))
+				b.WriteString("\n")
+				break
+			}
+			b.WriteString(errorStyle.Render(runewidth.Truncate(e, innerWidth-2, "...")))
+			b.WriteString("\n")
+		}
+
+		if i < len(list)-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+
+	if d.message != "" {
+		messageStyle := lipgloss.NewStyle().
+			Width(width-4).
+			Padding(0, 1).
+			Foreground(lipgloss.Color("214"))
+		b.WriteString(messageStyle.Render(d.message))
+		b.WriteString("\n")
+	}
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("j/k:Move  p:Pause/Resume  x:Cancel  c:Clear finished  Esc:Close"))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// formatJobProgress renders the progress line of a job.
+func formatJobProgress(job jobs.Snapshot) string {
+	p := job.Progress
+	bar := strings.Repeat("█", p.Percentage()/5) + strings.Repeat("░", 20-p.Percentage()/5)
+	line := fmt.Sprintf("%s %3d%%  %d/%d files", bar, p.Percentage(), p.DoneFiles, p.TotalFiles)
+	if p.TotalBytes > 0 {
+		line += fmt.Sprintf("  %s/%s", FormatSize(p.DoneBytes), FormatSize(p.TotalBytes))
+	}
+	if job.State == jobs.StateRunning && p.DoneBytes > 0 {
+		line += fmt.Sprintf("  %s/s", FormatSize(int64(p.BytesPerSecond())))
+	}
+	return line
+}
+
+// IsActive returns whether the dialog is active.
+func (d *JobsDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type.
+func (d *JobsDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/jobs"
+)
+
+// submitBlockingJob submits a job that runs until release is closed
+func submitBlockingJob(manager *jobs.Manager, opts jobs.Options, release <-chan struct{}) *jobs.Job {
+	started := make(chan struct{})
+	job := manager.Submit(opts, func(ctx context.Context, job *jobs.Job) error {
+		close(started)
+		for {
+			if err := job.WaitIfPaused(ctx); err != nil {
+				return err
+			}
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Millisecond):
+			}
+		}
+	})
+	<-started
+	return job
+}
+
+func TestNewJobsDialog(t *testing.T) {
+	dialog := NewJobsDialog(jobs.NewManager(1))
+
+	if !dialog.IsActive() {
+		t.Error("expected dialog to be active")
+	}
+	if dialog.DisplayType() != DialogDisplayScreen {
+		t.Error("expected DialogDisplayScreen")
+	}
+	if !strings.Contains(dialog.View(), "No jobs") {
+		t.Error("empty dialog should show 'No jobs'")
+	}
+}
+
+func TestJobsDialogView(t *testing.T) {
+	manager := jobs.NewManager(1)
+	release := make(chan struct{})
+	defer close(release)
+	submitBlockingJob(manager, jobs.Options{Kind: "copy", Title: "Copy big.iso -> /backup", Pausable: true}, release)
+
+	view := NewJobsDialog(manager).View()
+	for _, want := range []string{"Jobs", "[running]", "Copy big.iso -> /backup", "Esc:Close"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q", want)
+		}
+	}
+}
+
+func TestJobsDialogNavigation(t *testing.T) {
+	manager := jobs.NewManager(2)
+	release := make(chan struct{})
+	defer close(release)
+	submitBlockingJob(manager, jobs.Options{Kind: "copy"}, release)
+	submitBlockingJob(manager, jobs.Options{Kind: "move"}, release)
+
+	dialog := NewJobsDialog(manager)
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if dialog.cursor != 1 {
+		t.Errorf("expected cursor at 1, got %d", dialog.cursor)
+	}
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if dialog.cursor != 0 {
+		t.Errorf("expected cursor to wrap to 0, got %d", dialog.cursor)
+	}
+	dialog.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if dialog.cursor != 1 {
+		t.Errorf("expected cursor to wrap to 1, got %d", dialog.cursor)
+	}
+}
+
+func TestJobsDialogPauseResume(t *testing.T) {
+	manager := jobs.NewManager(1)
+	release := make(chan struct{})
+	defer close(release)
+	job := submitBlockingJob(manager, jobs.Options{Kind: "copy", Pausable: true}, release)
+
+	dialog := NewJobsDialog(manager)
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if job.State() != jobs.StatePaused {
+		t.Errorf("State() = %v, want %v", job.State(), jobs.StatePaused)
+	}
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if job.State() != jobs.StateRunning {
+		t.Errorf("State() = %v, want %v", job.State(), jobs.StateRunning)
+	}
+}
+
+func TestJobsDialogPauseNotPausable(t *testing.T) {
+	manager := jobs.NewManager(1)
+	release := make(chan struct{})
+	defer close(release)
+	submitBlockingJob(manager, jobs.Options{Kind: "compress"}, release)
+
+	dialog := NewJobsDialog(manager)
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if !strings.Contains(dialog.View(), "compress jobs cannot be paused") {
+		t.Error("View() should explain that the job cannot be paused")
+	}
+}
+
+func TestJobsDialogCancelAndClear(t *testing.T) {
+	manager := jobs.NewManager(1)
+	job := submitBlockingJob(manager, jobs.Options{Kind: "copy"}, nil)
+
+	dialog := NewJobsDialog(manager)
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	job.Wait()
+	if job.State() != jobs.StateCancelled {
+		t.Errorf("State() = %v, want %v", job.State(), jobs.StateCancelled)
+	}
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if len(manager.Jobs()) != 0 {
+		t.Errorf("expected finished jobs to be cleared, got %d", len(manager.Jobs()))
+	}
+}
+
+func TestJobsDialogClose(t *testing.T) {
+	dialog := NewJobsDialog(jobs.NewManager(1))
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if dialog.IsActive() {
+		t.Error("expected dialog to be inactive after Esc")
+	}
+	if cmd == nil {
+		t.Fatal("expected close command")
+	}
+	if _, ok := cmd().(jobsDialogCloseMsg); !ok {
+		t.Error("expected jobsDialogCloseMsg")
+	}
+}
@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/frecency"
+)
+
+// jumpMaxResults is the number of directories the jump prompt lists
+const jumpMaxResults = 10
+
+// jumpSelectMsg is sent when a directory is chosen in the jump prompt
+type jumpSelectMsg struct {
+	path string
+}
+
+// JumpDialog jumps to a previously visited directory, like zoxide. The
+// keywords typed are matched against the path components and the matches
+// are ranked by frecency; Enter goes to the best one right away.
+type JumpDialog struct {
+	visits  *frecency.DB
+	current string // Directory of the pane, left out of the results
+	input   *InputDialog
+	results []frecency.Entry
+	cursor  int
+	active  bool
+	width   int
+}
+
+// NewJumpDialog creates a jump prompt over the visited directories.
+// current is the directory of the pane.
+func NewJumpDialog(visits *frecency.DB, current string) *JumpDialog {
+	d := &JumpDialog{
+		visits:  visits,
+		current: current,
+		input:   NewInputDialog("", nil),
+		active:  true,
+		width:   60,
+	}
+	d.refresh()
+	return d
+}
+
+// refresh ranks the directories for the typed keywords. Directories that no
+// longer exist are forgotten on the way.
+func (d *JumpDialog) refresh() {
+	d.results = nil
+	d.cursor = 0
+	for _, entry := range d.visits.Query(strings.Fields(d.input.input)) {
+		if entry.Path == d.current {
+			continue
+		}
+		if info, err := os.Stat(entry.Path); err != nil || !info.IsDir() {
+			d.visits.Remove(entry.Path)
+			continue
+		}
+		d.results = append(d.results, entry)
+		if len(d.results) == jumpMaxResults {
+			break
+		}
+	}
+}
+
+// Update handles keyboard input
+func (d *JumpDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch keyMsg.String() {
+	case "enter":
+		if len(d.results) == 0 {
+			return d, nil
+		}
+		path := d.results[d.cursor].Path
+		d.active = false
+		return d, func() tea.Msg {
+			return jumpSelectMsg{path: path}
+		}
+	case "esc":
+		d.active = false
+		return d, func() tea.Msg {
+			return dialogResultMsg{result: DialogResult{Cancelled: true}}
+		}
+	case "down", "ctrl+n":
+		if d.cursor < len(d.results)-1 {
+			d.cursor++
+		}
+		return d, nil
+	case "up", "ctrl+p":
+		if d.cursor > 0 {
+			d.cursor--
+		}
+		return d, nil
+	}
+
+	before := d.input.input
+	d.input.Update(keyMsg)
+	if d.input.input != before {
+		d.refresh()
+	}
+	return d, nil
+}
+
+// View renders the dialog
+func (d *JumpDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render("Jump to directory"))
+	b.WriteString("\n\n")
+
+	b.WriteString(d.input.renderInputField(width - 8))
+	b.WriteString("\n\n")
+
+	rowWidth := width - 6
+	if len(d.results) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Width(rowWidth).
+			Padding(0, 1).
+			Foreground(lipgloss.Color("240"))
+		b.WriteString(emptyStyle.Render("No visited directory matches"))
+		b.WriteString("\n")
+	}
+	now := time.Now()
+	for i, entry := range d.results {
+		score := fmt.Sprintf("%6.1f", entry.Score(now))
+		pathWidth := rowWidth - 2 - runewidth.StringWidth(score) - 1
+		line := runewidth.FillRight(truncatePath(entry.Path, pathWidth), pathWidth) + " " + score
+
+		rowStyle := lipgloss.NewStyle().Width(rowWidth).Padding(0, 1)
+		if i == d.cursor {
+			rowStyle = rowStyle.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0"))
+		}
+		b.WriteString(rowStyle.Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	hintStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("245"))
+	b.WriteString(hintStyle.Render("Keywords match path parts in order"))
+	b.WriteString("\n")
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("↑/↓:Select  Enter:Jump  Esc:Cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// IsActive returns whether the dialog is open
+func (d *JumpDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type
+func (d *JumpDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayPane
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/frecency"
+)
+
+func jumpResults(d *JumpDialog) []string {
+	var paths []string
+	for _, e := range d.results {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func TestJumpDialogRanksAndFilters(t *testing.T) {
+	root := t.TempDir()
+	shopAPI := filepath.Join(root, "projects", "shop", "api")
+	oldAPI := filepath.Join(root, "projects", "old", "api")
+	docs := filepath.Join(root, "docs")
+	for _, dir := range []string{shopAPI, oldAPI, docs} {
+		os.MkdirAll(dir, 0755)
+	}
+	gone := filepath.Join(root, "projects", "gone", "api")
+
+	db := frecency.NewDB()
+	db.Visit(oldAPI)
+	for i := 0; i < 3; i++ {
+		db.Visit(shopAPI)
+		db.Visit(gone)
+	}
+	db.Visit(docs)
+
+	d := NewJumpDialog(db, docs)
+	if d.DisplayType() != DialogDisplayPane {
+		t.Error("expected DialogDisplayPane")
+	}
+	// The pane directory is left out and missing directories are forgotten
+	if got := jumpResults(d); len(got) != 2 || got[0] != shopAPI || got[1] != oldAPI {
+		t.Fatalf("results = %v, want [%s %s]", got, shopAPI, oldAPI)
+	}
+	if len(db.Query([]string{"gone"})) != 0 {
+		t.Error("a missing directory should be removed from the database")
+	}
+
+	typeText(d, "old api")
+	if got := jumpResults(d); len(got) != 1 || got[0] != oldAPI {
+		t.Fatalf("results for 'old api' = %v", got)
+	}
+	typeText(d, "x")
+	if len(d.results) != 0 {
+		t.Fatalf("results for 'old apix' = %v", jumpResults(d))
+	}
+	if _, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || !d.IsActive() {
+		t.Error("Enter without a match should do nothing")
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	_, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(jumpSelectMsg); !ok || msg.path != oldAPI {
+		t.Errorf("Enter should jump to %s, got %#v", oldAPI, msg)
+	}
+}
+
+func TestJumpDialogCursorAndCancel(t *testing.T) {
+	root := t.TempDir()
+	a, b := filepath.Join(root, "a"), filepath.Join(root, "b")
+	os.MkdirAll(a, 0755)
+	os.MkdirAll(b, 0755)
+	db := frecency.NewDB()
+	db.Visit(a)
+	db.Visit(a)
+	db.Visit(b)
+
+	d := NewJumpDialog(db, root)
+	d.Update(tea.KeyMsg{Type: tea.KeyDown})
+	d.Update(tea.KeyMsg{Type: tea.KeyDown}) // stays on the last result
+	_, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(jumpSelectMsg); !ok || msg.path != b {
+		t.Errorf("Enter should jump to the selected %s, got %#v", b, msg)
+	}
+
+	d = NewJumpDialog(db, root)
+	_, cmd = d.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg, ok := cmd().(dialogResultMsg); !ok || !msg.result.Cancelled || d.IsActive() {
+		t.Errorf("Esc should cancel the dialog, got %#v", msg)
+	}
+}
@@ -20,6 +20,7 @@ const (
 	KeyToggleHidden = "ctrl+h" // 隠しファイル表示トグル
 	KeyHome         = "~"      // ホームディレクトリへ移動
 	KeyPrevDir      = "-"      // 直前のディレクトリへ移動
+	KeyJump         = "z"      // 訪問したディレクトリへジャンプ
 
 	// カーソルキー（hjklの代替）
 	KeyArrowDown  = "down"
@@ -30,6 +31,9 @@ const (
 	// 検索機能
 	KeySearch      = "/"      // インクリメンタル検索
 	KeyRegexSearch = "ctrl+f" // 正規表現検索
+	KeyFindFiles   = "f"      // サブディレクトリを含めた再帰検索
+	KeyGrep        = "g"      // ファイルの内容を検索
+	KeyFuzzyFind   = "ctrl+p" // サブディレクトリのファイルをあいまい検索
 
 	// ファイル操作
 	KeyView = "v" // ファイルをビューアー(less)で開く
@@ -57,4 +61,38 @@ const (
 	// Bookmarks
 	KeyBookmark    = "b" // Open bookmark manager
 	KeyAddBookmark = "B" // Add current directory to bookmarks (Shift+b)
+
+	// Jobs
+	KeyJobs = "J" // Open background jobs panel (Shift+j)
+
+	// Trash
+	KeyDeletePermanently = "D" // Delete permanently without using the trash (Shift+d)
+	KeyTrash             = "t" // Open trash browser
+
+	// Undo
+	KeyUndo = "u" // Undo the last file operation
+	KeyRedo = "U" // Redo the last undone operation (Shift+u)
+
+	// Copy options
+	KeyTogglePreserve  = "P" // Toggle metadata preservation for copy/move (Shift+p)
+	KeyToggleVerify    = "V" // Cycle checksum verification for copy/move (Shift+v)
+	KeyOperationFilter = "F" // Choose the include/exclude filter for copy/move/delete (Shift+f)
+
+	// Directory sync
+	KeySyncDirectories    = "S" // Sync the directories of both panes (Shift+s)
+	KeyCompareDirectories = "C" // Compare both panes and mark the differences (Shift+c)
+	KeyDiffFiles          = "X" // Diff the files under both cursors side by side (Shift+x)
+
+	// Tabs
+	KeyNewTab       = "ctrl+t" // Open a tab on the directory under the cursor
+	KeyDuplicateTab = "alt+t"  // Duplicate the current tab
+	KeyCloseTab     = "ctrl+w" // Close the current tab
+	KeyNextTab      = "tab"    // Switch to the next tab
+	KeyPrevTab      = "alt+h"  // Switch to the previous tab
+
+	// Sessions
+	KeySessions = "W" // Open the named sessions (Shift+w)
+
+	// Chooser mode
+	KeyChoose = "ctrl+o" // Choose the marked files or the current directory and exit
 )
@@ -5,6 +5,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sakura/duofm/internal/fs"
+	"github.com/sakura/duofm/internal/undo"
 )
 
 // diskSpaceUpdateMsg はディスク容量の定期更新を通知
@@ -61,10 +62,11 @@ func ctrlCTimeoutCmd(duration time.Duration) tea.Cmd {
 
 // inputDialogResultMsg は入力ダイアログの結果を通知
 type inputDialogResultMsg struct {
-	operation string // "create_file", "create_dir", "rename"
-	input     string // 入力された名前
-	oldName   string // リネームの場合の元の名前
-	err       error  // エラー
+	operation string      // "create_file", "create_dir", "rename"
+	input     string      // 入力された名前
+	oldName   string      // リネームの場合の元の名前
+	err       error       // エラー
+	entry     *undo.Entry // 取り消し履歴に記録する操作
 }
 
 // archiveOperationStartMsg はアーカイブ操作の開始を通知
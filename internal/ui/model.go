@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,7 +13,12 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sakura/duofm/internal/archive"
 	"github.com/sakura/duofm/internal/config"
+	"github.com/sakura/duofm/internal/frecency"
 	"github.com/sakura/duofm/internal/fs"
+	"github.com/sakura/duofm/internal/jobs"
+	"github.com/sakura/duofm/internal/session"
+	"github.com/sakura/duofm/internal/trash"
+	"github.com/sakura/duofm/internal/undo"
 )
 
 // ANSIエスケープシーケンスを除去するための正規表現
@@ -19,12 +26,42 @@ var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
 
 // BatchOperation holds state for batch file operations
 type BatchOperation struct {
-	Files      []string // List of source file paths
-	CurrentIdx int      // Current file index
-	DestPath   string   // Destination directory
-	Operation  string   // "copy" or "move"
-	Completed  []string // Successfully completed files
-	Failed     []string // Failed files
+	Files      []string            // List of source file paths
+	CurrentIdx int                 // Current file index
+	DestPath   string              // Destination directory
+	Operation  string              // "copy" or "move"
+	Items      []fileOperationItem // Resolved operations, run as one job when planning completes
+	Policy     ConflictPolicy      // How remaining conflicts are resolved without asking
+}
+
+// ConflictPolicy decides how a batch resolves a file that already exists at the destination
+type ConflictPolicy int
+
+const (
+	ConflictPolicyAsk                    ConflictPolicy = iota // Show the overwrite dialog
+	ConflictPolicyOverwrite                                    // Replace the existing file
+	ConflictPolicySkip                                         // Keep the existing file
+	ConflictPolicyOverwriteIfNewer                             // Replace only if the source is newer
+	ConflictPolicyOverwriteIfSizeDiffers                       // Replace only if the sizes differ
+	ConflictPolicyBackup                                       // Rename the existing file to name.~N~ first
+)
+
+// conflictPolicyFor returns the policy matching an overwrite dialog choice.
+// Cancel and Rename have no policy and return ConflictPolicyAsk.
+func conflictPolicyFor(choice OverwriteChoice) ConflictPolicy {
+	switch choice {
+	case OverwriteChoiceOverwrite:
+		return ConflictPolicyOverwrite
+	case OverwriteChoiceSkip:
+		return ConflictPolicySkip
+	case OverwriteChoiceNewer:
+		return ConflictPolicyOverwriteIfNewer
+	case OverwriteChoiceSizeDiffers:
+		return ConflictPolicyOverwriteIfSizeDiffers
+	case OverwriteChoiceBackup:
+		return ConflictPolicyBackup
+	}
+	return ConflictPolicyAsk
 }
 
 // ArchiveOperationState holds state for in-progress archive operations
@@ -37,6 +74,15 @@ type ArchiveOperationState struct {
 	TaskID      string                // Task ID for background operation
 }
 
+// FileOperationState holds state for the copy/move job shown in the progress dialog.
+// A batch operation runs as a single job so progress covers all files.
+type FileOperationState struct {
+	Operation string              // "copy" or "move"
+	Progress  *fs.Progress        // Byte-level progress shared with the job
+	job       *jobs.Job           // Background job running the operation
+	dialog    *FileProgressDialog // Progress dialog for this operation
+}
+
 // Model はアプリケーション全体の状態を保持
 type Model struct {
 	leftPane           *Pane
@@ -67,6 +113,37 @@ type Model struct {
 	bookmarkEditIndex  int                        // 編集中のブックマークインデックス
 	archiveOp          *ArchiveOperationState     // アーカイブ操作の状態
 	archiveController  *archive.ArchiveController // アーカイブコントローラー
+	fileOp             *FileOperationState        // 進捗ダイアログに表示中のコピー/移動操作
+	jobs               *jobs.Manager              // バックグラウンドジョブ
+	jobsPolling        bool                       // ジョブ進捗のポーリング中かどうか
+	trash              *trash.Trash               // ゴミ箱（nil = 利用不可）
+	permanentDelete    bool                       // 確認待ちの削除が完全削除かどうか
+	undoJournal        *undo.Journal              // 取り消し可能な操作の履歴
+	copyConfig         config.CopyConfig          // コピー/移動操作の設定
+	filterSets         []config.FilterSet         // 保存済みのフィルタセット
+	opFilter           *fs.Filter                 // コピー/移動/削除に適用するフィルタ（nil = なし）
+	opFilterName       string                     // 適用中のフィルタセットの名前（手入力の場合は空）
+	compareMode        fs.CompareMode             // 前回のディレクトリ比較の方法
+	compareRecursive   bool                       // 前回のディレクトリ比較がサブディレクトリを含むかどうか
+	compare            *compareState              // 最後のディレクトリ比較の結果（凡例の表示用）
+	find               *findSearch                // 実行中の再帰検索（nil = なし）
+	findQuery          string                     // 前回の再帰検索のクエリ
+	grepSettings       grepSettings               // 前回の内容検索の条件
+	dirVisits          *frecency.DB               // 訪問したディレクトリの記録（ジャンプ用）
+	sessionPath        string                     // 終了時にセッションを保存するファイル（空 = 保存しない）
+	savedSession       *session.State             // 起動時に復元するセッション（nil = 復元しない）
+	startOptions       StartOptions               // コマンドラインで指定された起動時の設定
+	chooseMode         ChooseMode                 // 選択結果を返して終了するモード
+	chosen             []string                   // 選択モードで選ばれたパス（nil = 選択なし）
+}
+
+// compareState は両ペインのディレクトリ比較の結果を保持する
+type compareState struct {
+	leftPath  string
+	rightPath string
+	mode      fs.CompareMode
+	recursive bool
+	result    *fs.CompareResult
 }
 
 // PanePosition はペインの位置を表す
@@ -107,17 +184,33 @@ func NewModelWithConfig(keybindingMap *KeybindingMap, theme *Theme, warnings []s
 		theme = DefaultTheme()
 	}
 
-	// ブックマークを読み込み
+	// ブックマークとフィルタセットを読み込み
 	var bookmarks []config.Bookmark
+	var filterSets []config.FilterSet
 	configPath, configErr := config.GetConfigPath()
-	if configErr != nil {
+	switch {
+	case errors.Is(configErr, config.ErrConfigDisabled):
+		// --no-config: ブックマークとフィルタセットは読み込まない
+	case configErr != nil:
 		warnings = append(warnings, fmt.Sprintf("Warning: failed to get config path: %v", configErr))
-	} else {
-		var bookmarkWarnings []string
+	default:
+		var bookmarkWarnings, filterWarnings []string
 		bookmarks, bookmarkWarnings = config.LoadBookmarks(configPath)
 		warnings = append(warnings, bookmarkWarnings...)
+		filterSets, filterWarnings = config.LoadFilterSets(configPath)
+		warnings = append(warnings, filterWarnings...)
 	}
 
+	// ゴミ箱を初期化
+	trashBin, trashErr := trash.New()
+	if trashErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Warning: trash is unavailable: %v", trashErr))
+	}
+
+	// 取り消し履歴（永続化はSetUndoJournalで設定）
+	undoJournal := undo.NewJournal()
+	undoJournal.SetTrash(trashBin)
+
 	return Model{
 		leftPane:          nil, // Updateで初期化
 		rightPane:         nil, // Updateで初期化
@@ -134,9 +227,32 @@ func NewModelWithConfig(keybindingMap *KeybindingMap, theme *Theme, warnings []s
 		bookmarks:         bookmarks,
 		bookmarkEditIndex: -1,
 		archiveController: archive.NewArchiveController(),
+		jobs:              jobs.NewManager(jobs.DefaultMaxConcurrent),
+		trash:             trashBin,
+		undoJournal:       undoJournal,
+		copyConfig:        config.DefaultCopyConfig(),
+		grepSettings:      defaultGrepSettings,
+		dirVisits:         frecency.NewDB(),
+		filterSets:        filterSets,
 	}
 }
 
+// SetCopyConfig は設定ファイルのコピー/移動操作の設定を適用
+func (m *Model) SetCopyConfig(cfg config.CopyConfig) {
+	m.copyConfig = cfg
+}
+
+// SetUndoJournal は永続化された取り消し履歴を設定
+func (m *Model) SetUndoJournal(journal *undo.Journal) {
+	journal.SetTrash(m.trash)
+	m.undoJournal = journal
+}
+
+// SetDirectoryVisits は永続化されたディレクトリの訪問記録を設定
+func (m *Model) SetDirectoryVisits(db *frecency.DB) {
+	m.dirVisits = db
+}
+
 // Init はBubble Teaの初期化
 func (m Model) Init() tea.Cmd {
 	// 設定ファイルの警告があれば最初の警告をステータスバーに表示
@@ -340,9 +456,11 @@ func (m *Model) handleCreateFile(dirPath, filename string) tea.Cmd {
 			}
 		}
 
+		entry := undo.NewEntry(undo.KindCreate, undo.Step{Dest: fullPath})
 		return inputDialogResultMsg{
 			operation: "create_file",
 			input:     filename,
+			entry:     &entry,
 		}
 	}
 }
@@ -366,9 +484,11 @@ func (m *Model) handleCreateDirectory(dirPath, dirname string) tea.Cmd {
 			}
 		}
 
+		entry := undo.NewEntry(undo.KindCreate, undo.Step{Dest: fullPath, IsDir: true})
 		return inputDialogResultMsg{
 			operation: "create_dir",
 			input:     dirname,
+			entry:     &entry,
 		}
 	}
 }
@@ -392,10 +512,12 @@ func (m *Model) handleRename(dirPath, oldName, newName string) tea.Cmd {
 			}
 		}
 
+		entry := undo.NewEntry(undo.KindRename, undo.Step{Src: oldPath, Dest: filepath.Join(dirPath, newName)})
 		return inputDialogResultMsg{
 			operation: "rename",
 			input:     newName,
 			oldName:   oldName,
+			entry:     &entry,
 		}
 	}
 }
@@ -449,8 +571,8 @@ func (m *Model) checkFileConflict(srcPath, destDir, operation string) tea.Cmd {
 	// Check destination using Lstat to handle symlinks properly
 	destInfo, err := os.Lstat(destPath)
 	if os.IsNotExist(err) {
-		// No conflict - execute immediately
-		return m.executeFileOperation(srcPath, destDir, operation)
+		// No conflict - execute immediately (or add to the batch)
+		return m.queueFileOperation(srcPath, destDir, operation)
 	}
 
 	if err != nil {
@@ -467,6 +589,16 @@ func (m *Model) checkFileConflict(srcPath, destDir, operation string) tea.Cmd {
 		}
 	}
 
+	srcMeta := OverwriteFileInfo{Size: srcInfo.Size(), ModTime: srcInfo.ModTime()}
+	destMeta := OverwriteFileInfo{Size: destInfo.Size(), ModTime: destInfo.ModTime()}
+
+	// A batch with an "apply to all" policy resolves the conflict without asking
+	if m.batchOp != nil && m.batchOp.Policy != ConflictPolicyAsk {
+		if m.batchOp.Policy == ConflictPolicySkip || !(srcInfo.IsDir() && destInfo.IsDir()) {
+			return m.resolveConflict(m.batchOp.Policy, srcPath, destDir, srcMeta, destMeta, operation)
+		}
+	}
+
 	// Directory conflict - show error dialog
 	if srcInfo.IsDir() && destInfo.IsDir() {
 		return func() tea.Msg {
@@ -482,33 +614,437 @@ func (m *Model) checkFileConflict(srcPath, destDir, operation string) tea.Cmd {
 			filename:  filename,
 			srcPath:   srcPath,
 			destPath:  destDir,
-			srcInfo:   OverwriteFileInfo{Size: srcInfo.Size(), ModTime: srcInfo.ModTime()},
-			destInfo:  OverwriteFileInfo{Size: destInfo.Size(), ModTime: destInfo.ModTime()},
+			srcInfo:   srcMeta,
+			destInfo:  destMeta,
 			operation: operation,
 		}
 	}
 }
 
-// executeFileOperation executes a copy or move operation
+// resolveConflict applies a conflict policy to one conflicting file.
+// Conditional policies fall back to skipping when their condition does not hold.
+func (m *Model) resolveConflict(policy ConflictPolicy, srcPath, destDir string, srcInfo, destInfo OverwriteFileInfo, operation string) tea.Cmd {
+	item := fileOperationItem{Src: srcPath, Dest: destDir}
+	switch policy {
+	case ConflictPolicyOverwrite:
+		item.Replace = true
+	case ConflictPolicyOverwriteIfNewer:
+		if !srcInfo.ModTime.After(destInfo.ModTime) {
+			return m.skipFileOperation()
+		}
+		item.Replace = true
+	case ConflictPolicyOverwriteIfSizeDiffers:
+		if srcInfo.Size == destInfo.Size {
+			return m.skipFileOperation()
+		}
+		item.Replace = true
+	case ConflictPolicyBackup:
+		item.Backup = true
+	default:
+		return m.skipFileOperation()
+	}
+	return m.queueFileOperationItem(item, operation)
+}
+
+// skipFileOperation leaves the current file untouched and continues the batch
+func (m *Model) skipFileOperation() tea.Cmd {
+	if m.batchOp == nil {
+		return nil
+	}
+	m.batchOp.CurrentIdx++
+	return m.processBatchFile()
+}
+
+// fileOperationItem is a resolved copy/move of one source
+type fileOperationItem struct {
+	Src          string // Source path
+	Dest         string // Destination directory, or full path when renaming
+	Replace      bool   // Remove the existing destination before copying
+	SkipExisting bool   // Keep existing files when merging into an existing directory
+	Backup       bool   // Rename the existing destination to a numbered backup before copying
+}
+
+// queueFileOperation runs a resolved copy/move. While planning a batch, the
+// item is collected instead and planning continues with the next file.
+func (m *Model) queueFileOperation(srcPath, destPath, operation string) tea.Cmd {
+	return m.queueFileOperationItem(fileOperationItem{Src: srcPath, Dest: destPath}, operation)
+}
+
+// queueFileOperationItem is queueFileOperation for an item with conflict options
+func (m *Model) queueFileOperationItem(item fileOperationItem, operation string) tea.Cmd {
+	if m.batchOp != nil {
+		m.batchOp.Items = append(m.batchOp.Items, item)
+		m.batchOp.CurrentIdx++
+		return m.processBatchFile()
+	}
+	return m.executeFileOperationItem(item, operation)
+}
+
+// executeFileOperation runs a single copy or move as a background job.
+// The returned command waits for the job; use trackFileOperation to also
+// start polling its progress.
 func (m *Model) executeFileOperation(srcPath, destPath, operation string) tea.Cmd {
+	return m.executeFileOperationItem(fileOperationItem{Src: srcPath, Dest: destPath}, operation)
+}
+
+// executeFileOperationItem runs a single resolved copy or move as a background job
+func (m *Model) executeFileOperationItem(item fileOperationItem, operation string) tea.Cmd {
+	srcPath, destPath := item.Src, item.Dest
+	filter := m.opFilter
+	totalBytes, totalFiles, _ := fs.CalculateFilteredSize([]string{srcPath}, filter)
+	title := fmt.Sprintf("%s %s -> %s", capitalize(operation), filepath.Base(srcPath), destPath)
+
+	entry := undo.NewEntry(undo.Kind(operation))
+	undoEntry := &entry
+	if filter != nil {
+		// 一部だけをコピー/移動した操作はまとめて取り消せないため記録しない
+		undoEntry = nil
+	}
+
+	copyConfig := m.copyConfig
+	bin := m.trash
+	skipped := &reportedFiles{}
+	mismatched := &reportedFiles{}
+	manager := m.jobs
+	job := manager.Add(jobs.Options{
+		Kind:     operation,
+		Title:    title,
+		Progress: fs.NewProgress(totalBytes, totalFiles),
+		Pausable: true,
+	}, func(ctx context.Context, job *jobs.Job) error {
+		merged := mergesIntoExisting(item)
+		step, err := runFileOperation(ctx, job, item, operation, copyConfig, bin, filter, skipped.add, mismatched.add)
+		if err != nil {
+			return err
+		}
+		// 既存のディレクトリにまとめた操作は取り消すと元からあったファイルまで消えるため記録しない
+		if !merged {
+			entry.Steps = append(entry.Steps, step)
+		}
+		return nil
+	})
+	m.showFileOperation(job, operation, destPath)
+
 	return func() tea.Msg {
-		var err error
-		if operation == "copy" {
-			err = fs.Copy(srcPath, destPath)
-		} else {
-			err = fs.MoveFile(srcPath, destPath)
+		manager.Start(job.ID())
+		err := job.Wait()
+		if errors.Is(err, context.Canceled) {
+			return fileOperationCancelledMsg{operation: operation}
+		}
+		if err != nil {
+			return showErrorDialogMsg{message: fmt.Sprintf("Failed to %s: %v", operation, err), jobID: job.ID()}
+		}
+		return fileOperationCompleteMsg{operation: operation, entry: undoEntry, skipped: skipped.list, mismatched: mismatched.list}
+	}
+}
+
+// executeBatchOperation runs all resolved items of a batch as one background job.
+// Failures of individual items are recorded on the job and do not stop the batch.
+func (m *Model) executeBatchOperation(items []fileOperationItem, destDir, operation string) tea.Cmd {
+	sources := make([]string, len(items))
+	for i, item := range items {
+		sources[i] = item.Src
+	}
+	filter := m.opFilter
+	totalBytes, totalFiles, _ := fs.CalculateFilteredSize(sources, filter)
+	title := fmt.Sprintf("%s %d files -> %s", capitalize(operation), len(items), destDir)
+	entry := undo.NewEntry(undo.Kind(operation))
+	undoEntry := &entry
+	if filter != nil {
+		undoEntry = nil
+	}
+
+	copyConfig := m.copyConfig
+	bin := m.trash
+	skipped := &reportedFiles{}
+	mismatched := &reportedFiles{}
+	unverified := 0 // Items kept back because verification failed
+	manager := m.jobs
+	job := manager.Add(jobs.Options{
+		Kind:     operation,
+		Title:    title,
+		Progress: fs.NewProgress(totalBytes, totalFiles),
+		Pausable: true,
+	}, func(ctx context.Context, job *jobs.Job) error {
+		for _, item := range items {
+			merged := mergesIntoExisting(item)
+			reported := len(mismatched.list)
+			step, err := runFileOperation(ctx, job, item, operation, copyConfig, bin, filter, skipped.add, mismatched.add)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if errors.Is(err, fs.ErrChecksumMismatch) {
+					// Files inside directories were already reported one by one
+					if len(mismatched.list) == reported {
+						mismatched.add(item.Src, err)
+					}
+					unverified++
+					continue
+				}
+				job.AddError(fmt.Sprintf("%s: %v", filepath.Base(item.Src), err))
+				continue
+			}
+			// Items merged into an existing directory are not journalled,
+			// undoing them would remove the files that were already there
+			if !merged {
+				entry.Steps = append(entry.Steps, step)
+			}
+		}
+		return nil
+	})
+	m.showFileOperation(job, operation, destDir)
+
+	return func() tea.Msg {
+		manager.Start(job.ID())
+		err := job.Wait()
+		// Items finished before a cancellation can still be undone
+		if errors.Is(err, context.Canceled) {
+			return fileOperationCancelledMsg{operation: operation, entry: undoEntry}
+		}
+		failed := job.Errors()
+		return batchOperationCompleteMsg{
+			operation:  operation,
+			count:      len(items) - len(failed) - unverified,
+			failed:     failed,
+			skipped:    skipped.list,
+			mismatched: mismatched.list,
+			entry:      undoEntry,
+		}
+	}
+}
+
+// executeSyncOperation は確認済みの同期計画を実行するジョブを登録し、完了を待つコマンドを返す
+// 宛先から消すエントリはゴミ箱が使える場合はゴミ箱へ移動する
+func (m *Model) executeSyncOperation(actions []fs.SyncAction, dest string) tea.Cmd {
+	var totalBytes int64
+	totalFiles := 0
+	for _, action := range actions {
+		if action.Op == fs.SyncDelete {
+			totalFiles++
+			continue
+		}
+		totalBytes += action.Size
+		totalFiles += action.Files
+	}
+
+	copyConfig := m.copyConfig
+	filter := m.opFilter
+	bin := m.trash
+	mismatched := &reportedFiles{}
+	manager := m.jobs
+	job := manager.Add(jobs.Options{
+		Kind:     "sync",
+		Title:    fmt.Sprintf("Sync %d entries: %s", len(actions), dest),
+		Progress: fs.NewProgress(totalBytes, totalFiles),
+		Pausable: true,
+	}, func(ctx context.Context, job *jobs.Job) error {
+		for _, action := range actions {
+			if err := job.WaitIfPaused(ctx); err != nil {
+				return err
+			}
+			var err error
+			if action.Op == fs.SyncDelete && bin != nil {
+				job.Progress().SetCurrentFile(action.Dst)
+				_, err = trashFile(bin, action.Dst)
+			} else {
+				err = fs.ApplySyncAction(ctx, action, fs.CopyOptions{
+					Progress:       job.Progress(),
+					Pauser:         job,
+					Verify:         fs.VerifyAlgorithm(copyConfig.Verify),
+					OnVerifyFailed: mismatched.add,
+					Filter:         filter,
+				})
+			}
+			if action.Op == fs.SyncDelete {
+				job.Progress().FileDone()
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				job.AddError(fmt.Sprintf("%s: %v", action.Rel, err))
+			}
+		}
+		return nil
+	})
+	m.showFileOperation(job, "sync", dest)
+
+	return func() tea.Msg {
+		manager.Start(job.ID())
+		err := job.Wait()
+		if errors.Is(err, context.Canceled) {
+			return fileOperationCancelledMsg{operation: "sync"}
+		}
+		failed := job.Errors()
+		return batchOperationCompleteMsg{
+			operation:  "sync",
+			count:      len(actions) - len(failed),
+			failed:     failed,
+			mismatched: mismatched.list,
 		}
+	}
+}
 
+// reportedFiles collects the files a copy/move job skipped or failed to verify.
+// It is only written by the job and read after the job finished.
+type reportedFiles struct {
+	list []string // "path: reason" for each reported file
+}
+
+func (r *reportedFiles) add(path string, err error) {
+	r.list = append(r.list, fmt.Sprintf("%s: %v", path, err))
+}
+
+// runFileOperation performs one copy or move inside a job and returns its undo journal step
+// Special files and symlink loops inside directories are skipped and passed to onSkip.
+// Files inside directories that fail checksum verification are passed to onVerifyFailed.
+// filter limits the files copied or moved out of directories (nil copies everything).
+// A replaced destination is moved to bin so that undo can restore it; without
+// a trash it is deleted and the step cannot be undone.
+func runFileOperation(ctx context.Context, job *jobs.Job, item fileOperationItem, operation string, copyConfig config.CopyConfig, bin *trash.Trash, filter *fs.Filter, onSkip, onVerifyFailed func(path string, err error)) (undo.Step, error) {
+	step := undo.Step{Src: item.Src, Dest: fs.ResolveDestPath(item.Src, item.Dest)}
+	if item.Replace {
+		if bin != nil {
+			trashed, err := trashFile(bin, step.Dest)
+			if err != nil {
+				return step, fmt.Errorf("failed to remove existing destination: %w", err)
+			}
+			step.Replaced, step.ReplacedInfo = trashed.FilesPath(), trashed.InfoPath()
+		} else {
+			if err := removeAllFiles(step.Dest); err != nil {
+				return step, fmt.Errorf("failed to remove existing destination: %w", err)
+			}
+			step.Overwritten = true
+		}
+	}
+	if item.Backup {
+		backup, err := fs.Backup(step.Dest)
 		if err != nil {
-			return showErrorDialogMsg{message: fmt.Sprintf("Failed to %s: %v", operation, err)}
+			return step, err
 		}
-		return fileOperationCompleteMsg{operation: operation}
+		step.Replaced = backup
+	}
+
+	opts := fs.CopyOptions{
+		Progress:         job.Progress(),
+		Pauser:           job,
+		PreserveMetadata: copyConfig.PreserveMetadata,
+		Dereference:      copyConfig.DereferenceSymlinks,
+		SkipExisting:     item.SkipExisting,
+		OnSkip:           onSkip,
+		Verify:           fs.VerifyAlgorithm(copyConfig.Verify),
+		OnVerifyFailed:   onVerifyFailed,
+		Filter:           filter,
+	}
+	if operation == "copy" {
+		return step, fs.CopyContext(ctx, item.Src, item.Dest, opts)
+	}
+	return step, fs.MoveContext(ctx, item.Src, item.Dest, opts)
+}
+
+// mergesIntoExisting reports whether item copies or moves into a destination
+// that already exists and is kept, i.e. merged into, rather than replaced
+func mergesIntoExisting(item fileOperationItem) bool {
+	if item.Replace || item.Backup {
+		return false
+	}
+	_, err := os.Lstat(fs.ResolveDestPath(item.Src, item.Dest))
+	return err == nil
+}
+
+// showFileOperation opens the progress dialog for a copy/move job
+func (m *Model) showFileOperation(job *jobs.Job, operation, destDir string) {
+	dialog := NewFileProgressDialog(operation, destDir, job.Progress())
+	manager := m.jobs
+	dialog.SetOnCancel(func() {
+		manager.Cancel(job.ID())
+	})
+
+	m.fileOp = &FileOperationState{
+		Operation: operation,
+		Progress:  job.Progress(),
+		job:       job,
+		dialog:    dialog,
+	}
+	m.dialog = dialog
+}
+
+// endFileOperation closes the progress dialog once its job has finished.
+// A job that is still running (e.g. after another job finished) keeps its dialog.
+func (m *Model) endFileOperation() {
+	if m.fileOp == nil {
+		return
+	}
+	if m.fileOp.job != nil && !m.fileOp.job.State().IsFinished() {
+		return
+	}
+	if m.dialog == m.fileOp.dialog {
+		m.dialog = nil
+	}
+	m.fileOp = nil
+}
+
+// backgroundFileOperation hides the progress dialog and lets the job continue
+func (m *Model) backgroundFileOperation() {
+	if m.fileOp == nil {
+		return
+	}
+	if m.dialog == m.fileOp.dialog {
+		m.dialog = nil
+	}
+	m.fileOp = nil
+}
+
+// trackFileOperation starts the progress poll loop alongside cmd when a
+// job is running and the loop is not already active
+func (m *Model) trackFileOperation(cmd tea.Cmd) tea.Cmd {
+	if m.jobsPolling || !m.hasActiveJobs() {
+		return cmd
+	}
+	m.jobsPolling = true
+	return tea.Batch(cmd, pollFileOperationProgress())
+}
+
+// hasActiveJobs reports whether any background job is queued or running
+func (m *Model) hasActiveJobs() bool {
+	return m.fileOp != nil || (m.jobs != nil && m.jobs.ActiveCount() > 0)
+}
+
+// pollFileOperationProgress schedules the next progress refresh
+func pollFileOperationProgress() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(t time.Time) tea.Msg {
+		return fileOperationProgressMsg{}
+	})
+}
+
+// undoResultMsg is sent when an undo or redo finished
+type undoResultMsg struct {
+	entry undo.Entry
+	redo  bool
+	err   error
+}
+
+// runUndo reverts (or with redo re-applies) the last journaled operation
+// outside the UI goroutine
+func (m *Model) runUndo(redo bool) tea.Cmd {
+	journal := m.undoJournal
+	return func() tea.Msg {
+		var entry undo.Entry
+		var err error
+		if redo {
+			entry, err = journal.Redo()
+		} else {
+			entry, err = journal.Undo()
+		}
+		return undoResultMsg{entry: entry, redo: redo, err: err}
 	}
 }
 
 // showErrorDialogMsg is a message to show an error dialog
 type showErrorDialogMsg struct {
 	message string
+	jobID   int // Background job that failed (0 for errors found while planning)
 }
 
 // showOverwriteDialogMsg is a message to show the overwrite confirmation dialog
@@ -523,40 +1059,182 @@ type showOverwriteDialogMsg struct {
 
 // fileOperationCompleteMsg is sent when a file operation completes successfully
 type fileOperationCompleteMsg struct {
+	operation  string
+	skipped    []string    // "path: reason" for each special file that was skipped
+	mismatched []string    // "path: reason" for each file that failed verification
+	entry      *undo.Entry // Undo journal entry for the completed operation
+}
+
+// fileOperationCancelledMsg is sent when the user cancels a copy/move operation
+type fileOperationCancelledMsg struct {
 	operation string
+	entry     *undo.Entry // Items completed before cancellation (nil if none)
+}
+
+// fileOperationProgressMsg triggers a refresh of progress displays while jobs run
+type fileOperationProgressMsg struct{}
+
+// sendToBackgroundMsg is sent when the user hides a progress dialog
+type sendToBackgroundMsg struct{}
+
+// copyMoveSources returns the marked files of the active pane, or the
+// selected entry when nothing is marked, as full paths
+func (m *Model) copyMoveSources() []string {
+	activePane := m.getActivePane()
+	if marked := activePane.GetMarkedFiles(); len(marked) > 0 {
+		sources := make([]string, len(marked))
+		for i, name := range marked {
+			sources[i] = filepath.Join(activePane.Path(), name)
+		}
+		return sources
+	}
+
+	entry := activePane.SelectedEntry()
+	if entry == nil || entry.IsParentDir() {
+		return nil
+	}
+	return []string{filepath.Join(activePane.Path(), entry.Name)}
 }
 
-// batchFileCompleteMsg is sent when one file in a batch operation completes
-type batchFileCompleteMsg struct {
-	success bool
-	srcPath string
+// showDestinationDialog asks where to copy/move the selected files,
+// starting from the other pane's directory
+func (m *Model) showDestinationDialog(operation string) {
+	sources := m.copyMoveSources()
+	if len(sources) == 0 {
+		return
+	}
+	m.dialog = NewDestinationDialog(operation, sources, m.getInactivePane().Path(), m.getActivePane().Path())
 }
 
-// startBatchOperation initializes a batch copy/move operation
-func (m *Model) startBatchOperation(files []string, operation string) tea.Cmd {
-	srcDir := m.getActivePane().Path()
-	destDir := m.getInactivePane().Path()
+// startCopyMoveTo starts a copy/move to a destination confirmed in the
+// destination dialog, creating missing directories first
+func (m *Model) startCopyMoveTo(operation string, sources []string, target destinationTarget) tea.Cmd {
+	if target.MissingDir != "" {
+		if err := os.MkdirAll(target.MissingDir, 0755); err != nil {
+			return func() tea.Msg {
+				return showErrorDialogMsg{message: fmt.Sprintf("Failed to create directory: %v", err)}
+			}
+		}
+	}
+
+	if target.NewName == "" {
+		return m.startPreflight(sources, target.Dir, operation)
+	}
 
-	// Build full paths
-	fullPaths := make([]string, len(files))
-	for i, f := range files {
-		fullPaths[i] = filepath.Join(srcDir, f)
+	// Copy-and-rename of a single source
+	src, dest := sources[0], target.Path()
+	var err error
+	if isSameFile(src, dest) {
+		err = fs.ErrSameFile
+	} else if isWithinDir(dest, src) {
+		err = fs.ErrDestinationInsideSource
+	}
+	if err != nil {
+		return func() tea.Msg {
+			return showErrorDialogMsg{message: fmt.Sprintf("Cannot %s: %v", operation, err)}
+		}
 	}
+	m.getActivePane().ClearMarks()
+	return m.executeFileOperationItem(fileOperationItem{Src: src, Dest: dest, Replace: target.Exists}, operation)
+}
 
+// isSameFile reports whether a and b name the same file
+func isSameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Stat(b)
+	return err == nil && os.SameFile(aInfo, bInfo)
+}
+
+// isWithinDir reports whether path is inside dir
+func isWithinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// startPreflight walks the sources of a copy/move outside the UI goroutine
+// before anything is changed
+func (m *Model) startPreflight(sources []string, dest, operation string) tea.Cmd {
+	if len(sources) == 0 {
+		return nil
+	}
+	filter := m.opFilter
+	return func() tea.Msg {
+		plan, err := fs.PlanOperation(sources, dest, operation == "move", filter)
+		return preflightResultMsg{
+			operation: operation,
+			sources:   sources,
+			dest:      dest,
+			plan:      plan,
+			err:       err,
+		}
+	}
+}
+
+// startFileOperation starts a copy/move that asks about each conflict
+func (m *Model) startFileOperation(sources []string, dest, operation string) tea.Cmd {
+	if len(sources) == 1 {
+		return m.checkFileConflict(sources[0], dest, operation)
+	}
+	return m.startBatchOperation(sources, dest, operation)
+}
+
+// startResolvedOperation starts a copy/move whose conflicts were resolved
+// up front by overwriting or skipping all of them
+func (m *Model) startResolvedOperation(sources []string, dest, operation string, overwrite bool) tea.Cmd {
+	var items []fileOperationItem
+	for _, src := range sources {
+		item := fileOperationItem{Src: src, Dest: dest}
+		destInfo, err := os.Lstat(fs.ResolveDestPath(src, dest))
+		if err == nil {
+			srcInfo, srcErr := os.Lstat(src)
+			merge := srcErr == nil && srcInfo.IsDir() && destInfo.IsDir()
+			switch {
+			case merge && !overwrite:
+				item.SkipExisting = true
+			case !merge && overwrite:
+				item.Replace = true
+			case !merge:
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+
+	m.getActivePane().ClearMarks()
+	switch {
+	case len(items) == 0:
+		return func() tea.Msg {
+			return batchOperationCompleteMsg{operation: operation, count: 0}
+		}
+	case len(sources) == 1:
+		return m.executeFileOperationItem(items[0], operation)
+	default:
+		return m.executeBatchOperation(items, dest, operation)
+	}
+}
+
+// startBatchOperation initializes a batch copy/move operation.
+// Conflicts are resolved file by file first; the resolved items then run as one job.
+func (m *Model) startBatchOperation(sources []string, destDir, operation string) tea.Cmd {
 	m.batchOp = &BatchOperation{
-		Files:      fullPaths,
+		Files:      sources,
 		CurrentIdx: 0,
 		DestPath:   destDir,
 		Operation:  operation,
-		Completed:  make([]string, 0),
-		Failed:     make([]string, 0),
+		Items:      make([]fileOperationItem, 0, len(sources)),
 	}
 
 	// Process first file
 	return m.processBatchFile()
 }
 
-// processBatchFile processes the current file in the batch operation
+// processBatchFile resolves the current file in the batch operation
 func (m *Model) processBatchFile() tea.Cmd {
 	if m.batchOp == nil || m.batchOp.CurrentIdx >= len(m.batchOp.Files) {
 		return m.completeBatchOperation()
@@ -566,42 +1244,26 @@ func (m *Model) processBatchFile() tea.Cmd {
 	return m.checkFileConflict(srcPath, m.batchOp.DestPath, m.batchOp.Operation)
 }
 
-// advanceBatchOperation moves to the next file in the batch
-func (m *Model) advanceBatchOperation(success bool, srcPath string) tea.Cmd {
-	if m.batchOp == nil {
-		return nil
-	}
-
-	if success {
-		m.batchOp.Completed = append(m.batchOp.Completed, srcPath)
-	} else {
-		m.batchOp.Failed = append(m.batchOp.Failed, srcPath)
-	}
-
-	m.batchOp.CurrentIdx++
-	return m.processBatchFile()
-}
-
-// completeBatchOperation finishes the batch operation
+// completeBatchOperation finishes planning and starts the batch job
 func (m *Model) completeBatchOperation() tea.Cmd {
 	if m.batchOp == nil {
 		return nil
 	}
 
 	operation := m.batchOp.Operation
-	completed := len(m.batchOp.Completed)
+	items := m.batchOp.Items
+	destDir := m.batchOp.DestPath
 	m.batchOp = nil
 
 	// Clear marks
 	m.getActivePane().ClearMarks()
 
-	// Reload both panes
-	m.getActivePane().LoadDirectory()
-	m.getInactivePane().LoadDirectory()
-
-	return func() tea.Msg {
-		return batchOperationCompleteMsg{operation: operation, count: completed}
+	if len(items) == 0 {
+		return func() tea.Msg {
+			return batchOperationCompleteMsg{operation: operation, count: 0}
+		}
 	}
+	return m.executeBatchOperation(items, destDir, operation)
 }
 
 // cancelBatchOperation cancels the remaining batch operation
@@ -621,8 +1283,12 @@ func (m *Model) cancelBatchOperation() {
 
 // batchOperationCompleteMsg is sent when a batch operation finishes
 type batchOperationCompleteMsg struct {
-	operation string
-	count     int
+	operation  string
+	count      int
+	failed     []string    // "name: error" for each item that failed
+	skipped    []string    // "path: reason" for each special file that was skipped
+	mismatched []string    // "path: reason" for each file that failed verification
+	entry      *undo.Entry // Undo journal entry for the completed items
 }
 
 // handleAddBookmark はブックマーク追加処理
@@ -685,6 +1351,184 @@ func saveBookmarksToConfig(bookmarks []config.Bookmark) error {
 	return nil
 }
 
+// compareResultMsg is sent when the directories of both panes were compared
+type compareResultMsg struct {
+	state *compareState
+	err   error
+}
+
+// compareDirectories compares the directories of both panes off the UI goroutine
+func (m *Model) compareDirectories(mode fs.CompareMode, recursive bool) tea.Cmd {
+	state := &compareState{
+		leftPath:  m.leftPane.Path(),
+		rightPath: m.rightPane.Path(),
+		mode:      mode,
+		recursive: recursive,
+	}
+	return func() tea.Msg {
+		result, err := fs.CompareDirs(context.Background(), state.leftPath, state.rightPath, mode, recursive)
+		state.result = result
+		return compareResultMsg{state: state, err: err}
+	}
+}
+
+// compareLegend returns the status bar legend of the last comparison, or an
+// empty string once either pane has left the compared directory
+func (m *Model) compareLegend() string {
+	c := m.compare
+	if c == nil || c.leftPath != m.leftPane.Path() || c.rightPath != m.rightPane.Path() {
+		return ""
+	}
+	method := compareModeShortLabels[c.mode]
+	if c.recursive {
+		method += ",recursive"
+	}
+	r := c.result
+	return fmt.Sprintf("cmp[%s]: <only %d  only> %d  newer %d/%d  differ %d",
+		method, r.Count(fs.CompareLeftOnly), r.Count(fs.CompareRightOnly),
+		r.Count(fs.CompareLeftNewer), r.Count(fs.CompareRightNewer), r.Count(fs.CompareDiffers))
+}
+
+// findBatchSize is the largest number of find results sent in one message
+const findBatchSize = 500
+
+// findBatchInterval is how long results are collected before they are shown
+const findBatchInterval = 100 * time.Millisecond
+
+// findSearch is a recursive find running in the background. The walk sends
+// each match over results and closes it when done; err is set before that.
+type findSearch struct {
+	pane    PanePosition
+	cancel  context.CancelFunc
+	results chan fs.FileEntry
+	err     error
+}
+
+// findResultsMsg carries the next batch of results of a find
+type findResultsMsg struct {
+	search  *findSearch
+	entries []fs.FileEntry
+	done    bool
+	err     error
+}
+
+// startFind starts a recursive find from the active pane directory and
+// shows the results in the pane as they come in
+func (m *Model) startFind(criteria *fs.FindCriteria, query string) tea.Cmd {
+	m.cancelFind()
+
+	pane := m.getActivePane()
+	criteria.Hidden = pane.IsShowingHidden()
+	root := pane.Path()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	search := &findSearch{
+		pane:    m.activePane,
+		cancel:  cancel,
+		results: make(chan fs.FileEntry, findBatchSize),
+	}
+	go func() {
+		search.err = fs.Find(ctx, root, criteria, func(entry fs.FileEntry) {
+			select {
+			case search.results <- entry:
+			case <-ctx.Done():
+			}
+		})
+		close(search.results)
+	}()
+
+	pane.StartVirtual(query)
+	m.find = search
+	m.findQuery = query
+	return waitFindResults(search)
+}
+
+// waitFindResults waits for the next results of a find. The first result is
+// sent right away; more are collected for a short while so a fast walk does
+// not redraw the screen for every match.
+func waitFindResults(search *findSearch) tea.Cmd {
+	return func() tea.Msg {
+		entry, ok := <-search.results
+		if !ok {
+			return findResultsMsg{search: search, done: true, err: search.err}
+		}
+		batch := []fs.FileEntry{entry}
+		timeout := time.After(findBatchInterval)
+		for len(batch) < findBatchSize {
+			select {
+			case entry, ok := <-search.results:
+				if !ok {
+					return findResultsMsg{search: search, entries: batch, done: true, err: search.err}
+				}
+				batch = append(batch, entry)
+			case <-timeout:
+				return findResultsMsg{search: search, entries: batch}
+			}
+		}
+		return findResultsMsg{search: search, entries: batch}
+	}
+}
+
+// cancelFind stops the running find, keeping the results found so far
+func (m *Model) cancelFind() {
+	if m.find == nil {
+		return
+	}
+	m.find.cancel()
+	if pane := m.findPane(); pane != nil {
+		pane.FinishVirtual()
+	}
+	m.find = nil
+}
+
+// findPane returns the pane showing the running find
+func (m *Model) findPane() *Pane {
+	if m.find == nil {
+		return nil
+	}
+	if m.find.pane == LeftPane {
+		return m.leftPane
+	}
+	return m.rightPane
+}
+
+// filterLabel returns the name of the active filter set, or its patterns
+func (m *Model) filterLabel() string {
+	if m.opFilterName != "" {
+		return m.opFilterName
+	}
+	return m.opFilter.String()
+}
+
+// saveFilterSet adds or replaces a named filter set and saves the sets
+func saveFilterSet(currentSets []config.FilterSet, set config.FilterSet) tea.Cmd {
+	return func() tea.Msg {
+		newSets, err := config.SetFilterSet(currentSets, set)
+		if err != nil {
+			if err == config.ErrEmptyAlias {
+				return showStatusMsg{message: "Filter name cannot be empty", isError: true}
+			}
+			return showStatusMsg{message: fmt.Sprintf("Failed to save filter: %v", err), isError: true}
+		}
+		if saveErr := saveFilterSetsToConfig(newSets); saveErr != nil {
+			return showStatusMsg{message: saveErr.Error(), isError: true}
+		}
+		return filterSetsSavedMsg{sets: newSets, name: set.Name}
+	}
+}
+
+// saveFilterSetsToConfig saves the filter sets to the configuration file.
+func saveFilterSetsToConfig(sets []config.FilterSet) error {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return fmt.Errorf("failed to get config path: %w", err)
+	}
+	if err := config.SaveFilterSets(configPath, sets); err != nil {
+		return fmt.Errorf("failed to save filters: %w", err)
+	}
+	return nil
+}
+
 // showStatusMsg is a message to show a status message
 type showStatusMsg struct {
 	message string
@@ -836,6 +1680,67 @@ func (m *Model) pollArchiveProgress(taskID string) tea.Cmd {
 	})
 }
 
+// trackArchiveJob lists a running archive task in the jobs panel so it can be
+// monitored and cancelled after its progress dialog is sent to the background
+func (m *Model) trackArchiveJob(taskID string) {
+	if m.jobs == nil || m.archiveController == nil || m.archiveOp == nil {
+		return
+	}
+
+	controller := m.archiveController
+	kind := "compress"
+	title := fmt.Sprintf("Compress %s", m.archiveOp.ArchiveName)
+	if m.archiveOp.ArchiveName == "" && len(m.archiveOp.Sources) > 0 {
+		kind = "extract"
+		title = fmt.Sprintf("Extract %s -> %s", filepath.Base(m.archiveOp.Sources[0]), m.archiveOp.DestDir)
+	}
+
+	m.jobs.Submit(jobs.Options{Kind: kind, Title: title, Unqueued: true}, func(ctx context.Context, job *jobs.Job) error {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			status := controller.GetTaskStatus(taskID)
+			if status == nil {
+				return fmt.Errorf("task not found")
+			}
+			if p := status.Progress; p != nil {
+				job.Progress().Update(p.ProcessedBytes, p.TotalBytes, p.ProcessedFiles, p.TotalFiles)
+				job.Progress().SetCurrentFile(p.CurrentFile)
+			}
+
+			switch status.State {
+			case archive.TaskStateCompleted:
+				return nil
+			case archive.TaskStateCancelled:
+				return context.Canceled
+			case archive.TaskStateFailed:
+				return status.Error
+			}
+
+			select {
+			case <-ctx.Done():
+				// Cancelled from the jobs panel; wait for the task to stop
+				controller.CancelTask(taskID)
+				controller.WaitForTask(taskID)
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// closeArchiveProgress clears the archive state and its progress dialog when
+// they belong to the given task (another archive may be set up meanwhile)
+func (m *Model) closeArchiveProgress(taskID string) {
+	if m.archiveOp != nil && m.archiveOp.TaskID != taskID {
+		return
+	}
+	m.archiveOp = nil
+	if _, ok := m.dialog.(*ArchiveProgressDialog); ok {
+		m.dialog = nil
+	}
+}
+
 // checkExtractSecurity performs security checks before archive extraction
 func (m *Model) checkExtractSecurity(archivePath, destDir string) tea.Cmd {
 	if m.archiveController == nil {
@@ -2,10 +2,13 @@ package ui
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/sakura/duofm/internal/config"
 	"github.com/sakura/duofm/internal/fs"
+	"github.com/sakura/duofm/internal/trash"
 )
 
 // File system helper functions
@@ -34,9 +37,20 @@ func removeAllFiles(path string) error {
 	return os.RemoveAll(path)
 }
 
-// isPermissionError checks if an error is a permission error
-func isPermissionError(err error) bool {
-	return os.IsPermission(err)
+// capitalize returns the operation name s with its first letter in upper case
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// countFiles returns "1 file" or "N files"
+func countFiles(n int) string {
+	if n == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", n)
 }
 
 // deleteFile deletes a file or directory
@@ -44,6 +58,11 @@ func deleteFile(path string) error {
 	return fs.Delete(path)
 }
 
+// trashFile moves a file or directory to the trash
+func trashFile(t *trash.Trash, path string) (trash.Item, error) {
+	return t.Put(path)
+}
+
 // copyFile copies a file or directory
 func copyFile(src, dest string) error {
 	return fs.Copy(src, dest)
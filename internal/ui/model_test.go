@@ -2,12 +2,21 @@ package ui
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/config"
+	"github.com/sakura/duofm/internal/fs"
+	"github.com/sakura/duofm/internal/session"
+	"github.com/sakura/duofm/internal/trash"
+	"github.com/sakura/duofm/internal/undo"
 )
 
 func TestNewModel(t *testing.T) {
@@ -1662,6 +1671,39 @@ func TestShowErrorDialogMsg(t *testing.T) {
 	}
 }
 
+func TestJobErrorKeepsOtherOperations(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+
+	// A batch still being planned and its overwrite dialog belong to another operation
+	m := setupPreflightModel(t, root, other)
+	m.batchOp = &BatchOperation{Files: []string{filepath.Join(root, "a.txt")}, DestPath: other, Operation: "copy"}
+	overwrite := NewOverwriteDialog("a.txt", other, OverwriteFileInfo{}, OverwriteFileInfo{}, "copy", filepath.Join(root, "a.txt"))
+	m.dialog = overwrite
+	os.WriteFile(filepath.Join(other, "partial.txt"), nil, 0644)
+
+	updatedModel, _ := m.Update(showErrorDialogMsg{message: "Failed to copy: disk full", jobID: 42})
+	m = updatedModel.(Model)
+	if m.batchOp == nil {
+		t.Error("a failed job should not cancel the batch being planned")
+	}
+	if m.dialog != overwrite {
+		t.Errorf("dialog = %T, the open dialog should be kept", m.dialog)
+	}
+	if m.statusMessage != "Failed to copy: disk full" || !m.isStatusError {
+		t.Errorf("status = %q, the error should be shown in the status bar", m.statusMessage)
+	}
+	if m.rightPane.findEntryIndex("partial.txt") < 0 {
+		t.Error("the panes should be reloaded after the failure")
+	}
+
+	// Errors found while planning abort the batch
+	updatedModel, _ = m.Update(showErrorDialogMsg{message: "Failed to check source"})
+	m = updatedModel.(Model)
+	if m.batchOp != nil {
+		t.Error("a planning error should cancel the batch")
+	}
+}
+
 func TestFileOperationCompleteMsg(t *testing.T) {
 	model := NewModel()
 
@@ -1886,12 +1928,10 @@ func TestCopyKeyShowsOverwriteDialogOnConflict(t *testing.T) {
 		t.Skip("No suitable entry for test")
 	}
 
-	// Press 'c' key for copy
-	keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}}
-	updatedModel, cmd := m.Update(keyMsg)
-	m = updatedModel.(Model)
+	// Press 'c' key for copy and accept the pre-filled destination
+	_, cmd := confirmDestination(t, m, 'c')
 
-	// Should return a command (from checkFileConflict)
+	// Should return a command (the preflight)
 	if cmd == nil {
 		t.Error("copy key should return a command")
 	}
@@ -1915,12 +1955,10 @@ func TestMoveKeyShowsOverwriteDialogOnConflict(t *testing.T) {
 		t.Skip("No suitable entry for test")
 	}
 
-	// Press 'm' key for move
-	keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}}
-	updatedModel, cmd := m.Update(keyMsg)
-	m = updatedModel.(Model)
+	// Press 'm' key for move and accept the pre-filled destination
+	_, cmd := confirmDestination(t, m, 'm')
 
-	// Should return a command (from checkFileConflict)
+	// Should return a command (the preflight)
 	if cmd == nil {
 		t.Error("move key should return a command")
 	}
@@ -2369,6 +2407,305 @@ func TestExecuteFileOperationError(t *testing.T) {
 	}
 }
 
+func TestExecuteFileOperationShowsProgressDialog(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	srcDir := t.TempDir()
+	srcFile := filepath.Join(srcDir, "source.txt")
+	os.WriteFile(srcFile, []byte("source content"), 0644)
+	destDir := t.TempDir()
+
+	run := m.executeFileOperation(srcFile, destDir, "copy")
+	cmd := m.trackFileOperation(run)
+	if cmd == nil {
+		t.Fatal("expected command, got nil")
+	}
+
+	if _, ok := m.dialog.(*FileProgressDialog); !ok {
+		t.Fatalf("dialog should be FileProgressDialog, got %T", m.dialog)
+	}
+	if m.fileOp == nil || !m.jobsPolling {
+		t.Fatal("file operation should be tracked and polling")
+	}
+	if snap := m.fileOp.Progress.Snapshot(); snap.TotalBytes != int64(len("source content")) {
+		t.Errorf("TotalBytes = %d, want %d", snap.TotalBytes, len("source content"))
+	}
+
+	result := run()
+	if _, ok := result.(fileOperationCompleteMsg); !ok {
+		t.Fatalf("expected fileOperationCompleteMsg, got %T", result)
+	}
+	updatedModel, _ = m.Update(result)
+	m = updatedModel.(Model)
+
+	if m.fileOp != nil {
+		t.Error("fileOp should be cleared after completion")
+	}
+	if m.dialog != nil {
+		t.Errorf("progress dialog should be closed after completion, got %T", m.dialog)
+	}
+}
+
+func TestExecuteFileOperationCancelled(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	srcDir := t.TempDir()
+	srcFile := filepath.Join(srcDir, "source.txt")
+	os.WriteFile(srcFile, []byte("source content"), 0644)
+	destDir := t.TempDir()
+
+	cmd := m.executeFileOperation(srcFile, destDir, "copy")
+
+	// Escで進捗ダイアログからキャンセル
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updatedModel.(Model)
+
+	result := cmd()
+	if _, ok := result.(fileOperationCancelledMsg); !ok {
+		t.Fatalf("expected fileOperationCancelledMsg, got %T", result)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "source.txt")); !os.IsNotExist(err) {
+		t.Error("destination file should not exist after cancellation")
+	}
+
+	updatedModel, _ = m.Update(result)
+	m = updatedModel.(Model)
+
+	if m.fileOp != nil || m.dialog != nil {
+		t.Error("file operation state and dialog should be cleared after cancellation")
+	}
+	if !strings.Contains(m.statusMessage, "cancelled") {
+		t.Errorf("status message should report cancellation, got %q", m.statusMessage)
+	}
+}
+
+func TestExecuteDeleteOperationRunsAsJob(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "keep.txt"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644)
+	}
+	m.activePane = LeftPane
+	if err := m.leftPane.ChangeDirectory(tmpDir); err != nil {
+		t.Fatalf("ChangeDirectory() error = %v", err)
+	}
+
+	// ../ の次から a.txt, b.txt をマーク
+	m.leftPane.MoveCursorDown()
+	m.leftPane.ToggleMark()
+	m.leftPane.MoveCursorDown()
+	m.leftPane.ToggleMark()
+
+	cmd := m.executeDeleteOperation(true)
+	if cmd == nil {
+		t.Fatal("executeDeleteOperation should return a command")
+	}
+	if m.jobs.ActiveCount() != 1 {
+		t.Errorf("ActiveCount() = %d, want 1", m.jobs.ActiveCount())
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "a.txt")); err != nil {
+		t.Error("files should not be deleted before the command runs")
+	}
+
+	result, ok := cmd().(batchOperationCompleteMsg)
+	if !ok {
+		t.Fatal("expected batchOperationCompleteMsg")
+	}
+	if result.operation != "delete" || result.count != 2 || len(result.failed) != 0 {
+		t.Errorf("result = %+v, want 2 deleted files", result)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should be deleted", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "keep.txt")); err != nil {
+		t.Error("keep.txt should not be deleted")
+	}
+
+	updatedModel, _ = m.Update(result)
+	m = updatedModel.(Model)
+	if !strings.Contains(m.statusMessage, "Delete 2 files completed") {
+		t.Errorf("status message = %q", m.statusMessage)
+	}
+}
+
+// collectBatchMsgs runs cmd and returns the messages of all batched commands
+func collectBatchMsgs(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, collectBatchMsgs(c)...)
+	}
+	return msgs
+}
+
+func TestExecuteDeleteOperationMovesToTrash(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+	m.trash = trash.NewWithHome(filepath.Join(t.TempDir(), "Trash"))
+
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "doc.txt"), []byte("doc"), 0644)
+	m.activePane = LeftPane
+	if err := m.leftPane.ChangeDirectory(tmpDir); err != nil {
+		t.Fatalf("ChangeDirectory() error = %v", err)
+	}
+	m.leftPane.MoveCursorDown()
+
+	// dキーはゴミ箱への移動を確認する
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updatedModel.(Model)
+	confirm, ok := m.dialog.(*ConfirmDialog)
+	if !ok {
+		t.Fatalf("dialog should be ConfirmDialog, got %T", m.dialog)
+	}
+	if !strings.Contains(confirm.message, "trash") {
+		t.Errorf("confirm message = %q, should mention trash", confirm.message)
+	}
+
+	updatedModel, cmd := m.Update(dialogResultMsg{result: DialogResult{Confirmed: true}})
+	m = updatedModel.(Model)
+	if cmd == nil {
+		t.Fatal("confirming should return a command")
+	}
+	var result tea.Msg
+	for _, msg := range collectBatchMsgs(cmd) {
+		if r, ok := msg.(batchOperationCompleteMsg); ok {
+			result = r
+		}
+	}
+	if result == nil {
+		t.Fatal("expected batchOperationCompleteMsg")
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "doc.txt")); !os.IsNotExist(err) {
+		t.Error("doc.txt should be moved out of the directory")
+	}
+	items, err := m.trash.List()
+	if err != nil || len(items) != 1 {
+		t.Fatalf("trash should contain 1 item, got %d (%v)", len(items), err)
+	}
+
+	updatedModel, _ = m.Update(result)
+	m = updatedModel.(Model)
+	if m.statusMessage != "Moved 1 files to trash" {
+		t.Errorf("status message = %q", m.statusMessage)
+	}
+}
+
+func TestDeletePermanentlyShowsConfirm(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	m.getActivePane().MoveCursorDown()
+	entry := m.getActivePane().SelectedEntry()
+	if entry == nil || entry.IsParentDir() {
+		t.Skip("No suitable entry for test")
+	}
+
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+	m = updatedModel.(Model)
+	confirm, ok := m.dialog.(*ConfirmDialog)
+	if !ok {
+		t.Fatalf("dialog should be ConfirmDialog, got %T", m.dialog)
+	}
+	if !strings.Contains(confirm.title, "permanently") {
+		t.Errorf("confirm title = %q, should mention permanent deletion", confirm.title)
+	}
+	if !m.permanentDelete {
+		t.Error("permanentDelete should be set")
+	}
+}
+
+func TestTrashBrowserRestore(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+	m.trash = trash.NewWithHome(filepath.Join(t.TempDir(), "Trash"))
+
+	src := filepath.Join(t.TempDir(), "restore-me.txt")
+	os.WriteFile(src, []byte("data"), 0644)
+	if _, err := m.trash.Put(src); err != nil {
+		t.Fatal(err)
+	}
+
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = updatedModel.(Model)
+	dialog, ok := m.dialog.(*TrashDialog)
+	if !ok {
+		t.Fatalf("dialog should be TrashDialog, got %T", m.dialog)
+	}
+	if len(dialog.items) != 1 {
+		t.Fatalf("trash dialog should list 1 item, got %d", len(dialog.items))
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+
+	if _, err := os.Stat(src); err != nil {
+		t.Error("file should be restored to its original path")
+	}
+	if len(dialog.items) != 0 {
+		t.Errorf("trash dialog should be refreshed, has %d items", len(dialog.items))
+	}
+	if !strings.Contains(dialog.View(), "Restored") {
+		t.Error("trash dialog should report the restore")
+	}
+
+	updatedModel, _ = m.Update(trashCloseMsg{})
+	m = updatedModel.(Model)
+	if m.dialog != nil {
+		t.Error("dialog should be closed after trashCloseMsg")
+	}
+}
+
+func TestJobsActionOpensJobsDialog(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'J'}})
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*JobsDialog); !ok {
+		t.Fatalf("dialog should be JobsDialog, got %T", m.dialog)
+	}
+
+	updatedModel, _ = m.Update(jobsDialogCloseMsg{})
+	m = updatedModel.(Model)
+	if m.dialog != nil {
+		t.Error("dialog should be closed after jobsDialogCloseMsg")
+	}
+}
+
+func TestFileOperationProgressMsgStopsWhenIdle(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	_, cmd := m.Update(fileOperationProgressMsg{})
+	if cmd != nil {
+		t.Error("progress polling should stop when no operation is running")
+	}
+}
+
 func TestOverwriteDialogResultMsgOverwriteActualFile(t *testing.T) {
 	model := NewModel()
 
@@ -4088,3 +4425,1360 @@ func TestContextMenuCompressWithNilAction(t *testing.T) {
 		t.Errorf("dialog should be CompressFormatDialog after compress action from context menu, got %T", m.dialog)
 	}
 }
+
+func TestUndoRedoRename(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+	m.undoJournal = undo.NewJournal()
+
+	tmpDir := t.TempDir()
+	oldPath := filepath.Join(tmpDir, "old.txt")
+	newPath := filepath.Join(tmpDir, "new.txt")
+	os.WriteFile(oldPath, []byte("data"), 0644)
+
+	updatedModel, _ = m.Update(m.handleRename(tmpDir, "old.txt", "new.txt")())
+	m = updatedModel.(Model)
+	if !m.undoJournal.CanUndo() {
+		t.Fatal("rename should be recorded in the undo journal")
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if cmd == nil {
+		t.Fatal("u should return an undo command")
+	}
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Error("undo should restore the old name")
+	}
+	if !strings.Contains(m.statusMessage, "Undo: rename old.txt -> new.txt") {
+		t.Errorf("statusMessage = %q", m.statusMessage)
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'U'}})
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if _, err := os.Stat(newPath); err != nil {
+		t.Error("redo should rename the file again")
+	}
+
+	// Nothing left to redo is not an error
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'U'}})
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.statusMessage != "Nothing to redo" || m.isStatusError {
+		t.Errorf("statusMessage = %q, isStatusError = %v", m.statusMessage, m.isStatusError)
+	}
+}
+
+func TestTogglePreserveMetadata(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+	m.SetCopyConfig(config.CopyConfig{PreserveMetadata: false})
+
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	m = updatedModel.(Model)
+	if !m.copyConfig.PreserveMetadata {
+		t.Fatal("Shift+P should enable metadata preservation")
+	}
+	if m.statusMessage != "Preserve metadata: on" {
+		t.Errorf("statusMessage = %q", m.statusMessage)
+	}
+
+	// The setting applies to the next copy
+	srcFile := filepath.Join(t.TempDir(), "old.txt")
+	os.WriteFile(srcFile, []byte("data"), 0644)
+	mtime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	os.Chtimes(srcFile, mtime, mtime)
+	destDir := t.TempDir()
+
+	if msg := m.executeFileOperation(srcFile, destDir, "copy")(); msg == nil {
+		t.Fatal("copy should report its result")
+	}
+	info, err := os.Stat(filepath.Join(destDir, "old.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("mtime = %v, want %v", info.ModTime(), mtime)
+	}
+}
+
+func TestCopyReportsSkippedSpecialFiles(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	srcDir := filepath.Join(t.TempDir(), "src")
+	os.Mkdir(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "file.txt"), []byte("data"), 0644)
+	listener, err := net.Listen("unix", filepath.Join(srcDir, "sock"))
+	if err != nil {
+		t.Skipf("cannot create socket: %v", err)
+	}
+	defer listener.Close()
+
+	msg, ok := m.executeFileOperation(srcDir, t.TempDir(), "copy")().(fileOperationCompleteMsg)
+	if !ok {
+		t.Fatal("copy should complete despite the special file")
+	}
+	if len(msg.skipped) != 1 {
+		t.Fatalf("skipped = %v, want the socket", msg.skipped)
+	}
+
+	updatedModel, _ = m.Update(msg)
+	m = updatedModel.(Model)
+	if !strings.Contains(m.statusMessage, "1 skipped") || !m.isStatusError {
+		t.Errorf("statusMessage = %q, isStatusError = %v", m.statusMessage, m.isStatusError)
+	}
+}
+
+// confirmDestination presses key (copy or move) and accepts the pre-filled
+// destination. It returns the model and the command that starts the operation.
+func confirmDestination(t *testing.T, m Model, key rune) (Model, tea.Cmd) {
+	t.Helper()
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*DestinationDialog); !ok {
+		t.Fatalf("dialog should be DestinationDialog, got %T", m.dialog)
+	}
+	updatedModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(Model)
+	if cmd == nil {
+		t.Fatal("Enter should confirm the destination")
+	}
+	updatedModel, cmd = m.Update(cmd())
+	return updatedModel.(Model), cmd
+}
+
+// setupPreflightModel returns a model whose left pane shows src and right pane shows dest
+func setupPreflightModel(t *testing.T, src, dest string) Model {
+	t.Helper()
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+	m.activePane = LeftPane
+	// Overwritten destinations go to the trash; keep them out of the user's
+	m.trash = trash.NewWithHome(filepath.Join(t.TempDir(), "Trash"))
+	if err := m.leftPane.ChangeDirectory(src); err != nil {
+		t.Fatalf("ChangeDirectory() error = %v", err)
+	}
+	if err := m.rightPane.ChangeDirectory(dest); err != nil {
+		t.Fatalf("ChangeDirectory() error = %v", err)
+	}
+	return m
+}
+
+func TestCopyPreflightRejectsDestinationInsideSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "project", "build"), 0755)
+	m := setupPreflightModel(t, tmpDir, filepath.Join(tmpDir, "project", "build"))
+	m.leftPane.MoveCursorDown() // project
+
+	m, cmd := confirmDestination(t, m, 'c')
+	if cmd == nil {
+		t.Fatal("copy should start the preflight")
+	}
+	updatedModel, _ := m.Update(cmd())
+	m = updatedModel.(Model)
+
+	errDialog, ok := m.dialog.(*ErrorDialog)
+	if !ok {
+		t.Fatalf("dialog should be ErrorDialog, got %T", m.dialog)
+	}
+	if !strings.Contains(errDialog.View(), "destination is inside the") {
+		t.Errorf("error should explain the problem, got %q", errDialog.View())
+	}
+	if m.jobs.ActiveCount() != 0 {
+		t.Error("no job should be started")
+	}
+}
+
+func TestCopyPreflightListsConflicts(t *testing.T) {
+	preflight := func(t *testing.T) (Model, string) {
+		srcDir := t.TempDir()
+		destDir := t.TempDir()
+		for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+			os.WriteFile(filepath.Join(srcDir, name), []byte("new"), 0644)
+		}
+		os.WriteFile(filepath.Join(destDir, "a.txt"), []byte("old"), 0644)
+		os.WriteFile(filepath.Join(destDir, "c.txt"), []byte("old"), 0644)
+
+		m := setupPreflightModel(t, srcDir, destDir)
+		for i := 0; i < 3; i++ {
+			m.leftPane.MoveCursorDown()
+			m.leftPane.ToggleMark()
+		}
+		m, cmd := confirmDestination(t, m, 'c')
+		updatedModel, _ := m.Update(cmd())
+		m = updatedModel.(Model)
+		dialog, ok := m.dialog.(*PreflightDialog)
+		if !ok {
+			t.Fatalf("dialog should be PreflightDialog, got %T", m.dialog)
+		}
+		if len(dialog.plan.Conflicts) != 2 {
+			t.Fatalf("Conflicts = %v, want a.txt and c.txt", dialog.plan.Conflicts)
+		}
+		return m, destDir
+	}
+
+	t.Run("skip existing", func(t *testing.T) {
+		m, destDir := preflight(t)
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+		updatedModel, cmd := m.Update(cmd())
+		m = updatedModel.(Model)
+		for _, msg := range collectBatchMsgs(cmd) {
+			if result, ok := msg.(batchOperationCompleteMsg); ok && result.count != 1 {
+				t.Errorf("count = %d, want 1", result.count)
+			}
+		}
+		if data, _ := os.ReadFile(filepath.Join(destDir, "a.txt")); string(data) != "old" {
+			t.Error("existing files should be kept")
+		}
+		if _, err := os.Stat(filepath.Join(destDir, "b.txt")); err != nil {
+			t.Error("files without conflict should be copied")
+		}
+	})
+
+	t.Run("overwrite all", func(t *testing.T) {
+		m, destDir := preflight(t)
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+		_, cmd = m.Update(cmd())
+		collectBatchMsgs(cmd)
+		for _, name := range []string{"a.txt", "c.txt"} {
+			if data, _ := os.ReadFile(filepath.Join(destDir, name)); string(data) != "new" {
+				t.Errorf("%s should be overwritten, got %q", name, data)
+			}
+		}
+	})
+}
+
+// resolveBatchConflict answers the overwrite dialog of a batch with key and
+// "apply to all remaining", and returns the messages of the resulting job.
+func resolveBatchConflict(t *testing.T, m Model, cmd tea.Cmd, key rune) (Model, []tea.Msg) {
+	t.Helper()
+	msg, ok := cmd().(showOverwriteDialogMsg)
+	if !ok {
+		t.Fatal("first conflict should show the overwrite dialog")
+	}
+	updatedModel, _ := m.Update(msg)
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*OverwriteDialog); !ok {
+		t.Fatalf("dialog should be OverwriteDialog, got %T", m.dialog)
+	}
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = updatedModel.(Model)
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+	updatedModel, cmd = m.Update(cmd())
+	return updatedModel.(Model), collectBatchMsgs(cmd)
+}
+
+func TestBatchConflictPolicies(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+
+	t.Run("overwrite if newer applies to all remaining", func(t *testing.T) {
+		src, dest := t.TempDir(), t.TempDir()
+		for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+			os.WriteFile(filepath.Join(src, name), []byte("new "+name), 0644)
+			os.WriteFile(filepath.Join(dest, name), []byte("old"), 0644)
+		}
+		// b.txt is newer at the destination and must be kept
+		os.Chtimes(filepath.Join(dest, "a.txt"), old, old)
+		os.Chtimes(filepath.Join(src, "b.txt"), old, old)
+		os.Chtimes(filepath.Join(dest, "c.txt"), old, old)
+
+		m := setupPreflightModel(t, src, dest)
+		sources := []string{filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt"), filepath.Join(src, "c.txt")}
+		m, msgs := resolveBatchConflict(t, m, m.startBatchOperation(sources, dest, "copy"), '5')
+
+		if _, asked := m.dialog.(*OverwriteDialog); asked || m.batchOp != nil {
+			t.Error("remaining conflicts should be resolved without asking")
+		}
+		var result *batchOperationCompleteMsg
+		for _, msg := range msgs {
+			if r, ok := msg.(batchOperationCompleteMsg); ok {
+				result = &r
+			}
+		}
+		if result == nil || result.count != 2 {
+			t.Fatalf("result = %+v, want 2 copied files", result)
+		}
+		for name, want := range map[string]string{"a.txt": "new a.txt", "b.txt": "old", "c.txt": "new c.txt"} {
+			if data, _ := os.ReadFile(filepath.Join(dest, name)); string(data) != want {
+				t.Errorf("%s = %q, want %q", name, data, want)
+			}
+		}
+	})
+
+	t.Run("skip keeps existing files", func(t *testing.T) {
+		src, dest := t.TempDir(), t.TempDir()
+		for _, name := range []string{"a.txt", "b.txt"} {
+			os.WriteFile(filepath.Join(src, name), []byte("new"), 0644)
+			os.WriteFile(filepath.Join(dest, name), []byte("old"), 0644)
+		}
+
+		m := setupPreflightModel(t, src, dest)
+		sources := []string{filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt")}
+		m, msgs := resolveBatchConflict(t, m, m.startBatchOperation(sources, dest, "move"), '4')
+
+		if m.batchOp != nil {
+			t.Error("batch should be finished")
+		}
+		for _, msg := range msgs {
+			if r, ok := msg.(batchOperationCompleteMsg); ok && r.count != 0 {
+				t.Errorf("count = %d, want 0", r.count)
+			}
+		}
+		for _, name := range []string{"a.txt", "b.txt"} {
+			if _, err := os.Stat(filepath.Join(src, name)); err != nil {
+				t.Errorf("skipped source %s should not be moved", name)
+			}
+		}
+	})
+
+	t.Run("cancelling later keeps a file chosen to be overwritten", func(t *testing.T) {
+		src, dest := t.TempDir(), t.TempDir()
+		for _, name := range []string{"a.txt", "b.txt"} {
+			os.WriteFile(filepath.Join(src, name), []byte("new"), 0644)
+			os.WriteFile(filepath.Join(dest, name), []byte("old"), 0644)
+		}
+
+		m := setupPreflightModel(t, src, dest)
+		sources := []string{filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt")}
+		cmd := m.startBatchOperation(sources, dest, "copy")
+		for _, key := range []rune{'1', '2'} { // Overwrite a.txt, then Cancel at b.txt
+			updatedModel, _ := m.Update(cmd())
+			m = updatedModel.(Model)
+			if _, ok := m.dialog.(*OverwriteDialog); !ok {
+				t.Fatalf("dialog should be OverwriteDialog, got %T", m.dialog)
+			}
+			_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+			updatedModel, cmd = m.Update(cmd())
+			m = updatedModel.(Model)
+		}
+		collectBatchMsgs(cmd)
+
+		if m.batchOp != nil {
+			t.Error("batch should be cancelled")
+		}
+		for _, name := range []string{"a.txt", "b.txt"} {
+			if data, _ := os.ReadFile(filepath.Join(dest, name)); string(data) != "old" {
+				t.Errorf("%s = %q, want the untouched %q", name, data, "old")
+			}
+		}
+	})
+
+	t.Run("backup keeps the existing file as name.~1~", func(t *testing.T) {
+		src, dest := t.TempDir(), t.TempDir()
+		for _, name := range []string{"a.txt", "b.txt"} {
+			os.WriteFile(filepath.Join(src, name), []byte("new"), 0644)
+			os.WriteFile(filepath.Join(dest, name), []byte("old"), 0644)
+		}
+
+		m := setupPreflightModel(t, src, dest)
+		sources := []string{filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt")}
+		_, _ = resolveBatchConflict(t, m, m.startBatchOperation(sources, dest, "copy"), '7')
+
+		for _, name := range []string{"a.txt", "b.txt"} {
+			if data, _ := os.ReadFile(filepath.Join(dest, name)); string(data) != "new" {
+				t.Errorf("%s = %q, want %q", name, data, "new")
+			}
+			if data, _ := os.ReadFile(filepath.Join(dest, name+".~1~")); string(data) != "old" {
+				t.Errorf("%s.~1~ = %q, want %q", name, data, "old")
+			}
+		}
+	})
+}
+
+func TestToggleVerify(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+	m.SetCopyConfig(config.DefaultCopyConfig())
+
+	for _, want := range []string{"sha256", "xxhash", "off"} {
+		updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'V'}})
+		m = updatedModel.(Model)
+		if m.statusMessage != "Verify after copy: "+want {
+			t.Errorf("statusMessage = %q, want verify %s", m.statusMessage, want)
+		}
+	}
+
+	// A verified copy completes normally
+	m.copyConfig.Verify = "sha256"
+	srcFile := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(srcFile, []byte("data"), 0644)
+	destDir := t.TempDir()
+	if msg, ok := m.executeFileOperation(srcFile, destDir, "copy")().(fileOperationCompleteMsg); !ok || len(msg.mismatched) != 0 {
+		t.Fatalf("verified copy should complete without mismatches, got %+v", msg)
+	}
+}
+
+func TestBatchSummaryReportsVerificationFailures(t *testing.T) {
+	model := NewModel()
+	updatedModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updatedModel.(Model)
+
+	updatedModel, _ = m.Update(batchOperationCompleteMsg{
+		operation:  "move",
+		count:      2,
+		mismatched: []string{"/src/c.bin: checksum mismatch (sha256)"},
+	})
+	m = updatedModel.(Model)
+	if !m.isStatusError || !strings.Contains(m.statusMessage, "Move 2 files completed, 1 failed verification") {
+		t.Errorf("statusMessage = %q, want verification failures", m.statusMessage)
+	}
+}
+
+// typeDestination replaces the destination dialog input with input and presses Enter
+// until the dialog is confirmed, then runs the resulting operation
+func typeDestination(t *testing.T, m Model, key rune, input string) Model {
+	t.Helper()
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+	m = updatedModel.(Model)
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(input)})
+
+	var cmd tea.Cmd
+	for i := 0; i < 2 && cmd == nil; i++ {
+		updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updatedModel.(Model)
+	}
+	if cmd == nil {
+		t.Fatal("destination should be confirmed")
+	}
+	updatedModel, cmd = m.Update(cmd())
+	m = updatedModel.(Model)
+	for _, msg := range collectBatchMsgs(cmd) {
+		if _, ok := msg.(preflightResultMsg); ok {
+			updatedModel, cmd = m.Update(msg)
+			m = updatedModel.(Model)
+			collectBatchMsgs(cmd)
+		}
+	}
+	return m
+}
+
+func TestCopyToEditedDestination(t *testing.T) {
+	t.Run("copy and rename in one step", func(t *testing.T) {
+		src, dest := t.TempDir(), t.TempDir()
+		os.WriteFile(filepath.Join(src, "a.txt"), []byte("data"), 0644)
+		m := setupPreflightModel(t, src, dest)
+		m.leftPane.MoveCursorDown()
+
+		typeDestination(t, m, 'c', filepath.Join(dest, "renamed.txt"))
+
+		if data, err := os.ReadFile(filepath.Join(dest, "renamed.txt")); err != nil || string(data) != "data" {
+			t.Errorf("renamed copy = %q, %v", data, err)
+		}
+	})
+
+	t.Run("relative path with missing directories", func(t *testing.T) {
+		src, dest := t.TempDir(), t.TempDir()
+		os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644)
+		os.WriteFile(filepath.Join(src, "b.txt"), []byte("b"), 0644)
+		m := setupPreflightModel(t, src, dest)
+		m.leftPane.MoveCursorDown()
+		m.leftPane.ToggleMark()
+		m.leftPane.MoveCursorDown()
+		m.leftPane.ToggleMark()
+
+		typeDestination(t, m, 'm', "backup/2026")
+
+		for _, name := range []string{"a.txt", "b.txt"} {
+			if _, err := os.Stat(filepath.Join(src, "backup", "2026", name)); err != nil {
+				t.Errorf("%s should be moved into the created directory: %v", name, err)
+			}
+			if _, err := os.Stat(filepath.Join(src, name)); !os.IsNotExist(err) {
+				t.Errorf("%s should be moved", name)
+			}
+		}
+	})
+
+	t.Run("renaming a directory into itself is rejected", func(t *testing.T) {
+		src, dest := t.TempDir(), t.TempDir()
+		os.Mkdir(filepath.Join(src, "dir"), 0755)
+		m := setupPreflightModel(t, src, dest)
+		m.leftPane.MoveCursorDown()
+
+		updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+		m = updatedModel.(Model)
+		cmd := m.startCopyMoveTo("copy", []string{filepath.Join(src, "dir")},
+			destinationTarget{Dir: filepath.Join(src, "dir"), NewName: "inner"})
+		msg, ok := cmd().(showErrorDialogMsg)
+		if !ok || !strings.Contains(msg.message, "inside the source") {
+			t.Errorf("expected an error dialog, got %+v", msg)
+		}
+	})
+}
+
+func TestFilteredCopyShowsMatchedCount(t *testing.T) {
+	src, dest := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(src, "project", "vendor"), 0755)
+	for _, name := range []string{"main.go", "README.md", "vendor/lib.go"} {
+		os.WriteFile(filepath.Join(src, "project", name), []byte(name), 0644)
+	}
+	m := setupPreflightModel(t, src, dest)
+	m.leftPane.MoveCursorDown() // project
+	m.opFilter, _ = fs.ParseFilter("*.go !vendor")
+
+	m, cmd := confirmDestination(t, m, 'c')
+	updatedModel, _ := m.Update(cmd())
+	m = updatedModel.(Model)
+	dialog, ok := m.dialog.(*PreflightDialog)
+	if !ok {
+		t.Fatalf("a filtered copy should show the preflight summary, got %T", m.dialog)
+	}
+	if !strings.Contains(dialog.View(), "1 of 3 files match") {
+		t.Errorf("summary should show the matched count:\n%s", dialog.View())
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updatedModel, cmd = m.Update(cmd())
+	m = updatedModel.(Model)
+	for _, msg := range collectBatchMsgs(cmd) {
+		if result, ok := msg.(fileOperationCompleteMsg); ok && result.entry != nil {
+			t.Error("filtered operations should not be recorded for undo")
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "project", "main.go")); err != nil {
+		t.Errorf("main.go should be copied: %v", err)
+	}
+	for _, name := range []string{"README.md", "vendor"} {
+		if _, err := os.Stat(filepath.Join(dest, "project", name)); !os.IsNotExist(err) {
+			t.Errorf("%s should be filtered out", name)
+		}
+	}
+}
+
+func TestFilteredCopyWithoutMatches(t *testing.T) {
+	src, dest := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644)
+	m := setupPreflightModel(t, src, dest)
+	m.leftPane.MoveCursorDown()
+	m.opFilter, _ = fs.ParseFilter("*.go")
+
+	m, cmd := confirmDestination(t, m, 'c')
+	updatedModel, _ := m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.dialog != nil || !strings.Contains(m.statusMessage, "No files match filter *.go") {
+		t.Errorf("dialog = %T, statusMessage = %q", m.dialog, m.statusMessage)
+	}
+}
+
+func TestFilteredDeleteKeepsDirectories(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "logs", "old"), 0755)
+	for _, name := range []string{"app.log", "old/app.1.log", "notes.txt"} {
+		os.WriteFile(filepath.Join(tmpDir, "logs", name), []byte(name), 0644)
+	}
+	m := setupPreflightModel(t, tmpDir, t.TempDir())
+	m.leftPane.MoveCursorDown() // logs
+	m.opFilter, _ = fs.ParseFilter("*.log")
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+	m = updatedModel.(Model)
+	if !strings.Contains(m.dialog.View(), "Only files matching *.log") {
+		t.Error("confirmation should mention the filter")
+	}
+
+	result, ok := m.executeDeleteOperation(true)().(batchOperationCompleteMsg)
+	if !ok || result.count != 2 {
+		t.Fatalf("result = %+v, want 2 deleted files", result)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "logs", "notes.txt")); err != nil {
+		t.Error("notes.txt should not be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "logs", "old")); err != nil {
+		t.Error("directories should be kept")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "logs", "old", "app.1.log")); !os.IsNotExist(err) {
+		t.Error("matching files in subdirectories should be deleted")
+	}
+}
+
+func TestSyncDirectoriesWorkflow(t *testing.T) {
+	left, right := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(left, "docs"), 0755)
+	os.WriteFile(filepath.Join(left, "docs", "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(right, "extra.txt"), []byte("x"), 0644)
+	m := setupPreflightModel(t, left, right)
+	m.trash = trash.NewWithHome(filepath.Join(t.TempDir(), "Trash"))
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	m = updatedModel.(Model)
+	dialog, ok := m.dialog.(*SyncDialog)
+	if !ok {
+		t.Fatalf("dialog should be SyncDialog, got %T", m.dialog)
+	}
+	dialog.deleteExtraneous = true
+
+	// Enter requests the dry run, which the model computes in a command
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updatedModel, cmd = m.Update(cmd())
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if len(dialog.actions) != 2 {
+		t.Fatalf("plan = %+v, want copy of docs and delete of extra.txt", dialog.actions)
+	}
+	if _, err := os.Stat(filepath.Join(right, "docs")); !os.IsNotExist(err) {
+		t.Fatal("the dry run should not change files")
+	}
+
+	_, cmd = dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updatedModel, cmd = m.Update(cmd())
+	m = updatedModel.(Model)
+	var result tea.Msg
+	for _, msg := range collectBatchMsgs(cmd) {
+		if r, ok := msg.(batchOperationCompleteMsg); ok {
+			result = r
+		}
+	}
+	if result == nil {
+		t.Fatal("expected batchOperationCompleteMsg")
+	}
+	if _, err := os.Stat(filepath.Join(right, "docs", "a.txt")); err != nil {
+		t.Errorf("docs/a.txt should be copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(right, "extra.txt")); !os.IsNotExist(err) {
+		t.Error("extra.txt should be removed")
+	}
+
+	if items, _ := m.trash.List(); len(items) != 1 {
+		t.Errorf("extraneous entries should go to the trash, got %d items", len(items))
+	}
+
+	updatedModel, _ = m.Update(result)
+	m = updatedModel.(Model)
+	if m.statusMessage != "Sync 2 files completed" {
+		t.Errorf("status message = %q", m.statusMessage)
+	}
+}
+
+func TestCompareDirectoriesMarksDifferences(t *testing.T) {
+	left, right := t.TempDir(), t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	for _, dir := range []string{left, right} {
+		os.WriteFile(filepath.Join(dir, "same.txt"), []byte("same"), 0644)
+		os.Chtimes(filepath.Join(dir, "same.txt"), now, now)
+		os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	}
+	os.WriteFile(filepath.Join(left, "only-left.txt"), []byte("l"), 0644)
+	os.WriteFile(filepath.Join(right, "sub", "deep.txt"), []byte("d"), 0644)
+	m := setupPreflightModel(t, left, right)
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*CompareDialog); !ok {
+		t.Fatalf("dialog should be CompareDialog, got %T", m.dialog)
+	}
+
+	updatedModel, cmd := m.Update(compareRequestMsg{mode: fs.CompareBySizeTime, recursive: true})
+	m = updatedModel.(Model)
+	if m.dialog != nil {
+		t.Error("dialog should close while comparing")
+	}
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+
+	if got := m.leftPane.GetMarkedFiles(); len(got) != 1 || got[0] != "only-left.txt" {
+		t.Errorf("left marks = %v, want only-left.txt", got)
+	}
+	if got := m.rightPane.GetMarkedFiles(); len(got) != 1 || got[0] != "sub" {
+		t.Errorf("right marks = %v, want sub", got)
+	}
+	if m.statusMessage != "2 differences: marked 1 left, 1 right" {
+		t.Errorf("status message = %q", m.statusMessage)
+	}
+
+	m.statusMessage = ""
+	if bar := m.renderStatusBar(); !strings.Contains(bar, "cmp[size+mtime,recursive]: <only 1  only> 1") {
+		t.Errorf("status bar should show the compare legend: %s", bar)
+	}
+
+	// The legend goes away once a pane leaves the compared directory
+	m.leftPane.ChangeDirectory(filepath.Join(left, "sub"))
+	if legend := m.compareLegend(); legend != "" {
+		t.Errorf("legend = %q, want none", legend)
+	}
+}
+
+func TestFindFilesWorkflow(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(root, "src", "pkg"), 0755)
+	os.WriteFile(filepath.Join(root, "src", "pkg", "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(root, "src", "notes.txt"), []byte("notes"), 0644)
+	os.WriteFile(filepath.Join(root, "util.go"), []byte("package util"), 0644)
+	m := setupPreflightModel(t, root, other)
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*FindDialog); !ok {
+		t.Fatalf("dialog should be FindDialog, got %T", m.dialog)
+	}
+
+	criteria, _ := fs.ParseFindQuery("*.go", time.Now())
+	updatedModel, cmd := m.Update(findRequestMsg{criteria: criteria, query: "*.go"})
+	m = updatedModel.(Model)
+	if m.dialog != nil || !m.leftPane.IsVirtual() {
+		t.Fatal("the find should close the dialog and show a virtual listing")
+	}
+	for cmd != nil && m.find != nil {
+		updatedModel, cmd = m.Update(cmd())
+		m = updatedModel.(Model)
+	}
+
+	var names []string
+	for _, e := range m.leftPane.entries[1:] {
+		names = append(names, e.Name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != filepath.Join("src", "pkg", "main.go") || names[1] != "util.go" {
+		t.Errorf("results = %v", names)
+	}
+	if m.statusMessage != "Found 2 matches" {
+		t.Errorf("status message = %q", m.statusMessage)
+	}
+
+	// Enter jumps to the directory of the result with the cursor on it
+	m.leftPane.cursor = m.leftPane.findEntryIndex(filepath.Join("src", "pkg", "main.go"))
+	updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.leftPane.IsVirtual() || m.leftPane.Path() != filepath.Join(root, "src", "pkg") {
+		t.Errorf("pane should be in src/pkg, got %s (virtual %v)", m.leftPane.Path(), m.leftPane.IsVirtual())
+	}
+	if entry := m.leftPane.SelectedEntry(); entry == nil || entry.Name != "main.go" {
+		t.Errorf("cursor should be on main.go, got %v", entry)
+	}
+}
+
+func TestFindFilesCancelAndLeave(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0644)
+	m := setupPreflightModel(t, root, other)
+
+	criteria, _ := fs.ParseFindQuery("a", time.Now())
+	updatedModel, cmd := m.Update(findRequestMsg{criteria: criteria, query: "a"})
+	m = updatedModel.(Model)
+
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updatedModel.(Model)
+	if m.find != nil || !strings.HasPrefix(m.statusMessage, "Find cancelled") {
+		t.Errorf("Esc should cancel the find, status %q", m.statusMessage)
+	}
+	if !m.leftPane.IsVirtual() {
+		t.Error("cancelling should keep the results shown")
+	}
+
+	// Results of the cancelled find are dropped
+	before := len(m.leftPane.allEntries)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if len(m.leftPane.allEntries) != before {
+		t.Error("results of a cancelled find should be ignored")
+	}
+
+	// h on the left pane returns to the directory
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	m = updatedModel.(Model)
+	if m.leftPane.IsVirtual() || m.leftPane.Path() != root || m.leftPane.findEntryIndex("a.txt") < 0 {
+		t.Errorf("h should leave the results, path %s", m.leftPane.Path())
+	}
+}
+
+func TestGrepJumpsToFile(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(root, "pkg"), 0755)
+	os.WriteFile(filepath.Join(root, "pkg", "z.go"), []byte("needle\n"), 0644)
+	os.WriteFile(filepath.Join(root, "pkg", "a.go"), []byte("hay\n"), 0644)
+	m := setupPreflightModel(t, root, other)
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*GrepDialog); !ok {
+		t.Fatalf("dialog should be GrepDialog, got %T", m.dialog)
+	}
+
+	for _, r := range "needle" {
+		updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updatedModel.(Model)
+	}
+	updatedModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(Model)
+	for cmd != nil {
+		updatedModel, cmd = m.Update(cmd())
+		m = updatedModel.(Model)
+	}
+
+	updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(Model)
+	updatedModel, cmd = m.Update(cmd()) // grepCloseMsg
+	m = updatedModel.(Model)
+	if m.dialog != nil || m.grepSettings.pattern != "needle" {
+		t.Fatalf("dialog should close and keep the settings, dialog %T", m.dialog)
+	}
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.leftPane.Path() != filepath.Join(root, "pkg") {
+		t.Errorf("pane path = %s, want pkg", m.leftPane.Path())
+	}
+	if entry := m.leftPane.SelectedEntry(); entry == nil || entry.Name != "z.go" {
+		t.Errorf("cursor should be on z.go, got %v", entry)
+	}
+}
+
+func TestFuzzyFindMovesToFile(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(root, "pkg", "sub"), 0755)
+	os.WriteFile(filepath.Join(root, "pkg", "sub", "alpha.go"), nil, 0644)
+	os.WriteFile(filepath.Join(root, "pkg", "sub", "zeta.go"), nil, 0644)
+	os.WriteFile(filepath.Join(root, "readme.md"), nil, 0644)
+	m := setupPreflightModel(t, root, other)
+
+	// run drains the commands the model returns
+	run := func(cmd tea.Cmd) {
+		queue := []tea.Cmd{cmd}
+		for len(queue) > 0 {
+			cmd, queue = queue[0], queue[1:]
+			if cmd == nil {
+				continue
+			}
+			msg := cmd()
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				queue = append(queue, batch...)
+				continue
+			}
+			updatedModel, next := m.Update(msg)
+			m = updatedModel.(Model)
+			queue = append(queue, next)
+		}
+	}
+	press := func(msg tea.KeyMsg) {
+		updatedModel, cmd := m.Update(msg)
+		m = updatedModel.(Model)
+		run(cmd)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if _, ok := m.dialog.(*FuzzyFinderDialog); !ok {
+		t.Fatalf("dialog should be FuzzyFinderDialog, got %T", m.dialog)
+	}
+	for _, r := range "zeta" {
+		press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.dialog != nil {
+		t.Fatalf("dialog should close, got %T", m.dialog)
+	}
+	if m.leftPane.Path() != filepath.Join(root, "pkg", "sub") {
+		t.Errorf("pane path = %s, want pkg/sub", m.leftPane.Path())
+	}
+	if entry := m.leftPane.SelectedEntry(); entry == nil || entry.Name != "zeta.go" {
+		t.Errorf("cursor should be on zeta.go, got %v", entry)
+	}
+}
+
+func TestJumpToVisitedDirectory(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	target := filepath.Join(root, "projects", "api")
+	os.MkdirAll(target, 0755)
+	m := setupPreflightModel(t, root, other)
+
+	// Visits are recorded as the panes navigate
+	if err := m.leftPane.ChangeDirectory(target); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.leftPane.ChangeDirectory(root); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.dirVisits.Query([]string{"proj", "api"}); len(got) != 1 || got[0].Path != target {
+		t.Fatalf("visits for 'proj api' = %+v", got)
+	}
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*JumpDialog); !ok {
+		t.Fatalf("dialog should be JumpDialog, got %T", m.dialog)
+	}
+	for _, r := range "api" {
+		updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updatedModel.(Model)
+	}
+	updatedModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(Model)
+	updatedModel, cmd = m.Update(cmd()) // jumpSelectMsg
+	m = updatedModel.(Model)
+	if m.dialog != nil {
+		t.Fatalf("dialog should close, got %T", m.dialog)
+	}
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.leftPane.Path() != target {
+		t.Errorf("pane path = %s, want %s", m.leftPane.Path(), target)
+	}
+}
+
+func TestHistoryDialogJumpsSeveralSteps(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	var dirs []string
+	for _, name := range []string{"a", "b", "c", "d"} {
+		dir := filepath.Join(root, name)
+		os.MkdirAll(dir, 0755)
+		dirs = append(dirs, dir)
+	}
+	m := setupPreflightModel(t, root, other)
+	for _, dir := range dirs {
+		if err := m.leftPane.ChangeDirectory(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+	m = updatedModel.(Model)
+	dialog, ok := m.dialog.(*HistoryDialog)
+	if !ok {
+		t.Fatalf("dialog should be HistoryDialog, got %T", m.dialog)
+	}
+	// root, a, b, c, d: go back four steps to the start
+	for i := 0; i < 4; i++ {
+		dialog.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	updatedModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+
+	if m.dialog != nil || m.leftPane.Path() != root {
+		t.Fatalf("pane path = %s, want %s (dialog %T)", m.leftPane.Path(), root, m.dialog)
+	}
+	// The forward history is intact
+	updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}})
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.leftPane.Path() != dirs[0] {
+		t.Errorf("forward after the jump = %s, want %s", m.leftPane.Path(), dirs[0])
+	}
+}
+
+func TestTabActionsAndSession(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(root, "sub"), 0755)
+	m := setupPreflightModel(t, root, other)
+	m.leftPane.MoveCursorDown() // sub
+
+	// Ctrl+T opens the directory under the cursor in a new tab
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = updatedModel.(Model)
+	if m.leftPane.TabCount() != 2 || m.leftPane.Path() != filepath.Join(root, "sub") {
+		t.Fatalf("after Ctrl+T: %d tabs, path %s", m.leftPane.TabCount(), m.leftPane.Path())
+	}
+
+	// Tab cycles back to the first tab, with the cursor where it was
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updatedModel.(Model)
+	if m.leftPane.Path() != root || m.leftPane.SelectedEntry().Name != "sub" {
+		t.Fatalf("after Tab: path %s, cursor on %v", m.leftPane.Path(), m.leftPane.SelectedEntry())
+	}
+
+	// The tabs are saved on exit and reopened by the next model
+	sessionPath := filepath.Join(t.TempDir(), "session.json")
+	m.SetSession(sessionPath, nil)
+	if err := m.SaveSession(); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	state, err := session.Load(sessionPath)
+	if err != nil || state == nil {
+		t.Fatalf("session.Load() = %v, %v", state, err)
+	}
+
+	next := NewModel()
+	next.SetSession(sessionPath, state)
+	updatedModel, _ = next.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	next = updatedModel.(Model)
+	if got := next.leftPane.TabPaths(); len(got) != 2 || got[1] != filepath.Join(root, "sub") || next.leftPane.ActiveTab() != 0 {
+		t.Errorf("restored left tabs = %v (active %d)", got, next.leftPane.ActiveTab())
+	}
+	if next.rightPane.Path() != other {
+		t.Errorf("restored right pane = %s, want %s", next.rightPane.Path(), other)
+	}
+
+	// Closing the last tab is refused
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	m = updatedModel.(Model)
+	if m.leftPane.TabCount() != 1 || m.statusMessage == "" {
+		t.Errorf("closing the last tab should be refused, %d tabs, status %q", m.leftPane.TabCount(), m.statusMessage)
+	}
+}
+
+func TestNamedSessionSaveAndLoad(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	sub := filepath.Join(root, "sub")
+	os.MkdirAll(sub, 0755)
+	m := setupPreflightModel(t, root, other)
+	m.SetSession(filepath.Join(t.TempDir(), "session.json"), nil)
+	m.switchToPane(RightPane)
+
+	// Shift+W, n, name, Enter saves the current layout
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'W'}})
+	m = updatedModel.(Model)
+	if _, ok := m.dialog.(*SessionDialog); !ok {
+		t.Fatalf("dialog should be SessionDialog, got %T", m.dialog)
+	}
+	updatedModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	input, ok := m.dialog.(*InputDialog)
+	if !ok {
+		t.Fatalf("dialog should be InputDialog, got %T", m.dialog)
+	}
+	typeText(input, "work")
+	updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+	if m.statusMessage != "Session saved: work" {
+		t.Fatalf("status = %q", m.statusMessage)
+	}
+
+	// Move away, then load the session back
+	if err := m.rightPane.ChangeDirectory(sub); err != nil {
+		t.Fatal(err)
+	}
+	m.switchToPane(LeftPane)
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'W'}})
+	m = updatedModel.(Model)
+	updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(Model)
+	updatedModel, _ = m.Update(cmd())
+	m = updatedModel.(Model)
+
+	if m.dialog != nil || m.rightPane.Path() != other || m.activePane != RightPane {
+		t.Errorf("after loading: right %s, active %v, dialog %T", m.rightPane.Path(), m.activePane, m.dialog)
+	}
+	if !m.rightPane.isActive || m.leftPane.isActive {
+		t.Error("the restored active pane should be highlighted")
+	}
+}
+
+func TestStartOptions(t *testing.T) {
+	left, right, restored := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.txt", "big.txt", ".hidden"} {
+		os.WriteFile(filepath.Join(left, name), []byte(name), 0644)
+	}
+	os.WriteFile(filepath.Join(left, "big.txt"), make([]byte, 4096), 0644)
+	saved := &session.State{
+		Left:       session.Pane{Tabs: []session.Tab{{Path: restored}}},
+		Right:      session.Pane{Tabs: []session.Tab{{Path: restored}}},
+		ActivePane: "right",
+	}
+
+	t.Run("directories replace the previous session", func(t *testing.T) {
+		m := NewModel()
+		sessionPath := filepath.Join(t.TempDir(), "session.json")
+		m.SetSession(sessionPath, saved)
+		sortConfig := SortConfig{Field: SortBySize, Order: SortDesc}
+		m.SetStartOptions(StartOptions{LeftPath: left, RightPath: right, ShowHidden: true, Sort: &sortConfig})
+		updatedModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = updatedModel.(Model)
+
+		if m.leftPane.Path() != left || m.rightPane.Path() != right || m.activePane != LeftPane {
+			t.Fatalf("panes = %s, %s (active %v)", m.leftPane.Path(), m.rightPane.Path(), m.activePane)
+		}
+		if !m.leftPane.IsShowingHidden() || !m.rightPane.IsShowingHidden() {
+			t.Error("hidden files should be shown in both panes")
+		}
+		if m.rightPane.GetSortConfig() != sortConfig {
+			t.Errorf("right sort = %v", m.rightPane.GetSortConfig())
+		}
+		if entries := m.leftPane.entries; len(entries) < 2 || entries[1].Name != "big.txt" {
+			t.Errorf("left entries are not sorted by size: %v", entries)
+		}
+
+		// The session that was not restored is not overwritten either
+		if err := m.SaveSession(); err != nil {
+			t.Fatalf("SaveSession() error = %v", err)
+		}
+		if _, err := os.Stat(sessionPath); !os.IsNotExist(err) {
+			t.Errorf("SaveSession() should keep the previous session, Stat() error = %v", err)
+		}
+	})
+
+	t.Run("select opens the directory of the file", func(t *testing.T) {
+		m := NewModel()
+		m.SetSession(filepath.Join(t.TempDir(), "session.json"), saved)
+		m.SetStartOptions(StartOptions{SelectFile: filepath.Join(left, ".hidden")})
+		updatedModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = updatedModel.(Model)
+
+		if m.leftPane.Path() != left || m.activePane != LeftPane {
+			t.Fatalf("left pane = %s (active %v)", m.leftPane.Path(), m.activePane)
+		}
+		if entry := m.leftPane.SelectedEntry(); entry == nil || entry.Name != ".hidden" {
+			t.Errorf("cursor on %v, want .hidden", entry)
+		}
+	})
+
+	t.Run("without directories the session is restored", func(t *testing.T) {
+		m := NewModel()
+		m.SetSession(filepath.Join(t.TempDir(), "session.json"), saved)
+		m.SetStartOptions(StartOptions{ShowHidden: true})
+		updatedModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = updatedModel.(Model)
+
+		if m.leftPane.Path() != restored || m.activePane != RightPane || !m.leftPane.IsShowingHidden() {
+			t.Errorf("left pane = %s (active %v, hidden %v)", m.leftPane.Path(), m.activePane, m.leftPane.IsShowingHidden())
+		}
+	})
+
+	t.Run("sort and hidden apply to every restored tab", func(t *testing.T) {
+		m := NewModel()
+		tabs := &session.State{
+			Left:  session.Pane{Tabs: []session.Tab{{Path: restored}, {Path: left}}},
+			Right: session.Pane{Tabs: []session.Tab{{Path: restored}}},
+		}
+		m.SetSession(filepath.Join(t.TempDir(), "session.json"), tabs)
+		sortConfig := SortConfig{Field: SortBySize, Order: SortDesc}
+		m.SetStartOptions(StartOptions{ShowHidden: true, Sort: &sortConfig})
+		updatedModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = updatedModel.(Model)
+
+		if m.leftPane.TabCount() != 2 {
+			t.Fatalf("TabCount() = %d, want 2", m.leftPane.TabCount())
+		}
+		m.leftPane.SwitchTab(1 - m.leftPane.ActiveTab())
+		if m.leftPane.Path() != left {
+			t.Fatalf("switched to %s, want %s", m.leftPane.Path(), left)
+		}
+		if !m.leftPane.IsShowingHidden() || m.leftPane.GetSortConfig() != sortConfig {
+			t.Errorf("other tab: hidden %v, sort %v", m.leftPane.IsShowingHidden(), m.leftPane.GetSortConfig())
+		}
+		if m.leftPane.findEntryIndex(".hidden") < 0 {
+			t.Error("other tab should list hidden files")
+		}
+		if entries := m.leftPane.entries; len(entries) < 2 || entries[1].Name != "big.txt" {
+			t.Errorf("other tab entries are not sorted by size: %v", entries)
+		}
+	})
+}
+
+func TestChooserMode(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(root, "sub"), 0755)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		os.WriteFile(filepath.Join(root, name), []byte(name), 0644)
+	}
+
+	choose := func(m Model, key tea.KeyMsg) Model {
+		t.Helper()
+		updatedModel, cmd := m.Update(key)
+		m = updatedModel.(Model)
+		if m.Chosen() != nil {
+			if cmd == nil {
+				t.Fatal("choosing should quit")
+			}
+			if _, ok := cmd().(tea.QuitMsg); !ok {
+				t.Fatal("choosing should quit")
+			}
+		}
+		return m
+	}
+
+	t.Run("enter on a directory still navigates", func(t *testing.T) {
+		m := setupPreflightModel(t, root, other)
+		m.SetChooseMode(ChooseFiles)
+		m.leftPane.MoveCursorDown() // sub
+		m = choose(m, tea.KeyMsg{Type: tea.KeyEnter})
+		if m.Chosen() != nil {
+			t.Errorf("Chosen() = %v, want nil", m.Chosen())
+		}
+	})
+
+	t.Run("enter on a file chooses the marked files", func(t *testing.T) {
+		m := setupPreflightModel(t, root, other)
+		m.SetChooseMode(ChooseFiles)
+		m.leftPane.MoveCursorDown()
+		m.leftPane.MoveCursorDown() // a.txt
+		m.leftPane.MarkFiles([]string{"c.txt", "b.txt"})
+		m = choose(m, tea.KeyMsg{Type: tea.KeyEnter})
+		want := []string{filepath.Join(root, "b.txt"), filepath.Join(root, "c.txt")}
+		if !reflect.DeepEqual(m.Chosen(), want) {
+			t.Errorf("Chosen() = %v, want %v", m.Chosen(), want)
+		}
+	})
+
+	t.Run("ctrl+o chooses the entry under the cursor", func(t *testing.T) {
+		m := setupPreflightModel(t, root, other)
+		m.SetChooseMode(ChooseFiles)
+		m.leftPane.MoveCursorDown() // sub
+		m = choose(m, tea.KeyMsg{Type: tea.KeyCtrlO})
+		if want := []string{filepath.Join(root, "sub")}; !reflect.DeepEqual(m.Chosen(), want) {
+			t.Errorf("Chosen() = %v, want %v", m.Chosen(), want)
+		}
+	})
+
+	t.Run("choose dir returns the current directory", func(t *testing.T) {
+		m := setupPreflightModel(t, root, other)
+		m.SetChooseMode(ChooseDir)
+		m.switchToPane(RightPane)
+		m = choose(m, tea.KeyMsg{Type: tea.KeyCtrlO})
+		if want := []string{other}; !reflect.DeepEqual(m.Chosen(), want) {
+			t.Errorf("Chosen() = %v, want %v", m.Chosen(), want)
+		}
+	})
+
+	t.Run("the session is not saved", func(t *testing.T) {
+		m := setupPreflightModel(t, root, other)
+		sessionPath := filepath.Join(t.TempDir(), "session.json")
+		m.SetSession(sessionPath, nil)
+		m.SetChooseMode(ChooseDir)
+		if err := m.SaveSession(); err != nil {
+			t.Fatalf("SaveSession() error = %v", err)
+		}
+		if _, err := os.Stat(sessionPath); !os.IsNotExist(err) {
+			t.Errorf("SaveSession() in chooser mode should keep the previous session, Stat() error = %v", err)
+		}
+	})
+
+	t.Run("ctrl+o outside chooser mode", func(t *testing.T) {
+		m := setupPreflightModel(t, root, other)
+		m = choose(m, tea.KeyMsg{Type: tea.KeyCtrlO})
+		if m.Chosen() != nil || !m.isStatusError {
+			t.Errorf("Chosen() = %v, status %q", m.Chosen(), m.statusMessage)
+		}
+	})
+}
+
+func TestLastDirectory(t *testing.T) {
+	root, other := t.TempDir(), t.TempDir()
+	if dir := NewModel().LastDirectory(); dir == "" {
+		t.Error("LastDirectory() before the panes exist should be the start directory")
+	}
+
+	m := setupPreflightModel(t, root, other)
+	if m.LastDirectory() != root {
+		t.Errorf("LastDirectory() = %s, want %s", m.LastDirectory(), root)
+	}
+	m.switchToPane(RightPane)
+	if m.LastDirectory() != other {
+		t.Errorf("LastDirectory() = %s, want %s", m.LastDirectory(), other)
+	}
+}
+
+func TestUndoAfterMergeKeepsExistingFiles(t *testing.T) {
+	left, right := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(left, "proj"), 0755)
+	os.WriteFile(filepath.Join(left, "proj", "new.txt"), []byte("new"), 0644)
+	os.WriteFile(filepath.Join(left, "fresh.txt"), []byte("fresh"), 0644)
+	os.MkdirAll(filepath.Join(right, "proj"), 0755)
+	precious := filepath.Join(right, "proj", "precious.txt")
+	os.WriteFile(precious, []byte("keep"), 0644)
+
+	undoLast := func(m Model) Model {
+		t.Helper()
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+		updatedModel, _ := m.Update(cmd())
+		return updatedModel.(Model)
+	}
+
+	t.Run("single copy into an existing directory", func(t *testing.T) {
+		m := setupPreflightModel(t, left, right)
+		m.undoJournal = undo.NewJournal()
+		item := fileOperationItem{Src: filepath.Join(left, "proj"), Dest: right}
+		updatedModel, _ := m.Update(m.executeFileOperationItem(item, "copy")())
+		m = updatedModel.(Model)
+		if _, err := os.Stat(filepath.Join(right, "proj", "new.txt")); err != nil {
+			t.Fatal("the directories should be merged")
+		}
+		if m.undoJournal.CanUndo() {
+			t.Error("a merge should not be journalled")
+		}
+		undoLast(m)
+		if _, err := os.Stat(precious); err != nil {
+			t.Errorf("undo removed a file that existed before the copy: %v", err)
+		}
+	})
+
+	t.Run("batch undoes only what it created", func(t *testing.T) {
+		m := setupPreflightModel(t, left, right)
+		m.undoJournal = undo.NewJournal()
+		items := []fileOperationItem{
+			{Src: filepath.Join(left, "proj"), Dest: right},
+			{Src: filepath.Join(left, "fresh.txt"), Dest: right},
+		}
+		updatedModel, _ := m.Update(m.executeBatchOperation(items, right, "copy")())
+		m = updatedModel.(Model)
+		if !m.undoJournal.CanUndo() {
+			t.Fatal("the new file should be journalled")
+		}
+		undoLast(m)
+		if _, err := os.Stat(filepath.Join(right, "fresh.txt")); !os.IsNotExist(err) {
+			t.Error("undo should remove the copied file")
+		}
+		if _, err := os.Stat(precious); err != nil {
+			t.Errorf("undo removed a file that existed before the copy: %v", err)
+		}
+	})
+}
+
+func TestUndoOverwriteRestoresReplacedFile(t *testing.T) {
+	undoLast := func(m Model) Model {
+		t.Helper()
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+		updatedModel, _ := m.Update(cmd())
+		return updatedModel.(Model)
+	}
+	setup := func(t *testing.T) (Model, string) {
+		left, right := t.TempDir(), t.TempDir()
+		os.WriteFile(filepath.Join(left, "a.txt"), []byte("new"), 0644)
+		os.WriteFile(filepath.Join(right, "a.txt"), []byte("old"), 0644)
+		m := setupPreflightModel(t, left, right)
+		m.undoJournal = undo.NewJournal()
+		m.undoJournal.SetTrash(m.trash)
+		return m, right
+	}
+
+	for _, item := range []fileOperationItem{{Replace: true}, {Backup: true}} {
+		t.Run(fmt.Sprintf("replace=%v backup=%v", item.Replace, item.Backup), func(t *testing.T) {
+			m, right := setup(t)
+			item.Src, item.Dest = filepath.Join(m.leftPane.Path(), "a.txt"), right
+			updatedModel, _ := m.Update(m.executeFileOperationItem(item, "copy")())
+			m = updatedModel.(Model)
+			if data, _ := os.ReadFile(filepath.Join(right, "a.txt")); string(data) != "new" {
+				t.Fatalf("destination = %q, want the copied file", data)
+			}
+
+			undoLast(m)
+			if data, _ := os.ReadFile(filepath.Join(right, "a.txt")); string(data) != "old" {
+				t.Errorf("undo should restore the replaced file, got %q", data)
+			}
+		})
+	}
+
+	t.Run("without a trash undo is refused", func(t *testing.T) {
+		m, right := setup(t)
+		m.trash = nil
+		item := fileOperationItem{Src: filepath.Join(m.leftPane.Path(), "a.txt"), Dest: right, Replace: true}
+		updatedModel, _ := m.Update(m.executeFileOperationItem(item, "copy")())
+		m = updatedModel.(Model)
+
+		m = undoLast(m)
+		if data, _ := os.ReadFile(filepath.Join(right, "a.txt")); string(data) != "new" {
+			t.Errorf("a refused undo should keep the copied file, got %q", data)
+		}
+		if !m.isStatusError || !strings.Contains(m.statusMessage, "not kept") {
+			t.Errorf("statusMessage = %q, want the reason undo was refused", m.statusMessage)
+		}
+	})
+}
@@ -1,13 +1,21 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sakura/duofm/internal/archive"
+	"github.com/sakura/duofm/internal/config"
+	"github.com/sakura/duofm/internal/fs"
+	"github.com/sakura/duofm/internal/jobs"
+	"github.com/sakura/duofm/internal/session"
+	"github.com/sakura/duofm/internal/undo"
 )
 
 // Update はメッセージを処理してモデルを更新
@@ -39,6 +47,21 @@ func (m Model) handleCustomMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
 		return newModel, cmd, true
 	}
 
+	// フィルタ関連メッセージ
+	if newModel, cmd, handled := m.handleFilterMessages(msg); handled {
+		return newModel, cmd, true
+	}
+
+	// 名前付きセッション関連メッセージ
+	if newModel, cmd, handled := m.handleSessionMessages(msg); handled {
+		return newModel, cmd, true
+	}
+
+	// ゴミ箱関連メッセージ
+	if newModel, cmd, handled := m.handleTrashMessages(msg); handled {
+		return newModel, cmd, true
+	}
+
 	// アーカイブ関連メッセージ
 	if newModel, cmd, handled := m.handleArchiveMessages(msg); handled {
 		return newModel, cmd, true
@@ -68,22 +91,11 @@ func (m Model) handleContextMenuResult(msg tea.Msg) (Model, tea.Cmd, bool) {
 	activePane := m.getActivePane()
 	markedFiles := activePane.GetMarkedFiles()
 
-	// 削除の場合は確認ダイアログを表示
+	// 削除の場合はゴミ箱への移動の確認ダイアログを表示
 	if result.actionID == "delete" {
-		if len(markedFiles) > 0 {
-			m.dialog = NewConfirmDialog(
-				fmt.Sprintf("Delete %d files?", len(markedFiles)),
-				"This action cannot be undone.",
-			)
-		} else {
-			entry := activePane.SelectedEntry()
-			if entry != nil && !entry.IsParentDir() {
-				m.pendingAction = result.action
-				m.dialog = NewConfirmDialog(
-					"Delete file?",
-					entry.DisplayName(),
-				)
-			}
+		m.showDeleteConfirm(false)
+		if len(markedFiles) == 0 && m.dialog != nil {
+			m.pendingAction = result.action
 		}
 		return m, nil, true
 	}
@@ -107,15 +119,7 @@ func (m Model) handleContextMenuResult(msg tea.Msg) (Model, tea.Cmd, bool) {
 
 	// コピー/移動の場合
 	if result.actionID == "copy" || result.actionID == "move" {
-		if len(markedFiles) > 0 {
-			return m, m.startBatchOperation(markedFiles, result.actionID), true
-		}
-		entry := activePane.SelectedEntry()
-		if entry != nil && !entry.IsParentDir() {
-			srcPath := filepath.Join(activePane.Path(), entry.Name)
-			destPath := m.getInactivePane().Path()
-			return m, m.checkFileConflict(srcPath, destPath, result.actionID), true
-		}
+		m.showDestinationDialog(result.actionID)
 		return m, nil, true
 	}
 
@@ -349,21 +353,15 @@ func (m Model) handleOverwriteDialogResult(msg tea.Msg) (Model, tea.Cmd, bool) {
 
 	m.dialog = nil
 
+	if result.applyToAll && m.batchOp != nil {
+		m.batchOp.Policy = conflictPolicyFor(result.choice)
+	}
+
 	switch result.choice {
-	case OverwriteChoiceOverwrite:
-		destFile := filepath.Join(result.destPath, result.filename)
-		if err := removeAllFiles(destFile); err != nil {
-			if isPermissionError(err) {
-				m.dialog = NewErrorDialog("Permission denied: cannot remove existing file")
-			} else {
-				m.dialog = NewErrorDialog(fmt.Sprintf("Failed to remove: %v", err))
-			}
-			if m.batchOp != nil {
-				m.cancelBatchOperation()
-			}
-			return m, nil, true
-		}
-		return m, m.executeFileOperation(result.srcPath, result.destPath, result.operation), true
+	case OverwriteChoiceOverwrite, OverwriteChoiceSkip, OverwriteChoiceNewer, OverwriteChoiceSizeDiffers, OverwriteChoiceBackup:
+		// 上書きする既存のファイルはジョブの中で削除する（キャンセルしても残る）
+		cmd := m.resolveConflict(conflictPolicyFor(result.choice), result.srcPath, result.destPath, result.srcInfo, result.destInfo, result.operation)
+		return m, m.trackFileOperation(cmd), true
 
 	case OverwriteChoiceCancel:
 		if m.batchOp != nil {
@@ -398,54 +396,198 @@ func (m Model) handleConfirmDialogResult(msg tea.Msg) (Model, tea.Cmd, bool) {
 		return m, nil, true
 	}
 
-	// コンテキストメニューからの削除
-	if m.pendingAction != nil {
-		if err := m.pendingAction(); err != nil {
-			m.dialog = NewErrorDialog(fmt.Sprintf("Failed to delete: %v", err))
-		} else {
-			m.getActivePane().LoadDirectory()
-			m.getInactivePane().LoadDirectory()
-		}
-		m.pendingAction = nil
-		return m, nil, true
-	}
-
-	// 通常の削除
-	return m.executeDeleteOperation(), nil, true
+	// 削除はバックグラウンドジョブとして実行
+	// （コンテキストメニューからの単一削除も選択中のエントリが対象）
+	m.pendingAction = nil
+	permanent := m.permanentDelete
+	m.permanentDelete = false
+	cmd := m.executeDeleteOperation(permanent)
+	return m, m.trackFileOperation(cmd), true
 }
 
-// executeDeleteOperation は削除操作を実行
-func (m Model) executeDeleteOperation() Model {
+// executeDeleteOperation はマークされたファイル（なければ選択中のエントリ）を
+// ゴミ箱へ移動（permanentがtrueの場合は完全に削除）するジョブを登録し、
+// 完了を待つコマンドを返す
+func (m *Model) executeDeleteOperation(permanent bool) tea.Cmd {
 	activePane := m.getActivePane()
-	markedFiles := activePane.GetMarkedFiles()
 
+	if !permanent && m.trash == nil {
+		m.dialog = NewErrorDialog("Trash is not available. Use Shift+D to delete permanently.")
+		return nil
+	}
+
+	markedFiles := activePane.GetMarkedFiles()
+	var paths []string
 	if len(markedFiles) > 0 {
-		var deleteErr error
 		for _, name := range markedFiles {
-			fullPath := filepath.Join(activePane.Path(), name)
-			if err := deleteFile(fullPath); err != nil {
-				deleteErr = err
-				break
-			}
-		}
-		if deleteErr != nil {
-			m.dialog = NewErrorDialog(fmt.Sprintf("Failed to delete: %v", deleteErr))
+			paths = append(paths, filepath.Join(activePane.Path(), name))
 		}
 		activePane.ClearMarks()
-		activePane.LoadDirectory()
 	} else {
 		entry := activePane.SelectedEntry()
-		if entry != nil && !entry.IsParentDir() {
-			fullPath := filepath.Join(activePane.Path(), entry.Name)
-			if err := deleteFile(fullPath); err != nil {
-				m.dialog = NewErrorDialog(fmt.Sprintf("Failed to delete: %v", err))
-			} else {
-				activePane.LoadDirectory()
+		if entry == nil || entry.IsParentDir() {
+			return nil
+		}
+		paths = append(paths, filepath.Join(activePane.Path(), entry.Name))
+	}
+
+	operation := "trash"
+	entry := undo.NewEntry(undo.KindTrash)
+	remove := func(path string) error {
+		item, err := trashFile(m.trash, path)
+		if err == nil {
+			entry.Steps = append(entry.Steps, undo.Step{Src: item.OriginalPath, Dest: item.FilesPath(), Info: item.InfoPath()})
+		}
+		return err
+	}
+	if permanent {
+		operation = "delete"
+		remove = deleteFile
+	}
+
+	title := fmt.Sprintf("%s %s", capitalize(operation), filepath.Base(paths[0]))
+	if len(paths) > 1 {
+		title = fmt.Sprintf("%s %d files in %s", capitalize(operation), len(paths), activePane.Path())
+	}
+
+	// フィルタ適用中は対象となるファイルだけを削除し、ディレクトリは残す
+	filter := m.opFilter
+	targets := paths
+	manager := m.jobs
+	job := manager.Add(jobs.Options{
+		Kind:     operation,
+		Title:    title,
+		Progress: fs.NewProgress(0, len(paths)),
+		Pausable: true,
+	}, func(ctx context.Context, job *jobs.Job) error {
+		if filter != nil {
+			files, err := fs.FilterPaths(paths, filter)
+			if err != nil {
+				return err
 			}
+			targets = files
+			job.Progress().Update(0, 0, 0, len(targets))
 		}
+		for _, path := range targets {
+			if err := job.WaitIfPaused(ctx); err != nil {
+				return err
+			}
+			job.Progress().SetCurrentFile(path)
+			if err := remove(path); err != nil {
+				if len(targets) == 1 {
+					return err
+				}
+				job.AddError(fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			}
+			job.Progress().FileDone()
+		}
+		return nil
+	})
+
+	return func() tea.Msg {
+		manager.Start(job.ID())
+		err := job.Wait()
+		if errors.Is(err, context.Canceled) {
+			return fileOperationCancelledMsg{operation: operation, entry: &entry}
+		}
+		if err != nil {
+			if permanent {
+				return showErrorDialogMsg{message: fmt.Sprintf("Failed to delete: %v", err), jobID: job.ID()}
+			}
+			return showErrorDialogMsg{message: fmt.Sprintf("Failed to move to trash: %v", err), jobID: job.ID()}
+		}
+		failed := job.Errors()
+		return batchOperationCompleteMsg{
+			operation: operation,
+			count:     len(targets) - len(failed),
+			failed:    failed,
+			entry:     &entry,
+		}
+	}
+}
+
+// handleTrashMessages はゴミ箱ブラウザ関連のメッセージを処理する
+func (m Model) handleTrashMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case trashCloseMsg:
+		m.dialog = nil
+		return m, nil, true
+
+	case trashRestoreMsg:
+		if err := m.trash.Restore(msg.item); err != nil {
+			m.setTrashDialogMessage(err.Error(), true)
+			return m, nil, true
+		}
+		m.setTrashDialogMessage(fmt.Sprintf("Restored: %s", msg.item.OriginalPath), false)
+		m.refreshTrashDialog()
+		m.getActivePane().LoadDirectory()
+		m.getInactivePane().LoadDirectory()
+		return m, nil, true
+
+	case trashRemoveMsg:
+		if err := m.trash.Remove(msg.item); err != nil {
+			m.setTrashDialogMessage(err.Error(), true)
+			return m, nil, true
+		}
+		m.setTrashDialogMessage(fmt.Sprintf("Deleted: %s", filepath.Base(msg.item.OriginalPath)), false)
+		m.refreshTrashDialog()
+		return m, nil, true
+
+	case trashEmptyMsg:
+		m.dialog = nil
+		return m, m.trackFileOperation(m.emptyTrash()), true
+
+	case trashEmptiedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Failed to empty trash: %v", msg.err)
+			m.isStatusError = true
+			return m, statusMessageClearCmd(5 * time.Second), true
+		}
+		m.statusMessage = "Trash emptied"
+		m.isStatusError = false
+		return m, statusMessageClearCmd(3 * time.Second), true
+	}
+
+	return m, nil, false
+}
+
+// emptyTrash はゴミ箱を空にするジョブを登録し、完了を待つコマンドを返す
+func (m *Model) emptyTrash() tea.Cmd {
+	bin := m.trash
+	manager := m.jobs
+	job := manager.Add(jobs.Options{Kind: "delete", Title: "Empty trash"}, func(ctx context.Context, job *jobs.Job) error {
+		return bin.Empty(ctx)
+	})
+
+	return func() tea.Msg {
+		manager.Start(job.ID())
+		err := job.Wait()
+		if errors.Is(err, context.Canceled) {
+			return fileOperationCancelledMsg{operation: "empty trash"}
+		}
+		return trashEmptiedMsg{err: err}
+	}
+}
+
+// refreshTrashDialog はゴミ箱ブラウザの一覧を再読み込み
+func (m *Model) refreshTrashDialog() {
+	dialog, ok := m.dialog.(*TrashDialog)
+	if !ok {
+		return
+	}
+	items, err := m.trash.List()
+	if err != nil {
+		dialog.SetMessage(fmt.Sprintf("Failed to read trash: %v", err), true)
+		return
 	}
+	dialog.SetItems(items)
+}
 
-	return m
+// setTrashDialogMessage はゴミ箱ブラウザにメッセージを表示
+func (m *Model) setTrashDialogMessage(message string, isError bool) {
+	if dialog, ok := m.dialog.(*TrashDialog); ok {
+		dialog.SetMessage(message, isError)
+	}
 }
 
 // handleBookmarkMessages はブックマーク関連のメッセージを処理する
@@ -499,6 +641,12 @@ func (m Model) handleBookmarkMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
 		return m, nil, true
 	}
 
+	// ジョブパネル閉じる
+	if _, ok := msg.(jobsDialogCloseMsg); ok {
+		m.dialog = nil
+		return m, nil, true
+	}
+
 	// ブックマーク追加完了
 	if result, ok := msg.(bookmarkAddedMsg); ok {
 		m.dialog = nil
@@ -521,6 +669,129 @@ func (m Model) handleBookmarkMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
 	return m, nil, false
 }
 
+// handleSessionMessages は名前付きセッション関連のメッセージを処理する
+func (m Model) handleSessionMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case sessionLoadMsg:
+		m.dialog = nil
+		state, err := session.LoadNamed(m.namedSessionDir(), msg.name)
+		if err != nil {
+			m.statusMessage = err.Error()
+			m.isStatusError = true
+			return m, statusMessageClearCmd(5 * time.Second), true
+		}
+		m.applySession(state)
+		m.statusMessage = fmt.Sprintf("Session loaded: %s", msg.name)
+		m.isStatusError = false
+		return m, statusMessageClearCmd(3 * time.Second), true
+
+	case sessionSaveMsg:
+		m.dialog = nil
+		dir, state := m.namedSessionDir(), m.sessionState()
+		if msg.name != "" {
+			return m, saveNamedSession(dir, msg.name, state), true
+		}
+		dialog := NewInputDialog("Save session as:", func(name string) tea.Cmd {
+			return saveNamedSession(dir, name, state)
+		})
+		dialog.SetEmptyErrorMsg("Session name cannot be empty")
+		m.dialog = dialog
+		return m, nil, true
+
+	case sessionDeleteMsg:
+		m.dialog = nil
+		if err := session.DeleteNamed(m.namedSessionDir(), msg.name); err != nil {
+			m.statusMessage = err.Error()
+			m.isStatusError = true
+			return m, statusMessageClearCmd(5 * time.Second), true
+		}
+		m.statusMessage = fmt.Sprintf("Session deleted: %s", msg.name)
+		m.isStatusError = false
+		return m, statusMessageClearCmd(3 * time.Second), true
+	}
+
+	return m, nil, false
+}
+
+// handleFilterMessages はコピー/移動/削除のフィルタ関連のメッセージを処理する
+func (m Model) handleFilterMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case filterSelectMsg:
+		m.dialog = nil
+		m.opFilter = msg.filter
+		m.opFilterName = msg.name
+		m.statusMessage = "Filter cleared"
+		if msg.filter != nil {
+			m.statusMessage = fmt.Sprintf("Filter: %s", m.filterLabel())
+		}
+		m.isStatusError = false
+		return m, statusMessageClearCmd(3 * time.Second), true
+
+	case filterCustomMsg:
+		dialog := NewInputDialog("Filter (patterns, !pattern excludes):", func(spec string) tea.Cmd {
+			return func() tea.Msg {
+				filter, err := fs.ParseFilter(spec)
+				if err != nil {
+					return showStatusMsg{message: err.Error(), isError: true}
+				}
+				return filterSelectMsg{filter: filter}
+			}
+		})
+		dialog.SetEmptyErrorMsg("Filter cannot be empty")
+		dialog.input = m.opFilter.String()
+		dialog.cursorPos = len([]rune(dialog.input))
+		m.dialog = dialog
+		return m, nil, true
+
+	case filterSaveMsg:
+		m.dialog = nil
+		if m.opFilter == nil {
+			m.statusMessage = "No active filter to save"
+			m.isStatusError = true
+			return m, statusMessageClearCmd(5 * time.Second), true
+		}
+		currentSets, filter := m.filterSets, *m.opFilter
+		dialog := NewInputDialog("Save filter as:", func(name string) tea.Cmd {
+			return saveFilterSet(currentSets, config.FilterSet{Name: name, Include: filter.Include, Exclude: filter.Exclude})
+		})
+		dialog.SetEmptyErrorMsg("Filter name cannot be empty")
+		dialog.input = m.opFilterName
+		dialog.cursorPos = len([]rune(dialog.input))
+		m.dialog = dialog
+		return m, nil, true
+
+	case filterDeleteMsg:
+		m.dialog = nil
+		newSets, err := config.RemoveFilterSet(m.filterSets, msg.index)
+		if err == nil {
+			err = saveFilterSetsToConfig(newSets)
+		}
+		if err != nil {
+			m.statusMessage = err.Error()
+			m.isStatusError = true
+			return m, statusMessageClearCmd(5 * time.Second), true
+		}
+		m.filterSets = newSets
+		m.statusMessage = "Filter removed"
+		m.isStatusError = false
+		return m, statusMessageClearCmd(3 * time.Second), true
+
+	case filterSetsSavedMsg:
+		m.dialog = nil
+		m.filterSets = msg.sets
+		m.opFilterName = msg.name
+		m.statusMessage = fmt.Sprintf("Filter saved: %s", msg.name)
+		m.isStatusError = false
+		return m, statusMessageClearCmd(3 * time.Second), true
+
+	case filterCloseMsg:
+		m.dialog = nil
+		return m, nil, true
+	}
+
+	return m, nil, false
+}
+
 // handleArchiveMessages はアーカイブ関連のメッセージを処理する
 func (m Model) handleArchiveMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
 	// アーカイブ操作開始
@@ -529,7 +800,8 @@ func (m Model) handleArchiveMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
 			return m, nil, true
 		}
 		m.archiveOp.TaskID = result.taskID
-		return m, m.pollArchiveProgress(result.taskID), true
+		m.trackArchiveJob(result.taskID)
+		return m, m.trackFileOperation(m.pollArchiveProgress(result.taskID)), true
 	}
 
 	// アーカイブ進捗更新
@@ -547,8 +819,7 @@ func (m Model) handleArchiveMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
 
 	// アーカイブ操作完了
 	if result, ok := msg.(archiveOperationCompleteMsg); ok {
-		m.dialog = nil
-		m.archiveOp = nil
+		m.closeArchiveProgress(result.taskID)
 
 		if result.cancelled {
 			m.statusMessage = "Archive operation cancelled"
@@ -572,8 +843,7 @@ func (m Model) handleArchiveMessages(msg tea.Msg) (Model, tea.Cmd, bool) {
 
 	// アーカイブ操作エラー
 	if result, ok := msg.(archiveOperationErrorMsg); ok {
-		m.dialog = nil
-		m.archiveOp = nil
+		m.closeArchiveProgress(result.taskID)
 		m.statusMessage = result.message
 		m.isStatusError = true
 		return m, statusMessageClearCmd(5 * time.Second), true
@@ -669,11 +939,10 @@ func (m Model) handleSystemMessages(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleInputDialogResult(msg)
 
 	case showErrorDialogMsg:
-		m.dialog = NewErrorDialog(msg.message)
-		return m, nil
+		return m.handleErrorDialog(msg)
 
 	case showOverwriteDialogMsg:
-		m.dialog = NewOverwriteDialog(
+		dialog := NewOverwriteDialog(
 			msg.filename,
 			msg.destPath,
 			msg.srcInfo,
@@ -681,16 +950,155 @@ func (m Model) handleSystemMessages(msg tea.Msg) (tea.Model, tea.Cmd) {
 			msg.operation,
 			msg.srcPath,
 		)
+		if m.batchOp != nil {
+			dialog.EnableBatchMode(len(m.batchOp.Files) - m.batchOp.CurrentIdx - 1)
+		}
+		m.dialog = dialog
 		return m, nil
 
 	case fileOperationCompleteMsg:
 		return m.handleFileOperationComplete(msg)
 
-	case batchOperationCompleteMsg:
-		m.statusMessage = fmt.Sprintf("%s %d files completed", strings.Title(msg.operation), msg.count)
+	case fileOperationCancelledMsg:
+		return m.handleFileOperationCancelled(msg)
+
+	case fileOperationProgressMsg:
+		if !m.hasActiveJobs() {
+			m.jobsPolling = false
+			return m, nil
+		}
+		return m, pollFileOperationProgress()
+
+	case sendToBackgroundMsg:
+		m.backgroundFileOperation()
+		if _, ok := m.dialog.(*ArchiveProgressDialog); ok {
+			m.dialog = nil
+		}
+		m.statusMessage = "Running in background (Shift+J: jobs)"
 		m.isStatusError = false
 		return m, statusMessageClearCmd(3 * time.Second)
 
+	case batchOperationCompleteMsg:
+		return m.handleBatchOperationComplete(msg)
+
+	case diffLoadedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Cannot diff: %v", msg.err)
+			m.isStatusError = true
+			return m, statusMessageClearCmd(5 * time.Second)
+		}
+		dialog := NewDiffDialog(msg.left, msg.right)
+		dialog.SetSize(m.width, m.height-2)
+		m.dialog = dialog
+		return m, nil
+
+	case diffCloseMsg:
+		m.dialog = nil
+		if msg.saved {
+			return m, m.RefreshBothPanes()
+		}
+		return m, nil
+
+	case compareRequestMsg:
+		m.dialog = nil
+		m.compareMode = msg.mode
+		m.compareRecursive = msg.recursive
+		m.statusMessage = "Comparing directories..."
+		m.isStatusError = false
+		return m, m.compareDirectories(msg.mode, msg.recursive)
+
+	case compareResultMsg:
+		return m.handleCompareResult(msg)
+
+	case findRequestMsg:
+		m.dialog = nil
+		return m, m.startFind(msg.criteria, msg.query)
+
+	case findResultsMsg:
+		return m.handleFindResults(msg)
+
+	case grepResultsMsg:
+		if dialog, ok := m.dialog.(*GrepDialog); ok {
+			return m, dialog.handleResults(msg)
+		}
+		return m, nil
+
+	case jumpSelectMsg:
+		m.dialog = nil
+		return m, m.getActivePane().ChangeDirectoryAsync(msg.path)
+
+	case historySelectMsg:
+		m.dialog = nil
+		pane := m.getActivePane()
+		if msg.index < 0 {
+			return m, pane.ChangeDirectoryAsync(msg.path)
+		}
+		if err := pane.NavigateHistoryTo(msg.index); err != nil {
+			m.statusMessage = formatDirectoryError(err, msg.path)
+			m.isStatusError = true
+			return m, statusMessageClearCmd(5 * time.Second)
+		}
+		return m, nil
+
+	case fuzzyIndexMsg:
+		if dialog, ok := m.dialog.(*FuzzyFinderDialog); ok {
+			return m, dialog.handleIndex(msg)
+		}
+		return m, nil
+
+	case fuzzyRankMsg:
+		if dialog, ok := m.dialog.(*FuzzyFinderDialog); ok {
+			return m, dialog.handleRank(msg)
+		}
+		return m, nil
+
+	case fuzzyCloseMsg:
+		m.dialog = nil
+		if msg.path != "" {
+			return m.openFuzzyResult(msg.path)
+		}
+		return m, nil
+
+	case grepCloseMsg:
+		m.dialog = nil
+		m.grepSettings = msg.settings
+		if msg.jump != nil {
+			return m, m.jumpToFile(filepath.Join(msg.root, msg.jump.Path))
+		}
+		return m, nil
+
+	case syncPlanRequestMsg:
+		// 差分の計算は大きなディレクトリで時間がかかるためUIの外で行う
+		opts := msg.opts
+		opts.Filter = m.opFilter
+		return m, func() tea.Msg {
+			actions, err := fs.PlanSync(msg.plan.ctx, msg.src, msg.dst, opts)
+			return syncPlanResultMsg{plan: msg.plan, actions: actions, err: err}
+		}
+
+	case syncPlanResultMsg:
+		if dialog, ok := m.dialog.(*SyncDialog); ok {
+			dialog.SetPlan(msg.plan, msg.actions, msg.err)
+		}
+		return m, nil
+
+	case syncDialogResultMsg:
+		m.dialog = nil
+		return m, m.trackFileOperation(m.executeSyncOperation(msg.actions, msg.dest))
+
+	case undoResultMsg:
+		return m.handleUndoResult(msg)
+
+	case destinationDialogResultMsg:
+		m.dialog = nil
+		return m, m.startCopyMoveTo(msg.operation, msg.sources, msg.target)
+
+	case preflightResultMsg:
+		return m.handlePreflightResult(msg)
+
+	case preflightDialogResultMsg:
+		return m.handlePreflightDialogResult(msg)
+
 	case renameInputResultMsg:
 		return m.handleRenameInputResult(msg)
 
@@ -720,6 +1128,12 @@ func (m Model) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 		if err != nil {
 			return m, tea.Quit
 		}
+		if !m.startOptions.hasPaths() {
+			m.restoreSession()
+		}
+		m.applyStartOptions()
+		m.leftPane.SetVisitRecorder(m.dirVisits)
+		m.rightPane.SetVisitRecorder(m.dirVisits)
 
 		m.updateDiskSpace()
 		m.ready = true
@@ -730,6 +1144,14 @@ func (m Model) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	paneHeight := msg.Height - 2
 	m.leftPane.SetSize(paneWidth, paneHeight)
 	m.rightPane.SetSize(paneWidth, paneHeight)
+	switch dialog := m.dialog.(type) {
+	case *DiffDialog:
+		dialog.SetSize(msg.Width, paneHeight)
+	case *GrepDialog:
+		dialog.SetSize(msg.Width, paneHeight)
+	case *FuzzyFinderDialog:
+		dialog.SetSize(msg.Width, paneHeight)
+	}
 
 	return m, nil
 }
@@ -771,6 +1193,9 @@ func (m Model) handleDirectoryLoadComplete(msg directoryLoadCompleteMsg) (tea.Mo
 		return m, statusMessageClearCmd(5 * time.Second)
 	}
 
+	// 別のディレクトリを読み込んだので検索結果の表示は終わり
+	targetPane.virtual = nil
+
 	entries := msg.entries
 	if !targetPane.showHidden {
 		entries = filterHiddenFiles(entries)
@@ -841,6 +1266,7 @@ func (m Model) handleInputDialogResult(msg inputDialogResultMsg) (tea.Model, tea
 
 	m.getActivePane().LoadDirectory()
 	m.getInactivePane().LoadDirectory()
+	m.recordUndo(msg.entry)
 
 	switch msg.operation {
 	case "create_file", "create_dir":
@@ -851,35 +1277,251 @@ func (m Model) handleInputDialogResult(msg inputDialogResultMsg) (tea.Model, tea
 	return m, nil
 }
 
+// handleErrorDialog はエラーを表示する
+// 計画中のエラーは計画中の一括操作を中止し、ジョブの失敗はそのジョブの進捗ダイアログだけを閉じる
+func (m Model) handleErrorDialog(msg showErrorDialogMsg) (tea.Model, tea.Cmd) {
+	if msg.jobID == 0 {
+		m.cancelBatchOperation()
+	} else {
+		if m.fileOp != nil && m.fileOp.job != nil && m.fileOp.job.ID() == msg.jobID {
+			m.endFileOperation()
+		}
+		// 途中まで行われた操作の結果を表示する
+		m.getActivePane().LoadDirectory()
+		m.getInactivePane().LoadDirectory()
+	}
+
+	// 他の操作のダイアログが開いている場合はそのまま残してステータスバーに表示する
+	if m.dialog != nil {
+		m.statusMessage = strings.ReplaceAll(msg.message, "\n", " ")
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+	m.dialog = NewErrorDialog(msg.message)
+	return m, nil
+}
+
 // handleFileOperationComplete はファイル操作完了を処理
 func (m Model) handleFileOperationComplete(msg fileOperationCompleteMsg) (tea.Model, tea.Cmd) {
-	if m.batchOp != nil {
-		srcPath := m.batchOp.Files[m.batchOp.CurrentIdx]
-		return m, m.advanceBatchOperation(true, srcPath)
-	}
+	m.endFileOperation()
+	m.recordUndo(msg.entry)
 	m.getActivePane().LoadDirectory()
 	m.getInactivePane().LoadDirectory()
+
+	if len(msg.mismatched) > 0 {
+		m.statusMessage = fmt.Sprintf("%s completed, %d failed verification (%s)",
+			capitalize(msg.operation), len(msg.mismatched), msg.mismatched[0])
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+
+	if len(msg.skipped) > 0 {
+		m.statusMessage = fmt.Sprintf("%s completed, %d skipped (%s)",
+			capitalize(msg.operation), len(msg.skipped), msg.skipped[0])
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
 	return m, nil
 }
 
+// handleBatchOperationComplete は一括コピー/移動の完了を処理
+func (m Model) handleBatchOperationComplete(msg batchOperationCompleteMsg) (tea.Model, tea.Cmd) {
+	m.endFileOperation()
+	m.recordUndo(msg.entry)
+	m.getActivePane().LoadDirectory()
+	m.getInactivePane().LoadDirectory()
+
+	if len(msg.failed) > 0 {
+		m.statusMessage = fmt.Sprintf("%s %d files completed, %d failed (%s)",
+			capitalize(msg.operation), msg.count, len(msg.failed), msg.failed[0])
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+
+	if len(msg.mismatched) > 0 {
+		m.statusMessage = fmt.Sprintf("%s %d files completed, %d failed verification (%s)",
+			capitalize(msg.operation), msg.count, len(msg.mismatched), msg.mismatched[0])
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+
+	if len(msg.skipped) > 0 {
+		m.statusMessage = fmt.Sprintf("%s %d files completed, %d skipped (%s)",
+			capitalize(msg.operation), msg.count, len(msg.skipped), msg.skipped[0])
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+
+	if msg.operation == "trash" {
+		m.statusMessage = fmt.Sprintf("Moved %d files to trash", msg.count)
+	} else {
+		m.statusMessage = fmt.Sprintf("%s %d files completed", capitalize(msg.operation), msg.count)
+	}
+	m.isStatusError = false
+	return m, statusMessageClearCmd(3 * time.Second)
+}
+
+// handleCompareResult は両ペインのディレクトリ比較の結果を両ペインのマークに反映する
+// 反対側にない・新しい・内容が異なるエントリがマークされ、そのままコピーできる
+func (m Model) handleCompareResult(msg compareResultMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Compare failed: %v", msg.err)
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+	// 比較中に移動したペインには結果を反映しない
+	state := msg.state
+	if state.leftPath != m.leftPane.Path() || state.rightPath != m.rightPane.Path() {
+		m.statusMessage = ""
+		return m, nil
+	}
+
+	m.compare = state
+	leftNames, rightNames := state.result.Marks()
+	leftCount := m.leftPane.MarkFiles(leftNames)
+	rightCount := m.rightPane.MarkFiles(rightNames)
+	if len(state.result.Diffs) == 0 {
+		m.statusMessage = "Directories are identical"
+	} else {
+		m.statusMessage = fmt.Sprintf("%d differences: marked %d left, %d right", len(state.result.Diffs), leftCount, rightCount)
+	}
+	m.isStatusError = false
+	return m, statusMessageClearCmd(3 * time.Second)
+}
+
+// handleFindResults は再帰検索の結果をペインに追加する
+func (m Model) handleFindResults(msg findResultsMsg) (tea.Model, tea.Cmd) {
+	// キャンセル済みの検索や、新しい検索に置き換えられた検索の結果は捨てる
+	if msg.search != m.find {
+		return m, nil
+	}
+	pane := m.findPane()
+	// 検索中にペインが別のディレクトリへ移動した場合は検索を止める
+	if !pane.IsVirtual() {
+		m.cancelFind()
+		return m, nil
+	}
+
+	pane.AppendVirtualEntries(msg.entries)
+	if !msg.done {
+		return m, waitFindResults(msg.search)
+	}
+
+	pane.FinishVirtual()
+	m.find = nil
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Find failed: %v", msg.err)
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+	m.statusMessage = fmt.Sprintf("Found %d matches", pane.TotalEntryCount())
+	m.isStatusError = false
+	return m, statusMessageClearCmd(3 * time.Second)
+}
+
+// handleFileOperationCancelled はコピー/移動のキャンセルを処理
+func (m Model) handleFileOperationCancelled(msg fileOperationCancelledMsg) (tea.Model, tea.Cmd) {
+	m.endFileOperation()
+	m.recordUndo(msg.entry)
+	m.getActivePane().LoadDirectory()
+	m.getInactivePane().LoadDirectory()
+	m.statusMessage = fmt.Sprintf("%s cancelled", capitalize(msg.operation))
+	m.isStatusError = false
+	return m, statusMessageClearCmd(3 * time.Second)
+}
+
+// handlePreflightResult はコピー/移動の事前確認の結果を処理
+func (m Model) handlePreflightResult(msg preflightResultMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case errors.Is(msg.err, fs.ErrInsufficientSpace):
+		m.dialog = NewErrorDialog(fmt.Sprintf("Not enough disk space in %s\nRequired: %s, available: %s",
+			msg.dest, FormatSize(msg.plan.RequiredBytes), FormatSize(int64(msg.plan.FreeBytes))))
+		return m, nil
+	case msg.err != nil:
+		m.dialog = NewErrorDialog(fmt.Sprintf("Cannot %s: %v", msg.operation, msg.err))
+		return m, nil
+	case m.opFilter != nil && msg.plan.TotalFiles == 0:
+		m.statusMessage = fmt.Sprintf("No files match filter %s", m.opFilter)
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	case msg.plan.HasConflicts() || m.opFilter != nil:
+		// フィルタ適用中は対象となるファイル数を確認してから開始する
+		dialog := NewPreflightDialog(msg.operation, msg.sources, msg.dest, msg.plan)
+		dialog.SetFilter(m.opFilter.String())
+		m.dialog = dialog
+		return m, nil
+	}
+
+	cmd := m.startFileOperation(msg.sources, msg.dest, msg.operation)
+	return m, m.trackFileOperation(cmd)
+}
+
+// handlePreflightDialogResult は事前確認で見つかった競合の解決方法を処理
+func (m Model) handlePreflightDialogResult(msg preflightDialogResultMsg) (tea.Model, tea.Cmd) {
+	m.dialog = nil
+
+	var cmd tea.Cmd
+	switch msg.choice {
+	case PreflightChoiceOverwriteAll:
+		cmd = m.startResolvedOperation(msg.sources, msg.dest, msg.operation, true)
+	case PreflightChoiceSkipExisting:
+		cmd = m.startResolvedOperation(msg.sources, msg.dest, msg.operation, false)
+	case PreflightChoiceAskEach, PreflightChoiceStart:
+		cmd = m.startFileOperation(msg.sources, msg.dest, msg.operation)
+	default:
+		return m, nil
+	}
+	return m, m.trackFileOperation(cmd)
+}
+
+// recordUndo は完了した操作を取り消し履歴に記録
+func (m *Model) recordUndo(entry *undo.Entry) {
+	if entry == nil || m.undoJournal == nil {
+		return
+	}
+	if err := m.undoJournal.Record(*entry); err != nil {
+		m.statusMessage = err.Error()
+		m.isStatusError = true
+	}
+}
+
+// handleUndoResult は取り消し/やり直しの結果を処理
+func (m Model) handleUndoResult(msg undoResultMsg) (tea.Model, tea.Cmd) {
+	m.getActivePane().LoadDirectory()
+	m.getInactivePane().LoadDirectory()
+
+	action := "Undo"
+	if msg.redo {
+		action = "Redo"
+	}
+
+	switch {
+	case errors.Is(msg.err, undo.ErrNothingToUndo), errors.Is(msg.err, undo.ErrNothingToRedo):
+		m.statusMessage = fmt.Sprintf("Nothing to %s", strings.ToLower(action))
+		m.isStatusError = false
+		return m, statusMessageClearCmd(3 * time.Second)
+	case msg.err != nil:
+		m.statusMessage = fmt.Sprintf("%s failed: %v", action, msg.err)
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+
+	m.statusMessage = fmt.Sprintf("%s: %s", action, msg.entry.Description())
+	m.isStatusError = false
+	return m, statusMessageClearCmd(3 * time.Second)
+}
+
 // handleRenameInputResult はリネーム入力ダイアログの結果を処理
 func (m Model) handleRenameInputResult(msg renameInputResultMsg) (tea.Model, tea.Cmd) {
 	m.dialog = nil
 
 	newDestPath := filepath.Join(msg.destPath, msg.newName)
 
-	var err error
-	if msg.operation == "copy" {
-		err = copyFile(msg.srcPath, newDestPath)
-	} else {
-		err = moveFile(msg.srcPath, newDestPath)
-	}
-
-	if err != nil {
+	if _, err := os.Lstat(msg.srcPath); err != nil {
 		m.dialog = NewErrorDialog(fmt.Sprintf("Failed to %s: %v", msg.operation, err))
-	} else {
-		m.getActivePane().LoadDirectory()
-		m.getInactivePane().LoadDirectory()
+		return m, nil
 	}
-	return m, nil
+
+	cmd := m.queueFileOperation(msg.srcPath, newDestPath, msg.operation)
+	return m, m.trackFileOperation(cmd)
 }
@@ -3,9 +3,11 @@ package ui
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/config"
 )
 
 // handleKeyInput はキーボード入力を処理する
@@ -167,7 +169,63 @@ func (m Model) handleAction(action Action) (tea.Model, tea.Cmd) {
 		return m.handleMove()
 
 	case ActionDelete:
-		return m.handleDelete()
+		return m.handleDelete(false)
+
+	case ActionDeletePermanently:
+		return m.handleDelete(true)
+
+	case ActionTrash:
+		return m.handleTrashBrowser()
+
+	case ActionUndo:
+		return m, m.runUndo(false)
+
+	case ActionRedo:
+		return m, m.runUndo(true)
+
+	case ActionTogglePreserve:
+		return m.handleTogglePreserve()
+
+	case ActionToggleVerify:
+		return m.handleToggleVerify()
+
+	case ActionOperationFilter:
+		return m.handleOperationFilter()
+
+	case ActionSyncDirectories:
+		return m.handleSyncDirectories()
+
+	case ActionCompareDirectories:
+		m.dialog = NewCompareDialog(m.compareMode, m.compareRecursive)
+		return m, nil
+
+	case ActionDiffFiles:
+		return m.handleDiffFiles()
+
+	case ActionFindFiles:
+		m.dialog = NewFindDialog(m.getActivePane().Path(), m.findQuery)
+		return m, nil
+
+	case ActionGrep:
+		pane := m.getActivePane()
+		dialog := NewGrepDialog(pane.Path(), m.grepSettings, pane.IsShowingHidden())
+		dialog.SetSize(m.width, m.height-2)
+		m.dialog = dialog
+		return m, nil
+
+	case ActionFuzzyFind:
+		pane := m.getActivePane()
+		dialog := NewFuzzyFinderDialog(pane.Path(), pane.IsShowingHidden())
+		dialog.SetSize(m.width, m.height-2)
+		m.dialog = dialog
+		return m, dialog.start()
+
+	case ActionJump:
+		m.dialog = NewJumpDialog(m.dirVisits, m.getActivePane().Path())
+		return m, nil
+
+	case ActionEscape:
+		return m.handleEscape()
 
 	case ActionContextMenu:
 		return m.handleContextMenu()
@@ -192,6 +250,20 @@ func (m Model) handleAction(action Action) (tea.Model, tea.Cmd) {
 		cmd := m.getActivePane().NavigateHistoryForwardAsync()
 		return m, cmd
 
+	case ActionHistoryList:
+		paths, current := m.getActivePane().history.Entries()
+		m.dialog = NewHistoryDialog(paths, current, m.dirVisits.Recent())
+		return m, nil
+
+	case ActionSessions:
+		return m.handleSessionsUI()
+
+	case ActionChoose:
+		return m.handleChoose()
+
+	case ActionNewTab, ActionDuplicateTab, ActionCloseTab, ActionNextTab, ActionPrevTab:
+		return m.handleTabAction(action)
+
 	case ActionView:
 		return m.handleView()
 
@@ -217,13 +289,63 @@ func (m Model) handleAction(action Action) (tea.Model, tea.Cmd) {
 
 	case ActionAddBookmark:
 		return m.handleAddBookmarkUI()
+
+	case ActionJobs:
+		m.dialog = NewJobsDialog(m.jobs)
+		return m, m.trackFileOperation(nil)
 	}
 
 	return m, nil
 }
 
+// handleTabAction はアクティブペインのタブを開く・閉じる・切り替える
+func (m Model) handleTabAction(action Action) (tea.Model, tea.Cmd) {
+	pane := m.getActivePane()
+	if pane.IsLoading() {
+		return m, nil
+	}
+	// 実行中の再帰検索はタブを離れる前に止める（見つかった結果はタブに残る）
+	if m.find != nil && m.findPane() == pane {
+		m.cancelFind()
+	}
+
+	switch action {
+	case ActionNewTab:
+		// カーソル位置のディレクトリ（なければ現在のディレクトリ）を新しいタブで開く
+		path := pane.Path()
+		if entry := pane.SelectedEntry(); entry != nil && !entry.IsParentDir() {
+			if fullPath := filepath.Join(pane.Path(), entry.Name); isDirectory(fullPath) {
+				path = fullPath
+			}
+		}
+		if err := pane.NewTab(path); err != nil {
+			m.statusMessage = formatDirectoryError(err, path)
+			m.isStatusError = true
+			return m, statusMessageClearCmd(5 * time.Second)
+		}
+	case ActionDuplicateTab:
+		pane.DuplicateTab()
+	case ActionCloseTab:
+		if !pane.CloseTab() {
+			m.statusMessage = "Cannot close the last tab"
+			m.isStatusError = false
+			return m, statusMessageClearCmd(3 * time.Second)
+		}
+	case ActionNextTab:
+		pane.NextTab()
+	case ActionPrevTab:
+		pane.PrevTab()
+	}
+
+	m.updateDiskSpace()
+	return m, nil
+}
+
 // handleMoveLeft は左移動を処理
 func (m Model) handleMoveLeft() (tea.Model, tea.Cmd) {
+	if m.activePane == LeftPane && m.leftPane.IsVirtual() {
+		return m.leaveFindResults()
+	}
 	if m.activePane == LeftPane {
 		cmd := m.leftPane.MoveToParentAsync()
 		return m, cmd
@@ -234,6 +356,9 @@ func (m Model) handleMoveLeft() (tea.Model, tea.Cmd) {
 
 // handleMoveRight は右移動を処理
 func (m Model) handleMoveRight() (tea.Model, tea.Cmd) {
+	if m.activePane == RightPane && m.rightPane.IsVirtual() {
+		return m.leaveFindResults()
+	}
 	if m.activePane == RightPane {
 		cmd := m.rightPane.MoveToParentAsync()
 		return m, cmd
@@ -244,8 +369,15 @@ func (m Model) handleMoveRight() (tea.Model, tea.Cmd) {
 
 // handleEnter はEnterキーを処理
 func (m Model) handleEnter() (tea.Model, tea.Cmd) {
+	if m.getActivePane().IsVirtual() {
+		return m.handleFindJump()
+	}
 	entry := m.getActivePane().SelectedEntry()
 	if entry != nil && !entry.IsParentDir() && !entry.IsDir {
+		// 選択モードではファイルを開く代わりに選択して終了
+		if m.chooseMode != ChooseNone {
+			return m.handleChoose()
+		}
 		fullPath := filepath.Join(m.getActivePane().Path(), entry.Name)
 		if err := checkReadPermission(fullPath); err != nil {
 			m.statusMessage = fmt.Sprintf("Cannot read file: %v", err)
@@ -258,6 +390,91 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleEscape は実行中の再帰検索を止める
+// 検索が終わっていれば検索結果の表示を終える
+func (m Model) handleEscape() (tea.Model, tea.Cmd) {
+	if m.find != nil {
+		pane := m.findPane()
+		m.cancelFind()
+		m.statusMessage = fmt.Sprintf("Find cancelled: %d matches", pane.TotalEntryCount())
+		m.isStatusError = false
+		return m, statusMessageClearCmd(3 * time.Second)
+	}
+	if m.getActivePane().IsVirtual() {
+		return m.leaveFindResults()
+	}
+	return m, nil
+}
+
+// handleFindJump は検索結果のエントリがある場所へ移動する
+// ファイルは親ディレクトリを開いてカーソルを合わせ、ディレクトリはその中に入る
+func (m Model) handleFindJump() (tea.Model, tea.Cmd) {
+	pane := m.getActivePane()
+	entry := pane.SelectedEntry()
+	if entry == nil {
+		return m, nil
+	}
+	if entry.IsParentDir() {
+		return m.leaveFindResults()
+	}
+
+	if m.find != nil && m.find.pane == m.activePane {
+		m.cancelFind()
+	}
+	if entry.IsDir {
+		return m, pane.ChangeDirectoryAsync(filepath.Join(pane.Path(), entry.Name))
+	}
+	return m, m.jumpToFile(filepath.Join(pane.Path(), entry.Name))
+}
+
+// jumpToFile はアクティブペインを path のあるディレクトリに移動し、path にカーソルを合わせる
+func (m *Model) jumpToFile(path string) tea.Cmd {
+	pane := m.getActivePane()
+	cmd := pane.ChangeDirectoryAsync(filepath.Dir(path))
+	pane.pendingCursorTarget = filepath.Base(path)
+	return cmd
+}
+
+// openFuzzyResult はアクティブペインを path のあるディレクトリに移動し、カーソルを path に合わせる
+func (m Model) openFuzzyResult(path string) (tea.Model, tea.Cmd) {
+	pane := m.getActivePane()
+	wasVirtual := pane.IsVirtual()
+	if wasVirtual {
+		if m.find != nil && m.find.pane == m.activePane {
+			m.cancelFind()
+		}
+		pane.virtual = nil
+	}
+
+	dir := filepath.Dir(path)
+	var err error
+	if dir != pane.Path() {
+		err = pane.ChangeDirectory(dir)
+	} else if wasVirtual {
+		err = pane.LoadDirectory()
+	}
+	if err != nil {
+		m.statusMessage = formatDirectoryError(err, dir)
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+	m.moveCursorToFile(filepath.Base(path))
+	return m, nil
+}
+
+// leaveFindResults はアクティブペインの検索結果の表示を終えて起点のディレクトリに戻る
+func (m Model) leaveFindResults() (tea.Model, tea.Cmd) {
+	if m.find != nil && m.find.pane == m.activePane {
+		m.cancelFind()
+	}
+	if err := m.getActivePane().ExitVirtual(); err != nil {
+		m.statusMessage = formatDirectoryError(err, m.getActivePane().Path())
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+	return m, nil
+}
+
 // handleMark はマークを処理
 func (m Model) handleMark() (tea.Model, tea.Cmd) {
 	activePane := m.getActivePane()
@@ -278,59 +495,68 @@ func (m Model) handleToggleInfo() (tea.Model, tea.Cmd) {
 
 // handleCopy はコピーを処理
 func (m Model) handleCopy() (tea.Model, tea.Cmd) {
-	activePane := m.getActivePane()
-	markedFiles := activePane.GetMarkedFiles()
-
-	if len(markedFiles) > 0 {
-		return m, m.startBatchOperation(markedFiles, "copy")
-	}
-
-	entry := activePane.SelectedEntry()
-	if entry != nil && !entry.IsParentDir() {
-		srcPath := filepath.Join(activePane.Path(), entry.Name)
-		destPath := m.getInactivePane().Path()
-		return m, m.checkFileConflict(srcPath, destPath, "copy")
-	}
+	m.showDestinationDialog("copy")
 	return m, nil
 }
 
 // handleMove は移動を処理
 func (m Model) handleMove() (tea.Model, tea.Cmd) {
-	activePane := m.getActivePane()
-	markedFiles := activePane.GetMarkedFiles()
-
-	if len(markedFiles) > 0 {
-		return m, m.startBatchOperation(markedFiles, "move")
-	}
-
-	entry := activePane.SelectedEntry()
-	if entry != nil && !entry.IsParentDir() {
-		srcPath := filepath.Join(activePane.Path(), entry.Name)
-		destPath := m.getInactivePane().Path()
-		return m, m.checkFileConflict(srcPath, destPath, "move")
-	}
+	m.showDestinationDialog("move")
 	return m, nil
 }
 
 // handleDelete は削除を処理
-func (m Model) handleDelete() (tea.Model, tea.Cmd) {
+func (m Model) handleDelete(permanent bool) (tea.Model, tea.Cmd) {
+	m.showDeleteConfirm(permanent)
+	return m, nil
+}
+
+// showDeleteConfirm はゴミ箱への移動または完全削除の確認ダイアログを表示
+func (m *Model) showDeleteConfirm(permanent bool) {
 	activePane := m.getActivePane()
 	markedFiles := activePane.GetMarkedFiles()
 
-	if len(markedFiles) > 0 {
-		m.dialog = NewConfirmDialog(
-			fmt.Sprintf("Delete %d files?", len(markedFiles)),
-			"This action cannot be undone.",
-		)
-	} else {
+	var target string
+	if len(markedFiles) == 0 {
 		entry := activePane.SelectedEntry()
-		if entry != nil && !entry.IsParentDir() {
-			m.dialog = NewConfirmDialog(
-				"Delete file?",
-				entry.DisplayName(),
-			)
+		if entry == nil || entry.IsParentDir() {
+			return
 		}
+		target = entry.DisplayName()
+	}
+
+	m.permanentDelete = permanent
+	var title, message string
+	switch {
+	case permanent && len(markedFiles) > 0:
+		title = fmt.Sprintf("Delete %d files permanently?", len(markedFiles))
+		message = "This action cannot be undone."
+	case permanent:
+		title, message = "Delete file permanently?", target
+	case len(markedFiles) > 0:
+		title = fmt.Sprintf("Delete %d files?", len(markedFiles))
+		message = "Files will be moved to the trash."
+	default:
+		title, message = "Delete file?", target+" will be moved to the trash."
+	}
+	if m.opFilter != nil {
+		message += fmt.Sprintf("\nOnly files matching %s are deleted, directories are kept.", m.opFilter)
+	}
+	m.dialog = NewConfirmDialog(title, message)
+}
+
+// handleTrashBrowser はゴミ箱ブラウザを表示
+func (m Model) handleTrashBrowser() (tea.Model, tea.Cmd) {
+	if m.trash == nil {
+		m.dialog = NewErrorDialog("Trash is not available")
+		return m, nil
+	}
+	items, err := m.trash.List()
+	if err != nil {
+		m.dialog = NewErrorDialog(fmt.Sprintf("Failed to read trash: %v", err))
+		return m, nil
 	}
+	m.dialog = NewTrashDialog(items)
 	return m, nil
 }
 
@@ -375,7 +601,7 @@ func (m Model) handleEdit() (tea.Model, tea.Cmd) {
 			m.isStatusError = true
 			return m, statusMessageClearCmd(5 * time.Second)
 		}
-		return m, openWithEditor(fullPath, m.getActivePane().Path())
+		return m, openWithEditor(fullPath, m.getActivePane().Path(), 0)
 	}
 	return m, nil
 }
@@ -433,3 +659,53 @@ func (m Model) handleAddBookmarkUI() (tea.Model, tea.Cmd) {
 	m.dialog = dialog
 	return m, nil
 }
+
+// handleTogglePreserve はコピー/移動時のメタデータ保持を切り替える
+func (m Model) handleTogglePreserve() (tea.Model, tea.Cmd) {
+	m.copyConfig.PreserveMetadata = !m.copyConfig.PreserveMetadata
+	state := "off"
+	if m.copyConfig.PreserveMetadata {
+		state = "on"
+	}
+	m.statusMessage = fmt.Sprintf("Preserve metadata: %s", state)
+	m.isStatusError = false
+	return m, statusMessageClearCmd(3 * time.Second)
+}
+
+// handleOperationFilter はコピー/移動/削除に適用するフィルタの選択ダイアログを表示
+func (m Model) handleOperationFilter() (tea.Model, tea.Cmd) {
+	m.dialog = NewFilterDialog(m.filterSets, m.opFilter.String())
+	return m, nil
+}
+
+// handleSyncDirectories は両ペインのディレクトリを同期するダイアログを表示
+func (m Model) handleSyncDirectories() (tea.Model, tea.Cmd) {
+	m.dialog = NewSyncDialog(m.leftPane.Path(), m.rightPane.Path())
+	return m, nil
+}
+
+// handleDiffFiles は両ペインのカーソル位置のファイルを並べて比較するビューアを開く
+func (m Model) handleDiffFiles() (tea.Model, tea.Cmd) {
+	left := m.leftPane.SelectedEntry()
+	right := m.rightPane.SelectedEntry()
+	if left == nil || right == nil || left.IsParentDir() || right.IsParentDir() || left.IsDir || right.IsDir {
+		m.statusMessage = "Select a file in both panes to compare"
+		m.isStatusError = true
+		return m, statusMessageClearCmd(3 * time.Second)
+	}
+	return m, loadDiffCmd(filepath.Join(m.leftPane.Path(), left.Name), filepath.Join(m.rightPane.Path(), right.Name))
+}
+
+// handleToggleVerify はコピー/移動後のチェックサム検証を 無効→sha256→xxhash の順に切り替える
+func (m Model) handleToggleVerify() (tea.Model, tea.Cmd) {
+	algorithms := config.VerifyAlgorithms
+	next := (slices.Index(algorithms, m.copyConfig.Verify) + 1) % len(algorithms)
+	m.copyConfig.Verify = algorithms[next]
+	state := m.copyConfig.Verify
+	if state == "" {
+		state = "off"
+	}
+	m.statusMessage = fmt.Sprintf("Verify after copy: %s", state)
+	m.isStatusError = false
+	return m, statusMessageClearCmd(3 * time.Second)
+}
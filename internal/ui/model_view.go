@@ -275,6 +275,28 @@ func (m Model) renderStatusBar() string {
 	// 選択位置情報
 	posInfo := fmt.Sprintf("%d/%d", activePane.cursor+1, len(activePane.entries))
 
+	// 実行中のバックグラウンドジョブ数
+	if m.jobs != nil {
+		if active := m.jobs.ActiveCount(); active > 0 {
+			posInfo += fmt.Sprintf("  jobs:%d", active)
+		}
+	}
+
+	// コピー/移動/削除に適用中のフィルタ
+	if m.opFilter != nil {
+		posInfo += "  filter:" + m.filterLabel()
+	}
+
+	// ディレクトリ比較の凡例
+	if legend := m.compareLegend(); legend != "" {
+		posInfo += "  " + legend
+	}
+
+	// 選択モード
+	if m.chooseMode != ChooseNone {
+		posInfo += "  choose:" + m.chooseMode.String()
+	}
+
 	// キーヒント（動的に変更）
 	hints := "?:help q:quit"
 	if activePane != nil && activePane.CanToggleMode() {
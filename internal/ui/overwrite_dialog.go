@@ -16,8 +16,27 @@ const (
 	OverwriteChoiceOverwrite OverwriteChoice = iota
 	OverwriteChoiceCancel
 	OverwriteChoiceRename
+	// The following choices are only offered while resolving a batch
+	OverwriteChoiceSkip
+	OverwriteChoiceNewer
+	OverwriteChoiceSizeDiffers
+	OverwriteChoiceBackup
 )
 
+// overwriteOptions are the labels of the choices, indexed by OverwriteChoice
+var overwriteOptions = []string{
+	"Overwrite",
+	"Cancel",
+	"Rename",
+	"Skip",
+	"Overwrite if source is newer",
+	"Overwrite if size differs",
+	"Keep backup (name.~N~)",
+}
+
+// singleOverwriteOptions is the number of choices offered outside a batch
+const singleOverwriteOptions = 3
+
 // OverwriteFileInfo holds file metadata for display
 type OverwriteFileInfo struct {
 	Size    int64
@@ -31,10 +50,14 @@ type OverwriteDialog struct {
 	srcPath   string            // Source file full path
 	srcInfo   OverwriteFileInfo // Source file information
 	destInfo  OverwriteFileInfo // Destination file information
-	cursor    int               // Current selection (0-2)
+	cursor    int               // Current selection
 	active    bool              // Whether dialog is active
 	operation string            // "copy" or "move"
 	width     int               // Dialog width
+
+	batch      bool // Whether batch-only choices are offered
+	remaining  int  // Files left in the batch after this one
+	applyToAll bool // Apply the choice to all remaining conflicts
 }
 
 // overwriteDialogResultMsg is the message sent when the dialog is closed
@@ -44,6 +67,10 @@ type overwriteDialogResultMsg struct {
 	destPath  string
 	filename  string
 	operation string
+
+	srcInfo    OverwriteFileInfo
+	destInfo   OverwriteFileInfo
+	applyToAll bool // Use the choice for the remaining conflicts of the batch
 }
 
 // NewOverwriteDialog creates a new overwrite confirmation dialog
@@ -61,6 +88,22 @@ func NewOverwriteDialog(filename, destPath string, srcInfo, destInfo OverwriteFi
 	}
 }
 
+// EnableBatchMode offers the skip, conditional overwrite and backup choices
+// and the "apply to all remaining" toggle. remaining is the number of files
+// left in the batch after the current one.
+func (d *OverwriteDialog) EnableBatchMode(remaining int) {
+	d.batch = true
+	d.remaining = remaining
+}
+
+// optionCount returns the number of choices offered
+func (d *OverwriteDialog) optionCount() int {
+	if d.batch {
+		return len(overwriteOptions)
+	}
+	return singleOverwriteOptions
+}
+
 // Update handles keyboard input
 func (d *OverwriteDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
 	if !d.active {
@@ -72,7 +115,7 @@ func (d *OverwriteDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
 		switch msg.String() {
 		case "j", "down":
 			d.cursor++
-			if d.cursor > 2 {
+			if d.cursor >= d.optionCount() {
 				d.cursor = 0
 			}
 			return d, nil
@@ -80,21 +123,23 @@ func (d *OverwriteDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
 		case "k", "up":
 			d.cursor--
 			if d.cursor < 0 {
-				d.cursor = 2
+				d.cursor = d.optionCount() - 1
 			}
 			return d, nil
 
-		case "1":
+		case "1", "2", "3", "4", "5", "6", "7":
+			choice := int(msg.String()[0] - '1')
+			if choice >= d.optionCount() {
+				return d, nil
+			}
 			d.active = false
-			return d, d.createResultCmd(OverwriteChoiceOverwrite)
+			return d, d.createResultCmd(OverwriteChoice(choice))
 
-		case "2":
-			d.active = false
-			return d, d.createResultCmd(OverwriteChoiceCancel)
-
-		case "3":
-			d.active = false
-			return d, d.createResultCmd(OverwriteChoiceRename)
+		case "a":
+			if d.batch {
+				d.applyToAll = !d.applyToAll
+			}
+			return d, nil
 
 		case "enter":
 			d.active = false
@@ -113,11 +158,14 @@ func (d *OverwriteDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
 func (d *OverwriteDialog) createResultCmd(choice OverwriteChoice) tea.Cmd {
 	return func() tea.Msg {
 		return overwriteDialogResultMsg{
-			choice:    choice,
-			srcPath:   d.srcPath,
-			destPath:  d.destPath,
-			filename:  d.filename,
-			operation: d.operation,
+			choice:     choice,
+			srcPath:    d.srcPath,
+			destPath:   d.destPath,
+			filename:   d.filename,
+			operation:  d.operation,
+			srcInfo:    d.srcInfo,
+			destInfo:   d.destInfo,
+			applyToAll: d.applyToAll,
 		}
 	}
 }
@@ -171,8 +219,7 @@ func (d *OverwriteDialog) View() string {
 	b.WriteString("\n\n")
 
 	// Options
-	options := []string{"Overwrite", "Cancel", "Rename"}
-	for i, opt := range options {
+	for i, opt := range overwriteOptions[:d.optionCount()] {
 		optStyle := lipgloss.NewStyle().
 			Width(width-4).
 			Padding(0, 2)
@@ -197,7 +244,17 @@ func (d *OverwriteDialog) View() string {
 		Width(width-4).
 		Padding(0, 2).
 		Foreground(lipgloss.Color("240"))
-	b.WriteString(footerStyle.Render("1-3/j/k:select Enter:confirm"))
+	if d.batch {
+		check := "[ ]"
+		if d.applyToAll {
+			check = "[x]"
+		}
+		b.WriteString(messageStyle.Render(fmt.Sprintf("%s Apply to all remaining (%d)", check, d.remaining)))
+		b.WriteString("\n\n")
+		b.WriteString(footerStyle.Render("1-7/j/k:select a:apply to all Enter:confirm"))
+	} else {
+		b.WriteString(footerStyle.Render("1-3/j/k:select Enter:confirm"))
+	}
 
 	// Border
 	boxStyle := lipgloss.NewStyle().
@@ -438,3 +438,55 @@ func TestOverwriteDialogViewWithZeroModTime(t *testing.T) {
 		t.Error("view should contain 'unknown' for zero mod time")
 	}
 }
+
+func TestOverwriteDialogBatchMode(t *testing.T) {
+	t.Run("offers batch choices", func(t *testing.T) {
+		d := NewOverwriteDialog("test.txt", "/dest", OverwriteFileInfo{}, OverwriteFileInfo{}, "copy", "/src/test.txt")
+		d.EnableBatchMode(3)
+
+		view := d.View()
+		for _, want := range []string{"Skip", "source is newer", "size differs", "backup", "Apply to all remaining (3)"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View() should contain %q", want)
+			}
+		}
+
+		for i := 0; i < 6; i++ {
+			d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+		}
+		if d.cursor != 6 {
+			t.Errorf("cursor = %d, want 6", d.cursor)
+		}
+		d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+		if d.cursor != 0 {
+			t.Errorf("cursor should wrap to 0, got %d", d.cursor)
+		}
+	})
+
+	t.Run("apply to all is passed with the choice", func(t *testing.T) {
+		d := NewOverwriteDialog("test.txt", "/dest", OverwriteFileInfo{}, OverwriteFileInfo{}, "copy", "/src/test.txt")
+		d.EnableBatchMode(2)
+
+		d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+		if !strings.Contains(d.View(), "[x] Apply to all") {
+			t.Error("View() should show the toggle as checked")
+		}
+		_, cmd := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'4'}})
+		result := cmd().(overwriteDialogResultMsg)
+		if result.choice != OverwriteChoiceSkip || !result.applyToAll {
+			t.Errorf("result = %+v, want skip applied to all", result)
+		}
+	})
+
+	t.Run("single mode ignores batch keys", func(t *testing.T) {
+		d := NewOverwriteDialog("test.txt", "/dest", OverwriteFileInfo{}, OverwriteFileInfo{}, "copy", "/src/test.txt")
+
+		if _, cmd := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'4'}}); cmd != nil {
+			t.Error("key 4 should do nothing outside a batch")
+		}
+		d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+		if d.applyToAll || strings.Contains(d.View(), "Apply to all") {
+			t.Error("apply to all should not be offered outside a batch")
+		}
+	})
+}
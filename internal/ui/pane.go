@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sakura/duofm/internal/frecency"
 	"github.com/sakura/duofm/internal/fs"
 )
 
@@ -55,6 +56,10 @@ type Pane struct {
 	theme               *Theme           // カラーテーマ
 	pendingCursorTarget string           // 親ディレクトリ遷移後のカーソル位置決定用（サブディレクトリ名）
 	history             DirectoryHistory // ディレクトリ履歴（ブラウザ風のback/forward）
+	virtual             *virtualListing  // 検索結果の仮想リスト（nil = 通常のディレクトリ表示）
+	visits              *frecency.DB     // 訪問したディレクトリの記録（nil = 記録しない）
+	tabs                []paneTab        // タブ（アクティブなタブの状態は上のフィールドが持つ）
+	activeTab           int              // アクティブなタブの番号
 }
 
 // NewPane は新しいペインを作成
@@ -77,6 +82,7 @@ func NewPane(paneID PanePosition, path string, width, height int, isActive bool,
 		sortConfig:      DefaultSortConfig(), // デフォルトは名前昇順
 		theme:           theme,
 		history:         NewDirectoryHistory(),
+		tabs:            make([]paneTab, 1),
 	}
 
 	if err := pane.LoadDirectory(); err != nil {
@@ -91,18 +97,11 @@ func NewPane(paneID PanePosition, path string, width, height int, isActive bool,
 
 // LoadDirectory はディレクトリを読み込む（同期版）
 func (p *Pane) LoadDirectory() error {
-	entries, err := fs.ReadDirectory(p.path)
+	entries, err := p.readEntries()
 	if err != nil {
 		return err
 	}
 
-	entries = SortEntries(entries, p.sortConfig)
-
-	// 隠しファイルをフィルタリング
-	if !p.showHidden {
-		entries = filterHiddenFiles(entries)
-	}
-
 	// allEntriesにすべてのエントリを保存
 	p.allEntries = entries
 	// フィルタをクリアして全エントリを表示
@@ -135,7 +134,7 @@ func (p *Pane) MoveCursorUp() {
 
 // adjustScroll はスクロール位置を調整
 func (p *Pane) adjustScroll() {
-	visibleLines := p.height - 4 // ヘッダー2行 + ボーダー1行 = 3行を除く
+	visibleLines := p.visibleLines()
 
 	// カーソルが表示範囲外に出たらスクロール
 	if p.cursor < p.scrollOffset {
@@ -145,6 +144,16 @@ func (p *Pane) adjustScroll() {
 	}
 }
 
+// visibleLines はファイルリストに使える行数を返す
+// ヘッダー2行 + ボーダー1行 = 3行を除き、タブが複数あればタブ見出しの1行も除く
+func (p *Pane) visibleLines() int {
+	lines := p.height - 4
+	if p.showsTabStrip() {
+		lines--
+	}
+	return lines
+}
+
 // EnsureCursorVisible はカーソルが表示範囲内に収まるようスクロールを調整
 func (p *Pane) EnsureCursorVisible() {
 	p.adjustScroll()
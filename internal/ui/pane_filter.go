@@ -197,19 +197,12 @@ func (p *Pane) RefreshDirectoryPreserveCursor() error {
 		selectedName = entry.Name
 	}
 
-	// Reload directory entries
-	entries, err := fs.ReadDirectory(p.path)
+	// Reload directory entries (sorted, hidden files filtered)
+	entries, err := p.readEntries()
 	if err != nil {
 		return err
 	}
 
-	entries = SortEntries(entries, p.sortConfig)
-
-	// Filter hidden files
-	if !p.showHidden {
-		entries = filterHiddenFiles(entries)
-	}
-
 	p.allEntries = entries
 	p.entries = entries
 	p.filterPattern = ""
@@ -354,3 +354,20 @@ func TestHasMarkedFiles(t *testing.T) {
 		t.Error("Expected HasMarkedFiles=true after marking")
 	}
 }
+
+func TestMarkFiles(t *testing.T) {
+	pane, tmpDir := setupTestPane(t)
+	defer os.RemoveAll(tmpDir)
+
+	pane.markedFiles["file3.txt"] = true
+	count := pane.MarkFiles([]string{"file1.txt", "subdir", "missing.txt", ".."})
+	if count != 2 {
+		t.Errorf("MarkFiles() = %d, want 2", count)
+	}
+	if !pane.IsMarked("file1.txt") || !pane.IsMarked("subdir") {
+		t.Error("file1.txt and subdir should be marked")
+	}
+	if pane.IsMarked("file3.txt") || pane.IsMarked("missing.txt") || pane.IsMarked("..") {
+		t.Errorf("unexpected marks %v", pane.GetMarkedFiles())
+	}
+}
@@ -71,3 +71,21 @@ func (p *Pane) MarkCount() int {
 func (p *Pane) HasMarkedFiles() bool {
 	return len(p.markedFiles) > 0
 }
+
+// MarkFiles replaces the marks with the given filenames
+// Names that are not shown in the pane are ignored. Returns the number of marked files
+func (p *Pane) MarkFiles(names []string) int {
+	visible := make(map[string]bool, len(p.entries))
+	for _, entry := range p.entries {
+		if !entry.IsParentDir() {
+			visible[entry.Name] = true
+		}
+	}
+	p.markedFiles = make(map[string]bool)
+	for _, name := range names {
+		if visible[name] {
+			p.markedFiles[name] = true
+		}
+	}
+	return len(p.markedFiles)
+}
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/frecency"
 	"github.com/sakura/duofm/internal/fs"
 )
 
@@ -62,6 +63,22 @@ func (p *Pane) recordPreviousPath() {
 // 通常のディレクトリ遷移で呼び出され、履歴ナビゲーション自体では呼び出されない
 func (p *Pane) addToHistory() {
 	p.history.AddToHistory(p.path)
+	p.recordVisit()
+}
+
+// recordVisit は現在のパスへの訪問を記録する
+// 記録のファイルへの保存はバックグラウンドでまとめて行われる
+func (p *Pane) recordVisit() {
+	if p.visits != nil {
+		p.visits.Visit(p.path)
+	}
+}
+
+// SetVisitRecorder は訪問したディレクトリを記録するデータベースを設定し、
+// 現在のディレクトリへの訪問を記録する
+func (p *Pane) SetVisitRecorder(db *frecency.DB) {
+	p.visits = db
+	p.recordVisit()
 }
 
 // restorePreviousPath は読み込み失敗時に前のパスに復元する
@@ -511,6 +528,27 @@ func (p *Pane) NavigateHistoryForward() error {
 	return nil
 }
 
+// NavigateHistoryTo はディレクトリ履歴の index の位置へ何段階でも一度に移動する
+// 履歴ナビゲーションなので previousPath は更新せず、履歴にも追加しない
+// ディレクトリが存在しない場合はパスと履歴位置を元に戻してエラーを返す
+func (p *Pane) NavigateHistoryTo(index int) error {
+	oldIndex := p.history.currentIndex
+	path, ok := p.history.NavigateTo(index)
+	if !ok || index == oldIndex {
+		return nil
+	}
+
+	oldPath := p.path // エラー時の復元用
+	p.path = path
+	p.pendingCursorTarget = ""
+	if err := p.LoadDirectory(); err != nil {
+		p.path = oldPath
+		p.history.NavigateTo(oldIndex)
+		return err
+	}
+	return nil
+}
+
 // NavigateHistoryBackAsync はディレクトリ履歴を遡って移動を開始
 func (p *Pane) NavigateHistoryBackAsync() tea.Cmd {
 	path, ok := p.history.NavigateBack()
@@ -28,6 +28,12 @@ func (p *Pane) ViewWithMinibuffer(diskSpace uint64, minibuffer *Minibuffer) stri
 func (p *Pane) viewInternal(diskSpace uint64, minibuffer *Minibuffer) string {
 	var b strings.Builder
 
+	// タブ見出し（タブが複数ある場合のみ）
+	if p.showsTabStrip() {
+		b.WriteString(p.renderTabStrip(false))
+		b.WriteString("\n")
+	}
+
 	// パス表示（ホームディレクトリは ~ に置換）
 	displayPath := p.formatPath()
 	// 隠しファイル表示中は [H] インジケーターを追加
@@ -39,6 +45,10 @@ func (p *Pane) viewInternal(diskSpace uint64, minibuffer *Minibuffer) string {
 		filterIndicator := p.formatFilterIndicator()
 		displayPath = filterIndicator + " " + displayPath
 	}
+	// 検索結果の表示中は検索クエリを表示
+	if p.virtual != nil {
+		displayPath = p.formatVirtualIndicator() + " " + displayPath
+	}
 	pathStyle := lipgloss.NewStyle().
 		Width(p.width-2).
 		Padding(0, 1).
@@ -73,7 +83,7 @@ func (p *Pane) viewInternal(diskSpace uint64, minibuffer *Minibuffer) string {
 	b.WriteString("\n")
 
 	// ファイルリスト（ミニバッファ表示時は1行少なく）
-	visibleLines := p.height - 4 // ヘッダー2行 + ボーダー1行 = 3行
+	visibleLines := p.visibleLines()
 	if minibuffer != nil && minibuffer.IsVisible() {
 		visibleLines-- // ミニバッファ分1行減らす
 	}
@@ -156,9 +166,11 @@ func (p *Pane) renderHeaderLine2(diskSpace uint64) string {
 		markedInfo = fmt.Sprintf("Marked %d/%d %s", markedCount, totalCount, FormatSize(markedSize))
 	}
 
-	// 空き容量情報
+	// 空き容量情報（検索中は代わりに検索中であることを表示）
 	freeInfo := ""
-	if diskSpace > 0 {
+	if p.virtual != nil && p.virtual.searching {
+		freeInfo = "Searching..."
+	} else if diskSpace > 0 {
 		freeInfo = fmt.Sprintf("%s Free", FormatSize(int64(diskSpace)))
 	}
 
@@ -319,6 +331,12 @@ func (p *Pane) formatDetailEntry(entry fs.FileEntry, nameWidth int) string {
 func (p *Pane) ViewDimmedWithDiskSpace(diskSpace uint64) string {
 	var b strings.Builder
 
+	// タブ見出し（暗いスタイル）
+	if p.showsTabStrip() {
+		b.WriteString(p.renderTabStrip(true))
+		b.WriteString("\n")
+	}
+
 	// パス表示（暗いスタイル）
 	displayPath := p.formatPath()
 	// 隠しファイル表示中は [H] インジケーターを追加
@@ -355,7 +373,7 @@ func (p *Pane) ViewDimmedWithDiskSpace(diskSpace uint64) string {
 	b.WriteString("\n")
 
 	// ファイルリスト
-	visibleLines := p.height - 4 // ヘッダー2行 + ボーダー1行 = 3行
+	visibleLines := p.visibleLines()
 	endIdx := p.scrollOffset + visibleLines
 	if endIdx > len(p.entries) {
 		endIdx = len(p.entries)
@@ -0,0 +1,295 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// paneTab はタブごとの状態を保持する
+// アクティブなタブの状態はPane自身のフィールドにあり、タブを切り替えるときに
+// ここへ退避・復元する
+type paneTab struct {
+	path                string
+	entries             []fs.FileEntry
+	allEntries          []fs.FileEntry
+	cursor              int
+	scrollOffset        int
+	showHidden          bool
+	previousPath        string
+	filterPattern       string
+	filterMode          SearchMode
+	markedFiles         map[string]bool
+	sortConfig          SortConfig
+	pendingCursorTarget string
+	history             DirectoryHistory
+	virtual             *virtualListing
+}
+
+// captureTab は現在の表示状態をタブとして取り出す
+func (p *Pane) captureTab() paneTab {
+	return paneTab{
+		path:                p.path,
+		entries:             p.entries,
+		allEntries:          p.allEntries,
+		cursor:              p.cursor,
+		scrollOffset:        p.scrollOffset,
+		showHidden:          p.showHidden,
+		previousPath:        p.previousPath,
+		filterPattern:       p.filterPattern,
+		filterMode:          p.filterMode,
+		markedFiles:         p.markedFiles,
+		sortConfig:          p.sortConfig,
+		pendingCursorTarget: p.pendingCursorTarget,
+		history:             p.history,
+		virtual:             p.virtual,
+	}
+}
+
+// restoreTab はタブの状態をペインに戻す
+func (p *Pane) restoreTab(tab paneTab) {
+	p.path = tab.path
+	p.entries = tab.entries
+	p.allEntries = tab.allEntries
+	p.cursor = tab.cursor
+	p.scrollOffset = tab.scrollOffset
+	p.showHidden = tab.showHidden
+	p.previousPath = tab.previousPath
+	p.filterPattern = tab.filterPattern
+	p.filterMode = tab.filterMode
+	p.markedFiles = tab.markedFiles
+	p.sortConfig = tab.sortConfig
+	p.pendingCursorTarget = tab.pendingCursorTarget
+	p.history = tab.history
+	p.virtual = tab.virtual
+	p.pendingPath = ""
+}
+
+// newTabState は path を開いた新しいタブを作成する
+func newTabState(path string, sortConfig SortConfig, showHidden bool) (paneTab, error) {
+	entries, err := fs.ReadDirectory(path)
+	if err != nil {
+		return paneTab{}, err
+	}
+	entries = SortEntries(entries, sortConfig)
+	if !showHidden {
+		entries = filterHiddenFiles(entries)
+	}
+
+	history := NewDirectoryHistory()
+	history.AddToHistory(path)
+	return paneTab{
+		path:        path,
+		entries:     entries,
+		allEntries:  entries,
+		showHidden:  showHidden,
+		markedFiles: make(map[string]bool),
+		sortConfig:  sortConfig,
+		history:     history,
+	}, nil
+}
+
+// TabCount はタブの数を返す
+func (p *Pane) TabCount() int {
+	return len(p.tabs)
+}
+
+// ActiveTab はアクティブなタブの番号（0始まり）を返す
+func (p *Pane) ActiveTab() int {
+	return p.activeTab
+}
+
+// TabPaths は各タブのパスを返す
+func (p *Pane) TabPaths() []string {
+	if len(p.tabs) == 0 {
+		return []string{p.path}
+	}
+	paths := make([]string, len(p.tabs))
+	for i, tab := range p.tabs {
+		paths[i] = tab.path
+	}
+	paths[p.activeTab] = p.path
+	return paths
+}
+
+// NewTab は path を開いたタブを現在のタブの右に追加し、そのタブに切り替える
+// ソート設定と隠しファイルの表示は現在のタブから引き継ぐ
+func (p *Pane) NewTab(path string) error {
+	tab, err := newTabState(path, p.sortConfig, p.showHidden)
+	if err != nil {
+		return err
+	}
+	p.insertTab(tab)
+	p.recordVisit()
+	return nil
+}
+
+// DuplicateTab は現在のタブを複製して右に追加し、そのタブに切り替える
+// パス・カーソル・ソート・フィルタ・マーク・履歴をすべて引き継ぐ
+func (p *Pane) DuplicateTab() {
+	tab := p.captureTab()
+	tab.entries = slices.Clone(p.entries)
+	tab.allEntries = slices.Clone(p.allEntries)
+	tab.markedFiles = maps.Clone(p.markedFiles)
+	tab.history = p.history.Clone()
+	if p.virtual != nil {
+		virtual := *p.virtual
+		tab.virtual = &virtual
+	}
+	p.insertTab(tab)
+}
+
+// insertTab は現在のタブの右にタブを追加し、そのタブに切り替える
+func (p *Pane) insertTab(tab paneTab) {
+	p.tabs[p.activeTab] = p.captureTab()
+	p.activeTab++
+	p.tabs = append(p.tabs[:p.activeTab], append([]paneTab{tab}, p.tabs[p.activeTab:]...)...)
+	p.restoreTab(tab)
+	p.adjustScroll()
+}
+
+// CloseTab は現在のタブを閉じて隣のタブに切り替える
+// 最後の1つのタブは閉じられないので false を返す
+func (p *Pane) CloseTab() bool {
+	if len(p.tabs) <= 1 {
+		return false
+	}
+	p.tabs = append(p.tabs[:p.activeTab], p.tabs[p.activeTab+1:]...)
+	if p.activeTab >= len(p.tabs) {
+		p.activeTab = len(p.tabs) - 1
+	}
+	p.restoreTab(p.tabs[p.activeTab])
+	p.reloadTab()
+	return true
+}
+
+// SwitchTab は index のタブに切り替える
+func (p *Pane) SwitchTab(index int) {
+	if index < 0 || index >= len(p.tabs) || index == p.activeTab {
+		return
+	}
+	p.tabs[p.activeTab] = p.captureTab()
+	p.activeTab = index
+	p.restoreTab(p.tabs[index])
+	p.reloadTab()
+}
+
+// NextTab は右のタブに切り替える（右端では左端に戻る）
+func (p *Pane) NextTab() {
+	p.SwitchTab((p.activeTab + 1) % len(p.tabs))
+}
+
+// PrevTab は左のタブに切り替える（左端では右端に移る）
+func (p *Pane) PrevTab() {
+	p.SwitchTab((p.activeTab + len(p.tabs) - 1) % len(p.tabs))
+}
+
+// updateInactiveTabs はアクティブでない各タブの状態を update で変更する
+// 変更したタブのエントリは切り替え時に読み直される
+func (p *Pane) updateInactiveTabs(update func(tab *paneTab)) {
+	for i := range p.tabs {
+		if i != p.activeTab {
+			update(&p.tabs[i])
+		}
+	}
+}
+
+// reloadTab は切り替えたタブのエントリを読み直す
+// タブを離れていた間の変更を反映しつつ、フィルタ・マーク・カーソル位置は維持する
+// 読み直せない場合は離れたときの表示のまま
+func (p *Pane) reloadTab() {
+	var selectedName string
+	if entry := p.SelectedEntry(); entry != nil {
+		selectedName = entry.Name
+	}
+
+	entries, err := p.readEntries()
+	if err != nil {
+		p.adjustScroll()
+		return
+	}
+	p.allEntries = entries
+
+	// 存在しなくなったファイルのマークを外す
+	existing := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		existing[entry.Name] = true
+	}
+	for name := range p.markedFiles {
+		if !existing[name] {
+			delete(p.markedFiles, name)
+		}
+	}
+
+	if p.IsFiltered() {
+		_ = p.ApplyFilter(p.filterPattern, p.filterMode)
+	} else {
+		p.entries = entries
+	}
+
+	if index := p.findEntryIndex(selectedName); index >= 0 {
+		p.cursor = index
+	} else if p.cursor >= len(p.entries) {
+		p.cursor = max(0, len(p.entries)-1)
+	}
+	p.adjustScroll()
+}
+
+// tabLabel はタブ見出しに表示する名前を返す
+func tabLabel(path string) string {
+	if name := filepath.Base(path); name != "" && name != string(filepath.Separator) {
+		return name
+	}
+	return path
+}
+
+// showsTabStrip はタブ見出しを表示するかどうかを返す
+func (p *Pane) showsTabStrip() bool {
+	return len(p.tabs) > 1
+}
+
+// renderTabStrip はタブ見出しの行をレンダリングする
+// 各タブは番号とディレクトリ名で表示し、アクティブなタブを強調する
+func (p *Pane) renderTabStrip(dimmed bool) string {
+	paths := p.TabPaths()
+	available := p.width - 4
+	// 1タブあたりの幅（" 1:name " の前後の空白を含む）
+	tabWidth := max(available/len(paths), 6)
+
+	var b strings.Builder
+	for i, path := range paths {
+		label := fmt.Sprintf("%d:%s", i+1, tabLabel(path))
+		label = " " + runewidth.Truncate(label, tabWidth-2, "…") + " "
+
+		style := lipgloss.NewStyle()
+		switch {
+		case dimmed:
+			style = style.Background(p.theme.DimmedBg).Foreground(p.theme.DimmedFg)
+			if i == p.activeTab {
+				style = style.Bold(true)
+			}
+		case i == p.activeTab && p.isActive:
+			style = style.Bold(true).Background(p.theme.CursorBg).Foreground(p.theme.CursorFg)
+		case i == p.activeTab:
+			style = style.Bold(true).Background(p.theme.CursorBgInactive).Foreground(p.theme.CursorFg)
+		default:
+			style = style.Foreground(p.theme.HeaderFgInactive)
+		}
+		b.WriteString(style.Render(label))
+	}
+
+	lineStyle := lipgloss.NewStyle().
+		Width(p.width-2).
+		MaxWidth(p.width-2).
+		Padding(0, 1)
+	if dimmed {
+		lineStyle = lineStyle.Background(p.theme.DimmedBg)
+	}
+	return lineStyle.Render(b.String())
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPaneTabs(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"alpha", "beta"} {
+		os.MkdirAll(filepath.Join(root, name), 0755)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.log"} {
+		os.WriteFile(filepath.Join(root, "alpha", name), []byte(name), 0644)
+	}
+
+	pane, err := NewPane(LeftPane, filepath.Join(root, "alpha"), 80, 20, true, nil)
+	if err != nil {
+		t.Fatalf("NewPane() error = %v", err)
+	}
+	if pane.TabCount() != 1 || strings.Contains(pane.View(), "1:alpha") {
+		t.Fatalf("a new pane should have one tab and no tab strip")
+	}
+
+	// Leave state behind in the first tab: a filter, a mark, the cursor and a sort
+	pane.SetSortConfig(SortConfig{Field: SortByName, Order: SortDesc})
+	pane.ApplySortAndPreserveCursor()
+	pane.ApplyFilter("txt", SearchModeIncremental)
+	pane.SetCursor(1)
+	pane.ToggleMark()
+	marked := pane.GetMarkedFiles()
+
+	if err := pane.NewTab(filepath.Join(root, "beta")); err != nil {
+		t.Fatalf("NewTab() error = %v", err)
+	}
+	if pane.TabCount() != 2 || pane.ActiveTab() != 1 || pane.Path() != filepath.Join(root, "beta") {
+		t.Fatalf("after NewTab: count %d, active %d, path %s", pane.TabCount(), pane.ActiveTab(), pane.Path())
+	}
+	if pane.IsFiltered() || pane.MarkCount() != 0 || pane.GetSortConfig().Order != SortDesc {
+		t.Errorf("a new tab should start unfiltered and unmarked with the current sort")
+	}
+	if view := pane.View(); !strings.Contains(view, "1:alpha") || !strings.Contains(view, "2:beta") {
+		t.Errorf("view should show the tab strip:\n%s", view)
+	}
+
+	// Switching back restores everything, picking up files created meanwhile
+	os.WriteFile(filepath.Join(root, "alpha", "d.txt"), []byte("d"), 0644)
+	pane.PrevTab()
+	if pane.Path() != filepath.Join(root, "alpha") || pane.FilterPattern() != "txt" {
+		t.Fatalf("after PrevTab: path %s, filter %q", pane.Path(), pane.FilterPattern())
+	}
+	if got := pane.GetMarkedFiles(); len(got) != 1 || got[0] != marked[0] {
+		t.Errorf("marks = %v, want %v", got, marked)
+	}
+	if entry := pane.SelectedEntry(); entry == nil || entry.Name != marked[0] {
+		t.Errorf("cursor should stay on %s, got %v", marked[0], entry)
+	}
+	if pane.FilteredEntryCount() != 3 { // d.txt, b.txt, a.txt
+		t.Errorf("FilteredEntryCount() = %d, want 3", pane.FilteredEntryCount())
+	}
+
+	// A duplicate is independent of the original
+	pane.DuplicateTab()
+	if pane.TabCount() != 3 || pane.ActiveTab() != 1 || pane.MarkCount() != 1 {
+		t.Fatalf("after DuplicateTab: count %d, active %d, marks %d", pane.TabCount(), pane.ActiveTab(), pane.MarkCount())
+	}
+	pane.ClearMarks()
+	pane.SwitchTab(0)
+	if pane.MarkCount() != 1 {
+		t.Errorf("clearing marks in the duplicate should not affect the original")
+	}
+
+	if got := pane.TabPaths(); len(got) != 3 || got[2] != filepath.Join(root, "beta") {
+		t.Errorf("TabPaths() = %v", got)
+	}
+
+	// Cycling wraps around, and the last tab cannot be closed
+	pane.PrevTab()
+	if pane.ActiveTab() != 2 {
+		t.Errorf("PrevTab() from the first tab should wrap to the last, got %d", pane.ActiveTab())
+	}
+	for pane.CloseTab() {
+	}
+	if pane.TabCount() != 1 {
+		t.Errorf("TabCount() = %d after closing all tabs, want 1", pane.TabCount())
+	}
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// virtualListing は検索結果を表示している仮想リストの状態
+// エントリの Name は root からの相対パスなので、通常のエントリと同じく
+// filepath.Join(path, Name) で実際のパスになる
+type virtualListing struct {
+	root      string // 検索を開始したディレクトリ（ペインのパス）
+	query     string // 検索クエリ（ヘッダー表示用）
+	searching bool   // 検索中かどうか
+}
+
+// StartVirtual は現在のディレクトリを起点とする検索結果の表示を開始する
+// 結果は AppendVirtualEntries で追加されていく
+func (p *Pane) StartVirtual(query string) {
+	p.virtual = &virtualListing{
+		root:      p.path,
+		query:     query,
+		searching: true,
+	}
+	p.pendingCursorTarget = ""
+
+	// ".." で検索結果を抜けられるようにする
+	entries := []fs.FileEntry{{Name: "..", IsDir: true}}
+	p.allEntries = entries
+	p.entries = entries
+	p.filterPattern = ""
+	p.filterMode = SearchModeNone
+	p.cursor = 0
+	p.scrollOffset = 0
+	p.markedFiles = make(map[string]bool)
+}
+
+// AppendVirtualEntries は検索結果を末尾に追加する
+// フィルタ適用中は一致するものだけを表示に加える
+func (p *Pane) AppendVirtualEntries(entries []fs.FileEntry) {
+	if p.virtual == nil || len(entries) == 0 {
+		return
+	}
+
+	if !p.IsFiltered() {
+		p.allEntries = append(p.allEntries, entries...)
+		p.entries = p.allEntries
+		return
+	}
+
+	// entries と allEntries は別のスライスなので個別に追加する
+	p.allEntries = append(p.allEntries, entries...)
+	var matched []fs.FileEntry
+	switch p.filterMode {
+	case SearchModeIncremental:
+		matched = filterIncremental(entries, p.filterPattern)
+	case SearchModeRegex:
+		matched, _ = filterRegex(entries, p.filterPattern)
+	}
+	p.entries = append(p.entries, matched...)
+}
+
+// FinishVirtual は検索の終了を記録する
+func (p *Pane) FinishVirtual() {
+	if p.virtual != nil {
+		p.virtual.searching = false
+	}
+}
+
+// IsVirtual は検索結果を表示中かどうかを返す
+// 別のディレクトリへの移動を開始した後は false になる
+func (p *Pane) IsVirtual() bool {
+	return p.virtual != nil && p.virtual.root == p.path
+}
+
+// VirtualQuery は表示中の検索結果のクエリを返す
+func (p *Pane) VirtualQuery() string {
+	if p.virtual == nil {
+		return ""
+	}
+	return p.virtual.query
+}
+
+// formatVirtualIndicator は検索結果のインジケーターをフォーマットする
+// 例: [find:*.go]
+func (p *Pane) formatVirtualIndicator() string {
+	query := p.virtual.query
+	// クエリが長い場合は切り詰める
+	maxLen := 15
+	if len(query) > maxLen {
+		query = query[:maxLen-2] + ".."
+	}
+	return "[find:" + query + "]"
+}
+
+// ExitVirtual は検索結果の表示をやめて起点のディレクトリを読み込み直す
+func (p *Pane) ExitVirtual() error {
+	if p.virtual == nil {
+		return nil
+	}
+	p.virtual = nil
+	return p.LoadDirectory()
+}
+
+// readEntries はペインに表示するエントリを読み込む
+// 検索結果の表示中は、ディレクトリを読む代わりに結果の各エントリを読み直し、
+// 削除や移動で存在しなくなったものを取り除く。ペインが別のディレクトリに
+// 移動していれば検索結果の表示を終える
+func (p *Pane) readEntries() ([]fs.FileEntry, error) {
+	if p.virtual != nil && p.virtual.root != p.path {
+		p.virtual = nil
+	}
+	if p.virtual == nil {
+		entries, err := fs.ReadDirectory(p.path)
+		if err != nil {
+			return nil, err
+		}
+		entries = SortEntries(entries, p.sortConfig)
+		if !p.showHidden {
+			entries = filterHiddenFiles(entries)
+		}
+		return entries, nil
+	}
+
+	// 検索結果は見つかった順（またはソート済みの順）を維持する
+	entries := make([]fs.FileEntry, 0, len(p.allEntries))
+	for _, entry := range p.allEntries {
+		if entry.IsParentDir() {
+			entries = append(entries, entry)
+			continue
+		}
+		if updated, err := fs.ReadEntry(p.path, entry.Name); err == nil {
+			entries = append(entries, updated)
+		}
+	}
+	return entries, nil
+}
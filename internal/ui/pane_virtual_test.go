@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sakura/duofm/internal/fs"
+)
+
+func TestPaneVirtualListing(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "a", "b"), 0755)
+	os.WriteFile(filepath.Join(root, "a", "b", "deep.txt"), []byte("deep"), 0644)
+	os.WriteFile(filepath.Join(root, "a", "gone.txt"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(root, "top.txt"), []byte("top"), 0644)
+
+	pane, err := NewPane(LeftPane, root, 80, 20, true, nil)
+	if err != nil {
+		t.Fatalf("NewPane() error = %v", err)
+	}
+
+	pane.StartVirtual("*.txt")
+	if !pane.IsVirtual() || len(pane.entries) != 1 || !pane.entries[0].IsParentDir() {
+		t.Fatalf("StartVirtual() should only show '..', got %v", pane.entries)
+	}
+
+	var found []fs.FileEntry
+	for _, name := range []string{filepath.Join("a", "b", "deep.txt"), filepath.Join("a", "gone.txt")} {
+		entry, err := fs.ReadEntry(root, name)
+		if err != nil {
+			t.Fatalf("ReadEntry() error = %v", err)
+		}
+		found = append(found, entry)
+	}
+	pane.AppendVirtualEntries(found)
+	pane.FinishVirtual()
+
+	if pane.TotalEntryCount() != 2 {
+		t.Errorf("TotalEntryCount() = %d, want 2", pane.TotalEntryCount())
+	}
+	if view := pane.View(); !strings.Contains(view, "[find:*.txt]") || !strings.Contains(view, "a/b/deep.txt") {
+		t.Errorf("view should show the query and relative paths:\n%s", view)
+	}
+
+	// Marks use the relative path, so the marked file resolves under the root
+	pane.cursor = 1
+	pane.ToggleMark()
+	if got := pane.GetMarkedFiles(); len(got) != 1 || filepath.Join(pane.Path(), got[0]) != filepath.Join(root, "a", "b", "deep.txt") {
+		t.Errorf("marked files = %v", got)
+	}
+
+	// Reloading re-reads the results instead of the directory
+	os.Remove(filepath.Join(root, "a", "gone.txt"))
+	if err := pane.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if !pane.IsVirtual() || pane.TotalEntryCount() != 1 || pane.entries[1].Name != filepath.Join("a", "b", "deep.txt") {
+		t.Errorf("entries after refresh = %v", pane.entries)
+	}
+	if !pane.IsMarked(filepath.Join("a", "b", "deep.txt")) {
+		t.Error("Refresh() should keep the mark of a result that still exists")
+	}
+
+	if err := pane.ExitVirtual(); err != nil {
+		t.Fatalf("ExitVirtual() error = %v", err)
+	}
+	if pane.IsVirtual() || pane.findEntryIndex("top.txt") < 0 {
+		t.Errorf("ExitVirtual() should list the directory again, got %v", pane.entries)
+	}
+}
+
+func TestPaneVirtualListingFilter(t *testing.T) {
+	root := t.TempDir()
+	pane, err := NewPane(LeftPane, root, 80, 20, true, nil)
+	if err != nil {
+		t.Fatalf("NewPane() error = %v", err)
+	}
+
+	pane.StartVirtual("x")
+	pane.AppendVirtualEntries([]fs.FileEntry{{Name: "x/alpha"}, {Name: "x/beta"}})
+	pane.ApplyFilter("alp", SearchModeIncremental)
+	pane.AppendVirtualEntries([]fs.FileEntry{{Name: "y/alpine"}, {Name: "y/gamma"}})
+
+	if pane.TotalEntryCount() != 4 {
+		t.Errorf("TotalEntryCount() = %d, want 4", pane.TotalEntryCount())
+	}
+	var names []string
+	for _, e := range pane.entries {
+		names = append(names, e.Name)
+	}
+	if len(names) != 2 || names[0] != "x/alpha" || names[1] != "y/alpine" {
+		t.Errorf("filtered entries = %v, want new results filtered too", names)
+	}
+}
+
+func TestPaneVirtualListingEndsOnNavigation(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, "sub"), 0755)
+	pane, err := NewPane(LeftPane, root, 80, 20, true, nil)
+	if err != nil {
+		t.Fatalf("NewPane() error = %v", err)
+	}
+
+	pane.StartVirtual("sub")
+	if err := pane.ChangeDirectory(filepath.Join(root, "sub")); err != nil {
+		t.Fatalf("ChangeDirectory() error = %v", err)
+	}
+	if pane.IsVirtual() || pane.virtual != nil {
+		t.Error("moving to another directory should end the virtual listing")
+	}
+}
@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// preflightDialogMaxVisible is the number of conflicting paths shown at once
+const preflightDialogMaxVisible = 8
+
+// PreflightChoice represents how the conflicts found by the preflight are resolved
+type PreflightChoice int
+
+const (
+	PreflightChoiceOverwriteAll PreflightChoice = iota
+	PreflightChoiceSkipExisting
+	PreflightChoiceAskEach
+	PreflightChoiceCancel
+	// PreflightChoiceStart starts an operation without conflicts; it is only
+	// offered when the dialog summarizes a filtered operation
+	PreflightChoiceStart
+)
+
+// preflightChoiceLabels are the labels of the choices, indexed by PreflightChoice
+var preflightChoiceLabels = []string{"Overwrite all", "Skip existing", "Ask for each", "Cancel", "Start"}
+
+// preflightConflictChoices are the choices offered when there are conflicts
+var preflightConflictChoices = []PreflightChoice{
+	PreflightChoiceOverwriteAll, PreflightChoiceSkipExisting, PreflightChoiceAskEach, PreflightChoiceCancel,
+}
+
+// preflightSummaryChoices are the choices offered when there is nothing to resolve
+var preflightSummaryChoices = []PreflightChoice{PreflightChoiceStart, PreflightChoiceCancel}
+
+// preflightResultMsg is sent when the preflight walk of a copy/move finished
+type preflightResultMsg struct {
+	operation string
+	sources   []string
+	dest      string
+	plan      *fs.Plan
+	err       error
+}
+
+// preflightDialogResultMsg is sent when the user decided on the conflicts
+type preflightDialogResultMsg struct {
+	choice    PreflightChoice
+	operation string
+	sources   []string
+	dest      string
+}
+
+// PreflightDialog lists every conflicting destination of a copy/move before
+// it starts and lets the user resolve all of them at once. With an active
+// filter it is also shown without conflicts to summarize the matched files.
+type PreflightDialog struct {
+	operation string
+	sources   []string
+	dest      string
+	plan      *fs.Plan
+	filter    string            // Active include/exclude filter, empty if none
+	choices   []PreflightChoice // Offered choices
+	cursor    int               // Selected choice
+	offset    int               // First visible conflict
+	active    bool
+	width     int
+}
+
+// NewPreflightDialog creates a dialog for the conflicts found by plan
+func NewPreflightDialog(operation string, sources []string, dest string, plan *fs.Plan) *PreflightDialog {
+	choices := preflightConflictChoices
+	if !plan.HasConflicts() {
+		choices = preflightSummaryChoices
+	}
+	return &PreflightDialog{
+		operation: operation,
+		sources:   sources,
+		dest:      dest,
+		plan:      plan,
+		choices:   choices,
+		active:    true,
+		width:     76,
+	}
+}
+
+// SetFilter shows the active filter and how many files it matched
+func (d *PreflightDialog) SetFilter(filter string) {
+	d.filter = filter
+}
+
+// Update handles keyboard input
+func (d *PreflightDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch key := keyMsg.String(); key {
+	case "j", "down":
+		d.scroll(1)
+	case "k", "up":
+		d.scroll(-1)
+	case "tab", "l", "right":
+		d.cursor = (d.cursor + 1) % len(d.choices)
+	case "shift+tab", "h", "left":
+		d.cursor = (d.cursor + len(d.choices) - 1) % len(d.choices)
+	case "1", "2", "3", "4":
+		i := int(key[0] - '1')
+		if i >= len(d.choices) {
+			return d, nil
+		}
+		d.active = false
+		return d, d.resultCmd(d.choices[i])
+	case "enter":
+		d.active = false
+		return d, d.resultCmd(d.choices[d.cursor])
+	case "esc", "ctrl+c":
+		d.active = false
+		return d, d.resultCmd(PreflightChoiceCancel)
+	}
+
+	return d, nil
+}
+
+// scroll moves the visible window of the conflict list
+func (d *PreflightDialog) scroll(delta int) {
+	maxOffset := len(d.plan.Conflicts) - preflightDialogMaxVisible
+	d.offset += delta
+	if d.offset > maxOffset {
+		d.offset = maxOffset
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+}
+
+func (d *PreflightDialog) resultCmd(choice PreflightChoice) tea.Cmd {
+	return func() tea.Msg {
+		return preflightDialogResultMsg{
+			choice:    choice,
+			operation: d.operation,
+			sources:   d.sources,
+			dest:      d.dest,
+		}
+	}
+}
+
+// View renders the dialog
+func (d *PreflightDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+	innerWidth := width - 4
+
+	titleStyle := lipgloss.NewStyle().
+		Width(innerWidth).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	lineStyle := lipgloss.NewStyle().
+		Width(innerWidth).
+		Padding(0, 1)
+	mutedStyle := lineStyle.Foreground(lipgloss.Color("245"))
+
+	var title string
+	switch conflicts := len(d.plan.Conflicts); conflicts {
+	case 0:
+		title = fmt.Sprintf("%s %s?", capitalize(d.operation), countFiles(d.plan.TotalFiles))
+	case 1:
+		title = fmt.Sprintf("%s: 1 file already exists", capitalize(d.operation))
+	default:
+		title = fmt.Sprintf("%s: %d files already exist", capitalize(d.operation), conflicts)
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n\n")
+	b.WriteString(lineStyle.Render(fmt.Sprintf("%s, %s -> %s",
+		countFiles(d.plan.TotalFiles), FormatSize(d.plan.TotalBytes), truncatePath(d.dest, innerWidth-30))))
+	b.WriteString("\n")
+	if d.filter != "" {
+		b.WriteString(mutedStyle.Render(truncatePath(fmt.Sprintf("Filter %s: %d of %d files match",
+			d.filter, d.plan.TotalFiles, d.plan.TotalFiles+d.plan.ExcludedFiles), innerWidth-2)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Conflicting paths, relative to the destination when possible
+	end := d.offset + preflightDialogMaxVisible
+	if end > len(d.plan.Conflicts) {
+		end = len(d.plan.Conflicts)
+	}
+	for _, path := range d.plan.Conflicts[d.offset:end] {
+		if rel, err := filepath.Rel(d.dest, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		b.WriteString(mutedStyle.Render(truncatePath(path, innerWidth-2)))
+		b.WriteString("\n")
+	}
+	if len(d.plan.Conflicts) > preflightDialogMaxVisible {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%d-%d of %d", d.offset+1, end, len(d.plan.Conflicts))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Choices
+	var choices []string
+	for i, choice := range d.choices {
+		label := preflightChoiceLabels[choice]
+		style := lipgloss.NewStyle().Padding(0, 1)
+		if i == d.cursor {
+			style = style.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0"))
+		}
+		choices = append(choices, style.Render(fmt.Sprintf("%d.%s", i+1, label)))
+	}
+	b.WriteString(lineStyle.Render(strings.Join(choices, " ")))
+	b.WriteString("\n\n")
+
+	footerStyle := lineStyle.Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render(fmt.Sprintf("1-%d/Tab:select Enter:confirm j/k:scroll Esc:cancel", len(d.choices))))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// IsActive returns whether the dialog is active
+func (d *PreflightDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type
+func (d *PreflightDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+func testPreflightDialog(conflicts int) *PreflightDialog {
+	plan := &fs.Plan{TotalFiles: 12, TotalBytes: 2048}
+	for i := 0; i < conflicts; i++ {
+		plan.Conflicts = append(plan.Conflicts, fmt.Sprintf("/dest/dir/file%02d.txt", i))
+	}
+	return NewPreflightDialog("copy", []string{"/src/dir"}, "/dest", plan)
+}
+
+func TestPreflightDialogView(t *testing.T) {
+	dialog := testPreflightDialog(3)
+
+	if dialog.DisplayType() != DialogDisplayScreen {
+		t.Error("expected DialogDisplayScreen")
+	}
+	view := dialog.View()
+	for _, want := range []string{"Copy: 3 files already exist", "12 files", "dir/file00.txt", "Overwrite all", "Skip existing", "Ask for each"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q", want)
+		}
+	}
+}
+
+func TestPreflightDialogTitleSingular(t *testing.T) {
+	if view := testPreflightDialog(1).View(); !strings.Contains(view, "Copy: 1 file already exists") {
+		t.Errorf("View() should use the singular:\n%s", view)
+	}
+
+	dialog := NewPreflightDialog("move", []string{"/src/a.txt"}, "/dest", &fs.Plan{TotalFiles: 1})
+	if view := dialog.View(); !strings.Contains(view, "Move 1 file?") || !strings.Contains(view, "1 file, ") {
+		t.Errorf("View() should use the singular:\n%s", view)
+	}
+}
+
+func TestPreflightDialogScroll(t *testing.T) {
+	dialog := testPreflightDialog(preflightDialogMaxVisible + 2)
+
+	for i := 0; i < 5; i++ {
+		dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	}
+	if dialog.offset != 2 {
+		t.Errorf("offset = %d, want 2 (clamped to the last page)", dialog.offset)
+	}
+	if !strings.Contains(dialog.View(), fmt.Sprintf("3-%d of %d", preflightDialogMaxVisible+2, preflightDialogMaxVisible+2)) {
+		t.Error("View() should show the visible range")
+	}
+
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	if dialog.offset != 1 {
+		t.Errorf("offset = %d, want 1", dialog.offset)
+	}
+}
+
+func TestPreflightDialogChoices(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want PreflightChoice
+	}{
+		{"enter selects overwrite all", []tea.KeyMsg{{Type: tea.KeyEnter}}, PreflightChoiceOverwriteAll},
+		{"tab then enter skips", []tea.KeyMsg{{Type: tea.KeyTab}, {Type: tea.KeyEnter}}, PreflightChoiceSkipExisting},
+		{"number key", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'3'}}}, PreflightChoiceAskEach},
+		{"shift+tab wraps", []tea.KeyMsg{{Type: tea.KeyShiftTab}, {Type: tea.KeyEnter}}, PreflightChoiceCancel},
+		{"esc cancels", []tea.KeyMsg{{Type: tea.KeyEsc}}, PreflightChoiceCancel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialog := testPreflightDialog(1)
+			var cmd tea.Cmd
+			for _, key := range tt.keys {
+				_, cmd = dialog.Update(key)
+			}
+			if cmd == nil {
+				t.Fatal("expected a result command")
+			}
+			msg, ok := cmd().(preflightDialogResultMsg)
+			if !ok {
+				t.Fatal("expected preflightDialogResultMsg")
+			}
+			if msg.choice != tt.want {
+				t.Errorf("choice = %v, want %v", msg.choice, tt.want)
+			}
+			if msg.operation != "copy" || msg.dest != "/dest" || len(msg.sources) != 1 {
+				t.Errorf("result should carry the operation, got %+v", msg)
+			}
+			if dialog.IsActive() {
+				t.Error("dialog should close after a choice")
+			}
+		})
+	}
+}
+
+func TestPreflightDialogFilterSummary(t *testing.T) {
+	plan := &fs.Plan{TotalFiles: 3, TotalBytes: 100, ExcludedFiles: 5}
+	dialog := NewPreflightDialog("move", []string{"/src/dir"}, "/dest", plan)
+	dialog.SetFilter("*.go !vendor")
+
+	view := dialog.View()
+	for _, want := range []string{"Move 3 files?", "*.go !vendor: 3 of 8 files match", "Start"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q", want)
+		}
+	}
+	if strings.Contains(view, "Overwrite all") {
+		t.Error("View() should not offer conflict choices without conflicts")
+	}
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}})
+	if cmd != nil {
+		t.Error("choices beyond the offered ones should be ignored")
+	}
+	_, cmd = dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg := cmd().(preflightDialogResultMsg); msg.choice != PreflightChoiceStart {
+		t.Errorf("choice = %v, want PreflightChoiceStart", msg.choice)
+	}
+}
@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/session"
+)
+
+// セッションファイルでのソート項目・順序・フィルタモードの名前
+var (
+	sortFieldNames  = map[SortField]string{SortByName: "name", SortBySize: "size", SortByDate: "date"}
+	sortOrderNames  = map[SortOrder]string{SortAsc: "asc", SortDesc: "desc"}
+	filterModeNames = map[SearchMode]string{SearchModeIncremental: "incremental", SearchModeRegex: "regex"}
+)
+
+// SetSession はセッションの保存先と、起動時に復元する前回のセッションを設定
+func (m *Model) SetSession(path string, state *session.State) {
+	m.sessionPath = path
+	m.savedSession = state
+}
+
+// restoreSession は前回終了時のセッションを復元する（ペインの作成直後に呼び出す）
+func (m *Model) restoreSession() {
+	if m.savedSession == nil {
+		return
+	}
+	m.applySession(m.savedSession)
+	m.savedSession = nil
+}
+
+// SaveSession は両ペインの状態を保存する（終了時に呼び出す）
+// 開始ディレクトリが指定されてセッションを復元しなかった場合と、
+// 選択モードで起動された場合は前回のセッションを残す
+func (m Model) SaveSession() error {
+	if m.sessionPath == "" || m.leftPane == nil || m.rightPane == nil {
+		return nil
+	}
+	if m.startOptions.hasPaths() || m.chooseMode != ChooseNone {
+		return nil
+	}
+	return session.Save(m.sessionPath, m.sessionState())
+}
+
+// LastDirectory は終了時のアクティブペインのディレクトリを返す（シェルの cd 用）
+func (m Model) LastDirectory() string {
+	if m.leftPane == nil || m.rightPane == nil {
+		return m.leftPath
+	}
+	return m.getActivePane().Path()
+}
+
+// namedSessionDir は名前付きセッションの保存先を返す（空 = 利用不可）
+func (m *Model) namedSessionDir() string {
+	if m.sessionPath == "" {
+		return ""
+	}
+	return session.NamedDir(m.sessionPath)
+}
+
+// handleSessionsUI は名前付きセッションのダイアログを表示
+func (m Model) handleSessionsUI() (tea.Model, tea.Cmd) {
+	dir := m.namedSessionDir()
+	if dir == "" {
+		m.statusMessage = "Sessions are unavailable"
+		m.isStatusError = true
+		return m, statusMessageClearCmd(3 * time.Second)
+	}
+	sessions, err := session.ListNamed(dir)
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.isStatusError = true
+		return m, statusMessageClearCmd(5 * time.Second)
+	}
+	m.dialog = NewSessionDialog(sessions)
+	return m, nil
+}
+
+// saveNamedSession は state を dir に name という名前で保存する
+func saveNamedSession(dir, name string, state *session.State) tea.Cmd {
+	return func() tea.Msg {
+		if err := session.SaveNamed(dir, name, state); err != nil {
+			return showStatusMsg{message: fmt.Sprintf("Failed to save session: %v", err), isError: true}
+		}
+		return showStatusMsg{message: fmt.Sprintf("Session saved: %s", name)}
+	}
+}
+
+// sessionState は両ペインの現在の状態を返す
+func (m *Model) sessionState() *session.State {
+	state := &session.State{
+		Left:       m.leftPane.SessionState(),
+		Right:      m.rightPane.SessionState(),
+		ActivePane: "left",
+	}
+	if m.activePane == RightPane {
+		state.ActivePane = "right"
+	}
+	return state
+}
+
+// applySession は保存されたセッションを両ペインに復元する
+func (m *Model) applySession(state *session.State) {
+	m.cancelFind()
+	m.leftPane.RestoreSession(state.Left)
+	m.rightPane.RestoreSession(state.Right)
+	if state.ActivePane == "right" {
+		m.switchToPane(RightPane)
+	} else {
+		m.switchToPane(LeftPane)
+	}
+	m.updateDiskSpace()
+}
+
+// SessionState はペインのタブと表示モードを保存用に返す
+func (p *Pane) SessionState() session.Pane {
+	tabs := make([]session.Tab, max(len(p.tabs), 1))
+	for i := range tabs {
+		tab := p.captureTab()
+		if i != p.activeTab {
+			tab = p.tabs[i]
+		}
+		tabs[i] = session.Tab{
+			Path:      tab.path,
+			SortField: sortFieldNames[tab.sortConfig.Field],
+			SortOrder: sortOrderNames[tab.sortConfig.Order],
+			Hidden:    tab.showHidden,
+		}
+		if tab.filterPattern != "" {
+			tabs[i].Filter = tab.filterPattern
+			tabs[i].FilterMode = filterModeNames[tab.filterMode]
+		}
+	}
+
+	state := session.Pane{Tabs: tabs, ActiveTab: p.activeTab, DisplayMode: "basic"}
+	if p.displayMode == DisplayDetail {
+		state.DisplayMode = "detail"
+	}
+	return state
+}
+
+// RestoreSession は保存されたタブと表示モードをペインに復元する
+// 開けないディレクトリのタブは飛ばす。1つも開けなければ現在のタブと表示モードのまま
+func (p *Pane) RestoreSession(state session.Pane) {
+	var tabs []paneTab
+	activeTab := 0
+	for i, saved := range state.Tabs {
+		tab, err := restoreTabState(saved)
+		if err != nil {
+			continue
+		}
+		if i == state.ActiveTab {
+			activeTab = len(tabs)
+		}
+		tabs = append(tabs, tab)
+	}
+	if len(tabs) == 0 {
+		return
+	}
+
+	switch state.DisplayMode {
+	case "detail":
+		p.displayMode = DisplayDetail
+	case "basic":
+		p.displayMode = DisplayBasic
+	}
+	p.tabs = tabs
+	p.activeTab = activeTab
+	p.restoreTab(tabs[activeTab])
+	p.adjustScroll()
+	p.recordVisit()
+}
+
+// restoreTabState は保存されたタブを開き直す
+// 不明なソート設定は既定値に、不正なフィルタはフィルタなしになる
+func restoreTabState(saved session.Tab) (paneTab, error) {
+	sortConfig := DefaultSortConfig()
+	for field, name := range sortFieldNames {
+		if name == saved.SortField {
+			sortConfig.Field = field
+		}
+	}
+	for order, name := range sortOrderNames {
+		if name == saved.SortOrder {
+			sortConfig.Order = order
+		}
+	}
+
+	tab, err := newTabState(saved.Path, sortConfig, saved.Hidden)
+	if err != nil {
+		return paneTab{}, err
+	}
+
+	switch saved.FilterMode {
+	case filterModeNames[SearchModeIncremental]:
+		if saved.Filter != "" {
+			tab.entries = filterIncremental(tab.allEntries, saved.Filter)
+			tab.filterPattern = saved.Filter
+			tab.filterMode = SearchModeIncremental
+		}
+	case filterModeNames[SearchModeRegex]:
+		if filtered, err := filterRegex(tab.allEntries, saved.Filter); err == nil && saved.Filter != "" {
+			tab.entries = filtered
+			tab.filterPattern = saved.Filter
+			tab.filterMode = SearchModeRegex
+		}
+	}
+	return tab, nil
+}
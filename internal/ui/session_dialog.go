@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/session"
+)
+
+// sessionLoadMsg is sent when a named session is chosen to be loaded
+type sessionLoadMsg struct {
+	name string
+}
+
+// sessionSaveMsg is sent to save the current layout as a named session.
+// An empty name asks for a new name.
+type sessionSaveMsg struct {
+	name string
+}
+
+// sessionDeleteMsg is sent when a named session is to be deleted
+type sessionDeleteMsg struct {
+	name string
+}
+
+// SessionDialog lists the named sessions. A session can be loaded, the
+// current layout saved as a new session or over an existing one, and
+// sessions deleted.
+type SessionDialog struct {
+	sessions []session.Named
+	cursor   int
+	active   bool
+	width    int
+}
+
+// NewSessionDialog creates a dialog for the named sessions
+func NewSessionDialog(sessions []session.Named) *SessionDialog {
+	return &SessionDialog{
+		sessions: sessions,
+		active:   true,
+		width:    60,
+	}
+}
+
+// Update handles keyboard input
+func (d *SessionDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch keyMsg.String() {
+	case "j", "down":
+		if d.cursor < len(d.sessions)-1 {
+			d.cursor++
+		}
+	case "k", "up":
+		if d.cursor > 0 {
+			d.cursor--
+		}
+	case "enter":
+		if len(d.sessions) == 0 {
+			return d, nil
+		}
+		return d.close(sessionLoadMsg{name: d.sessions[d.cursor].Name})
+	case "n":
+		return d.close(sessionSaveMsg{})
+	case "s":
+		if len(d.sessions) == 0 {
+			return d, nil
+		}
+		return d.close(sessionSaveMsg{name: d.sessions[d.cursor].Name})
+	case "d":
+		if len(d.sessions) == 0 {
+			return d, nil
+		}
+		return d.close(sessionDeleteMsg{name: d.sessions[d.cursor].Name})
+	case "esc":
+		return d.close(dialogResultMsg{result: DialogResult{Cancelled: true}})
+	}
+	return d, nil
+}
+
+// close closes the dialog, sending msg
+func (d *SessionDialog) close(msg tea.Msg) (Dialog, tea.Cmd) {
+	d.active = false
+	return d, func() tea.Msg {
+		return msg
+	}
+}
+
+// View renders the dialog
+func (d *SessionDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render("Sessions"))
+	b.WriteString("\n\n")
+
+	rowWidth := width - 6
+	if len(d.sessions) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Width(rowWidth).
+			Padding(0, 1).
+			Foreground(lipgloss.Color("240"))
+		b.WriteString(emptyStyle.Render("No saved sessions"))
+		b.WriteString("\n")
+	}
+	for i, named := range d.sessions {
+		saved := named.SavedAt.Format("2006-01-02 15:04")
+		nameWidth := rowWidth - 2 - runewidth.StringWidth(saved) - 1
+		line := runewidth.FillRight(runewidth.Truncate(named.Name, nameWidth, "…"), nameWidth) + " " + saved
+
+		rowStyle := lipgloss.NewStyle().Width(rowWidth).Padding(0, 1)
+		if i == d.cursor {
+			rowStyle = rowStyle.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0"))
+		}
+		b.WriteString(rowStyle.Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("Enter:Load  n:Save as  s:Overwrite  d:Delete  Esc:Close"))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// IsActive returns whether the dialog is open
+func (d *SessionDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type
+func (d *SessionDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayPane
+}
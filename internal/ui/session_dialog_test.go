@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/session"
+)
+
+func TestSessionDialog(t *testing.T) {
+	saved := time.Date(2024, 5, 1, 9, 30, 0, 0, time.Local)
+	sessions := []session.Named{{Name: "project", SavedAt: saved}, {Name: "review", SavedAt: saved}}
+
+	t.Run("lists the sessions", func(t *testing.T) {
+		view := NewSessionDialog(sessions).View()
+		for _, want := range []string{"Sessions", "project", "review", "2024-05-01 09:30"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("view should contain %q:\n%s", want, view)
+			}
+		}
+		if view := NewSessionDialog(nil).View(); !strings.Contains(view, "No saved sessions") {
+			t.Errorf("empty view:\n%s", view)
+		}
+	})
+
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want tea.Msg
+	}{
+		{"enter loads", []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyEnter}}, sessionLoadMsg{name: "review"}},
+		{"n saves as new", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'n'}}}, sessionSaveMsg{}},
+		{"s overwrites", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'s'}}}, sessionSaveMsg{name: "project"}},
+		{"d deletes", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'j'}}, {Type: tea.KeyRunes, Runes: []rune{'d'}}}, sessionDeleteMsg{name: "review"}},
+		{"esc cancels", []tea.KeyMsg{{Type: tea.KeyEsc}}, dialogResultMsg{result: DialogResult{Cancelled: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewSessionDialog(sessions)
+			var cmd tea.Cmd
+			for _, key := range tt.keys {
+				_, cmd = d.Update(key)
+			}
+			if cmd == nil {
+				t.Fatal("expected a command")
+			}
+			if got := cmd(); got != tt.want {
+				t.Errorf("msg = %#v, want %#v", got, tt.want)
+			}
+			if d.IsActive() {
+				t.Error("dialog should be closed")
+			}
+		})
+	}
+
+	t.Run("keys on an empty list do nothing", func(t *testing.T) {
+		d := NewSessionDialog(nil)
+		for _, key := range []tea.KeyMsg{{Type: tea.KeyEnter}, {Type: tea.KeyRunes, Runes: []rune{'s'}}, {Type: tea.KeyRunes, Runes: []rune{'d'}}} {
+			if _, cmd := d.Update(key); cmd != nil {
+				t.Errorf("%s should do nothing", key)
+			}
+		}
+		if !d.IsActive() {
+			t.Error("dialog should stay open")
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sakura/duofm/internal/session"
+)
+
+func TestPaneSessionState(t *testing.T) {
+	root := t.TempDir()
+	a, b := filepath.Join(root, "a"), filepath.Join(root, "b")
+	os.MkdirAll(a, 0755)
+	os.MkdirAll(b, 0755)
+	for _, name := range []string{"x.go", "y.go", "z.txt", ".hidden"} {
+		os.WriteFile(filepath.Join(b, name), []byte(name), 0644)
+	}
+
+	pane, err := NewPane(LeftPane, a, 120, 20, true, nil)
+	if err != nil {
+		t.Fatalf("NewPane() error = %v", err)
+	}
+	if err := pane.NewTab(b); err != nil {
+		t.Fatalf("NewTab() error = %v", err)
+	}
+	pane.SetSortConfig(SortConfig{Field: SortBySize, Order: SortDesc})
+	pane.ApplySortAndPreserveCursor()
+	pane.ToggleHidden()
+	pane.ApplyFilter(`\.go$`, SearchModeRegex)
+	pane.ToggleDisplayMode()
+
+	state := pane.SessionState()
+	if len(state.Tabs) != 2 || state.ActiveTab != 1 || state.DisplayMode != "detail" {
+		t.Fatalf("SessionState() = %+v", state)
+	}
+	want := session.Tab{Path: b, SortField: "size", SortOrder: "desc", Hidden: true, Filter: `\.go$`, FilterMode: "regex"}
+	if state.Tabs[1] != want {
+		t.Errorf("active tab = %+v, want %+v", state.Tabs[1], want)
+	}
+	if state.Tabs[0].Path != a || state.Tabs[0].SortField != "name" || state.Tabs[0].Filter != "" {
+		t.Errorf("first tab = %+v", state.Tabs[0])
+	}
+
+	// Restoring into a fresh pane brings everything back; missing
+	// directories are skipped and the active index follows
+	state.Tabs = append([]session.Tab{{Path: filepath.Join(root, "missing")}}, state.Tabs...)
+	state.ActiveTab = 2
+	restored, err := NewPane(LeftPane, root, 120, 20, true, nil)
+	if err != nil {
+		t.Fatalf("NewPane() error = %v", err)
+	}
+	restored.RestoreSession(state)
+	if restored.TabCount() != 2 || restored.ActiveTab() != 1 || restored.Path() != b {
+		t.Fatalf("restored %d tabs, active %d, path %s", restored.TabCount(), restored.ActiveTab(), restored.Path())
+	}
+	if restored.GetSortConfig() != (SortConfig{Field: SortBySize, Order: SortDesc}) || !restored.IsShowingHidden() {
+		t.Errorf("sort %v, hidden %v", restored.GetSortConfig(), restored.IsShowingHidden())
+	}
+	if restored.FilterPattern() != `\.go$` || restored.FilteredEntryCount() != 2 {
+		t.Errorf("filter %q matches %d entries, want 2", restored.FilterPattern(), restored.FilteredEntryCount())
+	}
+	if restored.GetEffectiveDisplayMode() != DisplayDetail {
+		t.Errorf("display mode = %v, want detail", restored.GetEffectiveDisplayMode())
+	}
+
+	// Nothing to restore keeps the pane as it is, display mode included
+	restored.RestoreSession(session.Pane{Tabs: []session.Tab{{Path: filepath.Join(root, "missing")}}, DisplayMode: "basic"})
+	if restored.TabCount() != 2 || restored.Path() != b {
+		t.Errorf("restoring only missing directories should change nothing")
+	}
+	if restored.GetEffectiveDisplayMode() != DisplayDetail {
+		t.Errorf("display mode = %v, want detail to be kept", restored.GetEffectiveDisplayMode())
+	}
+}
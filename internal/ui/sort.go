@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/sakura/duofm/internal/fs"
 )
@@ -106,3 +107,35 @@ func getLessFunc(config SortConfig) func(a, b fs.FileEntry) bool {
 		return func(a, b fs.FileEntry) bool { return a.Name < b.Name }
 	}
 }
+
+// ParseSortConfig は "name"・"size:desc" のような FIELD[:ORDER] 形式のソート設定を解析する
+// ORDER を省略した場合は昇順
+func ParseSortConfig(s string) (SortConfig, error) {
+	fieldName, orderName, hasOrder := strings.Cut(strings.ToLower(s), ":")
+
+	config := DefaultSortConfig()
+	found := false
+	for field, name := range sortFieldNames {
+		if name == fieldName {
+			config.Field = field
+			found = true
+		}
+	}
+	if !found {
+		return config, fmt.Errorf("invalid sort field %q (want name, size or date)", fieldName)
+	}
+
+	if hasOrder {
+		found = false
+		for order, name := range sortOrderNames {
+			if name == orderName {
+				config.Order = order
+				found = true
+			}
+		}
+		if !found {
+			return config, fmt.Errorf("invalid sort order %q (want asc or desc)", orderName)
+		}
+	}
+	return config, nil
+}
@@ -310,3 +310,28 @@ func TestDefaultSortConfig(t *testing.T) {
 		t.Errorf("Default Order = %v, want SortAsc", config.Order)
 	}
 }
+
+func TestParseSortConfig(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    SortConfig
+		wantErr bool
+	}{
+		{"name", SortConfig{Field: SortByName, Order: SortAsc}, false},
+		{"size:desc", SortConfig{Field: SortBySize, Order: SortDesc}, false},
+		{"Date:ASC", SortConfig{Field: SortByDate, Order: SortAsc}, false},
+		{"type", SortConfig{}, true},
+		{"size:down", SortConfig{}, true},
+		{"", SortConfig{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSortConfig(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSortConfig(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseSortConfig(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
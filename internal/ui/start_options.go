@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// StartOptions はコマンドラインで指定された起動時の設定
+type StartOptions struct {
+	LeftPath   string      // 左ペインの開始ディレクトリ（空 = 既定）
+	RightPath  string      // 右ペインの開始ディレクトリ（空 = 既定）
+	SelectFile string      // カーソルを合わせるファイルの絶対パス（そのディレクトリを左ペインで開く）
+	ShowHidden bool        // 隠しファイルを表示する
+	Sort       *SortConfig // ソート設定（nil = 既定または前回のセッション）
+}
+
+// hasPaths はコマンドラインで開始ディレクトリが指定されたかどうかを返す
+// 指定された場合は前回のセッションを復元も保存もしない
+func (o StartOptions) hasPaths() bool {
+	return o.LeftPath != "" || o.RightPath != "" || o.SelectFile != ""
+}
+
+// SetStartOptions はコマンドラインで指定された起動時の設定を適用する
+func (m *Model) SetStartOptions(opts StartOptions) {
+	m.startOptions = opts
+	if opts.LeftPath != "" {
+		m.leftPath = opts.LeftPath
+	}
+	if opts.RightPath != "" {
+		m.rightPath = opts.RightPath
+	}
+	if opts.SelectFile != "" {
+		m.leftPath = filepath.Dir(opts.SelectFile)
+	}
+}
+
+// applyStartOptions は作成したペインにソート・隠しファイル・カーソル位置の指定を反映する
+// ソートと隠しファイルの指定は復元したセッションのすべてのタブに適用する
+func (m *Model) applyStartOptions() {
+	opts := m.startOptions
+	selectName := filepath.Base(opts.SelectFile)
+	for _, pane := range []*Pane{m.leftPane, m.rightPane} {
+		pane.updateInactiveTabs(func(tab *paneTab) {
+			if opts.Sort != nil {
+				tab.sortConfig = *opts.Sort
+			}
+			if opts.ShowHidden {
+				tab.showHidden = true
+			}
+		})
+		if opts.Sort != nil {
+			pane.SetSortConfig(*opts.Sort)
+			pane.ApplySortAndPreserveCursor()
+		}
+		// 隠しファイルを選択する場合も表示に切り替える
+		showHidden := opts.ShowHidden || (pane == m.leftPane && opts.SelectFile != "" && strings.HasPrefix(selectName, "."))
+		if showHidden && !pane.IsShowingHidden() {
+			pane.ToggleHidden()
+		}
+	}
+
+	if opts.SelectFile != "" {
+		m.switchToPane(LeftPane)
+		m.moveCursorToFile(selectName)
+	}
+}
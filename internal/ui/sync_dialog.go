@@ -0,0 +1,496 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// syncDialogMaxVisible is the number of plan rows shown at once
+const syncDialogMaxVisible = 12
+
+// SyncDirection is the direction of a directory synchronization
+type SyncDirection int
+
+const (
+	SyncLeftToRight SyncDirection = iota
+	SyncRightToLeft
+	SyncBothWays
+)
+
+// syncDirectionLabels are the labels of the directions, indexed by SyncDirection
+var syncDirectionLabels = []string{"Left -> Right", "Right -> Left", "Both ways"}
+
+// syncCompareLabels are the labels of the compare methods, indexed by fs.SyncCompare
+var syncCompareLabels = []string{"Size + mtime", "Checksum"}
+
+// syncDialogState is the step the sync dialog is in
+type syncDialogState int
+
+const (
+	syncStateOptions  syncDialogState = iota // Choosing direction and comparison
+	syncStatePlanning                        // Waiting for the dry run
+	syncStatePlan                            // Reviewing and editing the plan
+)
+
+// syncOptionRows is the number of rows on the options page
+const syncOptionRows = 3
+
+// syncPlanning is a dry run being computed in the background. Leaving the
+// planning step cancels it, and the result of an older request is told apart
+// from the current one by this pointer.
+type syncPlanning struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// syncPlanRequestMsg asks the model to compute the dry run off the UI goroutine
+type syncPlanRequestMsg struct {
+	plan     *syncPlanning
+	src, dst string
+	opts     fs.SyncOptions
+}
+
+// syncPlanResultMsg carries the computed dry run back to the dialog
+type syncPlanResultMsg struct {
+	plan    *syncPlanning
+	actions []fs.SyncAction
+	err     error
+}
+
+// syncDialogResultMsg is sent when the reviewed plan should be executed
+type syncDialogResultMsg struct {
+	actions []fs.SyncAction
+	dest    string // Directory shown in the progress dialog
+}
+
+// SyncDialog synchronizes the directories of both panes. The options page
+// chooses the direction, how files are compared and whether extraneous
+// entries are deleted; Enter computes a dry-run plan in which every action can
+// be toggled and conflicts of a two-way sync can be resolved before it runs.
+type SyncDialog struct {
+	leftPath  string
+	rightPath string
+	state     syncDialogState
+
+	direction        SyncDirection
+	compare          fs.SyncCompare
+	deleteExtraneous bool
+	row              int // Selected option row
+
+	planning *syncPlanning // Dry run being computed (nil = none)
+	actions  []fs.SyncAction
+	enabled  []bool
+	cursor   int // Selected plan row
+	offset   int // First visible plan row
+	errorMsg string
+
+	active bool
+	width  int
+}
+
+// NewSyncDialog creates a sync dialog for the directories of both panes
+func NewSyncDialog(leftPath, rightPath string) *SyncDialog {
+	return &SyncDialog{
+		leftPath:  leftPath,
+		rightPath: rightPath,
+		active:    true,
+		width:     80,
+	}
+}
+
+// endpoints returns the first and second directory of the sync
+func (d *SyncDialog) endpoints() (string, string) {
+	if d.direction == SyncRightToLeft {
+		return d.rightPath, d.leftPath
+	}
+	return d.leftPath, d.rightPath
+}
+
+// Update handles keyboard input
+func (d *SyncDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	switch d.state {
+	case syncStateOptions:
+		return d, d.updateOptions(keyMsg.String())
+	case syncStatePlan:
+		return d, d.updatePlan(keyMsg.String())
+	}
+
+	if keyMsg.String() == "esc" {
+		d.stopPlanning()
+		d.state = syncStateOptions
+	}
+	return d, nil
+}
+
+// stopPlanning cancels the dry run being computed
+func (d *SyncDialog) stopPlanning() {
+	if d.planning == nil {
+		return
+	}
+	d.planning.cancel()
+	d.planning = nil
+}
+
+// updateOptions handles the keys of the options page
+func (d *SyncDialog) updateOptions(key string) tea.Cmd {
+	switch key {
+	case "j", "down", "tab":
+		d.row = (d.row + 1) % syncOptionRows
+	case "k", "up", "shift+tab":
+		d.row = (d.row + syncOptionRows - 1) % syncOptionRows
+	case "l", "right", " ":
+		d.changeOption(1)
+	case "h", "left":
+		d.changeOption(-1)
+	case "enter":
+		d.state = syncStatePlanning
+		d.errorMsg = ""
+		ctx, cancel := context.WithCancel(context.Background())
+		plan := &syncPlanning{ctx: ctx, cancel: cancel}
+		d.planning = plan
+		src, dst := d.endpoints()
+		opts := fs.SyncOptions{
+			Compare:          d.compare,
+			TwoWay:           d.direction == SyncBothWays,
+			DeleteExtraneous: d.deleteExtraneous && d.direction != SyncBothWays,
+		}
+		return func() tea.Msg {
+			return syncPlanRequestMsg{plan: plan, src: src, dst: dst, opts: opts}
+		}
+	case "esc":
+		d.active = false
+		return func() tea.Msg {
+			return dialogResultMsg{result: DialogResult{Cancelled: true}}
+		}
+	}
+	return nil
+}
+
+// changeOption cycles the value of the selected option row
+func (d *SyncDialog) changeOption(delta int) {
+	switch d.row {
+	case 0:
+		n := len(syncDirectionLabels)
+		d.direction = SyncDirection((int(d.direction) + delta + n) % n)
+	case 1:
+		n := len(syncCompareLabels)
+		d.compare = fs.SyncCompare((int(d.compare) + delta + n) % n)
+	case 2:
+		d.deleteExtraneous = !d.deleteExtraneous
+	}
+}
+
+// SetPlan shows the dry run computed for the current options. Results of a
+// request that was cancelled or replaced are dropped.
+func (d *SyncDialog) SetPlan(plan *syncPlanning, actions []fs.SyncAction, err error) {
+	if d.state != syncStatePlanning || plan != d.planning {
+		return
+	}
+	d.planning.cancel()
+	d.planning = nil
+	if err != nil {
+		d.state = syncStateOptions
+		d.errorMsg = err.Error()
+		return
+	}
+	d.state = syncStatePlan
+	d.actions = actions
+	d.enabled = make([]bool, len(actions))
+	for i, action := range actions {
+		// Conflicts only run once a direction was chosen
+		d.enabled[i] = action.Op != fs.SyncConflict
+	}
+	d.cursor = 0
+	d.offset = 0
+}
+
+// updatePlan handles the keys of the plan page
+func (d *SyncDialog) updatePlan(key string) tea.Cmd {
+	switch key {
+	case "j", "down":
+		d.moveCursor(1)
+	case "k", "up":
+		d.moveCursor(-1)
+	case " ":
+		if len(d.actions) > 0 && d.actions[d.cursor].Op != fs.SyncConflict {
+			d.enabled[d.cursor] = !d.enabled[d.cursor]
+		}
+		d.moveCursor(1)
+	case "a":
+		// Enable everything unless everything is enabled already
+		all := true
+		for i, action := range d.actions {
+			if action.Op != fs.SyncConflict && !d.enabled[i] {
+				all = false
+			}
+		}
+		for i, action := range d.actions {
+			if action.Op != fs.SyncConflict {
+				d.enabled[i] = !all
+			}
+		}
+	case "h", "l", "left", "right":
+		d.resolveConflict(key == "h" || key == "left")
+	case "enter":
+		d.active = false
+		selected := d.selectedActions()
+		if len(selected) == 0 {
+			return func() tea.Msg { return showStatusMsg{message: "Nothing to sync"} }
+		}
+		_, dest := d.endpoints()
+		if d.direction == SyncBothWays {
+			dest = fmt.Sprintf("%s <-> %s", d.leftPath, d.rightPath)
+		}
+		return func() tea.Msg {
+			return syncDialogResultMsg{actions: selected, dest: dest}
+		}
+	case "esc":
+		d.state = syncStateOptions
+	}
+	return nil
+}
+
+// resolveConflict turns the selected conflict of a two-way sync into an
+// overwrite of the right side (toLeft false) or the left side (toLeft true)
+func (d *SyncDialog) resolveConflict(toLeft bool) {
+	if len(d.actions) == 0 {
+		return
+	}
+	action := d.actions[d.cursor]
+	if action.Op == fs.SyncConflict {
+		d.actions[d.cursor] = action.ResolveConflict(toLeft)
+		d.enabled[d.cursor] = true
+		return
+	}
+	// Updates of a two-way sync can be flipped to the other direction
+	if action.Op == fs.SyncUpdate && d.direction == SyncBothWays && action.Reverse != toLeft {
+		action.Op = fs.SyncConflict
+		if action.Reverse {
+			action.Src, action.Dst = action.Dst, action.Src
+		}
+		d.actions[d.cursor] = action.ResolveConflict(toLeft)
+	}
+}
+
+// selectedActions returns the enabled actions of the plan
+func (d *SyncDialog) selectedActions() []fs.SyncAction {
+	var selected []fs.SyncAction
+	for i, action := range d.actions {
+		if d.enabled[i] && action.Op != fs.SyncConflict {
+			selected = append(selected, action)
+		}
+	}
+	return selected
+}
+
+// moveCursor moves the plan cursor and keeps it visible
+func (d *SyncDialog) moveCursor(delta int) {
+	if len(d.actions) == 0 {
+		return
+	}
+	d.cursor = (d.cursor + delta + len(d.actions)) % len(d.actions)
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+syncDialogMaxVisible {
+		d.offset = d.cursor - syncDialogMaxVisible + 1
+	}
+}
+
+// View renders the dialog
+func (d *SyncDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+	innerWidth := width - 4
+
+	titleStyle := lipgloss.NewStyle().
+		Width(innerWidth).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	lineStyle := lipgloss.NewStyle().
+		Width(innerWidth).
+		Padding(0, 1)
+	mutedStyle := lineStyle.Foreground(lipgloss.Color("245"))
+	footerStyle := lineStyle.Foreground(lipgloss.Color("240"))
+
+	b.WriteString(titleStyle.Render("Sync directories"))
+	b.WriteString("\n\n")
+	b.WriteString(mutedStyle.Render("Left:  " + truncatePath(d.leftPath, innerWidth-10)))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("Right: " + truncatePath(d.rightPath, innerWidth-10)))
+	b.WriteString("\n\n")
+
+	switch d.state {
+	case syncStateOptions:
+		d.renderOptions(&b, lineStyle)
+		if d.errorMsg != "" {
+			b.WriteString(lineStyle.Foreground(lipgloss.Color("196")).Render(d.errorMsg))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(footerStyle.Render("j/k:select h/l/Space:change Enter:preview Esc:cancel"))
+	case syncStatePlanning:
+		b.WriteString(lineStyle.Render("Comparing directories..."))
+		b.WriteString("\n\n")
+		b.WriteString(footerStyle.Render("Esc:back"))
+	case syncStatePlan:
+		d.renderPlan(&b, lineStyle, mutedStyle, innerWidth)
+		b.WriteString("\n")
+		b.WriteString(footerStyle.Render("Space:toggle a:all h/l:resolve conflict Enter:run Esc:back"))
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+// renderOptions renders the option rows
+func (d *SyncDialog) renderOptions(b *strings.Builder, lineStyle lipgloss.Style) {
+	deleteLabel := "off"
+	if d.deleteExtraneous {
+		deleteLabel = "on"
+	}
+	if d.direction == SyncBothWays {
+		deleteLabel += " (one-way only)"
+	}
+	rows := []string{
+		"Direction:         " + syncDirectionLabels[d.direction],
+		"Compare:           " + syncCompareLabels[d.compare],
+		"Delete extraneous: " + deleteLabel,
+	}
+	for i, row := range rows {
+		style := lineStyle
+		if i == d.row {
+			style = style.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0"))
+		}
+		b.WriteString(style.Render(row))
+		b.WriteString("\n")
+	}
+}
+
+// renderPlan renders the summary and the visible plan rows
+func (d *SyncDialog) renderPlan(b *strings.Builder, lineStyle, mutedStyle lipgloss.Style, innerWidth int) {
+	if len(d.actions) == 0 {
+		b.WriteString(lineStyle.Render("Directories are already in sync"))
+		b.WriteString("\n")
+		return
+	}
+
+	var counts [4]int
+	var bytes int64
+	for i, action := range d.actions {
+		counts[action.Op]++
+		if d.enabled[i] && action.Op != fs.SyncDelete {
+			bytes += action.Size
+		}
+	}
+	summary := fmt.Sprintf("%d copy, %d update, %d delete", counts[fs.SyncCopy], counts[fs.SyncUpdate], counts[fs.SyncDelete])
+	if counts[fs.SyncConflict] > 0 {
+		summary += fmt.Sprintf(", %d conflicts", counts[fs.SyncConflict])
+	}
+	summary += fmt.Sprintf(" - %d selected, %s", len(d.selectedActions()), FormatSize(bytes))
+	b.WriteString(lineStyle.Render(summary))
+	b.WriteString("\n\n")
+
+	end := d.offset + syncDialogMaxVisible
+	if end > len(d.actions) {
+		end = len(d.actions)
+	}
+	for i := d.offset; i < end; i++ {
+		style := lineStyle
+		if i == d.cursor {
+			style = style.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0"))
+		} else if !d.enabled[i] {
+			style = mutedStyle
+		}
+		b.WriteString(style.Render(runewidth.Truncate(d.formatAction(i), innerWidth-2, "...")))
+		b.WriteString("\n")
+	}
+	if len(d.actions) > syncDialogMaxVisible {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%d-%d of %d", d.offset+1, end, len(d.actions))))
+		b.WriteString("\n")
+	}
+}
+
+// formatAction formats a plan row: checkbox, operation, direction and path
+func (d *SyncDialog) formatAction(i int) string {
+	action := d.actions[i]
+	check := "[ ]"
+	if d.enabled[i] {
+		check = "[x]"
+	}
+
+	var op string
+	switch action.Op {
+	case fs.SyncCopy:
+		op = "copy  "
+	case fs.SyncUpdate:
+		op = "update"
+	case fs.SyncDelete:
+		op = "delete"
+	case fs.SyncConflict:
+		op = "CONFLICT"
+		check = "[!]"
+	}
+
+	// Arrows are shown in terms of the panes
+	arrow := "->"
+	if action.Reverse != (d.direction == SyncRightToLeft) {
+		arrow = "<-"
+	}
+	switch action.Op {
+	case fs.SyncDelete:
+		arrow = "  "
+	case fs.SyncConflict:
+		arrow = "<>"
+	}
+
+	path := filepath.ToSlash(action.Rel)
+	if action.IsDir {
+		path += "/"
+	}
+	size := ""
+	if action.Op != fs.SyncDelete && action.Op != fs.SyncConflict {
+		size = "  " + FormatSize(action.Size)
+	}
+	return fmt.Sprintf("%s %s %s %s%s", check, op, arrow, path, size)
+}
+
+// IsActive returns whether the dialog is active
+func (d *SyncDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type
+func (d *SyncDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
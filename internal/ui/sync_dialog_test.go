@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/fs"
+)
+
+func syncKey(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+func TestSyncDialogOptions(t *testing.T) {
+	dialog := NewSyncDialog("/left", "/right")
+
+	if dialog.DisplayType() != DialogDisplayScreen {
+		t.Error("expected DialogDisplayScreen")
+	}
+
+	// Direction: right to left, compare: checksum, delete extraneous on
+	dialog.Update(syncKey('l'))
+	dialog.Update(syncKey('j'))
+	dialog.Update(syncKey('l'))
+	dialog.Update(syncKey('j'))
+	dialog.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	view := dialog.View()
+	for _, want := range []string{"Right -> Left", "Checksum", "Delete extraneous: on"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q:\n%s", want, view)
+		}
+	}
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	req, ok := cmd().(syncPlanRequestMsg)
+	if !ok {
+		t.Fatal("Enter should request a plan")
+	}
+	if req.src != "/right" || req.dst != "/left" {
+		t.Errorf("plan %s -> %s, want /right -> /left", req.src, req.dst)
+	}
+	if req.opts.Compare != fs.SyncCompareChecksum || !req.opts.DeleteExtraneous || req.opts.TwoWay {
+		t.Errorf("opts = %+v", req.opts)
+	}
+	if !strings.Contains(dialog.View(), "Comparing directories") {
+		t.Error("dialog should show that the plan is being computed")
+	}
+
+	// Errors bring the options back
+	dialog.SetPlan(dialog.planning, nil, errors.New("permission denied"))
+	if !strings.Contains(dialog.View(), "permission denied") {
+		t.Error("plan errors should be shown")
+	}
+}
+
+func TestSyncDialogPlan(t *testing.T) {
+	dialog := NewSyncDialog("/left", "/right")
+	dialog.direction = SyncBothWays
+	dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	dialog.SetPlan(dialog.planning, []fs.SyncAction{
+		{Op: fs.SyncCopy, Rel: "a.txt", Src: "/left/a.txt", Dst: "/right/a.txt", Size: 10, Files: 1},
+		{Op: fs.SyncCopy, Rel: "b.txt", Src: "/right/b.txt", Dst: "/left/b.txt", Size: 20, Files: 1, Reverse: true},
+		{Op: fs.SyncConflict, Rel: "c.txt", Src: "/left/c.txt", Dst: "/right/c.txt", Files: 1},
+	}, nil)
+
+	view := dialog.View()
+	for _, want := range []string{"2 copy, 0 update, 0 delete, 1 conflicts", "2 selected", "a.txt", "c.txt"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q:\n%s", want, view)
+		}
+	}
+
+	// Skip b.txt and resolve the conflict towards the right side
+	dialog.Update(syncKey('j'))
+	dialog.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	dialog.Update(syncKey('l'))
+	if got := dialog.actions[2]; got.Op != fs.SyncUpdate || got.Dst != "/right/c.txt" {
+		t.Errorf("resolved conflict = %+v, want update of the right side", got)
+	}
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	result, ok := cmd().(syncDialogResultMsg)
+	if !ok {
+		t.Fatal("Enter should run the plan")
+	}
+	if len(result.actions) != 2 || result.actions[0].Rel != "a.txt" || result.actions[1].Rel != "c.txt" {
+		t.Errorf("actions = %+v, want a.txt and c.txt", result.actions)
+	}
+	if dialog.IsActive() {
+		t.Error("dialog should close after running")
+	}
+}
+
+func TestSyncDialogEmptySelection(t *testing.T) {
+	dialog := NewSyncDialog("/left", "/right")
+	dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	dialog.SetPlan(dialog.planning, nil, nil)
+	if !strings.Contains(dialog.View(), "already in sync") {
+		t.Error("an empty plan should say the directories are in sync")
+	}
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(showStatusMsg); !ok || msg.message != "Nothing to sync" {
+		t.Errorf("Enter on an empty plan = %+v", msg)
+	}
+}
+
+func TestSyncDialogEscCloses(t *testing.T) {
+	dialog := NewSyncDialog("/left", "/right")
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if dialog.IsActive() {
+		t.Error("Esc should close the dialog")
+	}
+	if msg, ok := cmd().(dialogResultMsg); !ok || !msg.result.Cancelled {
+		t.Errorf("Esc should send a cancelled result, got %#v", msg)
+	}
+}
+
+func TestSyncDialogStalePlan(t *testing.T) {
+	dialog := NewSyncDialog("/left", "/right")
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	first := cmd().(syncPlanRequestMsg)
+
+	// Esc while planning cancels the running dry run
+	dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if first.plan.ctx.Err() == nil {
+		t.Error("Esc should cancel the dry run")
+	}
+
+	_, cmd = dialog.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	second := cmd().(syncPlanRequestMsg)
+
+	// The result of the cancelled request must not fill the new one
+	dialog.SetPlan(first.plan, []fs.SyncAction{{Op: fs.SyncCopy, Rel: "stale.txt", Files: 1}}, nil)
+	if dialog.state != syncStatePlanning {
+		t.Fatal("a stale plan should be dropped")
+	}
+
+	dialog.SetPlan(second.plan, []fs.SyncAction{{Op: fs.SyncCopy, Rel: "fresh.txt", Files: 1}}, nil)
+	if dialog.state != syncStatePlan || len(dialog.actions) != 1 || dialog.actions[0].Rel != "fresh.txt" {
+		t.Errorf("state %v, actions %+v", dialog.state, dialog.actions)
+	}
+}
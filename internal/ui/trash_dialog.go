@@ -0,0 +1,296 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sakura/duofm/internal/trash"
+)
+
+// trashDialogMaxVisible is the number of trash items shown at once
+const trashDialogMaxVisible = 12
+
+// TrashDialog is a browser for trashed items with restore, delete and empty actions.
+type TrashDialog struct {
+	items   []trash.Item
+	cursor  int
+	offset  int // First visible item
+	active  bool
+	width   int
+	confirm string // Pending confirmation: "delete", "empty" or ""
+	message string // Result of the last action
+	isError bool
+}
+
+// trashRestoreMsg is sent when the user restores an item.
+type trashRestoreMsg struct {
+	item trash.Item
+}
+
+// trashRemoveMsg is sent when the user deletes an item permanently.
+type trashRemoveMsg struct {
+	item trash.Item
+}
+
+// trashEmptyMsg is sent when the user empties the trash.
+type trashEmptyMsg struct{}
+
+// trashEmptiedMsg is sent when emptying the trash finished.
+type trashEmptiedMsg struct {
+	err error
+}
+
+// trashCloseMsg is sent when the trash browser is closed.
+type trashCloseMsg struct{}
+
+// NewTrashDialog creates a new trash browser.
+func NewTrashDialog(items []trash.Item) *TrashDialog {
+	return &TrashDialog{
+		items:  items,
+		active: true,
+		width:  80,
+	}
+}
+
+// SetItems replaces the listed items, keeping the cursor in range.
+func (d *TrashDialog) SetItems(items []trash.Item) {
+	d.items = items
+	if d.cursor >= len(items) {
+		d.cursor = len(items) - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+	d.adjustOffset()
+}
+
+// SetMessage shows the result of the last action in the dialog.
+func (d *TrashDialog) SetMessage(message string, isError bool) {
+	d.message = message
+	d.isError = isError
+}
+
+// Update handles keyboard input.
+func (d *TrashDialog) Update(msg tea.Msg) (Dialog, tea.Cmd) {
+	if !d.active {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+	key := keyMsg.String()
+	d.message = ""
+
+	// Destructive actions need a second key press
+	if d.confirm != "" {
+		confirm := d.confirm
+		d.confirm = ""
+		if key != "y" {
+			return d, nil
+		}
+		if confirm == "empty" {
+			d.active = false
+			return d, func() tea.Msg {
+				return trashEmptyMsg{}
+			}
+		}
+		if len(d.items) > 0 {
+			item := d.items[d.cursor]
+			return d, func() tea.Msg {
+				return trashRemoveMsg{item: item}
+			}
+		}
+		return d, nil
+	}
+
+	switch key {
+	case "j", "down":
+		d.moveCursor(1)
+	case "k", "up":
+		d.moveCursor(-1)
+	case "enter", "r":
+		if len(d.items) > 0 {
+			item := d.items[d.cursor]
+			return d, func() tea.Msg {
+				return trashRestoreMsg{item: item}
+			}
+		}
+	case "d":
+		if len(d.items) > 0 {
+			d.confirm = "delete"
+		}
+	case "E":
+		if len(d.items) > 0 {
+			d.confirm = "empty"
+		}
+	case "esc", "q", "t":
+		d.active = false
+		return d, func() tea.Msg {
+			return trashCloseMsg{}
+		}
+	}
+
+	return d, nil
+}
+
+func (d *TrashDialog) moveCursor(delta int) {
+	if len(d.items) == 0 {
+		return
+	}
+	d.cursor = (d.cursor + delta + len(d.items)) % len(d.items)
+	d.adjustOffset()
+}
+
+// adjustOffset keeps the cursor within the visible window
+func (d *TrashDialog) adjustOffset() {
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+trashDialogMaxVisible {
+		d.offset = d.cursor - trashDialogMaxVisible + 1
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+}
+
+// View renders the dialog.
+func (d *TrashDialog) View() string {
+	if !d.active {
+		return ""
+	}
+
+	var b strings.Builder
+	width := d.width
+	innerWidth := width - 6
+
+	titleStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Trash (%d items)", len(d.items))))
+	b.WriteString("\n\n")
+
+	if len(d.items) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Width(width-4).
+			Padding(0, 1).
+			Foreground(lipgloss.Color("240"))
+		b.WriteString(emptyStyle.Render("Trash is empty"))
+		b.WriteString("\n")
+	}
+
+	end := d.offset + trashDialogMaxVisible
+	if end > len(d.items) {
+		end = len(d.items)
+	}
+	for i := d.offset; i < end; i++ {
+		item := d.items[i]
+		isSelected := i == d.cursor
+
+		nameStyle := lipgloss.NewStyle().
+			Width(innerWidth).
+			Padding(0, 1)
+		pathStyle := nameStyle.Foreground(lipgloss.Color("245"))
+		if isSelected {
+			nameStyle = nameStyle.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0")).
+				Bold(true)
+			pathStyle = pathStyle.
+				Background(lipgloss.Color("39")).
+				Foreground(lipgloss.Color("0"))
+		}
+
+		// Line 1: name, size and deletion date
+		name := filepath.Base(item.OriginalPath)
+		size := "<DIR>"
+		if !item.IsDir {
+			size = FormatSize(item.Size)
+		}
+		date := ""
+		if !item.DeletionDate.IsZero() {
+			date = item.DeletionDate.Format("2006-01-02 15:04")
+		}
+		meta := fmt.Sprintf("  %8s  %s", size, date)
+		nameWidth := innerWidth - 2 - runewidth.StringWidth(meta)
+		name = runewidth.FillRight(runewidth.Truncate(name, nameWidth, "..."), nameWidth)
+		b.WriteString(nameStyle.Render(name + meta))
+		b.WriteString("\n")
+
+		// Line 2: original location
+		b.WriteString(pathStyle.Render(truncatePath(filepath.Dir(item.OriginalPath), innerWidth-2)))
+		b.WriteString("\n")
+	}
+
+	if len(d.items) > trashDialogMaxVisible {
+		moreStyle := lipgloss.NewStyle().
+			Width(width-4).
+			Padding(0, 1).
+			Foreground(lipgloss.Color("240"))
+		b.WriteString(moreStyle.Render(fmt.Sprintf("%d-%d of %d", d.offset+1, end, len(d.items))))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+
+	// Confirmation prompt or result of the last action
+	switch {
+	case d.confirm == "delete":
+		b.WriteString(d.promptStyle().Render("Delete permanently? (y/n)"))
+		b.WriteString("\n")
+	case d.confirm == "empty":
+		b.WriteString(d.promptStyle().Render(fmt.Sprintf("Permanently delete all %d items? (y/n)", len(d.items))))
+		b.WriteString("\n")
+	case d.message != "":
+		color := "245"
+		if d.isError {
+			color = "196"
+		}
+		messageStyle := lipgloss.NewStyle().
+			Width(width-4).
+			Padding(0, 1).
+			Foreground(lipgloss.Color(color))
+		b.WriteString(messageStyle.Render(d.message))
+		b.WriteString("\n")
+	}
+
+	footerStyle := lipgloss.NewStyle().
+		Width(width-4).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("240"))
+	b.WriteString(footerStyle.Render("j/k:Move  Enter/r:Restore  d:Delete  E:Empty trash  Esc:Close"))
+
+	boxStyle := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2)
+
+	return boxStyle.Render(b.String())
+}
+
+func (d *TrashDialog) promptStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Width(d.width-4).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("214"))
+}
+
+// IsActive returns whether the dialog is active.
+func (d *TrashDialog) IsActive() bool {
+	return d.active
+}
+
+// DisplayType returns the dialog display type.
+func (d *TrashDialog) DisplayType() DialogDisplayType {
+	return DialogDisplayScreen
+}
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sakura/duofm/internal/trash"
+)
+
+func testTrashItems() []trash.Item {
+	date := time.Date(2024, 5, 1, 10, 20, 0, 0, time.Local)
+	return []trash.Item{
+		{Name: "a.txt", OriginalPath: "/home/user/a.txt", DeletionDate: date, Size: 1024},
+		{Name: "docs", OriginalPath: "/home/user/docs", DeletionDate: date, IsDir: true},
+		{Name: "c.txt", OriginalPath: "/tmp/c.txt", DeletionDate: date, Size: 3},
+	}
+}
+
+func TestNewTrashDialog(t *testing.T) {
+	dialog := NewTrashDialog(testTrashItems())
+
+	if !dialog.IsActive() {
+		t.Error("expected dialog to be active")
+	}
+	if dialog.DisplayType() != DialogDisplayScreen {
+		t.Error("expected DialogDisplayScreen")
+	}
+
+	view := dialog.View()
+	for _, want := range []string{"Trash (3 items)", "a.txt", "/home/user", "<DIR>", "2024-05-01 10:20"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() should contain %q", want)
+		}
+	}
+}
+
+func TestTrashDialogEmpty(t *testing.T) {
+	dialog := NewTrashDialog(nil)
+
+	if !strings.Contains(dialog.View(), "Trash is empty") {
+		t.Error("empty dialog should show 'Trash is empty'")
+	}
+	if _, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("Enter on empty trash should do nothing")
+	}
+}
+
+func TestTrashDialogNavigation(t *testing.T) {
+	t.Run("j moves cursor down", func(t *testing.T) {
+		dialog := NewTrashDialog(testTrashItems())
+		dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+		if dialog.cursor != 1 {
+			t.Errorf("expected cursor at 1, got %d", dialog.cursor)
+		}
+	})
+
+	t.Run("k at first item wraps to last", func(t *testing.T) {
+		dialog := NewTrashDialog(testTrashItems())
+		dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+		if dialog.cursor != 2 {
+			t.Errorf("expected cursor to wrap to 2, got %d", dialog.cursor)
+		}
+	})
+
+	t.Run("offset follows cursor", func(t *testing.T) {
+		var items []trash.Item
+		for i := 0; i < trashDialogMaxVisible+5; i++ {
+			items = append(items, trash.Item{Name: "f", OriginalPath: "/f"})
+		}
+		dialog := NewTrashDialog(items)
+		for i := 0; i < trashDialogMaxVisible; i++ {
+			dialog.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		if dialog.offset != 1 {
+			t.Errorf("expected offset 1, got %d", dialog.offset)
+		}
+	})
+}
+
+func TestTrashDialogRestore(t *testing.T) {
+	dialog := NewTrashDialog(testTrashItems())
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if cmd == nil {
+		t.Fatal("expected restore command")
+	}
+	msg, ok := cmd().(trashRestoreMsg)
+	if !ok {
+		t.Fatal("expected trashRestoreMsg")
+	}
+	if msg.item.Name != "docs" {
+		t.Errorf("restored item = %q, want docs", msg.item.Name)
+	}
+	if !dialog.IsActive() {
+		t.Error("dialog should stay open after restore")
+	}
+}
+
+func TestTrashDialogDeleteRequiresConfirmation(t *testing.T) {
+	t.Run("y confirms", func(t *testing.T) {
+		dialog := NewTrashDialog(testTrashItems())
+		_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		if cmd != nil {
+			t.Fatal("d should ask for confirmation first")
+		}
+		if !strings.Contains(dialog.View(), "Delete permanently? (y/n)") {
+			t.Error("View() should show the confirmation prompt")
+		}
+
+		_, cmd = dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		if cmd == nil {
+			t.Fatal("expected remove command")
+		}
+		if msg, ok := cmd().(trashRemoveMsg); !ok || msg.item.Name != "a.txt" {
+			t.Errorf("expected trashRemoveMsg for a.txt, got %#v", cmd())
+		}
+	})
+
+	t.Run("other key cancels", func(t *testing.T) {
+		dialog := NewTrashDialog(testTrashItems())
+		dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+		if cmd != nil {
+			t.Error("n should cancel the deletion")
+		}
+		if dialog.confirm != "" {
+			t.Error("confirmation should be cleared")
+		}
+	})
+}
+
+func TestTrashDialogEmptyTrash(t *testing.T) {
+	dialog := NewTrashDialog(testTrashItems())
+	dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	if !strings.Contains(dialog.View(), "Permanently delete all 3 items?") {
+		t.Error("View() should show the empty trash prompt")
+	}
+
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if cmd == nil {
+		t.Fatal("expected empty command")
+	}
+	if _, ok := cmd().(trashEmptyMsg); !ok {
+		t.Error("expected trashEmptyMsg")
+	}
+	if dialog.IsActive() {
+		t.Error("dialog should close when emptying the trash")
+	}
+}
+
+func TestTrashDialogSetItems(t *testing.T) {
+	dialog := NewTrashDialog(testTrashItems())
+	dialog.cursor = 2
+
+	dialog.SetItems(testTrashItems()[:1])
+	if dialog.cursor != 0 {
+		t.Errorf("cursor should be clamped to 0, got %d", dialog.cursor)
+	}
+}
+
+func TestTrashDialogClose(t *testing.T) {
+	dialog := NewTrashDialog(testTrashItems())
+	_, cmd := dialog.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if dialog.IsActive() {
+		t.Error("expected dialog to be inactive after Esc")
+	}
+	if cmd == nil {
+		t.Fatal("expected close command")
+	}
+	if _, ok := cmd().(trashCloseMsg); !ok {
+		t.Error("expected trashCloseMsg")
+	}
+}
@@ -0,0 +1,314 @@
+// Package undo keeps a persistent journal of completed file operations so
+// they can be reverted (and re-applied) later.
+package undo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sakura/duofm/internal/config"
+	"github.com/sakura/duofm/internal/trash"
+)
+
+// DefaultMaxEntries is the number of undo entries kept in the journal
+const DefaultMaxEntries = 100
+
+// ErrNothingToUndo is returned by Undo when the journal is empty
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrNothingToRedo is returned by Redo when there is no undone entry
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// Kind is the type of a journaled operation
+type Kind string
+
+const (
+	KindRename Kind = "rename"
+	KindMove   Kind = "move"
+	KindCopy   Kind = "copy"
+	KindCreate Kind = "create"
+	KindTrash  Kind = "trash"
+)
+
+// Step is a single file affected by an operation
+type Step struct {
+	// Src is the path before the operation: the old name of a rename, the
+	// source of a move or copy, or the original path of a trashed file
+	Src string `json:"src,omitempty"`
+	// Dest is the path after the operation: the new name, the move or copy
+	// destination, the created path, or the file inside the trash
+	Dest string `json:"dest"`
+	// Info is the .trashinfo file of a trashed file
+	Info string `json:"info,omitempty"`
+	// IsDir marks created directories
+	IsDir bool `json:"is_dir,omitempty"`
+	// Replaced is where the file that Dest replaced was kept: a numbered
+	// backup, or the file inside the trash. It is put back on undo.
+	Replaced string `json:"replaced,omitempty"`
+	// ReplacedInfo is the .trashinfo file of a replaced file kept in the trash
+	ReplacedInfo string `json:"replaced_info,omitempty"`
+	// Overwritten marks a copy or move that deleted the file it replaced.
+	// Such steps cannot be undone.
+	Overwritten bool `json:"overwritten,omitempty"`
+}
+
+// Entry is one journaled operation. Batch operations have one step per file.
+type Entry struct {
+	Kind  Kind      `json:"kind"`
+	Time  time.Time `json:"time"`
+	Steps []Step    `json:"steps"`
+}
+
+// NewEntry creates an entry for an operation completed now
+func NewEntry(kind Kind, steps ...Step) Entry {
+	return Entry{Kind: kind, Time: time.Now(), Steps: steps}
+}
+
+// Description returns a short summary such as "rename a.txt -> b.txt"
+func (e Entry) Description() string {
+	if len(e.Steps) != 1 {
+		return fmt.Sprintf("%s %d files", e.Kind, len(e.Steps))
+	}
+	step := e.Steps[0]
+	switch e.Kind {
+	case KindRename:
+		return fmt.Sprintf("rename %s -> %s", filepath.Base(step.Src), filepath.Base(step.Dest))
+	case KindMove, KindCopy:
+		return fmt.Sprintf("%s %s -> %s", e.Kind, filepath.Base(step.Src), filepath.Dir(step.Dest))
+	case KindTrash:
+		return fmt.Sprintf("trash %s", filepath.Base(step.Src))
+	default:
+		return fmt.Sprintf("%s %s", e.Kind, filepath.Base(step.Dest))
+	}
+}
+
+// state is the on-disk format of the journal
+type state struct {
+	Undo []Entry `json:"undo"`
+	Redo []Entry `json:"redo"`
+}
+
+// Journal records completed operations and reverts them on request.
+// It is safe for concurrent use.
+type Journal struct {
+	mu         sync.Mutex
+	path       string // Journal file ("" keeps the journal in memory only)
+	trash      *trash.Trash
+	maxEntries int
+	undo       []Entry
+	redo       []Entry
+}
+
+// DefaultPath returns the journal file in the state directory (see config.StatePath)
+func DefaultPath() (string, error) {
+	return config.StatePath("undo.json")
+}
+
+// NewJournal creates an empty in-memory journal
+func NewJournal() *Journal {
+	return &Journal{maxEntries: DefaultMaxEntries}
+}
+
+// Open loads the journal stored at path. A missing file yields an empty
+// journal; changes are written back to path.
+func Open(path string) (*Journal, error) {
+	j := NewJournal()
+	j.path = path
+
+	s, err := readState(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return j, err
+	}
+	j.undo = s.Undo
+	j.redo = s.Redo
+	return j, nil
+}
+
+// readState reads the journal file at path
+func readState(path string) (state, error) {
+	var s state
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, err
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read undo journal: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("failed to parse undo journal: %w", err)
+	}
+	return s, nil
+}
+
+// SetTrash sets the trash used to discard copied and created files on undo
+// and to trash files again on redo
+func (j *Journal) SetTrash(t *trash.Trash) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.trash = t
+}
+
+// Record adds a completed operation and clears the redo history
+func (j *Journal) Record(e Entry) error {
+	if len(e.Steps) == 0 {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.reloadLocked()
+	j.undo = append(j.undo, e)
+	if len(j.undo) > j.maxEntries {
+		j.undo = j.undo[len(j.undo)-j.maxEntries:]
+	}
+	j.redo = nil
+	return j.saveLocked()
+}
+
+// Entries returns the undoable entries, oldest first
+func (j *Journal) Entries() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]Entry(nil), j.undo...)
+}
+
+// CanUndo reports whether there is an entry to undo
+func (j *Journal) CanUndo() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.undo) > 0
+}
+
+// CanRedo reports whether there is an undone entry to redo
+func (j *Journal) CanRedo() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.redo) > 0
+}
+
+// Undo reverts the most recent operation and moves it to the redo history.
+// All steps are checked before anything is changed; if a step still fails,
+// the steps not yet reverted remain in the journal.
+func (j *Journal) Undo() (Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.reloadLocked()
+
+	if len(j.undo) == 0 {
+		return Entry{}, ErrNothingToUndo
+	}
+	entry := j.undo[len(j.undo)-1]
+
+	if err := checkUndo(entry); err != nil {
+		return entry, err
+	}
+
+	// Revert in reverse order so nested paths are handled before their parents
+	for i := len(entry.Steps) - 1; i >= 0; i-- {
+		if err := j.undoStep(entry.Kind, entry.Steps[i]); err != nil {
+			remaining := entry
+			remaining.Steps = entry.Steps[:i+1]
+			j.undo[len(j.undo)-1] = remaining
+			j.saveLocked()
+			return entry, err
+		}
+	}
+
+	j.undo = j.undo[:len(j.undo)-1]
+	j.redo = append(j.redo, entry)
+	return entry, j.saveLocked()
+}
+
+// Redo re-applies the most recently undone operation
+func (j *Journal) Redo() (Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.reloadLocked()
+
+	if len(j.redo) == 0 {
+		return Entry{}, ErrNothingToRedo
+	}
+	entry := j.redo[len(j.redo)-1]
+
+	if err := checkRedo(entry); err != nil {
+		return entry, err
+	}
+
+	done := entry
+	done.Steps = nil
+	for i, step := range entry.Steps {
+		redone, err := j.redoStep(entry.Kind, step)
+		if err != nil {
+			// Steps already re-applied become undoable again
+			remaining := entry
+			remaining.Steps = entry.Steps[i:]
+			j.redo[len(j.redo)-1] = remaining
+			if len(done.Steps) > 0 {
+				j.undo = append(j.undo, done)
+			}
+			j.saveLocked()
+			return entry, err
+		}
+		done.Steps = append(done.Steps, redone)
+	}
+
+	j.redo = j.redo[:len(j.redo)-1]
+	j.undo = append(j.undo, done)
+	return done, j.saveLocked()
+}
+
+// reloadLocked replaces the entries with the journal on disk before a change,
+// so entries recorded or undone by other running instances are kept when
+// saving. A missing or unreadable file keeps the entries in memory.
+// j.mu must be held.
+func (j *Journal) reloadLocked() {
+	if j.path == "" {
+		return
+	}
+	s, err := readState(j.path)
+	if err != nil {
+		return
+	}
+	j.undo = s.Undo
+	j.redo = s.Redo
+}
+
+// saveLocked writes the journal atomically. j.mu must be held.
+func (j *Journal) saveLocked() error {
+	if j.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state{Undo: j.undo, Redo: j.redo}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode undo journal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return fmt.Errorf("failed to create undo journal directory: %w", err)
+	}
+
+	// A unique temporary file keeps other running instances from writing to it
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write undo journal: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), j.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write undo journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package undo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sakura/duofm/internal/trash"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertExists(t *testing.T, path string, want bool) {
+	t.Helper()
+	_, err := os.Lstat(path)
+	if got := err == nil; got != want {
+		t.Errorf("exists(%s) = %v, want %v", path, got, want)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Run("XDG_STATE_HOME", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/custom/state")
+		path, err := DefaultPath()
+		if err != nil {
+			t.Fatalf("DefaultPath() error = %v", err)
+		}
+		if path != "/custom/state/duofm/undo.json" {
+			t.Errorf("DefaultPath() = %q", path)
+		}
+	})
+
+	t.Run("fallback to ~/.local/state", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "")
+		t.Setenv("HOME", "/home/test")
+		path, err := DefaultPath()
+		if err != nil {
+			t.Fatalf("DefaultPath() error = %v", err)
+		}
+		if path != "/home/test/.local/state/duofm/undo.json" {
+			t.Errorf("DefaultPath() = %q", path)
+		}
+	})
+}
+
+func TestUndoRedoRename(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	writeFile(t, newPath, "data")
+
+	j := NewJournal()
+	j.Record(NewEntry(KindRename, Step{Src: oldPath, Dest: newPath}))
+
+	entry, err := j.Undo()
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if entry.Description() != "rename old.txt -> new.txt" {
+		t.Errorf("Description() = %q", entry.Description())
+	}
+	assertExists(t, oldPath, true)
+	assertExists(t, newPath, false)
+
+	if _, err := j.Redo(); err != nil {
+		t.Fatalf("Redo() error = %v", err)
+	}
+	assertExists(t, oldPath, false)
+	assertExists(t, newPath, true)
+	if !j.CanUndo() || j.CanRedo() {
+		t.Error("redone entry should be undoable again")
+	}
+}
+
+func TestUndoMoveBatch(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	var steps []Step
+	for _, name := range []string{"a.txt", "b.txt"} {
+		writeFile(t, filepath.Join(destDir, name), name)
+		steps = append(steps, Step{Src: filepath.Join(srcDir, name), Dest: filepath.Join(destDir, name)})
+	}
+
+	j := NewJournal()
+	j.Record(NewEntry(KindMove, steps...))
+	entry, err := j.Undo()
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if entry.Description() != "move 2 files" {
+		t.Errorf("Description() = %q", entry.Description())
+	}
+	for _, step := range steps {
+		assertExists(t, step.Src, true)
+		assertExists(t, step.Dest, false)
+	}
+}
+
+func TestUndoRefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	writeFile(t, newPath, "moved")
+	writeFile(t, oldPath, "someone else")
+
+	j := NewJournal()
+	j.Record(NewEntry(KindMove, Step{Src: oldPath, Dest: newPath}))
+
+	if _, err := j.Undo(); err == nil {
+		t.Fatal("Undo() should fail when the original path is occupied")
+	}
+	data, _ := os.ReadFile(oldPath)
+	if string(data) != "someone else" {
+		t.Error("existing file should not be overwritten")
+	}
+	if !j.CanUndo() {
+		t.Error("failed entry should stay in the journal")
+	}
+}
+
+func TestUndoCopyAndCreateUseTrash(t *testing.T) {
+	dir := t.TempDir()
+	tr := trash.NewWithHome(filepath.Join(t.TempDir(), "Trash"))
+	src := filepath.Join(dir, "src.txt")
+	copied := filepath.Join(dir, "copy", "src.txt")
+	created := filepath.Join(dir, "newdir")
+	writeFile(t, src, "data")
+	writeFile(t, copied, "data")
+	os.Mkdir(created, 0755)
+
+	j := NewJournal()
+	j.SetTrash(tr)
+	j.Record(NewEntry(KindCopy, Step{Src: src, Dest: copied}))
+	j.Record(NewEntry(KindCreate, Step{Dest: created, IsDir: true}))
+
+	// create
+	if _, err := j.Undo(); err != nil {
+		t.Fatalf("Undo(create) error = %v", err)
+	}
+	assertExists(t, created, false)
+
+	// copy
+	if _, err := j.Undo(); err != nil {
+		t.Fatalf("Undo(copy) error = %v", err)
+	}
+	assertExists(t, copied, false)
+	assertExists(t, src, true)
+
+	items, _ := tr.List()
+	if len(items) != 2 {
+		t.Errorf("undone copy and create should be in the trash, got %d items", len(items))
+	}
+
+	// Redo copy and create
+	if _, err := j.Redo(); err != nil {
+		t.Fatalf("Redo(copy) error = %v", err)
+	}
+	assertExists(t, copied, true)
+	if _, err := j.Redo(); err != nil {
+		t.Fatalf("Redo(create) error = %v", err)
+	}
+	if fi, err := os.Stat(created); err != nil || !fi.IsDir() {
+		t.Error("redo should recreate the directory")
+	}
+}
+
+func TestUndoRedoTrash(t *testing.T) {
+	tr := trash.NewWithHome(filepath.Join(t.TempDir(), "Trash"))
+	src := filepath.Join(t.TempDir(), "gone.txt")
+	writeFile(t, src, "data")
+	item, err := tr.Put(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := NewJournal()
+	j.SetTrash(tr)
+	j.Record(NewEntry(KindTrash, Step{Src: src, Dest: item.FilesPath(), Info: item.InfoPath()}))
+
+	if _, err := j.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	assertExists(t, src, true)
+	assertExists(t, item.InfoPath(), false)
+
+	redone, err := j.Redo()
+	if err != nil {
+		t.Fatalf("Redo() error = %v", err)
+	}
+	assertExists(t, src, false)
+	assertExists(t, redone.Steps[0].Dest, true)
+
+	// The re-trashed file can be restored again
+	if _, err := j.Undo(); err != nil {
+		t.Fatalf("second Undo() error = %v", err)
+	}
+	assertExists(t, src, true)
+}
+
+func TestUndoRedoReplacedFile(t *testing.T) {
+	readFile := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	t.Run("numbered backup", func(t *testing.T) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "a.txt")
+		dest := filepath.Join(dir, "out", "a.txt")
+		backup := dest + ".~1~"
+		writeFile(t, src, "new")
+		writeFile(t, dest, "new")
+		writeFile(t, backup, "old")
+
+		j := NewJournal()
+		j.Record(NewEntry(KindCopy, Step{Src: src, Dest: dest, Replaced: backup}))
+		if _, err := j.Undo(); err != nil {
+			t.Fatalf("Undo() error = %v", err)
+		}
+		if got := readFile(t, dest); got != "old" {
+			t.Errorf("undo should restore the backup, got %q", got)
+		}
+		assertExists(t, backup, false)
+
+		redone, err := j.Redo()
+		if err != nil {
+			t.Fatalf("Redo() error = %v", err)
+		}
+		if got := readFile(t, dest); got != "new" {
+			t.Errorf("redo should copy again, got %q", got)
+		}
+		if got := readFile(t, redone.Steps[0].Replaced); got != "old" {
+			t.Errorf("redo should back up the replaced file again, got %q", got)
+		}
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		dir := t.TempDir()
+		tr := trash.NewWithHome(filepath.Join(t.TempDir(), "Trash"))
+		src := filepath.Join(dir, "a.txt")
+		dest := filepath.Join(dir, "out", "a.txt")
+		writeFile(t, dest, "old")
+		item, err := tr.Put(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, dest, "new")
+
+		j := NewJournal()
+		j.SetTrash(tr)
+		j.Record(NewEntry(KindMove, Step{Src: src, Dest: dest, Replaced: item.FilesPath(), ReplacedInfo: item.InfoPath()}))
+		if _, err := j.Undo(); err != nil {
+			t.Fatalf("Undo() error = %v", err)
+		}
+		if got := readFile(t, src); got != "new" {
+			t.Errorf("undo should move the file back, got %q", got)
+		}
+		if got := readFile(t, dest); got != "old" {
+			t.Errorf("undo should restore the replaced file from the trash, got %q", got)
+		}
+		assertExists(t, item.InfoPath(), false)
+
+		redone, err := j.Redo()
+		if err != nil {
+			t.Fatalf("Redo() error = %v", err)
+		}
+		if got := readFile(t, dest); got != "new" {
+			t.Errorf("redo should move the file again, got %q", got)
+		}
+		if got := readFile(t, redone.Steps[0].Replaced); got != "old" {
+			t.Errorf("redo should trash the replaced file again, got %q", got)
+		}
+	})
+
+	t.Run("overwritten without a copy", func(t *testing.T) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "a.txt")
+		dest := filepath.Join(dir, "out", "a.txt")
+		writeFile(t, src, "new")
+		writeFile(t, dest, "new")
+
+		j := NewJournal()
+		j.Record(NewEntry(KindCopy, Step{Src: src, Dest: dest, Overwritten: true}))
+		if _, err := j.Undo(); err == nil {
+			t.Fatal("Undo() should refuse a step whose replaced file was not kept")
+		}
+		assertExists(t, dest, true)
+	})
+}
+
+func TestRecordClearsRedo(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a")
+	os.Mkdir(path, 0755)
+
+	j := NewJournal()
+	j.Record(NewEntry(KindCreate, Step{Dest: path, IsDir: true}))
+	j.Undo()
+	if !j.CanRedo() {
+		t.Fatal("undone entry should be redoable")
+	}
+
+	j.Record(NewEntry(KindCreate, Step{Dest: filepath.Join(dir, "b"), IsDir: true}))
+	if j.CanRedo() {
+		t.Error("Record should clear the redo history")
+	}
+}
+
+func TestNothingToUndoOrRedo(t *testing.T) {
+	j := NewJournal()
+	if _, err := j.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() error = %v, want ErrNothingToUndo", err)
+	}
+	if _, err := j.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Redo() error = %v, want ErrNothingToRedo", err)
+	}
+}
+
+func TestJournalPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "undo.json")
+	dir := t.TempDir()
+
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	j.Record(NewEntry(KindRename, Step{Src: filepath.Join(dir, "a"), Dest: filepath.Join(dir, "b")}))
+	j.Record(NewEntry(KindCreate, Step{Dest: filepath.Join(dir, "c")}))
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	entries := reopened.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries() length = %d, want 2", len(entries))
+	}
+	if entries[0].Kind != KindRename || entries[1].Kind != KindCreate {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestJournalSharedBetweenInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "undo.json")
+	dir := t.TempDir()
+
+	first, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	second, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	first.Record(NewEntry(KindCreate, Step{Dest: filepath.Join(dir, "a")}))
+	second.Record(NewEntry(KindCreate, Step{Dest: filepath.Join(dir, "b")}))
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	entries := reopened.Entries()
+	if len(entries) != 2 || entries[0].Steps[0].Dest != filepath.Join(dir, "a") || entries[1].Steps[0].Dest != filepath.Join(dir, "b") {
+		t.Errorf("entries of both instances should be kept, got %+v", entries)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(tmps) != 0 {
+		t.Errorf("temporary files left behind: %v", tmps)
+	}
+}
+
+func TestJournalMaxEntries(t *testing.T) {
+	j := NewJournal()
+	j.maxEntries = 3
+	for i := 0; i < 5; i++ {
+		j.Record(NewEntry(KindCreate, Step{Dest: filepath.Join("/tmp", string(rune('a'+i)))}))
+	}
+
+	entries := j.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("Entries() length = %d, want 3", len(entries))
+	}
+	if entries[0].Steps[0].Dest != "/tmp/c" {
+		t.Errorf("oldest entries should be dropped, first = %q", entries[0].Steps[0].Dest)
+	}
+}
+
+func TestOpenInvalidJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "undo.json")
+	os.WriteFile(path, []byte("not json"), 0600)
+
+	j, err := Open(path)
+	if err == nil {
+		t.Error("Open() should report a parse error")
+	}
+	if j == nil || j.CanUndo() {
+		t.Error("Open() should still return an empty journal")
+	}
+}
@@ -0,0 +1,178 @@
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/sakura/duofm/internal/fs"
+)
+
+// checkUndo verifies that every step of entry can be reverted
+func checkUndo(e Entry) error {
+	for _, step := range e.Steps {
+		if step.Overwritten {
+			return fmt.Errorf("cannot undo %s: the file replaced at %s was not kept", e.Kind, step.Dest)
+		}
+		if !exists(step.Dest) {
+			return fmt.Errorf("cannot undo %s: %s no longer exists", e.Kind, step.Dest)
+		}
+		if step.Replaced != "" && !exists(step.Replaced) {
+			return fmt.Errorf("cannot undo %s: %s no longer exists", e.Kind, step.Replaced)
+		}
+		switch e.Kind {
+		case KindRename, KindMove, KindTrash:
+			if exists(step.Src) {
+				return fmt.Errorf("cannot undo %s: %s already exists", e.Kind, step.Src)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRedo verifies that every step of entry can be re-applied
+func checkRedo(e Entry) error {
+	for _, step := range e.Steps {
+		if e.Kind != KindCreate && !exists(step.Src) {
+			return fmt.Errorf("cannot redo %s: %s no longer exists", e.Kind, step.Src)
+		}
+		// A replaced file is set aside again on redo
+		if e.Kind != KindTrash && step.Replaced == "" && exists(step.Dest) {
+			return fmt.Errorf("cannot redo %s: %s already exists", e.Kind, step.Dest)
+		}
+	}
+	return nil
+}
+
+// undoStep reverts a single step
+func (j *Journal) undoStep(kind Kind, step Step) error {
+	switch kind {
+	case KindRename, KindMove:
+		if err := movePath(step.Dest, step.Src); err != nil {
+			return err
+		}
+	case KindCopy, KindCreate:
+		if err := j.discard(step.Dest); err != nil {
+			return err
+		}
+	case KindTrash:
+		return restore(step.Dest, step.Src, step.Info)
+	default:
+		return fmt.Errorf("unknown operation: %s", kind)
+	}
+
+	if step.Replaced == "" {
+		return nil
+	}
+	return restore(step.Replaced, step.Dest, step.ReplacedInfo)
+}
+
+// redoStep re-applies a single step and returns it updated with new paths
+func (j *Journal) redoStep(kind Kind, step Step) (Step, error) {
+	if step.Replaced != "" {
+		var err error
+		if step, err = j.setAside(step); err != nil {
+			return step, err
+		}
+	}
+
+	switch kind {
+	case KindRename, KindMove:
+		return step, movePath(step.Src, step.Dest)
+	case KindCopy:
+		return step, fs.Copy(step.Src, step.Dest)
+	case KindCreate:
+		if step.IsDir {
+			return step, fs.CreateDirectory(step.Dest)
+		}
+		return step, fs.CreateFile(step.Dest)
+	case KindTrash:
+		if j.trash == nil {
+			return step, fmt.Errorf("trash is not available")
+		}
+		item, err := j.trash.Put(step.Src)
+		if err != nil {
+			return step, err
+		}
+		step.Dest = item.FilesPath()
+		step.Info = item.InfoPath()
+		return step, nil
+	default:
+		return step, fmt.Errorf("unknown operation: %s", kind)
+	}
+}
+
+// setAside keeps the file at step.Dest the way it was kept the first time,
+// in the trash or as a numbered backup, before the step is re-applied
+func (j *Journal) setAside(step Step) (Step, error) {
+	if !exists(step.Dest) {
+		step.Replaced, step.ReplacedInfo = "", ""
+		return step, nil
+	}
+	if step.ReplacedInfo == "" {
+		backup, err := fs.Backup(step.Dest)
+		if err != nil {
+			return step, err
+		}
+		step.Replaced = backup
+		return step, nil
+	}
+	if j.trash == nil {
+		return step, fmt.Errorf("trash is not available")
+	}
+	item, err := j.trash.Put(step.Dest)
+	if err != nil {
+		return step, err
+	}
+	step.Replaced = item.FilesPath()
+	step.ReplacedInfo = item.InfoPath()
+	return step, nil
+}
+
+// restore moves a trashed or backed up file at src back to dst and removes
+// its .trashinfo file (info is empty for backups)
+func restore(src, dst, info string) error {
+	if err := movePath(src, dst); err != nil {
+		return err
+	}
+	if info == "" {
+		return nil
+	}
+	if err := os.Remove(info); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove trash info: %w", err)
+	}
+	return nil
+}
+
+// discard removes a copied or created path, using the trash when available
+// so that undoing never destroys data
+func (j *Journal) discard(path string) error {
+	if j.trash != nil {
+		_, err := j.trash.Put(path)
+		return err
+	}
+	return fs.Delete(path)
+}
+
+// movePath moves src to dst, creating the parent of dst when needed
+func movePath(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, syscall.EXDEV) {
+		return fs.MoveFile(src, dst)
+	}
+	return fmt.Errorf("failed to move %s: %w", src, err)
+}
+
+// exists reports whether path exists without following symlinks
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -66,6 +66,7 @@ mkdir -p "${BUILD_DIR}/DEBIAN"
 mkdir -p "${BUILD_DIR}/usr/bin"
 mkdir -p "${BUILD_DIR}/usr/share/doc/${PROJECT_NAME}"
 mkdir -p "${BUILD_DIR}/usr/share/man/man1"
+mkdir -p "${BUILD_DIR}/usr/share/${PROJECT_NAME}/shell"
 
 # Build the binary
 echo "Building Go binary..."
@@ -100,6 +101,15 @@ elif [ -f "LICENCE" ]; then
     chmod 644 "${BUILD_DIR}/usr/share/doc/${PROJECT_NAME}/copyright"
 fi
 
+# Copy shell wrappers for cd-on-exit
+echo "Copying shell wrappers..."
+for script in scripts/shell/duofm.bash scripts/shell/duofm.zsh scripts/shell/duofm.fish; do
+    if [ -f "${script}" ]; then
+        cp "${script}" "${BUILD_DIR}/usr/share/${PROJECT_NAME}/shell/"
+        chmod 644 "${BUILD_DIR}/usr/share/${PROJECT_NAME}/shell/$(basename "${script}")"
+    fi
+done
+
 # Create changelog
 echo "Creating changelog..."
 cat > "${BUILD_DIR}/usr/share/doc/${PROJECT_NAME}/changelog" << EOF
@@ -0,0 +1,18 @@
+# duofm wrapper for bash: after quitting duofm, cd to the directory of the
+# active pane.
+#
+# Add to ~/.bashrc:
+#   source /path/to/duofm.bash
+
+duofm() {
+    local tmp dir ret
+    tmp="$(mktemp -t duofm-lastdir.XXXXXX)" || return
+    command duofm --last-dir-file "$tmp" "$@"
+    ret=$?
+    dir="$(cat -- "$tmp")"
+    rm -f -- "$tmp"
+    if [ -n "$dir" ] && [ -d "$dir" ] && [ "$dir" != "$PWD" ]; then
+        cd -- "$dir" || return
+    fi
+    return $ret
+}
@@ -0,0 +1,17 @@
+# duofm wrapper for fish: after quitting duofm, cd to the directory of the
+# active pane.
+#
+# Copy to ~/.config/fish/functions/duofm.fish, or add to config.fish:
+#   source /path/to/duofm.fish
+
+function duofm --wraps duofm --description 'duofm, changing to its last directory on exit'
+    set -l tmp (mktemp -t duofm-lastdir.XXXXXX); or return
+    command duofm --last-dir-file $tmp $argv
+    set -l ret $status
+    set -l dir (cat -- $tmp)
+    rm -f -- $tmp
+    if test -n "$dir" -a -d "$dir" -a "$dir" != "$PWD"
+        cd -- $dir
+    end
+    return $ret
+end
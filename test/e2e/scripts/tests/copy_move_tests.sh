@@ -1,8 +1,8 @@
 #!/bin/bash
 # Copy/Move Tests for duofm
 #
-# Description: Tests for copy and move operations including overwrite dialogs,
-#              conflict handling, and dialog navigation
+# Description: Tests for copy and move operations including the preflight
+#              conflict dialog, overwrite dialogs, and dialog navigation
 # Tests: 8
 
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
@@ -55,14 +55,18 @@ test_copy_overwrite_cancel() {
 
     # Press c to copy
     send_keys "$CURRENT_SESSION" "c"
+    sleep 0.3
+
+    # Accept the destination dialog (pre-filled with the opposite pane)
+    send_keys "$CURRENT_SESSION" "Enter"
     sleep 0.5
 
-    # Should show overwrite dialog (file already exists in canceldir)
-    assert_contains "$CURRENT_SESSION" "already exists" \
-        "Overwrite dialog appears for copy conflict"
+    # Should show the preflight dialog (file already exists in canceldir)
+    assert_contains "$CURRENT_SESSION" "Copy: 1 file already exists" \
+        "Preflight dialog appears for copy conflict"
 
-    # Press 2 to select Cancel
-    send_keys "$CURRENT_SESSION" "2"
+    # Press 4 to select Cancel
+    send_keys "$CURRENT_SESSION" "4"
     sleep 0.3
 
     # Dialog should close
@@ -136,13 +140,17 @@ test_copy_overwrite_confirm() {
 
     # Press c to copy
     send_keys "$CURRENT_SESSION" "c"
+    sleep 0.3
+
+    # Accept the destination dialog (pre-filled with the opposite pane)
+    send_keys "$CURRENT_SESSION" "Enter"
     sleep 0.5
 
-    # Should show overwrite dialog
-    assert_contains "$CURRENT_SESSION" "already exists" \
-        "Overwrite dialog appears for copy conflict"
+    # Should show the preflight dialog
+    assert_contains "$CURRENT_SESSION" "Copy: 1 file already exists" \
+        "Preflight dialog appears for copy conflict"
 
-    # Press 1 to Overwrite
+    # Press 1 to Overwrite all
     send_keys "$CURRENT_SESSION" "1"
     sleep 0.5
 
@@ -214,11 +222,22 @@ test_copy_overwrite_rename() {
 
     # Press c to copy
     send_keys "$CURRENT_SESSION" "c"
+    sleep 0.3
+
+    # Accept the destination dialog (pre-filled with the opposite pane)
+    send_keys "$CURRENT_SESSION" "Enter"
     sleep 0.5
 
-    # Should show overwrite dialog
-    assert_contains "$CURRENT_SESSION" "already exists" \
-        "Overwrite dialog appears for copy conflict"
+    # Should show the preflight dialog
+    assert_contains "$CURRENT_SESSION" "Copy: 1 file already exists" \
+        "Preflight dialog appears for copy conflict"
+
+    # Press 3 to Ask for each, which opens the overwrite dialog
+    send_keys "$CURRENT_SESSION" "3"
+    sleep 0.5
+
+    assert_contains "$CURRENT_SESSION" "File already exists" \
+        "Overwrite dialog appears after Ask for each"
 
     # Press 3 to Rename
     send_keys "$CURRENT_SESSION" "3"
@@ -309,13 +328,17 @@ test_move_overwrite() {
 
     # Press m to move
     send_keys "$CURRENT_SESSION" "m"
+    sleep 0.3
+
+    # Accept the destination dialog (pre-filled with the opposite pane)
+    send_keys "$CURRENT_SESSION" "Enter"
     sleep 0.5
 
-    # Should show overwrite dialog
-    assert_contains "$CURRENT_SESSION" "already exists" \
-        "Overwrite dialog appears for move conflict"
+    # Should show the preflight dialog
+    assert_contains "$CURRENT_SESSION" "Move: 1 file already exists" \
+        "Preflight dialog appears for move conflict"
 
-    # Press 1 to Overwrite
+    # Press 1 to Overwrite all
     send_keys "$CURRENT_SESSION" "1"
     sleep 0.5
 
@@ -396,9 +419,16 @@ test_directory_conflict_error() {
 
     # Press c to copy directory
     send_keys "$CURRENT_SESSION" "c"
+    sleep 0.3
+
+    # Accept the destination dialog (pre-filled with the opposite pane)
+    send_keys "$CURRENT_SESSION" "Enter"
     sleep 0.5
 
-    # Should show error dialog (directory conflict)
+    # Should show error dialog (directory conflict). An existing directory is
+    # not a preflight conflict, so the error comes without the preflight dialog
+    assert_not_contains "$CURRENT_SESSION" "Overwrite all" \
+        "No preflight dialog for a directory conflict"
     assert_contains "$CURRENT_SESSION" "already exists" \
         "Error dialog appears for directory conflict"
 
@@ -460,11 +490,31 @@ test_overwrite_dialog_navigation() {
 
     # Press c to copy
     send_keys "$CURRENT_SESSION" "c"
+    sleep 0.3
+
+    # Accept the destination dialog (pre-filled with the opposite pane)
+    send_keys "$CURRENT_SESSION" "Enter"
     sleep 0.5
 
-    # Should show overwrite dialog
-    assert_contains "$CURRENT_SESSION" "already exists" \
-        "Overwrite dialog appears"
+    # Should show the preflight dialog
+    assert_contains "$CURRENT_SESSION" "Copy: 1 file already exists" \
+        "Preflight dialog appears"
+
+    # Move between the choices with Tab and Shift+Tab
+    send_keys "$CURRENT_SESSION" "Tab"
+    sleep 0.2
+    send_keys "$CURRENT_SESSION" "BTab"
+    sleep 0.2
+
+    assert_contains "$CURRENT_SESSION" "Copy: 1 file already exists" \
+        "Preflight dialog navigation works without closing"
+
+    # Press 3 to Ask for each, which opens the overwrite dialog
+    send_keys "$CURRENT_SESSION" "3"
+    sleep 0.5
+
+    assert_contains "$CURRENT_SESSION" "File already exists" \
+        "Overwrite dialog appears after Ask for each"
 
     # Navigate with j (down)
     send_keys "$CURRENT_SESSION" "j"
@@ -540,11 +590,22 @@ test_rename_dialog_validation() {
 
     # Press c to copy
     send_keys "$CURRENT_SESSION" "c"
+    sleep 0.3
+
+    # Accept the destination dialog (pre-filled with the opposite pane)
+    send_keys "$CURRENT_SESSION" "Enter"
     sleep 0.5
 
-    # Should show overwrite dialog first
-    assert_contains "$CURRENT_SESSION" "already exists" \
-        "Overwrite dialog appears for copy conflict"
+    # Should show the preflight dialog first
+    assert_contains "$CURRENT_SESSION" "Copy: 1 file already exists" \
+        "Preflight dialog appears for copy conflict"
+
+    # Press 3 to Ask for each, which opens the overwrite dialog
+    send_keys "$CURRENT_SESSION" "3"
+    sleep 0.5
+
+    assert_contains "$CURRENT_SESSION" "File already exists" \
+        "Overwrite dialog appears after Ask for each"
 
     # Press 3 to Rename
     send_keys "$CURRENT_SESSION" "3"
@@ -621,6 +682,10 @@ test_copy_no_conflict() {
 
     # Press c to copy
     send_keys "$CURRENT_SESSION" "c"
+    sleep 0.3
+
+    # Accept the destination dialog (pre-filled with the opposite pane)
+    send_keys "$CURRENT_SESSION" "Enter"
     sleep 0.5
 
     # Should NOT show overwrite dialog (no conflict)